@@ -17,15 +17,140 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
 	// The set of controllers this controller process runs.
 	"knative.dev/net-gateway-api/pkg/reconciler/ingress"
 
-	// This defines the shared main for injected controllers.
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	cminformer "knative.dev/pkg/configmap/informer"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
 	"knative.dev/pkg/injection/sharedmain"
+	"knative.dev/pkg/leaderelection"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/profiling"
+	"knative.dev/pkg/signals"
+	"knative.dev/pkg/system"
 )
 
+const component = "net-gateway-api-controller"
+
+// main follows sharedmain.MainWithConfig, except that the profiling server
+// also serves the debug handlers of the Ingress controller. Like the profiles,
+// they are only served when profiling is enabled.
 func main() {
-	sharedmain.Main("net-gateway-api-controller",
-		ingress.NewController,
-	)
+	disableHighAvailability := flag.Bool("disable-ha", false,
+		"Whether to disable high-availability functionality for this component.")
+	cfg := injection.ParseAndGetRESTConfigOrDie()
+
+	ctx := signals.NewContext()
+	if *disableHighAvailability {
+		ctx = sharedmain.WithHADisabled(ctx)
+	}
+
+	metrics.MemStatsOrDie(ctx)
+	if cfg.QPS == 0 {
+		cfg.QPS = rest.DefaultQPS
+	}
+	if cfg.Burst == 0 {
+		cfg.Burst = rest.DefaultBurst
+	}
+
+	ctx, startInformers := injection.EnableInjectionOrDie(ctx, cfg)
+
+	logger, atomicLevel := sharedmain.SetupLoggerOrDie(ctx, component)
+	defer func() {
+		logger.Sync() //nolint:errcheck // nothing to do when it fails
+		metrics.FlushExporter()
+	}()
+	ctx = logging.WithLogger(ctx, logger)
+	rest.SetDefaultWarningHandler(&logging.WarningHandler{Logger: logger})
+
+	profilingHandler := profiling.NewHandler(logger, false)
+	debugMux := http.NewServeMux()
+	debugMux.Handle("/", profilingHandler)
+	ctx = ingress.WithDebugServeMux(ctx, debugMux)
+	var debugEnabled atomic.Bool
+	profilingServer := profiling.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !debugEnabled.Load() {
+			http.NotFound(w, r)
+			return
+		}
+		debugMux.ServeHTTP(w, r)
+	}))
+
+	sharedmain.CheckK8sClientMinimumVersionOrDie(ctx, logger)
+	cmw := sharedmain.SetupConfigMapWatchOrDie(ctx, logger)
+
+	leaderElectionConfig, err := sharedmain.GetLeaderElectionConfig(ctx)
+	if err != nil {
+		logger.Fatal("Error loading leader election configuration: ", err)
+	}
+	if !sharedmain.IsHADisabled(ctx) {
+		ctx = leaderelection.WithDynamicLeaderElectorBuilder(ctx, kubeclient.Get(ctx),
+			leaderElectionConfig.GetComponentConfig(component))
+	}
+
+	sharedmain.SetupObservabilityOrDie(ctx, component, logger, profilingHandler)
+
+	controllers, _ := sharedmain.ControllersAndWebhooksFromCtors(ctx, cmw, ingress.NewController)
+	sharedmain.WatchLoggingConfigOrDie(ctx, cmw, logger, atomicLevel, component)
+	watchObservabilityConfigOrDie(ctx, cmw, profilingHandler, &debugEnabled, logger)
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.Go(profilingServer.ListenAndServe)
+
+	logger.Info("Starting configuration manager...")
+	if err := cmw.Start(ctx.Done()); err != nil {
+		logger.Fatalw("Failed to start configuration manager", zap.Error(err))
+	}
+
+	startInformers()
+
+	logger.Info("Starting controllers...")
+	eg.Go(func() error {
+		return controller.StartAll(ctx, controllers...)
+	})
+	eg.Go(func() error {
+		return injection.ServeHealthProbes(ctx, injection.HealthCheckDefaultPort)
+	})
+
+	<-egCtx.Done()
+
+	profilingServer.Shutdown(context.Background()) //nolint:errcheck // shutting down anyway
+	if err := eg.Wait(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Errorw("Error while running server", zap.Error(err))
+	}
+}
+
+// watchObservabilityConfigOrDie follows sharedmain.WatchObservabilityConfigOrDie,
+// also enabling the debug handlers along with profiling.
+func watchObservabilityConfigOrDie(ctx context.Context, cmw *cminformer.InformedWatcher, profilingHandler *profiling.Handler, debugEnabled *atomic.Bool, logger *zap.SugaredLogger) {
+	if _, err := kubeclient.Get(ctx).CoreV1().ConfigMaps(system.Namespace()).Get(ctx, metrics.ConfigMapName(),
+		metav1.GetOptions{}); err == nil {
+		cmw.Watch(metrics.ConfigMapName(),
+			metrics.ConfigMapWatcher(ctx, component, sharedmain.SecretFetcher(ctx), logger),
+			profilingHandler.UpdateFromConfigMap,
+			func(cm *corev1.ConfigMap) {
+				// Like the profiling handler, keep the state when the flag is invalid
+				if enabled, err := profiling.ReadProfilingFlag(cm.Data); err == nil {
+					debugEnabled.Store(enabled)
+				}
+			})
+	} else if !apierrors.IsNotFound(err) {
+		logger.Fatalw("Error reading ConfigMap "+metrics.ConfigMapName(), zap.Error(err))
+	}
 }
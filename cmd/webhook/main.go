@@ -19,7 +19,11 @@ package main
 import (
 	"context"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
 	gatewayapiconfig "knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
+	gatewayapiwebhook "knative.dev/net-gateway-api/pkg/webhook"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/injection/sharedmain"
@@ -27,6 +31,8 @@ import (
 	"knative.dev/pkg/webhook"
 	"knative.dev/pkg/webhook/certificates"
 	"knative.dev/pkg/webhook/configmaps"
+	"knative.dev/pkg/webhook/resourcesemantics"
+	"knative.dev/pkg/webhook/resourcesemantics/validation"
 )
 
 func NewConfigValidationController(ctx context.Context, _ configmap.Watcher) *controller.Impl {
@@ -45,6 +51,33 @@ func NewConfigValidationController(ctx context.Context, _ configmap.Watcher) *co
 	)
 }
 
+func NewIngressValidationController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+	// The Ingresses are validated against the configured Gateways
+	store := gatewayapiconfig.NewStore(ctx)
+	store.WatchConfigs(cmw)
+
+	return validation.NewAdmissionController(ctx,
+
+		// Name of the resource webhook.
+		"validation.webhook.gateway-api.networking.internal.knative.dev",
+
+		// The path on which to serve the webhook.
+		"/resource-validation",
+
+		// The resources to validate.
+		map[schema.GroupVersionKind]resourcesemantics.GenericCRD{
+			v1alpha1.SchemeGroupVersion.WithKind("Ingress"): &gatewayapiwebhook.Ingress{},
+		},
+
+		// A function that infuses the context passed to Validate with the
+		// configuration.
+		store.ToContext,
+
+		// Whether to disallow unknown fields.
+		false,
+	)
+}
+
 func main() {
 	ctx := webhook.WithOptions(signals.NewContext(), webhook.Options{
 		ServiceName: "net-gateway-api-webhook",
@@ -57,5 +90,6 @@ func main() {
 		ctx, "net-gateway-api-webhook",
 		certificates.NewController,
 		NewConfigValidationController,
+		NewIngressValidationController,
 	)
 }
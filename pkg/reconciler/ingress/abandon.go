@@ -0,0 +1,133 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
+	"knative.dev/pkg/controller"
+	pkgreconciler "knative.dev/pkg/reconciler"
+
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/resources"
+)
+
+// leaderAwareReconciler is the generated Ingress reconciler.
+type leaderAwareReconciler interface {
+	controller.Reconciler
+	pkgreconciler.LeaderAware
+	IsLeaderFor(types.NamespacedName) bool
+}
+
+// configStore attaches the configuration to the reconciled contexts.
+type configStore interface {
+	ToContext(context.Context) context.Context
+}
+
+// classTransitionReconciler cleans up the resources of the Ingresses whose
+// class changed away from this controller, which the generated reconciler
+// skips, before delegating to it. The other Ingresses, including the ones
+// whose class changed to this controller and which it adopts, are reconciled
+// as usual.
+type classTransitionReconciler struct {
+	leaderAwareReconciler
+
+	reconciler    *Reconciler
+	ingressLister networkinglisters.IngressLister
+	configStore   configStore
+	recorder      record.EventRecorder
+}
+
+var _ leaderAwareReconciler = (*classTransitionReconciler)(nil)
+
+// Reconcile implements controller.Reconciler.
+func (r *classTransitionReconciler) Reconcile(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return r.leaderAwareReconciler.Reconcile(ctx, key)
+	}
+
+	ing, err := r.ingressLister.Ingresses(namespace).Get(name)
+	if err != nil || ing.GetAnnotations()[networking.IngressClassAnnotationKey] == gatewayAPIIngressClassName {
+		return r.leaderAwareReconciler.Reconcile(ctx, key)
+	}
+
+	if !r.IsLeaderFor(types.NamespacedName{Namespace: namespace, Name: name}) {
+		return nil
+	}
+	ctx = controller.WithEventRecorder(r.configStore.ToContext(ctx), r.recorder)
+	return r.reconciler.abandon(ctx, ing)
+}
+
+// abandon deletes the resources of an Ingress that is now reconciled by
+// another controller. Its status and finalizer are left to that controller.
+func (c *Reconciler) abandon(ctx context.Context, ing *v1alpha1.Ingress) error {
+	pluginConfig := config.FromContext(ctx).GatewayPlugin
+	c.probeFailures.reset(types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name})
+
+	routes, err := resources.OwnedHTTPRoutes(ctx, ing, c.httprouteLister)
+	if err != nil {
+		return err
+	}
+	tlsroutes, err := c.tlsrouteLister.TLSRoutes(ing.Namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	owned := len(routes) > 0
+	for _, tlsroute := range tlsroutes {
+		if !metav1.IsControlledBy(tlsroute, ing) {
+			continue
+		}
+		owned = true
+
+		err := c.gwapiclient.GatewayV1alpha2().TLSRoutes(tlsroute.Namespace).Delete(ctx, tlsroute.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete TLSRoute %s/%s: %w", tlsroute.Namespace, tlsroute.Name, err)
+		}
+	}
+	if !owned {
+		// Never reconciled by this controller, or already abandoned
+		return nil
+	}
+
+	if err := c.clearHTTPRoutes(ctx, ing, nil); err != nil {
+		return err
+	}
+	if gwName, ok := externalGatewayName(pluginConfig); ok {
+		if err := c.clearGatewayListeners(ctx, ing, gwName); err != nil {
+			return err
+		}
+	}
+	if err := c.clearReferenceGrants(ctx, ing); err != nil {
+		return err
+	}
+
+	controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeNormal, "Abandoned",
+		"Deleted the routes of the Ingress, its class is now %q", ing.GetAnnotations()[networking.IngressClassAnnotationKey])
+	return nil
+}
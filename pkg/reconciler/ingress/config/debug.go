@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/gateway-api/pkg/features"
+)
+
+// GatewaysDump is the effective configuration of the Gateways, as served by
+// the handler of the Store.
+type GatewaysDump struct {
+	ExternalGateways []GatewayDump
+	LocalGateways    []GatewayDump
+}
+
+// redactedValue replaces the values of the probe headers in the dumps, as
+// they may carry credentials.
+const redactedValue = "<redacted>"
+
+// GatewayDump is a Gateway with its sets sorted into lists, which read better
+// than the JSON objects of the sets, and the values of its probe headers
+// redacted.
+type GatewayDump struct {
+	Gateway
+	SupportedFeatures     []features.FeatureName
+	ProbeRetryStatusCodes []int
+	ProbeHeaders          map[string]string
+}
+
+func newGatewayDumps(gateways []Gateway) []GatewayDump {
+	dumps := make([]GatewayDump, 0, len(gateways))
+	for _, gw := range gateways {
+		var headers map[string]string
+		if gw.ProbeHeaders != nil {
+			headers = make(map[string]string, len(gw.ProbeHeaders))
+			for name := range gw.ProbeHeaders {
+				headers[name] = redactedValue
+			}
+		}
+		dumps = append(dumps, GatewayDump{
+			Gateway:               gw,
+			SupportedFeatures:     sets.List(gw.SupportedFeatures),
+			ProbeRetryStatusCodes: sets.List(gw.ProbeRetryStatusCodes),
+			ProbeHeaders:          headers,
+		})
+	}
+	return dumps
+}
+
+// GatewaysHandler returns a handler serving the Gateways of the configuration
+// currently loaded in the store as JSON, so that the result of parsing the
+// ConfigMap can be checked when troubleshooting it.
+func (s *Store) GatewaysHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		gpc, ok := s.UntypedLoad(GatewayConfigName).(*GatewayPlugin)
+		if !ok || gpc == nil {
+			http.Error(w, "the gateway configuration is not loaded", http.StatusServiceUnavailable)
+			return
+		}
+		b, err := json.Marshal(GatewaysDump{
+			ExternalGateways: newGatewayDumps(gpc.ExternalGateways),
+			LocalGateways:    newGatewayDumps(gpc.LocalGateways),
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b) //nolint:errcheck // the client is gone when it fails
+	})
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+func TestGatewaysHandler(t *testing.T) {
+	store := NewStore(logtesting.TestContextWithLogger(t))
+
+	rec := httptest.NewRecorder()
+	store.GatewaysHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status before loading = %d, want: %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: GatewayConfigName},
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        service: istio-system/istio-ingressgateway
+        supported-features: [HTTPRouteRequestTimeout, HTTPRouteRequestMirror]
+        probe-retry-status-codes: [503, 404]
+        probe-headers:
+          Authorization: Bearer secret`,
+			"local-gateways": `
+      - class: envoy
+        gateway: envoy-system/knative-local-gateway
+        probe-scheme: h2c`,
+		},
+	}
+	store.OnConfigChanged(cm)
+	cfg, err := FromConfigMap(cm)
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	rec = httptest.NewRecorder()
+	store.GatewaysHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want: %d", rec.Code, http.StatusOK)
+	}
+	if got, want := rec.Header().Get("Content-Type"), "application/json"; got != want {
+		t.Errorf("Content-Type = %q, want: %q", got, want)
+	}
+	// The sets are served as sorted lists
+	if body := rec.Body.String(); !strings.Contains(body, `"SupportedFeatures":["HTTPRouteRequestMirror","HTTPRouteRequestTimeout"]`) ||
+		!strings.Contains(body, `"ProbeRetryStatusCodes":[404,503]`) {
+		t.Error("Body doesn't list the sets:", body)
+	}
+	// The values of the probe headers may be credentials
+	if body := rec.Body.String(); strings.Contains(body, "secret") {
+		t.Error("Body doesn't redact the probe headers:", body)
+	}
+
+	var got GatewaysDump
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal("Failed to decode the body:", err)
+	}
+	want := GatewaysDump{
+		ExternalGateways: newGatewayDumps(cfg.ExternalGateways),
+		LocalGateways:    newGatewayDumps(cfg.LocalGateways),
+	}
+	for _, dumps := range [][]GatewayDump{want.ExternalGateways, want.LocalGateways} {
+		for i := range dumps {
+			// Only the lists of the sets are served
+			dumps[i].Gateway.SupportedFeatures = nil
+			dumps[i].Gateway.ProbeRetryStatusCodes = nil
+			dumps[i].Gateway.ProbeHeaders = nil
+		}
+	}
+	if got, want := got.ExternalGateways[0].ProbeHeaders["Authorization"], redactedValue; got != want {
+		t.Errorf("Authorization probe header = %q, want: %q", got, want)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("GatewaysHandler (-want, +got):", diff)
+	}
+}
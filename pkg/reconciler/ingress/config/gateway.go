@@ -19,14 +19,26 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
+	"golang.org/x/net/http/httpguts"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/networking/pkg/http/header"
 	"knative.dev/pkg/configmap"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
 	"sigs.k8s.io/gateway-api/pkg/features"
 	"sigs.k8s.io/yaml"
+
+	"knative.dev/net-gateway-api/pkg/status"
 )
 
 const (
@@ -35,6 +47,39 @@ const (
 
 	externalGatewaysKey = "external-gateways"
 	localGatewaysKey    = "local-gateways"
+	classDefaultsKey    = "class-defaults"
+	readyGracePeriodKey = "ready-grace-period"
+	resyncWindowKey     = "resync-window"
+
+	gatewayProgrammedTimeoutKey = "gateway-programmed-timeout"
+
+	probeInitialDelayKey = "probe-initial-delay"
+
+	probeRateLimitQPSKey     = "probe-rate-limit-qps"
+	probeRateLimitBurstKey   = "probe-rate-limit-burst"
+	probeBackoffBaseDelayKey = "probe-backoff-base-delay"
+	probeBackoffMaxDelayKey  = "probe-backoff-max-delay"
+	probeBackoffJitterKey    = "probe-backoff-jitter"
+
+	resiliencyPolicyTemplateKey = "resiliency-policy-template"
+
+	routeLabelsAllowlistKey = "route-labels-allowlist"
+	routeLabelsDenylistKey  = "route-labels-denylist"
+
+	manageReferenceGrantsKey = "manage-reference-grants"
+
+	routeNamespaceKey = "route-namespace"
+
+	maxHTTPRouteRulesKey = "max-http-route-rules"
+
+	deferRoutesUntilGatewayExistsKey = "defer-routes-until-gateway-exists"
+
+	endpointProbeNamespaceHeaderKey = "endpoint-probe-namespace-header"
+	endpointProbeRevisionHeaderKey  = "endpoint-probe-revision-header"
+
+	defaultHTTPOptionKey = "default-http-option"
+
+	httpsRedirectStatusCodeKey = "https-redirect-status-code"
 )
 
 func defaultExternalGateways() []Gateway {
@@ -75,6 +120,83 @@ func defaultLocalGateways() []Gateway {
 type GatewayPlugin struct {
 	ExternalGateways []Gateway
 	LocalGateways    []Gateway
+
+	// ReadyGracePeriod is how long an Ingress that was previously Ready keeps
+	// its LoadBalancerReady condition while its probes are failing.
+	ReadyGracePeriod time.Duration
+
+	// GatewayProgrammedTimeout is how long the Ingresses wait for a Gateway
+	// that isn't Programmed before failing. They wait indefinitely when zero.
+	GatewayProgrammedTimeout time.Duration
+
+	// ResyncWindow is the window over which the Ingresses are reconciled
+	// again when the configuration changes. They are all reconciled at once
+	// when zero.
+	ResyncWindow time.Duration
+
+	// ProbeInitialDelay is the delay before the first probes of a change of
+	// an Ingress. The prober default is used when nil.
+	ProbeInitialDelay *time.Duration
+
+	// ProbeRateLimiter are the parameters of the rate limiter of the probes.
+	// The prober is created once, so they are only read when the controller
+	// starts.
+	ProbeRateLimiter status.RateLimiterConfig
+
+	// ResiliencyPolicyTemplate is the gateway implementation specific policy
+	// object created for the routes of the Ingresses that request resiliency
+	// settings, and referenced from their rules with an ExtensionRef filter.
+	// When nil the settings requested by Ingresses are ignored.
+	ResiliencyPolicyTemplate *unstructured.Unstructured
+
+	// RouteLabelsAllowlist are the keys of the Ingress labels propagated to
+	// its routes. When empty all the labels are propagated.
+	RouteLabelsAllowlist sets.Set[string]
+	// RouteLabelsDenylist are the keys of the Ingress labels that are never
+	// propagated to its routes.
+	RouteLabelsDenylist sets.Set[string]
+
+	// ManageReferenceGrants is whether the ReferenceGrants allowing the
+	// external Gateway to use the TLS secrets of the Ingresses are created.
+	// When false they are expected to be managed by someone else.
+	ManageReferenceGrants bool
+
+	// RouteNamespace is the namespace of the HTTPRoutes of the Ingresses.
+	// When empty the HTTPRoutes are placed in the namespace of their Ingress.
+	RouteNamespace string
+
+	// MaxHTTPRouteRules is the maximum number of rules of the HTTPRoutes of
+	// the paths of a rule of an Ingress, which are split across several
+	// HTTPRoutes past it. The routes aren't split when zero.
+	MaxHTTPRouteRules int
+
+	// DeferRoutesUntilGatewayExists is whether the routes of the Ingresses
+	// are only created once their Gateways exist, for Gateway implementations
+	// that report routes without a parent noisily.
+	DeferRoutesUntilGatewayExists bool
+
+	// EndpointProbeHeaders are the names of the headers identifying the
+	// revision of the backends probed through dedicated rules.
+	EndpointProbeHeaders EndpointProbeHeaders
+
+	// DefaultHTTPOption is the HTTPOption of the Ingresses that leave it
+	// unset, e.g. so that their plain HTTP requests are redirected to HTTPS
+	// by default. Those Ingresses are served over plain HTTP when empty.
+	DefaultHTTPOption v1alpha1.HTTPOption
+
+	// HTTPSRedirectStatusCode is the status code of the redirects of the
+	// plain HTTP requests of the Ingresses to HTTPS, 301, 302, 307 or 308,
+	// e.g. 308 so that the clients keep the method and body of the requests.
+	// It is 301 when zero.
+	HTTPSRedirectStatusCode int
+}
+
+// EndpointProbeHeaders are the names of the headers set on the requests of
+// the endpoint probes so that the queue-proxy of the probed revision answers
+// them. Headers with an empty name aren't set.
+type EndpointProbeHeaders struct {
+	Namespace string
+	Revision  string
 }
 
 func (g *GatewayPlugin) ExternalGateway() Gateway {
@@ -85,6 +207,13 @@ func (g *GatewayPlugin) LocalGateway() Gateway {
 	return g.LocalGateways[0]
 }
 
+// HasLocalGateway returns whether a Gateway is configured for the cluster
+// local traffic. The cluster-local rules of the Ingresses aren't served
+// without one.
+func (g *GatewayPlugin) HasLocalGateway() bool {
+	return len(g.LocalGateways) > 0
+}
+
 // Note deepcopy gen is broken for sets.Set[features.SupportedFeatures]
 // So I've disabled the generator in this package for now
 type Gateway struct {
@@ -93,29 +222,286 @@ type Gateway struct {
 	Class             string
 	Service           *types.NamespacedName
 	SupportedFeatures sets.Set[features.FeatureName]
+
+	// SupportedFeaturesFromClass is whether the features advertised in the
+	// status of the GatewayClass of this Gateway are supported on top of the
+	// SupportedFeatures, for implementations reporting them.
+	SupportedFeaturesFromClass bool
+
+	// AllowedRoutesFrom is the policy for the namespaces of the routes that
+	// can attach to the listeners managed on this Gateway. When empty it
+	// defaults to Selector.
+	AllowedRoutesFrom gatewayapi.FromNamespaces
+	// AllowedRoutesSelector overrides the namespace selector used with the
+	// Selector policy. When nil only the Ingress namespace is selected.
+	AllowedRoutesSelector *metav1.LabelSelector
+
+	// ProbeHeaders are extra static headers sent with the probe requests
+	// through this Gateway, e.g. when it requires authentication.
+	ProbeHeaders map[string]string
+
+	// ProbeSampleSize caps the number of pods of the Gateway Service that are
+	// probed for each Ingress. All the pods are probed when zero.
+	ProbeSampleSize int
+
+	// ProbeRetryStatusCodes are the probe response status codes meaning that
+	// a route isn't ready yet through this Gateway. The prober defaults are
+	// used when empty.
+	ProbeRetryStatusCodes sets.Set[int]
+
+	// ProbeHTTP1Only is whether the probes through this Gateway are kept to
+	// HTTP/1.1, for Gateways that fail when HTTP/2 is negotiated with ALPN.
+	ProbeHTTP1Only bool
+
+	// ProbeUserAgent overrides the User-Agent of the probes through this
+	// Gateway, e.g. so that it can be allowlisted. The prober default is used
+	// when empty.
+	ProbeUserAgent string
+
+	// ProbeVersionHeader is the response header carrying the version of the
+	// routes probed through this Gateway, for Gateways that strip the
+	// K-Network-Hash header. The prober default is used when empty.
+	ProbeVersionHeader string
+
+	// ProbeNodePort is whether the probes of this Gateway go to the node port
+	// of its Service on the nodes of the cluster instead of to its pods, for
+	// Gateways that are only reachable through their node port.
+	ProbeNodePort bool
+
+	// ProbeScheme is the scheme of the probes through this Gateway, "http",
+	// "https" or "h2c", for Gateways whose port names don't tell it. It is
+	// inferred from the Ingress and the port names when empty.
+	ProbeScheme string
+
+	// ProbePort is the port of the probes to the address of this Gateway,
+	// for Gateways without a Service that don't listen on the ports 80 and
+	// 443. The port is inferred from the scheme of the probes when zero.
+	ProbePort int32
+
+	// ProbeResolveHostname is whether the hostname address of this Gateway,
+	// for Gateways without a Service, is resolved to its IP addresses before
+	// probing them, e.g. for the hostnames of cloud load balancers resolving
+	// to an address per zone. The hostname is probed when false.
+	ProbeResolveHostname bool
+
+	// ProbeClientCertificate is the TLS Secret with the client certificate
+	// presented by the probes through this Gateway, for Gateways requiring
+	// mutual TLS, e.g. behind a mesh sidecar. No certificate is presented
+	// when nil.
+	ProbeClientCertificate *types.NamespacedName
+
+	// ProbeVerifyTLS is whether the probes through this Gateway verify the
+	// certificates it presents, for Gateways fronted with valid certificates.
+	// They are verified against the CAs of ProbeCACertificate, or the roots
+	// of the system and the cluster CA when it is nil.
+	ProbeVerifyTLS bool
+
+	// ProbeCACertificate is the Secret with the CAs, under the "ca.crt" key,
+	// verifying the certificates of this Gateway when ProbeVerifyTLS is set.
+	ProbeCACertificate *types.NamespacedName
+
+	// RouteAnnotations are stamped onto the HTTPRoutes attached to this
+	// Gateway, for features its implementation reads from annotations.
+	RouteAnnotations map[string]string
+
+	// TLSOptions are set on the TLS configuration of the listeners managed
+	// on this Gateway for the Ingresses, for implementation specific settings
+	// such as the minimum TLS version.
+	TLSOptions map[string]string
+
+	// ZeroWeightBackends is how the backends of zero percent splits, e.g. of
+	// revisions being drained, are routed through this Gateway.
+	ZeroWeightBackends ZeroWeightPolicy
+
+	// WeightScale multiplies the percents of the splits into the weights of
+	// their backends, so that the small splits keep their share of the
+	// requests on Gateways rounding the weights. The percents are the weights
+	// when zero.
+	WeightScale int32
+
+	// SectionName and Port pin the HTTPRoutes to a listener of this Gateway.
+	// When nil the routes attach to all its compatible listeners.
+	SectionName *gatewayapi.SectionName
+	Port        *gatewayapi.PortNumber
+
+	// DenyFilter is the implementation specific filter, in the namespace of
+	// the routes, answering the requests of the denied paths of the Ingresses
+	// with a direct 404 response. When nil the denied paths have neither
+	// backends nor filters, leaving their response to the Gateway.
+	DenyFilter *gatewayapi.LocalObjectReference
+
+	// BackendGroup and BackendKind are the group and kind of the backends
+	// of the HTTPRoutes attached to this Gateway, for implementations
+	// routing to other backends than Services, e.g. their own resources
+	// named after the Services of the Ingresses. The backends are Services
+	// when BackendKind is empty.
+	BackendGroup gatewayapi.Group
+	BackendKind  gatewayapi.Kind
+
+	// AddressTypes is the preference order of the types of the addresses in
+	// the status of this Gateway, for Gateways reporting several addresses.
+	// The first address of the most preferred type is reported by the
+	// Ingresses and probed when there is no service, and the first address
+	// when none has one of the types or when empty.
+	AddressTypes []gatewayapi.AddressType
 }
 
+// ZeroWeightPolicy is how the backends of zero percent splits are routed.
+type ZeroWeightPolicy string
+
+const (
+	// ZeroWeightKeep keeps the backends with a zero weight. It is the
+	// default when no policy is set.
+	ZeroWeightKeep ZeroWeightPolicy = "Keep"
+	// ZeroWeightMinimal gives the backends the minimal positive weight, for
+	// Gateways that close the connections of backends with a zero weight.
+	// They get a small share of the requests.
+	ZeroWeightMinimal ZeroWeightPolicy = "Minimal"
+	// ZeroWeightDrop leaves the backends out of the route, unless all the
+	// splits of the path have zero percent.
+	ZeroWeightDrop ZeroWeightPolicy = "Drop"
+)
+
 // FromConfigMap creates a GatewayPlugin config from the supplied ConfigMap
 func FromConfigMap(cm *corev1.ConfigMap) (*GatewayPlugin, error) {
 	var (
 		err    error
-		config = &GatewayPlugin{}
+		config = &GatewayPlugin{
+			ProbeRateLimiter:      status.DefaultRateLimiterConfig(),
+			ManageReferenceGrants: true,
+			EndpointProbeHeaders: EndpointProbeHeaders{
+				Namespace: "K-Serving-Namespace",
+				Revision:  "K-Serving-Revision",
+			},
+		}
 	)
 
+	var defaults map[string]classDefaults
+	if data, ok := cm.Data[classDefaultsKey]; ok {
+		defaults, err = parseClassDefaults(data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %q: %w", classDefaultsKey, err)
+		}
+	}
+
 	if data, ok := cm.Data[externalGatewaysKey]; ok {
-		config.ExternalGateways, err = parseGatewayConfig(data)
+		config.ExternalGateways, err = parseGatewayConfig(data, defaults)
 		if err != nil {
 			return nil, fmt.Errorf("unable to parse %q: %w", externalGatewaysKey, err)
 		}
 	}
 
 	if data, ok := cm.Data[localGatewaysKey]; ok {
-		config.LocalGateways, err = parseGatewayConfig(data)
+		config.LocalGateways, err = parseGatewayConfig(data, defaults)
 		if err != nil {
 			return nil, fmt.Errorf("unable to parse %q: %w", localGatewaysKey, err)
 		}
 	}
 
+	if err := configmap.Parse(cm.Data,
+		configmap.AsDuration(readyGracePeriodKey, &config.ReadyGracePeriod),
+		configmap.AsDuration(gatewayProgrammedTimeoutKey, &config.GatewayProgrammedTimeout),
+		configmap.AsDuration(resyncWindowKey, &config.ResyncWindow),
+		configmap.AsFloat64(probeRateLimitQPSKey, &config.ProbeRateLimiter.QPS),
+		configmap.AsInt(probeRateLimitBurstKey, &config.ProbeRateLimiter.Burst),
+		configmap.AsDuration(probeBackoffBaseDelayKey, &config.ProbeRateLimiter.BaseDelay),
+		configmap.AsDuration(probeBackoffMaxDelayKey, &config.ProbeRateLimiter.MaxDelay),
+		configmap.AsFloat64(probeBackoffJitterKey, &config.ProbeRateLimiter.Jitter),
+		configmap.AsStringSet(routeLabelsAllowlistKey, &config.RouteLabelsAllowlist),
+		configmap.AsStringSet(routeLabelsDenylistKey, &config.RouteLabelsDenylist),
+		configmap.AsBool(manageReferenceGrantsKey, &config.ManageReferenceGrants),
+		configmap.AsString(routeNamespaceKey, &config.RouteNamespace),
+		configmap.AsInt(maxHTTPRouteRulesKey, &config.MaxHTTPRouteRules),
+		configmap.AsBool(deferRoutesUntilGatewayExistsKey, &config.DeferRoutesUntilGatewayExists),
+		configmap.AsString(endpointProbeNamespaceHeaderKey, &config.EndpointProbeHeaders.Namespace),
+		configmap.AsString(endpointProbeRevisionHeaderKey, &config.EndpointProbeHeaders.Revision),
+		configmap.AsString(defaultHTTPOptionKey, (*string)(&config.DefaultHTTPOption)),
+		configmap.AsInt(httpsRedirectStatusCodeKey, &config.HTTPSRedirectStatusCode),
+	); err != nil {
+		return nil, err
+	}
+
+	// Ignore the empty keys of empty or trailing comma separated lists
+	config.RouteLabelsAllowlist.Delete("")
+	config.RouteLabelsDenylist.Delete("")
+
+	if config.ReadyGracePeriod < 0 {
+		return nil, fmt.Errorf("%q must be non-negative, was: %v", readyGracePeriodKey, config.ReadyGracePeriod)
+	}
+	if config.GatewayProgrammedTimeout < 0 {
+		return nil, fmt.Errorf("%q must be non-negative, was: %v", gatewayProgrammedTimeoutKey, config.GatewayProgrammedTimeout)
+	}
+	// A path and its probe path are kept in the same HTTPRoute
+	if config.MaxHTTPRouteRules < 0 || config.MaxHTTPRouteRules == 1 {
+		return nil, fmt.Errorf("%q must be 0 or at least 2, was: %d", maxHTTPRouteRulesKey, config.MaxHTTPRouteRules)
+	}
+	if config.ResyncWindow < 0 {
+		return nil, fmt.Errorf("%q must be non-negative, was: %v", resyncWindowKey, config.ResyncWindow)
+	}
+
+	if err := validateProbeRateLimiter(config.ProbeRateLimiter); err != nil {
+		return nil, err
+	}
+
+	if data, ok := cm.Data[probeInitialDelayKey]; ok && strings.TrimSpace(data) != "" {
+		delay, err := time.ParseDuration(strings.TrimSpace(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q: %w", probeInitialDelayKey, err)
+		}
+		if delay < 0 {
+			return nil, fmt.Errorf("%q must be non-negative, was: %v", probeInitialDelayKey, delay)
+		}
+		config.ProbeInitialDelay = &delay
+	}
+
+	if data, ok := cm.Data[resiliencyPolicyTemplateKey]; ok && strings.TrimSpace(data) != "" {
+		config.ResiliencyPolicyTemplate, err = parseResiliencyPolicyTemplate(data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %q: %w", resiliencyPolicyTemplateKey, err)
+		}
+	}
+
+	probeHeaders := &config.EndpointProbeHeaders
+	probeHeaders.Namespace = strings.TrimSpace(probeHeaders.Namespace)
+	probeHeaders.Revision = strings.TrimSpace(probeHeaders.Revision)
+	for key, name := range map[string]string{
+		endpointProbeNamespaceHeaderKey: probeHeaders.Namespace,
+		endpointProbeRevisionHeaderKey:  probeHeaders.Revision,
+	} {
+		if errs := validation.IsHTTPHeaderName(name); name != "" && len(errs) > 0 {
+			return nil, fmt.Errorf("%q is not a valid header name: %s", key, strings.Join(errs, ", "))
+		}
+	}
+	if probeHeaders.Namespace != "" && strings.EqualFold(probeHeaders.Namespace, probeHeaders.Revision) {
+		return nil, fmt.Errorf("%q and %q must be different headers", endpointProbeNamespaceHeaderKey, endpointProbeRevisionHeaderKey)
+	}
+
+	config.DefaultHTTPOption = v1alpha1.HTTPOption(strings.TrimSpace(string(config.DefaultHTTPOption)))
+	switch config.DefaultHTTPOption {
+	case "", v1alpha1.HTTPOptionEnabled, v1alpha1.HTTPOptionRedirected:
+	default:
+		return nil, fmt.Errorf("%q must be %q or %q, was: %q", defaultHTTPOptionKey,
+			v1alpha1.HTTPOptionEnabled, v1alpha1.HTTPOptionRedirected, config.DefaultHTTPOption)
+	}
+
+	switch config.HTTPSRedirectStatusCode {
+	case 0, http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return nil, fmt.Errorf("%q must be 301, 302, 307 or 308, was: %d", httpsRedirectStatusCodeKey, config.HTTPSRedirectStatusCode)
+	}
+
+	config.RouteNamespace = strings.TrimSpace(config.RouteNamespace)
+	if config.RouteNamespace != "" {
+		if errs := validation.IsDNS1123Label(config.RouteNamespace); len(errs) > 0 {
+			return nil, fmt.Errorf("%q is not a valid namespace: %s", routeNamespaceKey, strings.Join(errs, ", "))
+		}
+		// The ExtensionRef filters referencing the policies are local
+		// references, which can't reach policies in the Ingress namespace.
+		if config.ResiliencyPolicyTemplate != nil {
+			return nil, fmt.Errorf("%q is not supported together with %q", routeNamespaceKey, resiliencyPolicyTemplateKey)
+		}
+	}
+
 	switch len(config.ExternalGateways) {
 	case 0:
 		config.ExternalGateways = defaultExternalGateways()
@@ -126,7 +512,10 @@ func FromConfigMap(cm *corev1.ConfigMap) (*GatewayPlugin, error) {
 
 	switch len(config.LocalGateways) {
 	case 0:
-		config.LocalGateways = defaultLocalGateways()
+		// An explicit empty list configures no local gateway
+		if config.LocalGateways == nil {
+			config.LocalGateways = defaultLocalGateways()
+		}
 	case 1:
 	default:
 		return nil, errors.New("only a single local gateway is supported")
@@ -136,24 +525,123 @@ func FromConfigMap(cm *corev1.ConfigMap) (*GatewayPlugin, error) {
 }
 
 type gatewayEntry struct {
-	Gateway           string                 `json:"gateway"`
-	Service           *string                `json:"service"`
+	Gateway            string                 `json:"gateway"`
+	Service            *string                `json:"service"`
+	Class              string                 `json:"class"`
+	SupportedFeatures  []features.FeatureName `json:"supported-features"`
+	FeaturesFromClass  bool                   `json:"supported-features-from-class"`
+	AllowedRoutes      *allowedRoutesEntry    `json:"allowed-routes"`
+	ProbeHeaders       map[string]string      `json:"probe-headers"`
+	ProbeSampleSize    int                    `json:"probe-sample-size"`
+	ProbeRetryCodes    []int                  `json:"probe-retry-status-codes"`
+	ProbeHTTP1Only     bool                   `json:"probe-http1-only"`
+	ProbeNodePort      bool                   `json:"probe-node-port"`
+	ProbeUserAgent     string                 `json:"probe-user-agent"`
+	ProbeVersionHeader string                 `json:"probe-version-header"`
+	ProbeScheme        string                 `json:"probe-scheme"`
+	ProbePort          int32                  `json:"probe-port"`
+	ProbeResolveHost   bool                   `json:"probe-resolve-hostname"`
+	ProbeClientCert    *string                `json:"probe-client-certificate"`
+	ProbeVerifyTLS     bool                   `json:"probe-verify-tls"`
+	ProbeCACert        *string                `json:"probe-ca-certificate"`
+	RouteAnnotations   map[string]string      `json:"route-annotations"`
+	TLSOptions         map[string]string      `json:"tls-options"`
+	ZeroWeight         ZeroWeightPolicy       `json:"zero-weight-backends"`
+	WeightScale        int32                  `json:"weight-scale"`
+	SectionName        *string                `json:"section-name"`
+	Port               *int32                 `json:"port"`
+	DenyFilter         *denyFilterEntry       `json:"deny-filter"`
+	BackendKind        *backendKindEntry      `json:"backend-kind"`
+	AddressTypes       []string               `json:"address-types"`
+}
+
+type denyFilterEntry struct {
+	Group string `json:"group"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+}
+
+type backendKindEntry struct {
+	Group string `json:"group"`
+	Kind  string `json:"kind"`
+}
+
+// classDefaults are the settings inherited by the gateway entries of a
+// GatewayClass that don't set them.
+type classDefaults struct {
 	Class             string                 `json:"class"`
 	SupportedFeatures []features.FeatureName `json:"supported-features"`
 }
 
-func parseGatewayConfig(data string) ([]Gateway, error) {
+type allowedRoutesEntry struct {
+	From     gatewayapi.FromNamespaces `json:"from"`
+	Selector *metav1.LabelSelector     `json:"selector"`
+}
+
+// maxTLSOptions and maxTLSOptionLength are the limits of the Gateway API on
+// the options of the TLS configuration of a listener.
+const (
+	maxTLSOptions      = 16
+	maxTLSOptionLength = 4096
+)
+
+// maxProbeUserAgentLength bounds the length of the User-Agent of the probes.
+const maxProbeUserAgentLength = 256
+
+// maxWeightScale keeps the weights of the backends within the maximum weight
+// of the Gateway API, 1000000, for percents up to 100.
+const maxWeightScale = 10000
+
+// reservedProbeHeaders are the headers the prober sets on probe requests.
+var reservedProbeHeaders = sets.New(
+	http.CanonicalHeaderKey(header.HashKey),
+	http.CanonicalHeaderKey(header.ProbeKey),
+	http.CanonicalHeaderKey(header.UserAgentKey),
+)
+
+// parseClassDefaults returns the defaults of the gateway entries by class.
+func parseClassDefaults(data string) (map[string]classDefaults, error) {
+	var entries []classDefaults
+
+	if err := yaml.Unmarshal([]byte(data), &entries); err != nil {
+		return nil, err
+	}
+
+	defaults := make(map[string]classDefaults, len(entries))
+	for i, entry := range entries {
+		if len(strings.TrimSpace(entry.Class)) == 0 {
+			return nil, fmt.Errorf(`entry [%d] field "class" is required`, i)
+		}
+		if _, ok := defaults[entry.Class]; ok {
+			return nil, fmt.Errorf(`entry [%d] field "class" has duplicate class %q`, i, entry.Class)
+		}
+		defaults[entry.Class] = entry
+	}
+	return defaults, nil
+}
+
+func parseGatewayConfig(data string, defaults map[string]classDefaults) ([]Gateway, error) {
 	var entries []gatewayEntry
 
 	if err := yaml.Unmarshal([]byte(data), &entries); err != nil {
 		return nil, err
 	}
+	if entries == nil {
+		return nil, nil
+	}
 
 	gws := make([]Gateway, 0, len(entries))
 	for i, entry := range entries {
+		// The entries without supported features inherit the ones of their
+		// class, an explicit empty list overrides them.
+		if entry.SupportedFeatures == nil {
+			entry.SupportedFeatures = defaults[entry.Class].SupportedFeatures
+		}
+
 		gw := Gateway{
-			Class:             entry.Class,
-			SupportedFeatures: sets.New(entry.SupportedFeatures...),
+			Class:                      entry.Class,
+			SupportedFeatures:          sets.New(entry.SupportedFeatures...),
+			SupportedFeaturesFromClass: entry.FeaturesFromClass,
 		}
 
 		names := map[string]string{
@@ -163,10 +651,18 @@ func parseGatewayConfig(data string) ([]Gateway, error) {
 		if entry.Service != nil {
 			names["service"] = *entry.Service
 		}
+		if entry.ProbeClientCert != nil {
+			names["probe-client-certificate"] = *entry.ProbeClientCert
+		}
+		if entry.ProbeCACert != nil {
+			names["probe-ca-certificate"] = *entry.ProbeCACert
+		}
 
 		err := configmap.Parse(names,
 			configmap.AsNamespacedName("gateway", &gw.NamespacedName),
 			configmap.AsOptionalNamespacedName("service", &gw.Service),
+			configmap.AsOptionalNamespacedName("probe-client-certificate", &gw.ProbeClientCertificate),
+			configmap.AsOptionalNamespacedName("probe-ca-certificate", &gw.ProbeCACertificate),
 		)
 		if err != nil {
 			return nil, err
@@ -175,8 +671,211 @@ func parseGatewayConfig(data string) ([]Gateway, error) {
 			return nil, fmt.Errorf(`entry [%d] field "class" is required`, i)
 		}
 
+		if ar := entry.AllowedRoutes; ar != nil {
+			switch ar.From {
+			case gatewayapi.NamespacesFromSelector:
+			case gatewayapi.NamespacesFromSame, gatewayapi.NamespacesFromAll:
+				if ar.Selector != nil {
+					return nil, fmt.Errorf(`entry [%d] field "allowed-routes.selector" requires "from: %s"`, i, gatewayapi.NamespacesFromSelector)
+				}
+			default:
+				return nil, fmt.Errorf(`entry [%d] field "allowed-routes.from" must be one of %s, %s or %s, was: %q`, i,
+					gatewayapi.NamespacesFromAll, gatewayapi.NamespacesFromSame, gatewayapi.NamespacesFromSelector, ar.From)
+			}
+			gw.AllowedRoutesFrom = ar.From
+			gw.AllowedRoutesSelector = ar.Selector
+		}
+
+		for name := range entry.ProbeHeaders {
+			if errs := validation.IsHTTPHeaderName(name); len(errs) > 0 {
+				return nil, fmt.Errorf(`entry [%d] field "probe-headers" has an invalid header name %q: %s`, i, name, strings.Join(errs, ", "))
+			}
+			if reservedProbeHeaders.Has(http.CanonicalHeaderKey(name)) {
+				return nil, fmt.Errorf(`entry [%d] field "probe-headers" must not set %q, it is set by the prober`, i, name)
+			}
+		}
+		gw.ProbeHeaders = entry.ProbeHeaders
+
+		if entry.ProbeSampleSize < 0 {
+			return nil, fmt.Errorf(`entry [%d] field "probe-sample-size" must be non-negative, was: %d`, i, entry.ProbeSampleSize)
+		}
+		gw.ProbeSampleSize = entry.ProbeSampleSize
+
+		for _, code := range entry.ProbeRetryCodes {
+			if code < 100 || code > 599 {
+				return nil, fmt.Errorf(`entry [%d] field "probe-retry-status-codes" must only have HTTP status codes, was: %d`, i, code)
+			}
+			if code == http.StatusOK {
+				return nil, fmt.Errorf(`entry [%d] field "probe-retry-status-codes" must not have %d`, i, code)
+			}
+		}
+		if len(entry.ProbeRetryCodes) > 0 {
+			gw.ProbeRetryStatusCodes = sets.New(entry.ProbeRetryCodes...)
+		}
+		gw.ProbeHTTP1Only = entry.ProbeHTTP1Only
+
+		if len(entry.ProbeUserAgent) > maxProbeUserAgentLength || !httpguts.ValidHeaderFieldValue(entry.ProbeUserAgent) {
+			return nil, fmt.Errorf(`entry [%d] field "probe-user-agent" must be a valid header value of at most %d characters, was: %q`,
+				i, maxProbeUserAgentLength, entry.ProbeUserAgent)
+		}
+		gw.ProbeUserAgent = strings.TrimSpace(entry.ProbeUserAgent)
+
+		if entry.ProbeVersionHeader != "" {
+			if errs := validation.IsHTTPHeaderName(entry.ProbeVersionHeader); len(errs) > 0 {
+				return nil, fmt.Errorf(`entry [%d] field "probe-version-header" has an invalid header name %q: %s`,
+					i, entry.ProbeVersionHeader, strings.Join(errs, ", "))
+			}
+		}
+		gw.ProbeVersionHeader = entry.ProbeVersionHeader
+
+		if entry.ProbeNodePort && gw.Service == nil {
+			return nil, fmt.Errorf(`entry [%d] field "probe-node-port" requires "service"`, i)
+		}
+		gw.ProbeNodePort = entry.ProbeNodePort
+
+		switch entry.ProbeScheme {
+		case "", "http", "https", "h2c":
+			gw.ProbeScheme = entry.ProbeScheme
+		default:
+			return nil, fmt.Errorf(`entry [%d] field "probe-scheme" must be "http", "https" or "h2c", was: %q`, i, entry.ProbeScheme)
+		}
+
+		if entry.ProbePort != 0 {
+			if gw.Service != nil {
+				return nil, fmt.Errorf(`entry [%d] field "probe-port" can't be set with "service"`, i)
+			}
+			if entry.ProbePort < 0 || entry.ProbePort > 65535 {
+				return nil, fmt.Errorf(`entry [%d] field "probe-port" must be a port number, was: %d`, i, entry.ProbePort)
+			}
+		}
+		gw.ProbePort = entry.ProbePort
+
+		if entry.ProbeResolveHost && gw.Service != nil {
+			return nil, fmt.Errorf(`entry [%d] field "probe-resolve-hostname" can't be set with "service"`, i)
+		}
+		gw.ProbeResolveHostname = entry.ProbeResolveHost
+
+		if gw.ProbeCACertificate != nil && !entry.ProbeVerifyTLS {
+			return nil, fmt.Errorf(`entry [%d] field "probe-ca-certificate" requires "probe-verify-tls"`, i)
+		}
+		gw.ProbeVerifyTLS = entry.ProbeVerifyTLS
+
+		for key := range entry.RouteAnnotations {
+			if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+				return nil, fmt.Errorf(`entry [%d] field "route-annotations" has an invalid key %q: %s`, i, key, strings.Join(errs, ", "))
+			}
+		}
+		gw.RouteAnnotations = entry.RouteAnnotations
+
+		if len(entry.TLSOptions) > maxTLSOptions {
+			return nil, fmt.Errorf(`entry [%d] field "tls-options" must have at most %d options, was: %d`, i, maxTLSOptions, len(entry.TLSOptions))
+		}
+		for key, value := range entry.TLSOptions {
+			if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+				return nil, fmt.Errorf(`entry [%d] field "tls-options" has an invalid key %q: %s`, i, key, strings.Join(errs, ", "))
+			}
+			if value == "" || len(value) > maxTLSOptionLength {
+				return nil, fmt.Errorf(`entry [%d] field "tls-options" must have values of 1 to %d characters, was: %q`, i, maxTLSOptionLength, value)
+			}
+		}
+		gw.TLSOptions = entry.TLSOptions
+
+		switch entry.ZeroWeight {
+		case "", ZeroWeightKeep, ZeroWeightMinimal, ZeroWeightDrop:
+			gw.ZeroWeightBackends = entry.ZeroWeight
+		default:
+			return nil, fmt.Errorf(`entry [%d] field "zero-weight-backends" must be one of %s, %s or %s, was: %q`, i,
+				ZeroWeightDrop, ZeroWeightKeep, ZeroWeightMinimal, entry.ZeroWeight)
+		}
+
+		if entry.WeightScale < 0 || entry.WeightScale > maxWeightScale {
+			return nil, fmt.Errorf(`entry [%d] field "weight-scale" must be between 0 and %d, was: %d`, i, maxWeightScale, entry.WeightScale)
+		}
+		gw.WeightScale = entry.WeightScale
+
+		if entry.SectionName != nil {
+			if errs := validation.IsDNS1123Subdomain(*entry.SectionName); len(errs) > 0 {
+				return nil, fmt.Errorf(`entry [%d] field "section-name" is invalid: %s`, i, strings.Join(errs, ", "))
+			}
+			gw.SectionName = ptr.To(gatewayapi.SectionName(*entry.SectionName))
+		}
+		if entry.Port != nil {
+			if errs := validation.IsValidPortNum(int(*entry.Port)); len(errs) > 0 {
+				return nil, fmt.Errorf(`entry [%d] field "port" is invalid: %s`, i, strings.Join(errs, ", "))
+			}
+			gw.Port = ptr.To(gatewayapi.PortNumber(*entry.Port))
+		}
+
+		if entry.DenyFilter != nil {
+			if entry.DenyFilter.Kind == "" || entry.DenyFilter.Name == "" {
+				return nil, fmt.Errorf(`entry [%d] field "deny-filter" must have a kind and a name`, i)
+			}
+			if errs := validation.IsDNS1123Subdomain(entry.DenyFilter.Name); len(errs) > 0 {
+				return nil, fmt.Errorf(`entry [%d] field "deny-filter" has an invalid name %q: %s`, i, entry.DenyFilter.Name, strings.Join(errs, ", "))
+			}
+			gw.DenyFilter = &gatewayapi.LocalObjectReference{
+				Group: gatewayapi.Group(entry.DenyFilter.Group),
+				Kind:  gatewayapi.Kind(entry.DenyFilter.Kind),
+				Name:  gatewayapi.ObjectName(entry.DenyFilter.Name),
+			}
+		}
+
+		if entry.BackendKind != nil {
+			if entry.BackendKind.Kind == "" {
+				return nil, fmt.Errorf(`entry [%d] field "backend-kind" must have a kind`, i)
+			}
+			gw.BackendGroup = gatewayapi.Group(entry.BackendKind.Group)
+			gw.BackendKind = gatewayapi.Kind(entry.BackendKind.Kind)
+		}
+
+		for _, addrType := range entry.AddressTypes {
+			switch gatewayapi.AddressType(addrType) {
+			case gatewayapi.IPAddressType, gatewayapi.HostnameAddressType, gatewayapi.NamedAddressType:
+			default:
+				// Implementation specific types are prefixed with a domain
+				if !strings.Contains(addrType, "/") {
+					return nil, fmt.Errorf(`entry [%d] field "address-types" must have types %s, %s, %s or domain-prefixed ones, was: %q`,
+						i, gatewayapi.IPAddressType, gatewayapi.HostnameAddressType, gatewayapi.NamedAddressType, addrType)
+				}
+			}
+			gw.AddressTypes = append(gw.AddressTypes, gatewayapi.AddressType(addrType))
+		}
+
 		gws = append(gws, gw)
 	}
 
 	return gws, nil
 }
+
+func parseResiliencyPolicyTemplate(data string) (*unstructured.Unstructured, error) {
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(data), &obj); err != nil {
+		return nil, err
+	}
+
+	template := &unstructured.Unstructured{Object: obj}
+	gvk := template.GroupVersionKind()
+	if gvk.Group == "" || gvk.Version == "" || gvk.Kind == "" {
+		return nil, fmt.Errorf("template must have a group qualified apiVersion and a kind, was: %q, %q", template.GetAPIVersion(), template.GetKind())
+	}
+	if _, ok := obj["metadata"]; ok {
+		return nil, errors.New("template must not have metadata, it is set for each route")
+	}
+	return template, nil
+}
+
+func validateProbeRateLimiter(rl status.RateLimiterConfig) error {
+	switch {
+	case rl.QPS <= 0:
+		return fmt.Errorf("%q must be positive, was: %v", probeRateLimitQPSKey, rl.QPS)
+	case rl.Burst <= 0:
+		return fmt.Errorf("%q must be positive, was: %d", probeRateLimitBurstKey, rl.Burst)
+	case rl.BaseDelay <= 0:
+		return fmt.Errorf("%q must be positive, was: %v", probeBackoffBaseDelayKey, rl.BaseDelay)
+	case rl.MaxDelay < rl.BaseDelay:
+		return fmt.Errorf("%q must be at least %q, was: %v", probeBackoffMaxDelayKey, probeBackoffBaseDelayKey, rl.MaxDelay)
+	case rl.Jitter < 0 || rl.Jitter > 1:
+		return fmt.Errorf("%q must be between 0 and 1, was: %v", probeBackoffJitterKey, rl.Jitter)
+	}
+	return nil
+}
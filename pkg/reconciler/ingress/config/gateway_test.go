@@ -19,9 +19,20 @@ package config
 import (
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
 	. "knative.dev/pkg/configmap/testing"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+	"sigs.k8s.io/gateway-api/pkg/features"
+
+	"knative.dev/net-gateway-api/pkg/status"
 )
 
 func TestFromConfigMap(t *testing.T) {
@@ -119,6 +130,400 @@ func TestFromConfigMapErrors(t *testing.T) {
 			"local-gateways": `[{"class": "class", "gateway": "ns/n", "service":"name"}]`,
 		},
 		want: `unable to parse "local-gateways"`,
+	}, {
+		name: "bad probe-client-certificate entry",
+		data: map[string]string{
+			"local-gateways": `[{"class": "class", "gateway": "ns/n", "probe-client-certificate":"name"}]`,
+		},
+		want: `unable to parse "local-gateways": failed to parse "probe-client-certificate"`,
+	}, {
+		name: "probe-ca-certificate without probe-verify-tls",
+		data: map[string]string{
+			"local-gateways": `[{"class": "class", "gateway": "ns/n", "probe-ca-certificate":"ns/ca"}]`,
+		},
+		want: `unable to parse "local-gateways": entry [0] field "probe-ca-certificate" requires "probe-verify-tls"`,
+	}, {
+		name: "bad allowed-routes from",
+		data: map[string]string{
+			"external-gateways": `[{"class": "class", "gateway": "ns/n", "allowed-routes": {"from": "Nowhere"}}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "allowed-routes.from" must be one of All, Same or Selector, was: "Nowhere"`,
+	}, {
+		name: "allowed-routes selector without Selector",
+		data: map[string]string{
+			"external-gateways": `[{"class": "class", "gateway": "ns/n", "allowed-routes": {"from": "All", "selector": {"matchLabels": {"a": "b"}}}}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "allowed-routes.selector" requires "from: Selector"`,
+	}, {
+		name: "invalid probe header name",
+		data: map[string]string{
+			"external-gateways": `[{"class": "class", "gateway": "ns/n", "probe-headers": {"X Auth": "secret"}}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "probe-headers" has an invalid header name "X Auth"`,
+	}, {
+		name: "reserved probe header",
+		data: map[string]string{
+			"local-gateways": `[{"class": "class", "gateway": "ns/n", "probe-headers": {"k-network-probe": "foo"}}]`,
+		},
+		want: `unable to parse "local-gateways": entry [0] field "probe-headers" must not set "k-network-probe", it is set by the prober`,
+	}, {
+		name: "bad zero-weight-backends",
+		data: map[string]string{
+			"external-gateways": `[{"class": "class", "gateway": "ns/n", "zero-weight-backends": "Remove"}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "zero-weight-backends" must be one of Drop, Keep or Minimal, was: "Remove"`,
+	}, {
+		name: "resiliency-policy-template without kind",
+		data: map[string]string{
+			"resiliency-policy-template": `apiVersion: policy.example.com/v1`,
+		},
+		want: `unable to parse "resiliency-policy-template": template must have a group qualified apiVersion and a kind, was: "policy.example.com/v1", ""`,
+	}, {
+		name: "resiliency-policy-template with metadata",
+		data: map[string]string{
+			"resiliency-policy-template": "apiVersion: policy.example.com/v1\nkind: RetryBudget\nmetadata:\n  name: foo",
+		},
+		want: `unable to parse "resiliency-policy-template": template must not have metadata, it is set for each route`,
+	}, {
+		name: "bad ready-grace-period",
+		data: map[string]string{
+			"ready-grace-period": "soon",
+		},
+		want: `failed to parse "ready-grace-period"`,
+	}, {
+		name: "negative ready-grace-period",
+		data: map[string]string{
+			"ready-grace-period": "-1s",
+		},
+		want: `"ready-grace-period" must be non-negative`,
+	}, {
+		name: "negative gateway-programmed-timeout",
+		data: map[string]string{
+			"gateway-programmed-timeout": "-1s",
+		},
+		want: `"gateway-programmed-timeout" must be non-negative`,
+	}, {
+		name: "negative resync-window",
+		data: map[string]string{
+			"resync-window": "-1m",
+		},
+		want: `"resync-window" must be non-negative`,
+	}, {
+		name: "bad probe-initial-delay",
+		data: map[string]string{
+			"probe-initial-delay": "soon",
+		},
+		want: `failed to parse "probe-initial-delay"`,
+	}, {
+		name: "negative probe-initial-delay",
+		data: map[string]string{
+			"probe-initial-delay": "-1s",
+		},
+		want: `"probe-initial-delay" must be non-negative`,
+	}, {
+		name: "negative probe-sample-size",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"probe-sample-size": -1
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "probe-sample-size" must be non-negative, was: -1`,
+	}, {
+		name: "invalid probe-user-agent",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"probe-user-agent": "prober\nX-Injected: true"
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "probe-user-agent" must be a valid header value of at most 256 characters, was: "prober\nX-Injected: true"`,
+	}, {
+		name: "invalid probe-version-header",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"probe-version-header": "X Version"
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "probe-version-header" has an invalid header name "X Version": a valid HTTP header must consist of alphanumeric characters or '-' (e.g. 'X-Header-Name', regex used for validation is '[-A-Za-z0-9]+')`,
+	}, {
+		name: "probe-node-port without service",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"probe-node-port": true
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "probe-node-port" requires "service"`,
+	}, {
+		name: "invalid probe-scheme",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"probe-scheme": "ftp"
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "probe-scheme" must be "http", "https" or "h2c", was: "ftp"`,
+	}, {
+		name: "probe-port with service",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"service": "ns/svc",
+					"probe-port": 8080
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "probe-port" can't be set with "service"`,
+	}, {
+		name: "invalid probe-port",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"probe-port": 70000
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "probe-port" must be a port number, was: 70000`,
+	}, {
+		name: "probe-resolve-hostname with service",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"service": "ns/svc",
+					"probe-resolve-hostname": true
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "probe-resolve-hostname" can't be set with "service"`,
+	}, {
+		name: "invalid probe-retry-status-codes",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"probe-retry-status-codes": [503, 42]
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "probe-retry-status-codes" must only have HTTP status codes, was: 42`,
+	}, {
+		name: "probe-retry-status-codes with 200",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"probe-retry-status-codes": [200]
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "probe-retry-status-codes" must not have 200`,
+	}, {
+		name: "invalid route-annotations key",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"route-annotations": {"not/a/key": "value"}
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "route-annotations" has an invalid key "not/a/key": `,
+	}, {
+		name: "invalid section-name",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"section-name": "Not_Valid"
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "section-name" is invalid: `,
+	}, {
+		name: "invalid port",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"port": 0
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "port" is invalid: `,
+	}, {
+		name: "deny-filter without a name",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"deny-filter": {"kind": "HTTPRouteFilter"}
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "deny-filter" must have a kind and a name`,
+	}, {
+		name: "invalid address-types",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"address-types": ["IPAddress", "Internal"]
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "address-types" must have types IPAddress, Hostname, NamedAddress or domain-prefixed ones, was: "Internal"`,
+	}, {
+		name: "invalid deny-filter name",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"deny-filter": {"kind": "HTTPRouteFilter", "name": "Not_Valid"}
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "deny-filter" has an invalid name "Not_Valid": `,
+	}, {
+		name: "backend-kind without a kind",
+		data: map[string]string{
+			"external-gateways": `[{
+					"class":"boo",
+					"gateway": "ns/n",
+					"backend-kind": {"group": "gateway.envoyproxy.io"}
+				}]`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "backend-kind" must have a kind`,
+	}, {
+		name: "bad probe-rate-limit-qps",
+		data: map[string]string{
+			"probe-rate-limit-qps": "fast",
+		},
+		want: `failed to parse "probe-rate-limit-qps"`,
+	}, {
+		name: "zero probe-rate-limit-qps",
+		data: map[string]string{
+			"probe-rate-limit-qps": "0",
+		},
+		want: `"probe-rate-limit-qps" must be positive`,
+	}, {
+		name: "negative probe-rate-limit-burst",
+		data: map[string]string{
+			"probe-rate-limit-burst": "-1",
+		},
+		want: `"probe-rate-limit-burst" must be positive`,
+	}, {
+		name: "zero probe-backoff-base-delay",
+		data: map[string]string{
+			"probe-backoff-base-delay": "0s",
+		},
+		want: `"probe-backoff-base-delay" must be positive`,
+	}, {
+		name: "probe-backoff-max-delay below the base delay",
+		data: map[string]string{
+			"probe-backoff-base-delay": "1s",
+			"probe-backoff-max-delay":  "500ms",
+		},
+		want: `"probe-backoff-max-delay" must be at least "probe-backoff-base-delay"`,
+	}, {
+		name: "probe-backoff-jitter above 1",
+		data: map[string]string{
+			"probe-backoff-jitter": "1.5",
+		},
+		want: `"probe-backoff-jitter" must be between 0 and 1`,
+	}, {
+		name: "invalid route-namespace",
+		data: map[string]string{
+			"route-namespace": "Not_Valid",
+		},
+		want: `"route-namespace" is not a valid namespace: `,
+	}, {
+		name: "route-namespace with resiliency-policy-template",
+		data: map[string]string{
+			"route-namespace":            "gateways",
+			"resiliency-policy-template": "apiVersion: policy.example.com/v1\nkind: RetryBudget",
+		},
+		want: `"route-namespace" is not supported together with "resiliency-policy-template"`,
+	}, {
+		name: "invalid endpoint-probe-revision-header",
+		data: map[string]string{
+			"endpoint-probe-revision-header": "X Revision",
+		},
+		want: `"endpoint-probe-revision-header" is not a valid header name: `,
+	}, {
+		name: "same endpoint probe headers",
+		data: map[string]string{
+			"endpoint-probe-namespace-header": "X-Revision",
+			"endpoint-probe-revision-header":  "x-revision",
+		},
+		want: `"endpoint-probe-namespace-header" and "endpoint-probe-revision-header" must be different headers`,
+	}, {
+		name: "invalid default-http-option",
+		data: map[string]string{
+			"default-http-option": "Disabled",
+		},
+		want: `"default-http-option" must be "Enabled" or "Redirected", was: "Disabled"`,
+	}, {
+		name: "invalid https-redirect-status-code",
+		data: map[string]string{
+			"https-redirect-status-code": "303",
+		},
+		want: `"https-redirect-status-code" must be 301, 302, 307 or 308, was: 303`,
+	}, {
+		name: "max-http-route-rules of 1",
+		data: map[string]string{
+			"max-http-route-rules": "1",
+		},
+		want: `"max-http-route-rules" must be 0 or at least 2, was: 1`,
+	}, {
+		name: "tls-options invalid key",
+		data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        tls-options:
+          "not a key": "1.2"`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "tls-options" has an invalid key "not a key": `,
+	}, {
+		name: "tls-options empty value",
+		data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        tls-options:
+          example.com/min-tls-version: ""`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "tls-options" must have values of 1 to 4096 characters, was: ""`,
+	}, {
+		name: "weight-scale too large",
+		data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        weight-scale: 100000`,
+		},
+		want: `unable to parse "external-gateways": entry [0] field "weight-scale" must be between 0 and 10000, was: 100000`,
+	}, {
+		name: "class-defaults bad yaml",
+		data: map[string]string{
+			"class-defaults": `{`,
+		},
+		want: `unable to parse "class-defaults"`,
+	}, {
+		name: "class-defaults without class",
+		data: map[string]string{
+			"class-defaults": `
+      - supported-features:
+        - HTTPRouteRequestTimeout`,
+		},
+		want: `unable to parse "class-defaults": entry [0] field "class" is required`,
+	}, {
+		name: "class-defaults duplicate class",
+		data: map[string]string{
+			"class-defaults": `
+      - class: istio
+      - class: istio`,
+		},
+		want: `unable to parse "class-defaults": entry [1] field "class" has duplicate class "istio"`,
 	}}
 
 	for _, tc := range cases {
@@ -150,3 +555,864 @@ func TestGatewayNoService(t *testing.T) {
 		t.Errorf("FromConfigMap(noService) = %v", err)
 	}
 }
+
+func TestReadyGracePeriod(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"ready-grace-period": "30s",
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	if got, want := cfg.ReadyGracePeriod, 30*time.Second; got != want {
+		t.Errorf("ReadyGracePeriod = %v, want %v", got, want)
+	}
+}
+
+func TestGatewayProgrammedTimeout(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"gateway-programmed-timeout": "10m",
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	if got, want := cfg.GatewayProgrammedTimeout, 10*time.Minute; got != want {
+		t.Errorf("GatewayProgrammedTimeout = %v, want %v", got, want)
+	}
+}
+
+func TestResyncWindow(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"resync-window": "5m",
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	if got, want := cfg.ResyncWindow, 5*time.Minute; got != want {
+		t.Errorf("ResyncWindow = %v, want %v", got, want)
+	}
+}
+
+func TestProbeInitialDelay(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	if cfg.ProbeInitialDelay != nil {
+		t.Errorf("ProbeInitialDelay = %v, want nil by default", *cfg.ProbeInitialDelay)
+	}
+
+	cfg, err = FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"probe-initial-delay": "1s",
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	if cfg.ProbeInitialDelay == nil || *cfg.ProbeInitialDelay != time.Second {
+		t.Errorf("ProbeInitialDelay = %v, want %v", cfg.ProbeInitialDelay, time.Second)
+	}
+}
+
+func TestProbeRateLimiter(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	if diff := cmp.Diff(status.DefaultRateLimiterConfig(), cfg.ProbeRateLimiter); diff != "" {
+		t.Error("ProbeRateLimiter (-want, +got):", diff)
+	}
+
+	cfg, err = FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"probe-rate-limit-qps":     "500",
+			"probe-rate-limit-burst":   "1000",
+			"probe-backoff-base-delay": "100ms",
+			"probe-backoff-max-delay":  "1m",
+			"probe-backoff-jitter":     "0.2",
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	want := status.RateLimiterConfig{
+		QPS:       500,
+		Burst:     1000,
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  time.Minute,
+		Jitter:    0.2,
+	}
+	if diff := cmp.Diff(want, cfg.ProbeRateLimiter); diff != "" {
+		t.Error("ProbeRateLimiter (-want, +got):", diff)
+	}
+}
+
+func TestManageReferenceGrants(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	if !cfg.ManageReferenceGrants {
+		t.Error("ManageReferenceGrants = false, want true by default")
+	}
+
+	cfg, err = FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"manage-reference-grants": "false",
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	if cfg.ManageReferenceGrants {
+		t.Error("ManageReferenceGrants = true, want false")
+	}
+}
+
+func TestDeferRoutesUntilGatewayExists(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	if cfg.DeferRoutesUntilGatewayExists {
+		t.Error("DeferRoutesUntilGatewayExists = true, want false by default")
+	}
+
+	cfg, err = FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"defer-routes-until-gateway-exists": "true",
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	if !cfg.DeferRoutesUntilGatewayExists {
+		t.Error("DeferRoutesUntilGatewayExists = false, want true")
+	}
+}
+
+func TestRouteNamespace(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	if cfg.RouteNamespace != "" {
+		t.Errorf("RouteNamespace = %q, want empty by default", cfg.RouteNamespace)
+	}
+
+	cfg, err = FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"route-namespace": " gateways ",
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	if got, want := cfg.RouteNamespace, "gateways"; got != want {
+		t.Errorf("RouteNamespace = %q, want %q", got, want)
+	}
+}
+
+func TestMaxHTTPRouteRules(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"max-http-route-rules": "16",
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	if got, want := cfg.MaxHTTPRouteRules, 16; got != want {
+		t.Errorf("MaxHTTPRouteRules = %d, want %d", got, want)
+	}
+}
+
+func TestHTTPSRedirectStatusCode(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"https-redirect-status-code": "308",
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	if got, want := cfg.HTTPSRedirectStatusCode, 308; got != want {
+		t.Errorf("HTTPSRedirectStatusCode = %d, want %d", got, want)
+	}
+}
+
+func TestEndpointProbeHeaders(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	want := EndpointProbeHeaders{Namespace: "K-Serving-Namespace", Revision: "K-Serving-Revision"}
+	if got := cfg.EndpointProbeHeaders; got != want {
+		t.Errorf("EndpointProbeHeaders = %+v, want %+v by default", got, want)
+	}
+
+	cfg, err = FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"endpoint-probe-namespace-header": " X-Fork-Namespace ",
+			"endpoint-probe-revision-header":  "",
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+	want = EndpointProbeHeaders{Namespace: "X-Fork-Namespace"}
+	if got := cfg.EndpointProbeHeaders; got != want {
+		t.Errorf("EndpointProbeHeaders = %+v, want %+v", got, want)
+	}
+}
+
+func TestAllowedRoutes(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        allowed-routes:
+          from: Selector
+          selector:
+            matchLabels:
+              knative-ingress: "true"`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	gw := cfg.ExternalGateway()
+	if got, want := gw.AllowedRoutesFrom, gatewayapi.NamespacesFromSelector; got != want {
+		t.Errorf("AllowedRoutesFrom = %v, want %v", got, want)
+	}
+	want := &metav1.LabelSelector{MatchLabels: map[string]string{"knative-ingress": "true"}}
+	if diff := cmp.Diff(want, gw.AllowedRoutesSelector); diff != "" {
+		t.Error("AllowedRoutesSelector (-want, +got):", diff)
+	}
+}
+
+func TestProbeHeaders(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        probe-headers:
+          X-Gateway-Auth: secret`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	want := map[string]string{"X-Gateway-Auth": "secret"}
+	if diff := cmp.Diff(want, cfg.ExternalGateway().ProbeHeaders); diff != "" {
+		t.Error("ProbeHeaders (-want, +got):", diff)
+	}
+	if got := cfg.LocalGateway().ProbeHeaders; got != nil {
+		t.Errorf("LocalGateway().ProbeHeaders = %v, want nil", got)
+	}
+}
+
+func TestProbeSampleSize(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        probe-sample-size: 3`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	if got, want := cfg.ExternalGateway().ProbeSampleSize, 3; got != want {
+		t.Errorf("ExternalGateway().ProbeSampleSize = %d, want %d", got, want)
+	}
+	if got := cfg.LocalGateway().ProbeSampleSize; got != 0 {
+		t.Errorf("LocalGateway().ProbeSampleSize = %d, want 0", got)
+	}
+}
+
+func TestListenerPinning(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        section-name: http
+        port: 8080`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	external := cfg.ExternalGateway()
+	if got, want := ptr.Deref(external.SectionName, ""), gatewayapi.SectionName("http"); got != want {
+		t.Errorf("ExternalGateway().SectionName = %q, want %q", got, want)
+	}
+	if got, want := ptr.Deref(external.Port, 0), gatewayapi.PortNumber(8080); got != want {
+		t.Errorf("ExternalGateway().Port = %d, want %d", got, want)
+	}
+	if local := cfg.LocalGateway(); local.SectionName != nil || local.Port != nil {
+		t.Errorf("LocalGateway() pinned to %v:%v, want no pinning", local.SectionName, local.Port)
+	}
+}
+
+func TestAddressTypes(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        address-types: [IPAddress, example.com/internal]`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	want := []gatewayapi.AddressType{gatewayapi.IPAddressType, "example.com/internal"}
+	if got := cfg.ExternalGateway().AddressTypes; !cmp.Equal(got, want) {
+		t.Errorf("ExternalGateway().AddressTypes = %v, want: %v", got, want)
+	}
+	if got := cfg.LocalGateway().AddressTypes; got != nil {
+		t.Errorf("LocalGateway().AddressTypes = %v, want: nil", got)
+	}
+}
+
+func TestDenyFilter(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: envoy
+        gateway: envoy-system/knative-gateway
+        deny-filter:
+          group: gateway.envoyproxy.io
+          kind: HTTPRouteFilter
+          name: not-found`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	want := &gatewayapi.LocalObjectReference{
+		Group: "gateway.envoyproxy.io",
+		Kind:  "HTTPRouteFilter",
+		Name:  "not-found",
+	}
+	if got := cfg.ExternalGateway().DenyFilter; !cmp.Equal(got, want) {
+		t.Errorf("ExternalGateway().DenyFilter = %v, want: %v", got, want)
+	}
+	if got := cfg.LocalGateway().DenyFilter; got != nil {
+		t.Errorf("LocalGateway().DenyFilter = %v, want: nil", got)
+	}
+}
+
+func TestBackendKind(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: envoy
+        gateway: envoy-system/knative-gateway
+        backend-kind:
+          group: gateway.envoyproxy.io
+          kind: Backend`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	gw := cfg.ExternalGateway()
+	if gw.BackendGroup != "gateway.envoyproxy.io" || gw.BackendKind != "Backend" {
+		t.Errorf("ExternalGateway() backend = %s/%s, want: gateway.envoyproxy.io/Backend", gw.BackendGroup, gw.BackendKind)
+	}
+	if gw := cfg.LocalGateway(); gw.BackendGroup != "" || gw.BackendKind != "" {
+		t.Errorf("LocalGateway() backend = %s/%s, want empty", gw.BackendGroup, gw.BackendKind)
+	}
+}
+
+func TestProbeRetryStatusCodes(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        probe-retry-status-codes: [404, 502, 503]`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	if got, want := cfg.ExternalGateway().ProbeRetryStatusCodes, sets.New(404, 502, 503); !got.Equal(want) {
+		t.Errorf("ExternalGateway().ProbeRetryStatusCodes = %v, want %v", sets.List(got), sets.List(want))
+	}
+	if got := cfg.LocalGateway().ProbeRetryStatusCodes; got != nil {
+		t.Errorf("LocalGateway().ProbeRetryStatusCodes = %v, want nil", sets.List(got))
+	}
+}
+
+func TestProbeHTTP1Only(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        probe-http1-only: true`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	if !cfg.ExternalGateway().ProbeHTTP1Only {
+		t.Error("ExternalGateway().ProbeHTTP1Only = false, want true")
+	}
+	if cfg.LocalGateway().ProbeHTTP1Only {
+		t.Error("LocalGateway().ProbeHTTP1Only = true, want false")
+	}
+}
+
+func TestProbeUserAgent(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        probe-user-agent: "Knative-Prober/1.0 (allowlisted)"`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	if got, want := cfg.ExternalGateway().ProbeUserAgent, "Knative-Prober/1.0 (allowlisted)"; got != want {
+		t.Errorf("ExternalGateway().ProbeUserAgent = %q, want %q", got, want)
+	}
+	if got := cfg.LocalGateway().ProbeUserAgent; got != "" {
+		t.Errorf("LocalGateway().ProbeUserAgent = %q, want empty", got)
+	}
+}
+
+func TestProbeVersionHeader(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        probe-version-header: X-Knative-Version`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	if got, want := cfg.ExternalGateway().ProbeVersionHeader, "X-Knative-Version"; got != want {
+		t.Errorf("ExternalGateway().ProbeVersionHeader = %q, want %q", got, want)
+	}
+	if got := cfg.LocalGateway().ProbeVersionHeader; got != "" {
+		t.Errorf("LocalGateway().ProbeVersionHeader = %q, want empty", got)
+	}
+}
+
+func TestProbeNodePort(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        service: istio-system/istio-gateway
+        probe-node-port: true`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	if !cfg.ExternalGateway().ProbeNodePort {
+		t.Error("ExternalGateway().ProbeNodePort = false, want true")
+	}
+	if cfg.LocalGateway().ProbeNodePort {
+		t.Error("LocalGateway().ProbeNodePort = true, want false")
+	}
+}
+
+func TestProbeScheme(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        service: istio-system/istio-gateway
+        probe-scheme: https`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	if got, want := cfg.ExternalGateway().ProbeScheme, "https"; got != want {
+		t.Errorf("ExternalGateway().ProbeScheme = %q, want %q", got, want)
+	}
+	if got := cfg.LocalGateway().ProbeScheme; got != "" {
+		t.Errorf("LocalGateway().ProbeScheme = %q, want empty", got)
+	}
+}
+
+func TestProbePort(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"local-gateways": `
+      - class: istio
+        gateway: istio-system/knative-local-gateway
+        probe-scheme: h2c
+        probe-port: 8081`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	if got, want := cfg.LocalGateway().ProbeScheme, "h2c"; got != want {
+		t.Errorf("LocalGateway().ProbeScheme = %q, want %q", got, want)
+	}
+	if got, want := cfg.LocalGateway().ProbePort, int32(8081); got != want {
+		t.Errorf("LocalGateway().ProbePort = %d, want %d", got, want)
+	}
+	if got := cfg.ExternalGateway().ProbePort; got != 0 {
+		t.Errorf("ExternalGateway().ProbePort = %d, want 0", got)
+	}
+}
+
+func TestProbeResolveHostname(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: aws
+        gateway: gateways/external
+        probe-resolve-hostname: true`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	if !cfg.ExternalGateway().ProbeResolveHostname {
+		t.Error("ExternalGateway().ProbeResolveHostname = false, want true")
+	}
+	if cfg.LocalGateway().ProbeResolveHostname {
+		t.Error("LocalGateway().ProbeResolveHostname = true, want false")
+	}
+}
+
+func TestProbeClientCertificate(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        service: istio-system/istio-ingressgateway
+        probe-client-certificate: knative-serving/gateway-probe-client`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	want := &types.NamespacedName{Namespace: "knative-serving", Name: "gateway-probe-client"}
+	if got := cfg.ExternalGateway().ProbeClientCertificate; !cmp.Equal(got, want) {
+		t.Errorf("ExternalGateway().ProbeClientCertificate = %v, want %v", got, want)
+	}
+	if got := cfg.LocalGateway().ProbeClientCertificate; got != nil {
+		t.Errorf("LocalGateway().ProbeClientCertificate = %v, want nil", got)
+	}
+}
+
+func TestProbeVerifyTLS(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        service: istio-system/istio-ingressgateway
+        probe-verify-tls: true
+        probe-ca-certificate: knative-serving/gateway-ca`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	if !cfg.ExternalGateway().ProbeVerifyTLS {
+		t.Error("ExternalGateway().ProbeVerifyTLS = false, want true")
+	}
+	want := &types.NamespacedName{Namespace: "knative-serving", Name: "gateway-ca"}
+	if got := cfg.ExternalGateway().ProbeCACertificate; !cmp.Equal(got, want) {
+		t.Errorf("ExternalGateway().ProbeCACertificate = %v, want %v", got, want)
+	}
+	if cfg.LocalGateway().ProbeVerifyTLS {
+		t.Error("LocalGateway().ProbeVerifyTLS = true, want false")
+	}
+}
+
+func TestRouteAnnotations(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        route-annotations:
+          example.com/load-balancing: round-robin`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	want := map[string]string{"example.com/load-balancing": "round-robin"}
+	if got := cfg.ExternalGateway().RouteAnnotations; !cmp.Equal(got, want) {
+		t.Errorf("ExternalGateway().RouteAnnotations = %v, want %v", got, want)
+	}
+	if got := cfg.LocalGateway().RouteAnnotations; got != nil {
+		t.Errorf("LocalGateway().RouteAnnotations = %v, want unset", got)
+	}
+}
+
+func TestClassDefaults(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"class-defaults": `
+      - class: istio
+        supported-features:
+        - HTTPRouteRequestTimeout
+        - HTTPRouteRequestMirror`,
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway`,
+			"local-gateways": `
+      - class: istio
+        gateway: istio-system/knative-local-gateway
+        supported-features:
+        - HTTPRouteBackendTimeout`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	// The entry without supported features inherits the ones of its class
+	want := sets.New(features.SupportHTTPRouteRequestTimeout, features.SupportHTTPRouteRequestMirror)
+	if got := cfg.ExternalGateway().SupportedFeatures; !got.Equal(want) {
+		t.Errorf("ExternalGateway().SupportedFeatures = %v, want %v", sets.List(got), sets.List(want))
+	}
+	// The entry with supported features overrides the ones of its class
+	want = sets.New(features.SupportHTTPRouteBackendTimeout)
+	if got := cfg.LocalGateway().SupportedFeatures; !got.Equal(want) {
+		t.Errorf("LocalGateway().SupportedFeatures = %v, want %v", sets.List(got), sets.List(want))
+	}
+}
+
+func TestSupportedFeaturesFromClass(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        supported-features-from-class: true`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	if !cfg.ExternalGateway().SupportedFeaturesFromClass {
+		t.Error("ExternalGateway().SupportedFeaturesFromClass = false, want true")
+	}
+	if cfg.LocalGateway().SupportedFeaturesFromClass {
+		t.Error("LocalGateway().SupportedFeaturesFromClass = true, want false")
+	}
+}
+
+func TestNoLocalGateway(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+		want bool
+	}{{
+		name: "explicit empty list",
+		data: `[]`,
+		want: false,
+	}, {
+		name: "empty value",
+		data: ``,
+		want: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := FromConfigMap(&corev1.ConfigMap{
+				Data: map[string]string{
+					"local-gateways": tc.data,
+				},
+			})
+			if err != nil {
+				t.Fatal("FromConfigMap() =", err)
+			}
+
+			if got := cfg.HasLocalGateway(); got != tc.want {
+				t.Errorf("HasLocalGateway() = %t, want: %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClassDefaultsOverrides(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"class-defaults": `
+      - class: istio
+        supported-features:
+        - HTTPRouteRequestTimeout`,
+			// An explicit empty list opts out of the features of the class
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        supported-features: []`,
+			// Entries of other classes don't inherit them
+			"local-gateways": `
+      - class: contour
+        gateway: contour-system/knative-local-gateway`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	if got := cfg.ExternalGateway().SupportedFeatures; got.Len() != 0 {
+		t.Errorf("ExternalGateway().SupportedFeatures = %v, want empty", sets.List(got))
+	}
+	if got := cfg.LocalGateway().SupportedFeatures; got.Len() != 0 {
+		t.Errorf("LocalGateway().SupportedFeatures = %v, want empty", sets.List(got))
+	}
+}
+
+func TestTLSOptions(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        tls-options:
+          example.com/min-tls-version: "1.2"`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	want := map[string]string{"example.com/min-tls-version": "1.2"}
+	if got := cfg.ExternalGateway().TLSOptions; !cmp.Equal(got, want) {
+		t.Errorf("ExternalGateway().TLSOptions = %v, want %v", got, want)
+	}
+	if got := cfg.LocalGateway().TLSOptions; got != nil {
+		t.Errorf("LocalGateway().TLSOptions = %v, want unset", got)
+	}
+}
+
+func TestWeightScale(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        weight-scale: 100`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	if got, want := cfg.ExternalGateway().WeightScale, int32(100); got != want {
+		t.Errorf("ExternalGateway().WeightScale = %d, want %d", got, want)
+	}
+	if got := cfg.LocalGateway().WeightScale; got != 0 {
+		t.Errorf("LocalGateway().WeightScale = %d, want unset", got)
+	}
+}
+
+func TestZeroWeightBackends(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"external-gateways": `
+      - class: istio
+        gateway: istio-system/knative-gateway
+        zero-weight-backends: Minimal`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	if got, want := cfg.ExternalGateway().ZeroWeightBackends, ZeroWeightMinimal; got != want {
+		t.Errorf("ExternalGateway().ZeroWeightBackends = %q, want %q", got, want)
+	}
+	if got := cfg.LocalGateway().ZeroWeightBackends; got != "" {
+		t.Errorf("LocalGateway().ZeroWeightBackends = %q, want unset", got)
+	}
+}
+
+func TestRouteLabels(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"route-labels-allowlist": "team, serving.knative.dev/route,",
+			"route-labels-denylist":  "",
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	if want := sets.New("team", "serving.knative.dev/route"); !cfg.RouteLabelsAllowlist.Equal(want) {
+		t.Errorf("RouteLabelsAllowlist = %v, want %v", sets.List(cfg.RouteLabelsAllowlist), sets.List(want))
+	}
+	if got := cfg.RouteLabelsDenylist.Len(); got != 0 {
+		t.Errorf("RouteLabelsDenylist = %v, want empty", sets.List(cfg.RouteLabelsDenylist))
+	}
+}
+
+func TestResiliencyPolicyTemplate(t *testing.T) {
+	cfg, err := FromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"resiliency-policy-template": `
+apiVersion: policy.example.com/v1
+kind: RetryBudget
+spec:
+  budgetPercent: 20`,
+		},
+	})
+	if err != nil {
+		t.Fatal("FromConfigMap() =", err)
+	}
+
+	want := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "policy.example.com/v1",
+		"kind":       "RetryBudget",
+		"spec": map[string]interface{}{
+			"budgetPercent": float64(20),
+		},
+	}}
+	if diff := cmp.Diff(want, cfg.ResiliencyPolicyTemplate); diff != "" {
+		t.Error("ResiliencyPolicyTemplate (-want, +got):", diff)
+	}
+}
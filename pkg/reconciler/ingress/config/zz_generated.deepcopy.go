@@ -22,9 +22,13 @@ limitations under the License.
 package config
 
 import (
+	time "time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	pkgconfig "knative.dev/networking/pkg/config"
+	apisv1 "sigs.k8s.io/gateway-api/apis/v1"
 	"sigs.k8s.io/gateway-api/pkg/features"
 )
 
@@ -54,6 +58,22 @@ func (in *Config) DeepCopy() *Config {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointProbeHeaders) DeepCopyInto(out *EndpointProbeHeaders) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointProbeHeaders.
+func (in *EndpointProbeHeaders) DeepCopy() *EndpointProbeHeaders {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointProbeHeaders)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Gateway) DeepCopyInto(out *Gateway) {
 	*out = *in
@@ -70,6 +90,69 @@ func (in *Gateway) DeepCopyInto(out *Gateway) {
 			(*out)[key] = val
 		}
 	}
+	if in.AllowedRoutesSelector != nil {
+		in, out := &in.AllowedRoutesSelector, &out.AllowedRoutesSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProbeHeaders != nil {
+		in, out := &in.ProbeHeaders, &out.ProbeHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ProbeRetryStatusCodes != nil {
+		in, out := &in.ProbeRetryStatusCodes, &out.ProbeRetryStatusCodes
+		*out = make(sets.Set[int], len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ProbeClientCertificate != nil {
+		in, out := &in.ProbeClientCertificate, &out.ProbeClientCertificate
+		*out = new(types.NamespacedName)
+		**out = **in
+	}
+	if in.ProbeCACertificate != nil {
+		in, out := &in.ProbeCACertificate, &out.ProbeCACertificate
+		*out = new(types.NamespacedName)
+		**out = **in
+	}
+	if in.RouteAnnotations != nil {
+		in, out := &in.RouteAnnotations, &out.RouteAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TLSOptions != nil {
+		in, out := &in.TLSOptions, &out.TLSOptions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SectionName != nil {
+		in, out := &in.SectionName, &out.SectionName
+		*out = new(apisv1.SectionName)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(apisv1.PortNumber)
+		**out = **in
+	}
+	if in.DenyFilter != nil {
+		in, out := &in.DenyFilter, &out.DenyFilter
+		*out = new(apisv1.LocalObjectReference)
+		**out = **in
+	}
+	if in.AddressTypes != nil {
+		in, out := &in.AddressTypes, &out.AddressTypes
+		*out = make([]apisv1.AddressType, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -100,6 +183,31 @@ func (in *GatewayPlugin) DeepCopyInto(out *GatewayPlugin) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ProbeInitialDelay != nil {
+		in, out := &in.ProbeInitialDelay, &out.ProbeInitialDelay
+		*out = new(time.Duration)
+		**out = **in
+	}
+	out.ProbeRateLimiter = in.ProbeRateLimiter
+	if in.ResiliencyPolicyTemplate != nil {
+		in, out := &in.ResiliencyPolicyTemplate, &out.ResiliencyPolicyTemplate
+		*out = (*in).DeepCopy()
+	}
+	if in.RouteLabelsAllowlist != nil {
+		in, out := &in.RouteLabelsAllowlist, &out.RouteLabelsAllowlist
+		*out = make(sets.Set[string], len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RouteLabelsDenylist != nil {
+		in, out := &in.RouteLabelsDenylist, &out.RouteLabelsDenylist
+		*out = make(sets.Set[string], len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	out.EndpointProbeHeaders = in.EndpointProbeHeaders
 	return
 }
 
@@ -18,32 +18,75 @@ package ingress
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
 
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
 	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	ingressinformer "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/ingress"
 	ingressreconciler "knative.dev/networking/pkg/client/injection/reconciler/networking/v1alpha1/ingress"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
 	networkcfg "knative.dev/networking/pkg/config"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	endpointsinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/endpoints"
+	nodeinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/node"
 	podinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod"
+	secretinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/secret"
+	serviceinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/service"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection/clients/dynamicclient"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayclientset "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
 
 	gwapiclient "knative.dev/net-gateway-api/pkg/client/injection/client"
 	gatewayinformer "knative.dev/net-gateway-api/pkg/client/injection/informers/apis/v1/gateway"
+	gatewayclassinformer "knative.dev/net-gateway-api/pkg/client/injection/informers/apis/v1/gatewayclass"
 	httprouteinformer "knative.dev/net-gateway-api/pkg/client/injection/informers/apis/v1/httproute"
+	tlsrouteinformer "knative.dev/net-gateway-api/pkg/client/injection/informers/apis/v1alpha2/tlsroute"
 	referencegrantinformer "knative.dev/net-gateway-api/pkg/client/injection/informers/apis/v1beta1/referencegrant"
 	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/resources"
 	"knative.dev/net-gateway-api/pkg/status"
 )
 
 const (
 	// gatewayAPIIngressClassName is the class name to reconcile.
-	gatewayAPIIngressClassName = "gateway-api.ingress.networking.knative.dev"
+	gatewayAPIIngressClassName = resources.IngressClassName
+
+	// proberStatsPath is the path serving the probing backlog.
+	proberStatsPath = "/debug/prober"
+
+	// proberRefreshPath is the path re-probing an Ingress, e.g.
+	// POST /debug/prober/refresh?namespace=ns&name=name
+	proberRefreshPath = "/debug/prober/refresh"
+
+	// gatewayConfigPath is the path serving the effective configuration of
+	// the Gateways.
+	gatewayConfigPath = "/debug/config/gateways"
 )
 
 // NewController initializes the controller and is called by the generated code
@@ -56,29 +99,55 @@ func NewController(
 
 	ingressInformer := ingressinformer.Get(ctx)
 	httprouteInformer := httprouteinformer.Get(ctx)
+	tlsrouteInformer := tlsrouteinformer.Get(ctx)
 	referenceGrantInformer := referencegrantinformer.Get(ctx)
 	gatewayInformer := gatewayinformer.Get(ctx)
+	gatewayClassInformer := gatewayclassinformer.Get(ctx)
 	endpointsInformer := endpointsinformer.Get(ctx)
+	nodeInformer := nodeinformer.Get(ctx)
 	podInformer := podinformer.Get(ctx)
+	secretInformer := secretinformer.Get(ctx)
+	serviceInformer := serviceinformer.Get(ctx)
 
 	c := &Reconciler{
 		gwapiclient:          gwapiclient.Get(ctx),
 		httprouteLister:      httprouteInformer.Lister(),
+		tlsrouteLister:       tlsrouteInformer.Lister(),
 		referenceGrantLister: referenceGrantInformer.Lister(),
+		secretLister:         secretInformer.Lister(),
+		serviceLister:        serviceInformer.Lister(),
 		gatewayLister:        gatewayInformer.Lister(),
+		gatewayClassLister:   gatewayClassInformer.Lister(),
+		dynamicClient:        dynamicclient.Get(ctx),
+		restMapper: restmapper.NewDeferredDiscoveryRESTMapper(
+			memory.NewMemCacheClient(kubeclient.Get(ctx).Discovery())),
 	}
 
 	filterFunc := reconciler.AnnotationFilterFunc(networking.IngressClassAnnotationKey, gatewayAPIIngressClassName, false)
 
+	var configStore *config.Store
 	impl := ingressreconciler.NewImpl(ctx, c, gatewayAPIIngressClassName, func(impl *controller.Impl) controller.Options {
 		configsToResync := []interface{}{
 			&networkcfg.Config{},
 			&config.GatewayPlugin{},
 		}
 		resync := configmap.TypeFilter(configsToResync...)(func(string, interface{}) {
+			if window := resyncWindow(configStore); window > 0 {
+				staggeredResync(ingressInformer.Lister(), filterFunc, window, impl.EnqueueAfter)
+				return
+			}
 			impl.GlobalResync(ingressInformer.Informer())
 		})
-		configStore := config.NewStore(logging.WithLogger(ctx, logger.Named("config-store")), resync)
+		// Ingresses can't become ready through a Gateway whose class is
+		// missing, so point that out whenever the Gateways are configured.
+		checkClasses := configmap.TypeFilter(&config.GatewayPlugin{})(func(_ string, value interface{}) {
+			go func() {
+				for _, warning := range gatewayClassWarnings(ctx, c.gwapiclient, value.(*config.GatewayPlugin)) {
+					logger.Warn(warning)
+				}
+			}()
+		})
+		configStore = config.NewStore(logging.WithLogger(ctx, logger.Named("config-store")), resync, checkClasses)
 		configStore.WatchConfigs(cmw)
 		return controller.Options{
 			ConfigStore:       configStore,
@@ -86,6 +155,21 @@ func NewController(
 		}
 	})
 
+	// The generated reconciler skips the Ingresses of other classes, so the
+	// ones that used to be of this class are cleaned up before reaching it.
+	impl.Reconciler = &classTransitionReconciler{
+		leaderAwareReconciler: impl.Reconciler.(leaderAwareReconciler),
+		reconciler:            c,
+		ingressLister:         ingressInformer.Lister(),
+		configStore:           configStore,
+		recorder:              newEventRecorder(ctx),
+	}
+
+	// The generated reconciler defaults the Ingresses before reconciling them
+	if err := ingressInformer.Informer().SetTransform(normalizeIngressRules); err != nil {
+		logger.Warnw("Failed to normalize the rules of the cached Ingresses", zap.Error(err))
+	}
+
 	logger.Info("Setting up Ingress event handlers")
 	ingressHandler := cache.FilteringResourceEventHandler{
 		FilterFunc: filterFunc,
@@ -98,6 +182,17 @@ func NewController(
 		FilterFunc: filterFunc,
 		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
 	})
+	// HTTPRoutes in the configured route namespace aren't owned by their
+	// Ingress, their labels identify it instead.
+	httprouteInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: reconciler.LabelExistsFilterFunc(resources.IngressNamespaceLabelKey),
+		Handler: controller.HandleAll(impl.EnqueueLabelOfNamespaceScopedResource(
+			resources.IngressNamespaceLabelKey, networking.IngressLabelKey)),
+	})
+	tlsrouteInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: filterFunc,
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
 	gatewayInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
 		FilterFunc: filterFunc,
 		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
@@ -105,17 +200,56 @@ func NewController(
 
 	statusProber := status.NewProber(
 		logger.Named("status-manager"),
-		NewProbeTargetLister(logger, endpointsInformer.Lister(), gatewayInformer.Lister()),
+		NewProbeTargetLister(logger, endpointsInformer.Lister(), serviceInformer.Lister(),
+			nodeInformer.Lister(), gatewayInformer.Lister()),
 		func(ing types.NamespacedName) {
 			logger.Debugf("Ready callback triggered for ingress: %v", ing)
 			impl.EnqueueKey(ing)
-		})
+		},
+		probeRateLimiter(ctx))
 	c.statusManager = statusProber
 	statusProber.Start(ctx.Done())
+	if mux := debugServeMuxFromContext(ctx); mux != nil {
+		handleDebug(mux, statusProber, configStore)
+	}
 
-	// Cancel probing when an Ingress is deleted
-	ingressInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		DeleteFunc: statusProber.CancelIngressProbing,
+	// Cancel probing when an Ingress is deleted or its class changes away
+	ingressInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: filterFunc,
+		Handler: cache.ResourceEventHandlerFuncs{
+			DeleteFunc: statusProber.CancelIngressProbing,
+		},
+	})
+
+	// Reconcile the Ingresses using a TLS secret when it changes
+	c.tracker = impl.Tracker
+	secretInformer.Informer().AddEventHandler(controller.HandleAll(
+		controller.EnsureTypeMeta(impl.Tracker.OnChanged, corev1.SchemeGroupVersion.WithKind("Secret")),
+	))
+
+	// Reconcile the Ingresses with listeners on a Gateway when its status changes
+	gatewayInformer.Informer().AddEventHandler(controller.HandleAll(
+		controller.EnsureTypeMeta(impl.Tracker.OnChanged, gatewayapi.SchemeGroupVersion.WithKind("Gateway")),
+	))
+
+	// Reconcile the Ingresses using the features advertised by a GatewayClass
+	// when it changes
+	gatewayClassInformer.Informer().AddEventHandler(controller.HandleAll(
+		controller.EnsureTypeMeta(impl.Tracker.OnChanged, gatewayapi.SchemeGroupVersion.WithKind("GatewayClass")),
+	))
+
+	// The Ingresses routed through a Gateway report its addresses and depend
+	// on its conditions, so they are reconciled when its status changes.
+	gatewayInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldGw, newGw := oldObj.(*gatewayapi.Gateway), newObj.(*gatewayapi.Gateway)
+			gpc, ok := configStore.UntypedLoad(config.GatewayConfigName).(*config.GatewayPlugin)
+			if !ok || !gatewayStatusChanged(oldGw, newGw) {
+				return
+			}
+			enqueueGatewayIngresses(ingressInformer.Lister(), filterFunc, gpc,
+				types.NamespacedName{Namespace: newGw.Namespace, Name: newGw.Name}, impl.Enqueue)
+		},
 	})
 
 	// Make sure trackers are deleted once the observers are removed.
@@ -123,10 +257,277 @@ func NewController(
 		DeleteFunc: impl.Tracker.OnDeletedObserver,
 	})
 
+	// The Ingresses report the address of the load balancers of the Gateway
+	// Services once they are provisioned.
+	serviceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldSvc, newSvc := oldObj.(*corev1.Service), newObj.(*corev1.Service)
+			if newSvc.Spec.Type != corev1.ServiceTypeLoadBalancer ||
+				equality.Semantic.DeepEqual(oldSvc.Status.LoadBalancer, newSvc.Status.LoadBalancer) {
+				return
+			}
+			gpc, ok := configStore.UntypedLoad(config.GatewayConfigName).(*config.GatewayPlugin)
+			if !ok {
+				return
+			}
+			serviceFilter, ok := gatewayServiceFilter(gpc,
+				types.NamespacedName{Namespace: newSvc.Namespace, Name: newSvc.Name}, filterFunc)
+			if !ok {
+				return
+			}
+			if window := resyncWindow(configStore); window > 0 {
+				staggeredResync(ingressInformer.Lister(), serviceFilter, window, impl.EnqueueAfter)
+				return
+			}
+			impl.FilteredGlobalResync(serviceFilter, ingressInformer.Informer())
+		},
+	})
+
 	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		// Cancel probing when a Pod is deleted
 		DeleteFunc: statusProber.CancelPodProbing,
 	})
 
+	// The HTTPRoutes in the configured route namespace aren't garbage
+	// collected with their Ingress, nor are the resources of an Ingress whose
+	// finalizer was removed by hand, so the ones left behind are swept.
+	go func() {
+		ticker := time.NewTicker(orphanSweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if !ingressInformer.Informer().HasSynced() || !httprouteInformer.Informer().HasSynced() ||
+					!tlsrouteInformer.Informer().HasSynced() || !referenceGrantInformer.Informer().HasSynced() {
+					continue
+				}
+				var policyTemplate *unstructured.Unstructured
+				if gpc, ok := configStore.UntypedLoad(config.GatewayConfigName).(*config.GatewayPlugin); ok {
+					policyTemplate = gpc.ResiliencyPolicyTemplate
+				}
+				leader := impl.Reconciler.(leaderAwareReconciler)
+				if err := c.sweepOrphans(ctx, ingressInformer.Lister(), policyTemplate, leader.IsLeaderFor); err != nil {
+					logger.Warnw("Failed to sweep the orphaned resources", zap.Error(err))
+				}
+			}
+		}
+	}()
+
 	return impl
 }
+
+// newEventRecorder returns the event recorder of the context, or one
+// recording the events to the API server until the context is done.
+func newEventRecorder(ctx context.Context) record.EventRecorder {
+	if recorder := controller.GetEventRecorder(ctx); recorder != nil {
+		return recorder
+	}
+
+	broadcaster := record.NewBroadcaster()
+	watches := []watch.Interface{
+		broadcaster.StartLogging(logging.FromContext(ctx).Named("event-broadcaster").Infof),
+		broadcaster.StartRecordingToSink(
+			&typedcorev1.EventSinkImpl{Interface: kubeclient.Get(ctx).CoreV1().Events("")}),
+	}
+	go func() {
+		<-ctx.Done()
+		for _, w := range watches {
+			w.Stop()
+		}
+	}()
+	return broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "ingress-controller"})
+}
+
+// normalizeIngressRules replaces the nil HTTP values of the rules of the
+// cached Ingresses with empty ones, as the defaulting of the generated
+// reconciler dereferences them. The rules without paths are then skipped by
+// the reconciler.
+func normalizeIngressRules(obj interface{}) (interface{}, error) {
+	if ing, ok := obj.(*v1alpha1.Ingress); ok {
+		for i := range ing.Spec.Rules {
+			if ing.Spec.Rules[i].HTTP == nil {
+				ing.Spec.Rules[i].HTTP = &v1alpha1.HTTPIngressRuleValue{}
+			}
+		}
+	}
+	return obj, nil
+}
+
+// resyncWindow returns the window over which the Ingresses are reconciled
+// again when the configuration changes.
+func resyncWindow(store *config.Store) time.Duration {
+	if store == nil {
+		return 0
+	}
+	// The network configuration may be stored before the gateway one
+	if gpc, ok := store.UntypedLoad(config.GatewayConfigName).(*config.GatewayPlugin); ok {
+		return gpc.ResyncWindow
+	}
+	return 0
+}
+
+// staggeredResync enqueues the Ingresses passing the filter spread evenly
+// over the window, rather than all at once like a global resync, so that
+// reconciling them again doesn't overload the API server.
+func staggeredResync(
+	lister networkinglisters.IngressLister,
+	filter func(interface{}) bool,
+	window time.Duration,
+	enqueueAfter func(interface{}, time.Duration),
+) {
+	ings, err := lister.List(labels.Everything())
+	if err != nil {
+		// Listing the informer cache doesn't fail
+		return
+	}
+	ings = slices.DeleteFunc(ings, func(ing *v1alpha1.Ingress) bool {
+		return !filter(ing)
+	})
+	for i, ing := range ings {
+		enqueueAfter(ing, window*time.Duration(i)/time.Duration(len(ings)))
+	}
+}
+
+// gatewayStatusChanged returns whether the addresses or the conditions of the
+// Gateway changed.
+func gatewayStatusChanged(oldGw, newGw *gatewayapi.Gateway) bool {
+	return !equality.Semantic.DeepEqual(oldGw.Status.Addresses, newGw.Status.Addresses) ||
+		!equality.Semantic.DeepEqual(oldGw.Status.Conditions, newGw.Status.Conditions)
+}
+
+// enqueueGatewayIngresses enqueues the Ingresses passing the filter with a
+// rule routed through the Gateway.
+func enqueueGatewayIngresses(
+	lister networkinglisters.IngressLister,
+	filter func(interface{}) bool,
+	gpc *config.GatewayPlugin,
+	gateway types.NamespacedName,
+	enqueue func(interface{}),
+) {
+	ings, err := lister.List(labels.Everything())
+	if err != nil {
+		// Listing the informer cache doesn't fail
+		return
+	}
+	for _, ing := range ings {
+		if filter(ing) && routedThroughGateway(gpc, ing, gateway) {
+			enqueue(ing)
+		}
+	}
+}
+
+// routedThroughGateway returns whether a rule of the Ingress is routed
+// through the Gateway.
+func routedThroughGateway(gpc *config.GatewayPlugin, ing *v1alpha1.Ingress, gateway types.NamespacedName) bool {
+	return slices.ContainsFunc(ing.Spec.Rules, func(rule v1alpha1.IngressRule) bool {
+		gwc := gpc.ExternalGateway()
+		if rule.Visibility == v1alpha1.IngressVisibilityClusterLocal {
+			if !gpc.HasLocalGateway() {
+				return false
+			}
+			gwc = gpc.LocalGateway()
+		}
+		return gwc.NamespacedName == gateway
+	})
+}
+
+// gatewayServiceFilter returns a filter of the Ingresses passing the filter
+// with a rule routed through a configured Gateway of the Service, and false
+// when the Service isn't the Service of any of them.
+func gatewayServiceFilter(
+	gpc *config.GatewayPlugin,
+	svc types.NamespacedName,
+	filter func(interface{}) bool,
+) (func(interface{}) bool, bool) {
+	var gateways []types.NamespacedName
+	for _, gw := range append(slices.Clone(gpc.ExternalGateways), gpc.LocalGateways...) {
+		if gw.Service != nil && *gw.Service == svc {
+			gateways = append(gateways, gw.NamespacedName)
+		}
+	}
+	if len(gateways) == 0 {
+		return nil, false
+	}
+	return func(obj interface{}) bool {
+		ing, ok := obj.(*v1alpha1.Ingress)
+		return ok && filter(ing) && slices.ContainsFunc(gateways, func(gateway types.NamespacedName) bool {
+			return routedThroughGateway(gpc, ing, gateway)
+		})
+	}, true
+}
+
+// gatewayClassWarnings checks that the classes of the configured Gateways
+// exist and were accepted by their controller, and returns a warning for each
+// one that isn't.
+func gatewayClassWarnings(ctx context.Context, client gatewayclientset.Interface, gpc *config.GatewayPlugin) []string {
+	classes := sets.New[string]()
+	for _, gw := range append(slices.Clone(gpc.ExternalGateways), gpc.LocalGateways...) {
+		classes.Insert(gw.Class)
+	}
+
+	var warnings []string
+	for _, class := range sets.List(classes) {
+		gwc, err := client.GatewayV1().GatewayClasses().Get(ctx, class, metav1.GetOptions{})
+		switch {
+		case apierrs.IsNotFound(err):
+			warnings = append(warnings, fmt.Sprintf(
+				"GatewayClass %q of the configured Gateways does not exist, Ingresses using them will not become ready", class))
+		case err != nil:
+			warnings = append(warnings, fmt.Sprintf("failed to check GatewayClass %q: %v", class, err))
+		case !meta.IsStatusConditionTrue(gwc.Status.Conditions, string(gatewayapi.GatewayClassConditionStatusAccepted)):
+			warnings = append(warnings, fmt.Sprintf(
+				"GatewayClass %q of the configured Gateways is not accepted by its controller %q", class, gwc.Spec.ControllerName))
+		}
+	}
+	return warnings
+}
+
+// probeRateLimiter returns the parameters of the rate limiter of the probes
+// from the gateway config map. The prober is created before the config store
+// is populated, so the config map is read directly, falling back to the
+// defaults when it can't be.
+func probeRateLimiter(ctx context.Context) status.RateLimiterConfig {
+	logger := logging.FromContext(ctx)
+
+	cm, err := kubeclient.Get(ctx).CoreV1().ConfigMaps(system.Namespace()).Get(ctx, config.GatewayConfigName, metav1.GetOptions{})
+	if err != nil {
+		logger.Warnw("Failed to get the gateway config, probing with the default rate limits", zap.Error(err))
+		return status.DefaultRateLimiterConfig()
+	}
+	gpc, err := config.FromConfigMap(cm)
+	if err != nil {
+		logger.Warnw("Failed to parse the gateway config, probing with the default rate limits", zap.Error(err))
+		return status.DefaultRateLimiterConfig()
+	}
+
+	return gpc.ProbeRateLimiter
+}
+
+// debugServeMuxKey is the context key of the mux serving the debug handlers.
+type debugServeMuxKey struct{}
+
+// WithDebugServeMux returns a context in which the controller registers its
+// debug handlers on the mux, e.g. the mux of the profiling server. The mux
+// should only be served when profiling is enabled, as the handlers expose the
+// configuration and the probing of the Ingresses.
+func WithDebugServeMux(ctx context.Context, mux *http.ServeMux) context.Context {
+	return context.WithValue(ctx, debugServeMuxKey{}, mux)
+}
+
+// debugServeMuxFromContext returns the mux of WithDebugServeMux, or nil when
+// the debug handlers aren't served.
+func debugServeMuxFromContext(ctx context.Context) *http.ServeMux {
+	mux, _ := ctx.Value(debugServeMuxKey{}).(*http.ServeMux)
+	return mux
+}
+
+// handleDebug registers the handlers serving the probing backlog of the
+// prober, the refresh of the probing of an Ingress and the effective
+// configuration of the Gateways on the mux.
+func handleDebug(mux *http.ServeMux, prober *status.Prober, configStore *config.Store) {
+	mux.Handle(proberStatsPath, status.StatsHandler(prober))
+	mux.Handle(proberRefreshPath, status.RefreshHandler(prober))
+	mux.Handle(gatewayConfigPath, configStore.GatewaysHandler())
+}
@@ -17,21 +17,45 @@ limitations under the License.
 package ingress
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+	fakegatewayclientset "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
 	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
+	"knative.dev/net-gateway-api/pkg/status"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
 	networkcfg "knative.dev/networking/pkg/config"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/reconciler"
 	"knative.dev/pkg/system"
 
 	_ "knative.dev/net-gateway-api/pkg/client/injection/informers/apis/v1/gateway/fake"
+	_ "knative.dev/net-gateway-api/pkg/client/injection/informers/apis/v1/gatewayclass/fake"
 	_ "knative.dev/net-gateway-api/pkg/client/injection/informers/apis/v1/httproute/fake"
+	_ "knative.dev/net-gateway-api/pkg/client/injection/informers/apis/v1alpha2/tlsroute/fake"
 	_ "knative.dev/net-gateway-api/pkg/client/injection/informers/apis/v1beta1/referencegrant/fake"
 	_ "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/ingress/fake"
 	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/endpoints/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/node/fake"
 	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/pod/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/secret/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/service/fake"
+	_ "knative.dev/pkg/injection/clients/dynamicclient/fake"
 
 	. "knative.dev/pkg/reconciler/testing"
 )
@@ -55,3 +79,295 @@ func TestNew(t *testing.T) {
 		t.Fatal("Expected NewController to return a non-nil value")
 	}
 }
+
+func TestNewDebugHandlers(t *testing.T) {
+	ctx, _ := SetupFakeContext(t)
+	mux := http.NewServeMux()
+	ctx = WithDebugServeMux(ctx, mux)
+
+	NewController(ctx, configmap.NewStaticWatcher(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: system.Namespace(),
+			Name:      config.GatewayConfigName,
+		},
+	}, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: system.Namespace(),
+			Name:      networkcfg.ConfigMapName,
+		},
+	}))
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		want   int
+	}{{
+		method: http.MethodGet,
+		path:   proberStatsPath,
+		want:   http.StatusOK,
+	}, {
+		method: http.MethodGet,
+		path:   gatewayConfigPath,
+		want:   http.StatusOK,
+	}, {
+		method: http.MethodPost,
+		path:   proberRefreshPath + "?namespace=ns&name=name",
+		want:   http.StatusAccepted,
+	}, {
+		// Refreshing the probing of an Ingress isn't a safe method
+		method: http.MethodGet,
+		path:   proberRefreshPath + "?namespace=ns&name=name",
+		want:   http.StatusMethodNotAllowed,
+	}} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.want {
+			t.Errorf("%s %s = %d, want: %d", tc.method, tc.path, rec.Code, tc.want)
+		}
+	}
+}
+
+func TestGatewayClassWarnings(t *testing.T) {
+	gpc := &config.GatewayPlugin{
+		ExternalGateways: []config.Gateway{{
+			NamespacedName: types.NamespacedName{Namespace: "ns", Name: "external"},
+			Class:          "accepted",
+		}},
+		LocalGateways: []config.Gateway{{
+			NamespacedName: types.NamespacedName{Namespace: "ns", Name: "local"},
+			Class:          "missing",
+		}},
+	}
+
+	for _, tc := range []struct {
+		name    string
+		classes []runtime.Object
+		want    []string
+	}{{
+		name: "all classes accepted",
+		classes: []runtime.Object{
+			gatewayClass("accepted", metav1.ConditionTrue),
+			gatewayClass("missing", metav1.ConditionTrue),
+		},
+	}, {
+		name: "missing class",
+		classes: []runtime.Object{
+			gatewayClass("accepted", metav1.ConditionTrue),
+		},
+		want: []string{
+			`GatewayClass "missing" of the configured Gateways does not exist, Ingresses using them will not become ready`,
+		},
+	}, {
+		name: "class not accepted",
+		classes: []runtime.Object{
+			gatewayClass("accepted", metav1.ConditionFalse),
+			gatewayClass("missing", metav1.ConditionTrue),
+		},
+		want: []string{
+			`GatewayClass "accepted" of the configured Gateways is not accepted by its controller "example.com/gateway-controller"`,
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fakegatewayclientset.NewSimpleClientset(tc.classes...)
+			got := gatewayClassWarnings(context.Background(), client, gpc)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error("Unexpected warnings (-want, +got):", diff)
+			}
+		})
+	}
+}
+
+func TestProbeRateLimiter(t *testing.T) {
+	ctx, _ := SetupFakeContext(t)
+
+	if diff := cmp.Diff(status.DefaultRateLimiterConfig(), probeRateLimiter(ctx)); diff != "" {
+		t.Error("Unexpected rate limiter without the config (-want, +got):", diff)
+	}
+
+	kubeclient.Get(ctx).CoreV1().ConfigMaps(system.Namespace()).Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: system.Namespace(),
+			Name:      config.GatewayConfigName,
+		},
+		Data: map[string]string{
+			"probe-rate-limit-qps":   "500",
+			"probe-rate-limit-burst": "1000",
+			"probe-backoff-jitter":   "0.1",
+		},
+	}, metav1.CreateOptions{})
+
+	want := status.DefaultRateLimiterConfig()
+	want.QPS, want.Burst, want.Jitter = 500, 1000, 0.1
+	if diff := cmp.Diff(want, probeRateLimiter(ctx)); diff != "" {
+		t.Error("Unexpected rate limiter (-want, +got):", diff)
+	}
+}
+
+func gatewayClass(name string, accepted metav1.ConditionStatus) *gatewayapi.GatewayClass {
+	return &gatewayapi.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: gatewayapi.GatewayClassSpec{
+			ControllerName: "example.com/gateway-controller",
+		},
+		Status: gatewayapi.GatewayClassStatus{
+			Conditions: []metav1.Condition{{
+				Type:   string(gatewayapi.GatewayClassConditionStatusAccepted),
+				Status: accepted,
+			}},
+		},
+	}
+}
+
+func TestNormalizeIngressRules(t *testing.T) {
+	got := ing(withBasicSpec, func(i *v1alpha1.Ingress) {
+		i.Spec.Rules = append(i.Spec.Rules, v1alpha1.IngressRule{Hosts: []string{"empty.example.com"}})
+	})
+	want := got.DeepCopy()
+	want.Spec.Rules[1].HTTP = &v1alpha1.HTTPIngressRuleValue{}
+
+	obj, err := normalizeIngressRules(got)
+	if err != nil {
+		t.Fatal("normalizeIngressRules() =", err)
+	}
+	if diff := cmp.Diff(want, obj); diff != "" {
+		t.Error("normalizeIngressRules (-want, +got):", diff)
+	}
+
+	// The Ingress can be defaulted once normalized
+	obj.(*v1alpha1.Ingress).SetDefaults(context.Background())
+
+	// Other objects, e.g. tombstones, are left alone
+	tombstone := cache.DeletedFinalStateUnknown{Key: "ns/name"}
+	if obj, err := normalizeIngressRules(tombstone); err != nil || obj != tombstone {
+		t.Errorf("normalizeIngressRules(tombstone) = %v, %v, want: %v", obj, err, tombstone)
+	}
+}
+
+func TestStaggeredResync(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, name := range []string{"a", "b", "c", "d"} {
+		indexer.Add(ing(withBasicSpec, withGatewayAPIClass, func(i *v1alpha1.Ingress) {
+			i.Name = name
+		}))
+	}
+	// Not of the class of the controller
+	indexer.Add(ing(withBasicSpec, func(i *v1alpha1.Ingress) {
+		i.Name = "other"
+	}))
+
+	filter := reconciler.AnnotationFilterFunc(networking.IngressClassAnnotationKey, gatewayAPIIngressClassName, false)
+	delays := map[string]time.Duration{}
+	staggeredResync(networkinglisters.NewIngressLister(indexer), filter, time.Minute, func(obj interface{}, after time.Duration) {
+		delays[obj.(*v1alpha1.Ingress).Name] = after
+	})
+
+	if len(delays) != 4 {
+		t.Fatalf("Enqueued %d Ingresses, want: 4 (%v)", len(delays), delays)
+	}
+	// The Ingresses are enqueued every quarter of the window
+	got := sets.New[time.Duration]()
+	for _, d := range delays {
+		got.Insert(d)
+	}
+	want := sets.New(0, 15*time.Second, 30*time.Second, 45*time.Second)
+	if !got.Equal(want) {
+		t.Errorf("Delays = %v, want: %v", sets.List(got), sets.List(want))
+	}
+}
+
+func TestEnqueueGatewayIngresses(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(ing(withBasicSpec, withGatewayAPIClass, func(i *v1alpha1.Ingress) {
+		i.Name = "external"
+	}))
+	indexer.Add(ing(withBasicSpec, withGatewayAPIClass, func(i *v1alpha1.Ingress) {
+		i.Name = "local"
+		i.Spec.Rules[0].Visibility = v1alpha1.IngressVisibilityClusterLocal
+	}))
+	// Not of the class of the controller
+	indexer.Add(ing(withBasicSpec, func(i *v1alpha1.Ingress) {
+		i.Name = "other"
+	}))
+
+	oldGw := &gatewayapi.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "istio-gateway"},
+	}
+	newGw := oldGw.DeepCopy()
+	newGw.Status.Addresses = []gatewayapi.GatewayStatusAddress{{Value: "1.2.3.4"}}
+
+	if gatewayStatusChanged(oldGw, oldGw.DeepCopy()) {
+		t.Error("gatewayStatusChanged() = true for an unchanged Gateway")
+	}
+	if !gatewayStatusChanged(oldGw, newGw) {
+		t.Error("gatewayStatusChanged() = false for a Gateway whose addresses changed")
+	}
+
+	filter := reconciler.AnnotationFilterFunc(networking.IngressClassAnnotationKey, gatewayAPIIngressClassName, false)
+	for _, tc := range []struct {
+		name    string
+		gateway types.NamespacedName
+		want    sets.Set[string]
+	}{{
+		name:    "external gateway",
+		gateway: defaultConfig.GatewayPlugin.ExternalGateway().NamespacedName,
+		want:    sets.New("external"),
+	}, {
+		name:    "local gateway",
+		gateway: defaultConfig.GatewayPlugin.LocalGateway().NamespacedName,
+		want:    sets.New("local"),
+	}, {
+		name:    "unrelated gateway",
+		gateway: types.NamespacedName{Namespace: "istio-system", Name: "other-gateway"},
+		want:    sets.New[string](),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := sets.New[string]()
+			enqueueGatewayIngresses(networkinglisters.NewIngressLister(indexer), filter, defaultConfig.GatewayPlugin,
+				tc.gateway, func(obj interface{}) {
+					got.Insert(obj.(*v1alpha1.Ingress).Name)
+				})
+			if !got.Equal(tc.want) {
+				t.Errorf("Enqueued = %v, want: %v", sets.List(got), sets.List(tc.want))
+			}
+		})
+	}
+}
+
+func TestGatewayServiceFilter(t *testing.T) {
+	filter := reconciler.AnnotationFilterFunc(networking.IngressClassAnnotationKey, gatewayAPIIngressClassName, false)
+	external := ing(withBasicSpec, withGatewayAPIClass)
+	local := ing(withBasicSpec, withGatewayAPIClass, func(i *v1alpha1.Ingress) {
+		i.Spec.Rules[0].Visibility = v1alpha1.IngressVisibilityClusterLocal
+	})
+	other := ing(withBasicSpec)
+
+	for _, tc := range []struct {
+		name    string
+		service types.NamespacedName
+		want    []bool
+	}{{
+		name:    "external gateway service",
+		service: *defaultConfig.GatewayPlugin.ExternalGateway().Service,
+		want:    []bool{true, false, false},
+	}, {
+		name:    "local gateway service",
+		service: *defaultConfig.GatewayPlugin.LocalGateway().Service,
+		want:    []bool{false, true, false},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			serviceFilter, ok := gatewayServiceFilter(defaultConfig.GatewayPlugin, tc.service, filter)
+			if !ok {
+				t.Fatal("gatewayServiceFilter() = false for the Service of a Gateway")
+			}
+			got := []bool{serviceFilter(external), serviceFilter(local), serviceFilter(other)}
+			if !cmp.Equal(got, tc.want) {
+				t.Errorf("Filtered [external, local, other] = %v, want: %v", got, tc.want)
+			}
+		})
+	}
+
+	if _, ok := gatewayServiceFilter(defaultConfig.GatewayPlugin,
+		types.NamespacedName{Namespace: "default", Name: "unrelated"}, filter); ok {
+		t.Error("gatewayServiceFilter() = true for a Service of no Gateway")
+	}
+}
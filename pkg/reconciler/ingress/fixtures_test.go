@@ -19,6 +19,7 @@ package ingress
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/resources"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/http/header"
 	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
@@ -36,6 +37,7 @@ type HTTPRoute struct {
 	Rules            []RuleBuilder
 	StatusConditions []metav1.Condition
 	ClusterLocal     bool
+	InputsHash       string
 }
 
 func (r HTTPRoute) Build() *gatewayapi.HTTPRoute {
@@ -58,6 +60,7 @@ func (r HTTPRoute) Build() *gatewayapi.HTTPRoute {
 			},
 			Labels: map[string]string{
 				networking.VisibilityLabelKey: "",
+				resources.IngressUIDLabelKey:  "",
 			},
 			OwnerReferences: []metav1.OwnerReference{{
 				APIVersion:         "networking.internal.knative.dev/v1alpha1",
@@ -104,6 +107,14 @@ func (r HTTPRoute) Build() *gatewayapi.HTTPRoute {
 		route.Spec.Rules = append(route.Spec.Rules, rule.Build())
 	}
 
+	// Routes built from inputs are the output of MakeHTTPRoute, with the
+	// hash of their spec.
+	if r.InputsHash != "" {
+		specHash, _ := resources.HTTPRouteSpecHash(route.Spec)
+		route.Annotations[resources.InputsHashAnnotationKey] = r.InputsHash
+		route.Annotations[resources.SpecHashAnnotationKey] = specHash
+	}
+
 	return &route
 }
 
@@ -20,27 +20,111 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/utils/ptr"
 
 	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/resources"
 	"knative.dev/net-gateway-api/pkg/status"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	ingressreconciler "knative.dev/networking/pkg/client/injection/reconciler/networking/v1alpha1/ingress"
 	"knative.dev/networking/pkg/ingress"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
 	"knative.dev/pkg/network"
 	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/tracker"
 
 	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayclientset "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
 	gatewaylisters "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1"
+	gatewaylistersv1alpha2 "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1alpha2"
 	gatewaylistersv1beta1 "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1beta1"
 )
 
 const (
 	notReconciledReason  = "ReconcileIngressFailed"
 	notReconciledMessage = "Ingress reconciliation failed"
+
+	duplicateRouteNameReason = "DuplicateRouteName"
+
+	tlsSecretInvalidReason = "TLSSecretInvalid"
+
+	invalidRewriteHostReason = "InvalidRewriteHost"
+
+	unmatchedHostsReason = "UnmatchedHosts"
+
+	// partiallyMatchedHostsReason is the event reason when only some of the
+	// hosts of a rule match the listeners of their Gateway.
+	partiallyMatchedHostsReason = "PartiallyMatchedHosts"
+
+	// listenerNotResolvedReason is the Ready reason when the Gateway reports
+	// that the references of a listener of the Ingress are not resolved.
+	listenerNotResolvedReason = "GatewayListenerNotResolved"
+
+	// noRulesReason is the Ready reason of Ingresses without rules, e.g. with
+	// only TLS, as nothing routes their hosts.
+	noRulesReason  = "NoRules"
+	noRulesMessage = "Ingress has no rules routing its hosts."
+
+	// noPathsReason is the event reason of the rules without HTTP paths,
+	// which aren't reconciled into routes.
+	noPathsReason = "RuleWithoutPaths"
+
+	// notProgrammedReason is the Ready reason when the routes were accepted
+	// but their Gateways report they are not programmed yet.
+	notProgrammedReason = "HTTPRouteNotProgrammed"
+
+	// routeConditionProgrammed is the condition some Gateways set on the
+	// route parents once their dataplane is programmed. It is not part of
+	// the Gateway API route conditions.
+	routeConditionProgrammed = "Programmed"
+
+	// partiallyReadyReason is the LoadBalancerReady reason when the routes of
+	// only some of the visibilities of the Ingress are ready.
+	partiallyReadyReason = "PartiallyReady"
+
+	// probeEventInterval is the number of consecutive reconciles with failing
+	// probes between two ProbesNotReady events for the same Ingress.
+	probeEventInterval = 10
+
+	// LastProbeFailureAnnotationKey is the status annotation with the URL and
+	// error of the last failed probe of an Ingress whose probes are failing.
+	LastProbeFailureAnnotationKey = "gateway-api.networking.knative.dev/last-probe-failure"
+
+	// gatewayNotProgrammedReason is the Ready reason while a Gateway of the
+	// Ingress isn't Programmed, and gatewayNotProgrammedTimeoutReason the
+	// reason once it has not been for longer than the configured timeout.
+	gatewayNotProgrammedReason        = "GatewayNotProgrammed"
+	gatewayNotProgrammedTimeoutReason = "GatewayNotProgrammedTimeout"
+
+	// gatewayNotFoundReason is the Ready reason while the routes of the
+	// Ingress are deferred until its Gateways exist.
+	gatewayNotFoundReason = "GatewayNotFound"
+
+	// gatewayRecheckInterval is how often the Gateways that aren't Programmed
+	// are checked again, as their changes don't reconcile the Ingresses.
+	gatewayRecheckInterval = 10 * time.Second
+
+	// GatewayNotProgrammedSinceAnnotationKey is the status annotation with
+	// the time a Gateway of the Ingress was first seen not Programmed.
+	GatewayNotProgrammedSinceAnnotationKey = "gateway-api.networking.knative.dev/gateway-not-programmed-since"
+
+	// maxProbeFailureLength bounds the length of the last probe failure
+	// annotation, as errors may embed arbitrary responses.
+	maxProbeFailureLength = 512
 )
 
 var ErrGatewayNotFound = errors.New("could not find Gateway")
@@ -54,9 +138,36 @@ type Reconciler struct {
 	// Listers index properties about resources
 	httprouteLister gatewaylisters.HTTPRouteLister
 
+	tlsrouteLister gatewaylistersv1alpha2.TLSRouteLister
+
+	// referenceGrantLister lists the v1beta1 ReferenceGrants, the newest
+	// version of ReferenceGrant in the supported Gateway API release. The
+	// ReferenceGrants are read and written with the same version.
 	referenceGrantLister gatewaylistersv1beta1.ReferenceGrantLister
 
+	secretLister corev1listers.SecretLister
+
+	// serviceLister looks up the load balancer addresses of the Gateway
+	// Services
+	serviceLister corev1listers.ServiceLister
+
+	// tracker reconciles the Ingresses when their TLS secrets change
+	tracker tracker.Interface
+
 	gatewayLister gatewaylisters.GatewayLister
+
+	// gatewayClassLister looks up the features advertised by the classes of
+	// the Gateways
+	gatewayClassLister gatewaylisters.GatewayClassLister
+
+	// dynamicClient manages the gateway implementation specific policies
+	dynamicClient dynamic.Interface
+
+	// restMapper resolves the resources of the kinds of the policies
+	restMapper meta.RESTMapper
+
+	// probeFailures throttles the ProbesNotReady events
+	probeFailures probeFailures
 }
 
 var _ ingressreconciler.Interface = (*Reconciler)(nil)
@@ -66,7 +177,9 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, ingress *v1alpha1.Ingres
 	reconcileErr := c.reconcileIngress(ctx, ingress)
 
 	if reconcileErr != nil {
-		ingress.Status.MarkIngressNotReady(notReconciledReason, notReconciledMessage)
+		if ok, _ := controller.IsRequeueKey(reconcileErr); !ok {
+			ingress.Status.MarkIngressNotReady(notReconciledReason, notReconciledMessage)
+		}
 		return reconcileErr
 	}
 
@@ -76,61 +189,240 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, ingress *v1alpha1.Ingres
 // FinalizeKind implements Interface.FinalizeKind
 func (c *Reconciler) FinalizeKind(ctx context.Context, ingress *v1alpha1.Ingress) pkgreconciler.Event {
 	pluginConfig := config.FromContext(ctx).GatewayPlugin
+	c.probeFailures.reset(types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name})
 
-	// We currently only support TLS on the external IP
-	return c.clearGatewayListeners(ctx, ingress, pluginConfig.ExternalGateway().NamespacedName)
+	if err := c.clearHTTPRoutes(ctx, ingress, nil); err != nil {
+		return err
+	}
+
+	// We currently only support TLS on the external IP. Deleting the Ingress
+	// mustn't be blocked while no external Gateway is configured.
+	if gwName, ok := externalGatewayName(pluginConfig); ok {
+		if err := c.clearGatewayListeners(ctx, ingress, gwName); err != nil {
+			return err
+		}
+	}
+
+	return c.clearReferenceGrants(ctx, ingress)
+}
+
+// externalGatewayName returns the name of the external Gateway of the
+// configuration, or false when there is none, e.g. when the configuration
+// isn't loaded.
+func externalGatewayName(gpc *config.GatewayPlugin) (types.NamespacedName, bool) {
+	if gpc == nil || len(gpc.ExternalGateways) == 0 {
+		return types.NamespacedName{}, false
+	}
+	return gpc.ExternalGateway().NamespacedName, true
 }
 
 func (c *Reconciler) reconcileIngress(ctx context.Context, ing *v1alpha1.Ingress) error {
+	ctx, err := c.withGatewayClassFeatures(ctx, ing)
+	if err != nil {
+		return err
+	}
 	pluginConfig := config.FromContext(ctx).GatewayPlugin
 
+	// The defaulting dereferences the HTTP values of the rules
+	c.skipRulesWithoutPaths(ctx, ing)
+
 	// We may be reading a version of the object that was stored at an older version
 	// and may not have had all of the assumed defaults specified.  This won't result
 	// in this getting written back to the API Server, but lets downstream logic make
 	// assumptions about defaulting.
 	ing.SetDefaults(ctx)
+	if ing.Spec.HTTPOption == "" {
+		ing.Spec.HTTPOption = pluginConfig.DefaultHTTPOption
+	}
+	if !pluginConfig.HasLocalGateway() {
+		// The cluster-local rules aren't served without a local gateway, the
+		// Ingress is ready once its external rules are.
+		ing.Spec.Rules = slices.DeleteFunc(ing.Spec.Rules, func(rule v1alpha1.IngressRule) bool {
+			return rule.Visibility == v1alpha1.IngressVisibilityClusterLocal
+		})
+	}
 	ing.Status.InitializeConditions()
 
-	var (
-		ingressHash string
-		err         error
-	)
+	passthrough := resources.IsTLSPassthrough(ing)
+
+	if msg := duplicateRouteNames(ing, passthrough); msg != "" {
+		// Retrying won't help until the Ingress changes
+		controller.GetEventRecorder(ctx).Event(ing, corev1.EventTypeWarning, duplicateRouteNameReason, msg)
+		ing.Status.MarkIngressNotReady(duplicateRouteNameReason, msg)
+		return nil
+	}
+
+	c.warnUnmatchedHosts(ctx, ing, passthrough)
+
+	if pluginConfig.DeferRoutesUntilGatewayExists {
+		gateway, err := c.missingGateway(ing, pluginConfig)
+		if err != nil {
+			return err
+		}
+		if gateway != nil {
+			ing.Status.MarkLoadBalancerNotReady()
+			ing.Status.MarkIngressNotReady(gatewayNotFoundReason, fmt.Sprintf("Waiting for Gateway %s to exist.", gateway))
+			return nil
+		}
+	}
 
-	if ingressHash, err = ingress.InsertProbe(ing); err != nil {
+	ingressHash, err := ingress.InsertProbe(ing)
+	if err != nil {
 		return fmt.Errorf("failed to add knative probe header: %w", err)
 	}
 
+	strategy, err := probeStrategy(ing)
+	if err != nil {
+		return err
+	}
+	maxProbes, err := maxConcurrentProbes(ing)
+	if err != nil {
+		return err
+	}
+
 	routesReady := true
+	routesAccepted := true
+	probesFailing := false
+	// lastReady is the oldest time any of the not-ready routes was last ready
+	var lastReady time.Time
+	// notReady are the probe targets of the routes whose probes are failing
+	var notReady []status.Backends
+	// probeFailure is the last failed probe of the first of those routes
+	var probeFailure string
+	// visibilities and notReadyVisibilities track readiness per visibility,
+	// so that the status can tell which routes the Ingress is waiting for.
+	visibilities := sets.New[v1alpha1.IngressVisibility]()
+	notReadyVisibilities := sets.New[v1alpha1.IngressVisibility]()
+	// httproutes are the HTTPRoutes of the rules, any other route of the
+	// Ingress is stale.
+	httproutes := sets.New[types.NamespacedName]()
 
-	for _, rule := range ing.Spec.Rules {
-		httproute, probeTargets, err := c.reconcileHTTPRoute(ctx, ingressHash, ing, &rule)
-		if err != nil {
+	// HTTPRoutes outside of the Ingress namespace need to be allowed to use
+	// its Services. When the grants aren't managed a missing one is reported
+	// in the status of the routes.
+	if grant := resources.MakeBackendReferenceGrant(ctx, ing); grant != nil && pluginConfig.ManageReferenceGrants {
+		if err := c.reconcileReferenceGrant(ctx, ing, grant); err != nil {
 			return err
 		}
+	}
 
-		if isHTTPRouteReady(httproute) {
+	for _, rp := range routeParts(ing, passthrough, pluginConfig.MaxHTTPRouteRules) {
+		var (
+			rule         = rp.rule
+			routeStatus  *gatewayapi.RouteStatus
+			probeTargets status.Backends
+		)
+
+		if passthrough && rule.Visibility != v1alpha1.IngressVisibilityClusterLocal {
+			tlsroute, backends, err := c.reconcileTLSRoute(ctx, ingressHash, ing, &rule)
+			if err != nil {
+				return err
+			}
+			routeStatus, probeTargets = &tlsroute.Status.RouteStatus, backends
+		} else {
+			// The policy is named after the hosts of the part, shared by the
+			// parts splitting their paths
+			if rp.firstOfHosts {
+				if err := c.reconcileResiliencyPolicy(ctx, ing, &rule); err != nil {
+					return err
+				}
+			}
+			httproute, backends, err := c.reconcileHTTPRoute(ctx, ingressHash, ing, &rule, rp.part)
+			if errors.Is(err, resources.ErrInvalidRewriteHost) || errors.Is(err, resources.ErrPreservedRewriteHost) {
+				// Retrying won't help until the Ingress changes
+				controller.GetEventRecorder(ctx).Event(ing, corev1.EventTypeWarning, invalidRewriteHostReason, err.Error())
+				ing.Status.MarkIngressNotReady(invalidRewriteHostReason, err.Error())
+				return nil
+			} else if err != nil {
+				return err
+			}
+			routeStatus, probeTargets = &httproute.Status.RouteStatus, backends
+			httproutes.Insert(types.NamespacedName{Namespace: httproute.Namespace, Name: httproute.Name})
+
+			if rp.firstOfHosts && resources.RedirectsToHTTPS(ing, &rule) {
+				redirect, err := c.reconcileRedirectHTTPRoute(ctx, ing, &rule)
+				if err != nil {
+					return err
+				}
+				httproutes.Insert(types.NamespacedName{Namespace: redirect.Namespace, Name: redirect.Name})
+			}
+		}
+
+		visibilities.Insert(rule.Visibility)
+		if isRouteReady(routeStatus) && isRouteProgrammed(routeStatus) {
 			ing.Status.MarkNetworkConfigured()
 
+			if strategy == probeStrategyNone {
+				// The route is ready as soon as it is accepted
+				continue
+			}
+
+			gwc := pluginConfig.ExternalGateway()
+			if rule.Visibility == v1alpha1.IngressVisibilityClusterLocal {
+				gwc = pluginConfig.LocalGateway()
+			}
+			probeTargets.Headers = gwc.ProbeHeaders
+			probeTargets.RetryStatusCodes = gwc.ProbeRetryStatusCodes
+			probeTargets.HTTP1Only = gwc.ProbeHTTP1Only
+			probeTargets.UserAgent = gwc.ProbeUserAgent
+			probeTargets.VersionHeader = gwc.ProbeVersionHeader
+			probeTargets.MaxConcurrentProbes = maxProbes
+			probeTargets.GetClientCertificate = c.probeClientCertificate(gwc)
+			probeTargets.GetRootCAs = c.probeRootCAs(gwc)
+			probeTargets.InitialDelay = pluginConfig.ProbeInitialDelay
+
 			state, err := c.statusManager.DoProbes(ctx, probeTargets)
 			if err != nil {
 				return fmt.Errorf("failed to probe Ingress: %w", err)
 			}
 
-			routesReady = routesReady && state.Ready
+			if !state.Ready {
+				routesReady = false
+				if !probesFailing || state.LastReady.Before(lastReady) {
+					lastReady = state.LastReady
+				}
+				probesFailing = true
+				notReady = append(notReady, probeTargets)
+				if probeFailure == "" {
+					probeFailure = state.LastFailure
+				}
+				notReadyVisibilities.Insert(rule.Visibility)
+			}
 		} else {
 			routesReady = false
-			ing.Status.MarkIngressNotReady("HTTPRouteNotReady", "Waiting for HTTPRoute becomes Ready.")
+			routesAccepted = false
+			notReadyVisibilities.Insert(rule.Visibility)
+			if isRouteReady(routeStatus) {
+				ing.Status.MarkIngressNotReady(notProgrammedReason, "Waiting for HTTPRoute to be programmed.")
+			} else {
+				ing.Status.MarkIngressNotReady("HTTPRouteNotReady", "Waiting for HTTPRoute becomes Ready.")
+			}
 		}
 	}
 
-	externalIngressTLS := ing.GetIngressTLSForVisibility(v1alpha1.IngressVisibilityExternalIP)
-	listeners := make([]*gatewayapi.Listener, 0, len(externalIngressTLS))
-	for _, tls := range externalIngressTLS {
-		l, err := c.reconcileTLS(ctx, &tls, ing)
-		if err != nil {
-			return err
+	if err := c.clearHTTPRoutes(ctx, ing, httproutes); err != nil {
+		return err
+	}
+
+	var listeners []*gatewayapi.Listener
+	if passthrough {
+		// The backends terminate TLS, so there are no certificates to reference
+		listeners = makePassthroughListeners(pluginConfig.ExternalGateway(), ing)
+	} else {
+		externalIngressTLS := ing.GetIngressTLSForVisibility(v1alpha1.IngressVisibilityExternalIP)
+		listeners = make([]*gatewayapi.Listener, 0, len(externalIngressTLS))
+		for _, tls := range externalIngressTLS {
+			l, err := c.reconcileTLS(ctx, &tls, ing)
+			if errors.Is(err, errTLSSecretInvalid) {
+				// Retrying won't help until the secret changes
+				controller.GetEventRecorder(ctx).Event(ing, corev1.EventTypeWarning, tlsSecretInvalidReason, err.Error())
+				ing.Status.MarkIngressNotReady(tlsSecretInvalidReason, err.Error())
+				return nil
+			} else if err != nil {
+				return err
+			}
+			listeners = append(listeners, l...)
 		}
-		listeners = append(listeners, l...)
 	}
 
 	if len(listeners) > 0 {
@@ -141,11 +433,50 @@ func (c *Reconciler) reconcileIngress(ctx context.Context, ing *v1alpha1.Ingress
 		if err != nil {
 			return err
 		}
+
+		msg, err := c.unresolvedListener(listeners, ing, pluginConfig.ExternalGateway().NamespacedName)
+		if err != nil {
+			return err
+		} else if msg != "" {
+			// Retrying won't help until the Gateway status changes
+			ing.Status.MarkIngressNotReady(listenerNotResolvedReason, msg)
+			return nil
+		}
+	}
+
+	ingKey := types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name}
+	if len(notReady) > 0 {
+		if c.probeFailures.failed(ingKey) {
+			controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeWarning, "ProbesNotReady",
+				"Waiting for probes to succeed: %s", describeProbeTargets(pluginConfig, notReady))
+		}
+		// Until a probe fails, e.g. while the first ones are in flight, the
+		// previous failure is kept
+		if probeFailure != "" {
+			setLastProbeFailure(ing, probeFailure)
+		}
+	} else {
+		c.probeFailures.reset(ingKey)
+		setLastProbeFailure(ing, "")
+	}
+
+	if len(ing.Spec.Rules) == 0 {
+		// Nothing routes the hosts, e.g. of the TLS of the Ingress, and no
+		// listeners are made for TLS hosts without rules
+		ing.Status.MarkLoadBalancerNotReady()
+		ing.Status.MarkIngressNotReady(noRulesReason, noRulesMessage)
+		return nil
+	}
+
+	if gateway, err := c.notProgrammedGateway(pluginConfig, visibilities); err != nil {
+		return err
+	} else if gateway != nil {
+		return waitForGateway(ctx, ing, pluginConfig, *gateway)
 	}
+	setGatewayNotProgrammedSince(ing, time.Time{})
 
-	// TODO: check Gateway readiness before reporting Ingress ready
 	if routesReady {
-		externalLBs, internalLBs, err := c.lookUpLoadBalancers(ing, pluginConfig)
+		externalLBs, internalLBs, err := c.lookUpLoadBalancers(ctx, ing, pluginConfig)
 		if err != nil {
 			if ok := errors.Is(err, ErrGatewayNotFound); ok {
 				// if we can't find a Gateway, we mark it as failed, and
@@ -157,22 +488,417 @@ func (c *Reconciler) reconcileIngress(ctx context.Context, ing *v1alpha1.Ingress
 		}
 
 		ing.Status.MarkLoadBalancerReady(externalLBs, internalLBs)
+	} else if remaining := readyGraceRemaining(ing, pluginConfig, routesAccepted && probesFailing, lastReady); remaining > 0 {
+		// Keep reporting the previous Ready state while probes are failing
+		// within the grace period, and check again once it elapses.
+		return controller.NewRequeueAfter(remaining)
 	} else {
-		ing.Status.MarkLoadBalancerNotReady()
+		markLoadBalancerNotReady(ing, visibilities, notReadyVisibilities)
 	}
 
 	return nil
 }
 
+// routePart is a part of the paths of a rule of the Ingress, routed by its
+// own HTTPRoute.
+type routePart struct {
+	rule v1alpha1.IngressRule
+	part int
+	// firstOfHosts is whether the part is the first one of its hosts, which
+	// reconciles the resources named after them.
+	firstOfHosts bool
+}
+
+// routeParts returns the rules of the Ingress, with its HTTP rules split
+// across parts with at most resources.MaxHTTPRouteHostnames hosts and making
+// at most maxRules HTTPRoute rules each.
+func routeParts(ing *v1alpha1.Ingress, passthrough bool, maxRules int) []routePart {
+	parts := make([]routePart, 0, len(ing.Spec.Rules))
+	for _, rule := range ing.Spec.Rules {
+		if passthrough && rule.Visibility != v1alpha1.IngressVisibilityClusterLocal {
+			// TLSRoutes have no paths
+			parts = append(parts, routePart{rule: rule, firstOfHosts: true})
+			continue
+		}
+		splits := resources.SplitHTTPRouteRule(&rule, maxRules)
+		for i, split := range splits {
+			parts = append(parts, routePart{
+				rule:         split,
+				part:         i,
+				firstOfHosts: i == 0 || !slices.Equal(split.Hosts, splits[i-1].Hosts),
+			})
+		}
+	}
+	return parts
+}
+
+// skipRulesWithoutPaths removes the rules of the Ingress without HTTP paths,
+// which route nothing and can't be probed, with a warning event. They are
+// rejected by the webhook, but may have been stored before it validated them.
+// The rules without an HTTP value are skipped before the Ingress is defaulted,
+// as the defaulting dereferences it.
+func (c *Reconciler) skipRulesWithoutPaths(ctx context.Context, ing *v1alpha1.Ingress) {
+	var hosts []string
+	ing.Spec.Rules = slices.DeleteFunc(ing.Spec.Rules, func(rule v1alpha1.IngressRule) bool {
+		if rule.HTTP != nil && len(rule.HTTP.Paths) > 0 {
+			return false
+		}
+		hosts = append(hosts, rule.Hosts...)
+		return true
+	})
+	if len(hosts) > 0 {
+		controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeWarning, noPathsReason,
+			"Skipping the rules without HTTP paths of hosts %s", strings.Join(hosts, ", "))
+	}
+}
+
+// warnUnmatchedHosts emits a warning event for the hosts of the HTTP rules
+// that don't match the hostname of any HTTP listener of their Gateway, as
+// their routes would attach without routing anything. When only some of the
+// hosts of a rule match, the route serves the others, so an informational
+// event lists the served and unserved hosts instead.
+func (c *Reconciler) warnUnmatchedHosts(ctx context.Context, ing *v1alpha1.Ingress, passthrough bool) {
+	pluginConfig := config.FromContext(ctx).GatewayPlugin
+
+	for _, rule := range ing.Spec.Rules {
+		if passthrough && rule.Visibility != v1alpha1.IngressVisibilityClusterLocal {
+			continue
+		}
+
+		gwc := pluginConfig.ExternalGateway()
+		if rule.Visibility == v1alpha1.IngressVisibilityClusterLocal {
+			gwc = pluginConfig.LocalGateway()
+		}
+		gw, err := c.gatewayLister.Gateways(gwc.Namespace).Get(gwc.Name)
+		if err != nil {
+			// Missing Gateways are reported when they are needed
+			continue
+		}
+
+		var matched, unmatched []string
+		for _, host := range rule.Hosts {
+			if slices.ContainsFunc(gw.Spec.Listeners, func(l gatewayapi.Listener) bool {
+				// Routes pinned to a listener only attach to that one
+				if (gwc.SectionName != nil && l.Name != *gwc.SectionName) || (gwc.Port != nil && l.Port != *gwc.Port) {
+					return false
+				}
+				return (l.Protocol == gatewayapi.HTTPProtocolType || l.Protocol == gatewayapi.HTTPSProtocolType) &&
+					hostnameMatches(l.Hostname, host)
+			}) {
+				matched = append(matched, host)
+			} else {
+				unmatched = append(unmatched, host)
+			}
+		}
+		switch {
+		case len(unmatched) == 0:
+			// All the hosts are served
+		case len(matched) == 0:
+			controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeWarning, unmatchedHostsReason,
+				"Hosts %s don't match the hostname of any listener of Gateway %s", strings.Join(unmatched, ", "), gwc.NamespacedName)
+		default:
+			controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeNormal, partiallyMatchedHostsReason,
+				"Only hosts %s are served through Gateway %s, hosts %s don't match the hostname of any of its listeners",
+				strings.Join(matched, ", "), gwc.NamespacedName, strings.Join(unmatched, ", "))
+		}
+	}
+}
+
+// hostnameMatches returns whether the host matches the hostname of a
+// listener, following the Gateway API rules for wildcards.
+func hostnameMatches(hostname *gatewayapi.Hostname, host string) bool {
+	if hostname == nil || *hostname == "" {
+		return true
+	}
+	if suffix, ok := strings.CutPrefix(string(*hostname), "*"); ok {
+		return len(host) > len(suffix) && strings.HasSuffix(host, suffix)
+	}
+	return string(*hostname) == host
+}
+
+// markLoadBalancerNotReady marks the load balancer of the Ingress as not
+// ready. When the routes of some visibilities are ready, the condition names
+// the visibilities that are still waiting.
+func markLoadBalancerNotReady(ing *v1alpha1.Ingress, visibilities, notReady sets.Set[v1alpha1.IngressVisibility]) {
+	ready := visibilities.Difference(notReady)
+	if ready.Len() == 0 || notReady.Len() == 0 {
+		ing.Status.MarkLoadBalancerNotReady()
+		return
+	}
+	ing.GetConditionSet().Manage(&ing.Status).MarkUnknown(v1alpha1.IngressConditionLoadBalancerReady,
+		partiallyReadyReason, "Waiting for %s routes to be ready, %s routes are ready",
+		joinVisibilities(notReady), joinVisibilities(ready))
+}
+
+func joinVisibilities(visibilities sets.Set[v1alpha1.IngressVisibility]) string {
+	names := make([]string, 0, visibilities.Len())
+	for _, visibility := range sets.List(visibilities) {
+		names = append(names, string(visibility))
+	}
+	return strings.Join(names, ", ")
+}
+
+// duplicateRouteNames returns a message describing the rules of the Ingress
+// that would be reconciled into routes of the same kind with the same name,
+// or an empty string if there are none.
+func duplicateRouteNames(ing *v1alpha1.Ingress, passthrough bool) string {
+	type routeKey struct {
+		name string
+		tls  bool
+	}
+	seen := make(map[routeKey]int, len(ing.Spec.Rules))
+	for i, rule := range ing.Spec.Rules {
+		if len(rule.Hosts) == 0 {
+			continue
+		}
+		key := routeKey{
+			name: resources.LongestHost(slices.Clone(rule.Hosts)),
+			tls:  passthrough && rule.Visibility != v1alpha1.IngressVisibilityClusterLocal,
+		}
+		if j, ok := seen[key]; ok {
+			if other := ing.Spec.Rules[j].Visibility; other != rule.Visibility {
+				// The routes of both visibilities are named after their hosts
+				return fmt.Sprintf("rules [%d] (%s) and [%d] (%s) both generate a route named %q, a host can't be exposed with both visibilities",
+					j, other, i, rule.Visibility, key.name)
+			}
+			return fmt.Sprintf("rules [%d] and [%d] both generate a route named %q", j, i, key.name)
+		}
+		seen[key] = i
+	}
+	return ""
+}
+
+// missingGateway returns the Gateway of a rule of the Ingress that doesn't
+// exist, or nil if there is none. The Gateways are tracked so that the
+// Ingress is reconciled once they are created.
+func (c *Reconciler) missingGateway(ing *v1alpha1.Ingress, gpc *config.GatewayPlugin) (*types.NamespacedName, error) {
+	visibilities := sets.New[v1alpha1.IngressVisibility]()
+	for _, rule := range ing.Spec.Rules {
+		visibilities.Insert(rule.Visibility)
+	}
+
+	for _, visibility := range sets.List(visibilities) {
+		gwc := gpc.ExternalGateway()
+		if visibility == v1alpha1.IngressVisibilityClusterLocal {
+			gwc = gpc.LocalGateway()
+		}
+
+		if err := c.tracker.TrackReference(tracker.Reference{
+			APIVersion: gatewayapi.GroupVersion.String(),
+			Kind:       "Gateway",
+			Namespace:  gwc.Namespace,
+			Name:       gwc.Name,
+		}, ing); err != nil {
+			return nil, fmt.Errorf("failed to track Gateway: %w", err)
+		}
+
+		if _, err := c.gatewayLister.Gateways(gwc.Namespace).Get(gwc.Name); apierrs.IsNotFound(err) {
+			return &gwc.NamespacedName, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get Gateway %s: %w", gwc.NamespacedName, err)
+		}
+	}
+	return nil, nil
+}
+
+// notProgrammedGateway returns the Gateway of the visibilities that reports it
+// isn't Programmed, or nil if there is none. Gateways without the condition
+// are assumed to be Programmed, and missing ones are reported elsewhere.
+func (c *Reconciler) notProgrammedGateway(gpc *config.GatewayPlugin, visibilities sets.Set[v1alpha1.IngressVisibility]) (*types.NamespacedName, error) {
+	for _, visibility := range sets.List(visibilities) {
+		gwc := gpc.ExternalGateway()
+		if visibility == v1alpha1.IngressVisibilityClusterLocal {
+			gwc = gpc.LocalGateway()
+		}
+
+		gw, err := c.gatewayLister.Gateways(gwc.Namespace).Get(gwc.Name)
+		if apierrs.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get Gateway %s: %w", gwc.NamespacedName, err)
+		}
+
+		cond := meta.FindStatusCondition(gw.Status.Conditions, string(gatewayapi.GatewayConditionProgrammed))
+		if cond != nil && cond.Status != metav1.ConditionTrue {
+			return &gwc.NamespacedName, nil
+		}
+	}
+	return nil, nil
+}
+
+// waitForGateway marks the Ingress not ready while the Gateway isn't
+// Programmed, and failed once it has been waiting for longer than the
+// GatewayProgrammedTimeout. The Gateway is checked again periodically, as
+// its changes don't reconcile the Ingress.
+func waitForGateway(ctx context.Context, ing *v1alpha1.Ingress, gpc *config.GatewayPlugin, gateway types.NamespacedName) error {
+	since := gatewayNotProgrammedSince(ing)
+	if since.IsZero() {
+		since = time.Now()
+		setGatewayNotProgrammedSince(ing, since)
+	}
+
+	if gpc.GatewayProgrammedTimeout > 0 && time.Since(since) >= gpc.GatewayProgrammedTimeout {
+		msg := fmt.Sprintf("Gateway %s has not been Programmed for more than %v.", gateway, gpc.GatewayProgrammedTimeout)
+		// The event is only emitted when the Ingress starts failing
+		if ing.Status.GetCondition(v1alpha1.IngressConditionLoadBalancerReady).GetReason() != gatewayNotProgrammedTimeoutReason {
+			controller.GetEventRecorder(ctx).Event(ing, corev1.EventTypeWarning, gatewayNotProgrammedTimeoutReason, msg)
+		}
+		ing.Status.MarkLoadBalancerFailed(gatewayNotProgrammedTimeoutReason, msg)
+		return controller.NewRequeueAfter(gatewayRecheckInterval)
+	}
+
+	ing.Status.MarkLoadBalancerNotReady()
+	ing.Status.MarkIngressNotReady(gatewayNotProgrammedReason, fmt.Sprintf("Waiting for Gateway %s to be Programmed.", gateway))
+	recheck := gatewayRecheckInterval
+	if gpc.GatewayProgrammedTimeout > 0 {
+		recheck = min(recheck, gpc.GatewayProgrammedTimeout-time.Since(since))
+	}
+	return controller.NewRequeueAfter(recheck)
+}
+
+// gatewayNotProgrammedSince returns the time a Gateway of the Ingress was
+// first seen not Programmed, or the zero time if it wasn't.
+func gatewayNotProgrammedSince(ing *v1alpha1.Ingress) time.Time {
+	since, err := time.Parse(time.RFC3339, ing.Status.Annotations[GatewayNotProgrammedSinceAnnotationKey])
+	if err != nil {
+		return time.Time{}
+	}
+	return since
+}
+
+// setGatewayNotProgrammedSince records the time a Gateway of the Ingress was
+// first seen not Programmed in its status annotations, or removes it when
+// zero.
+func setGatewayNotProgrammedSince(ing *v1alpha1.Ingress, since time.Time) {
+	if since.IsZero() {
+		delete(ing.Status.Annotations, GatewayNotProgrammedSinceAnnotationKey)
+		if len(ing.Status.Annotations) == 0 {
+			ing.Status.Annotations = nil
+		}
+		return
+	}
+
+	if ing.Status.Annotations == nil {
+		ing.Status.Annotations = make(map[string]string, 1)
+	}
+	ing.Status.Annotations[GatewayNotProgrammedSinceAnnotationKey] = since.UTC().Format(time.RFC3339)
+}
+
+// readyGraceRemaining returns how much longer an Ingress that was previously
+// Ready can keep its LoadBalancerReady condition while its probes are failing.
+func readyGraceRemaining(ing *v1alpha1.Ingress, gpc *config.GatewayPlugin, probesFailing bool, lastReady time.Time) time.Duration {
+	if !probesFailing || gpc.ReadyGracePeriod <= 0 || lastReady.IsZero() {
+		return 0
+	}
+	if !ing.Status.GetCondition(v1alpha1.IngressConditionLoadBalancerReady).IsTrue() {
+		return 0
+	}
+	return gpc.ReadyGracePeriod - time.Since(lastReady)
+}
+
+// describeProbeTargets lists the probed hosts and the Gateway they are probed
+// through for each visibility. The output is sorted so that repeated events
+// for the same targets are identical.
+func describeProbeTargets(gpc *config.GatewayPlugin, targets []status.Backends) string {
+	hosts := make(map[v1alpha1.IngressVisibility]sets.Set[string], 2)
+	for _, backends := range targets {
+		for visibility, urls := range backends.URLs {
+			if hosts[visibility] == nil {
+				hosts[visibility] = sets.New[string]()
+			}
+			for u := range urls {
+				hosts[visibility].Insert(u.Host)
+			}
+		}
+	}
+
+	visibilities := make([]v1alpha1.IngressVisibility, 0, len(hosts))
+	for visibility := range hosts {
+		visibilities = append(visibilities, visibility)
+	}
+	slices.Sort(visibilities)
+
+	descriptions := make([]string, 0, len(visibilities))
+	for _, visibility := range visibilities {
+		gwc := gpc.ExternalGateway()
+		if visibility == v1alpha1.IngressVisibilityClusterLocal {
+			gwc = gpc.LocalGateway()
+		}
+		descriptions = append(descriptions, fmt.Sprintf("hosts [%s] via %s",
+			strings.Join(sets.List(hosts[visibility]), ", "), gatewayAddress(gwc)))
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// gatewayAddress returns the address probes for the given Gateway are sent to.
+func gatewayAddress(gwc config.Gateway) string {
+	if gwc.Service != nil {
+		return network.GetServiceHostname(gwc.Service.Name, gwc.Service.Namespace)
+	}
+	return "Gateway " + gwc.NamespacedName.String()
+}
+
+// probeFailures counts the consecutive reconciles with failing probes of each
+// Ingress. The zero value is ready to use.
+type probeFailures struct {
+	mu     sync.Mutex
+	counts map[types.NamespacedName]int
+}
+
+// failed records a reconcile with failing probes for the Ingress and returns
+// true when a ProbesNotReady event should be emitted for it.
+func (p *probeFailures) failed(key types.NamespacedName) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.counts == nil {
+		p.counts = make(map[types.NamespacedName]int)
+	}
+	p.counts[key]++
+	return p.counts[key]%probeEventInterval == 0
+}
+
+// reset forgets the failures of the Ingress.
+func (p *probeFailures) reset(key types.NamespacedName) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.counts, key)
+}
+
+// setLastProbeFailure records the last failed probe in the status annotations
+// of the Ingress, or removes it when empty.
+func setLastProbeFailure(ing *v1alpha1.Ingress, failure string) {
+	if failure == "" {
+		delete(ing.Status.Annotations, LastProbeFailureAnnotationKey)
+		if len(ing.Status.Annotations) == 0 {
+			ing.Status.Annotations = nil
+		}
+		return
+	}
+
+	if len(failure) > maxProbeFailureLength {
+		// Drop the bytes of a rune cut in half
+		failure = strings.ToValidUTF8(failure[:maxProbeFailureLength-3], "") + "..."
+	}
+	if ing.Status.Annotations == nil {
+		ing.Status.Annotations = make(map[string]string, 1)
+	}
+	ing.Status.Annotations[LastProbeFailureAnnotationKey] = failure
+}
+
 // lookUpLoadBalancers will return a map of visibilites to
 // LoadBalancerIngressStatuses for the current Gateways in use.
-func (c *Reconciler) lookUpLoadBalancers(ing *v1alpha1.Ingress, gpc *config.GatewayPlugin) ([]v1alpha1.LoadBalancerIngressStatus, []v1alpha1.LoadBalancerIngressStatus, error) {
-	externalStatuses, err := c.collectLBIngressStatus(ing, gpc.ExternalGateway())
+func (c *Reconciler) lookUpLoadBalancers(ctx context.Context, ing *v1alpha1.Ingress, gpc *config.GatewayPlugin) ([]v1alpha1.LoadBalancerIngressStatus, []v1alpha1.LoadBalancerIngressStatus, error) {
+	externalStatuses, err := c.collectLBIngressStatus(ctx, ing, gpc.ExternalGateway(), v1alpha1.IngressVisibilityExternalIP)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	internalStatuses, err := c.collectLBIngressStatus(ing, gpc.LocalGateway())
+	if !gpc.HasLocalGateway() {
+		return externalStatuses, []v1alpha1.LoadBalancerIngressStatus{}, nil
+	}
+
+	internalStatuses, err := c.collectLBIngressStatus(ctx, ing, gpc.LocalGateway(), v1alpha1.IngressVisibilityClusterLocal)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -182,18 +908,27 @@ func (c *Reconciler) lookUpLoadBalancers(ing *v1alpha1.Ingress, gpc *config.Gate
 
 // collectLBIngressStatus will return LoadBalancerIngressStatuses for the
 // provided single Gateway config. If a service is available on a Gateway, it will
-// return the address of the service. Otherwise, it will return the first
-// address in the Gateway status.
-func (c *Reconciler) collectLBIngressStatus(ing *v1alpha1.Ingress, gwc config.Gateway) ([]v1alpha1.LoadBalancerIngressStatus, error) {
+// return the address of the service, which is the address of its load balancer
+// for the external visibility. Otherwise, it will return the first address in
+// the Gateway status. Named addresses are taken to be the name of the Service
+// backing the Gateway in its namespace.
+func (c *Reconciler) collectLBIngressStatus(
+	ctx context.Context,
+	ing *v1alpha1.Ingress,
+	gwc config.Gateway,
+	visibility v1alpha1.IngressVisibility,
+) ([]v1alpha1.LoadBalancerIngressStatus, error) {
 	statuses := []v1alpha1.LoadBalancerIngressStatus{}
 
 	// TODO: currently only 1 gateway is supported. When the config is updated to
 	// support multiple, this code must change to find out which Gateway is
 	// appropriate for the given Ingress
 	if gwc.Service != nil {
-		statuses = append(statuses, v1alpha1.LoadBalancerIngressStatus{
-			DomainInternal: network.GetServiceHostname(gwc.Service.Name, gwc.Service.Namespace),
-		})
+		status, err := c.serviceLBIngressStatus(*gwc.Service, visibility)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
 	} else {
 		gw, err := c.gatewayLister.Gateways(gwc.Namespace).Get(gwc.Name)
 		if err != nil {
@@ -211,13 +946,22 @@ func (c *Reconciler) collectLBIngressStatus(ing *v1alpha1.Ingress, gwc config.Ga
 			return nil, fmt.Errorf("failed to get Gateway \"%s/%s\": %w", gwc.Namespace, gwc.Name, err)
 		}
 
-		if len(gw.Status.Addresses) > 0 {
-			switch *gw.Status.Addresses[0].Type {
+		if addr, ok := gatewayStatusAddress(gw, gwc); ok {
+			// The address type defaults to IPAddress when unset
+			switch addrType := ptr.Deref(addr.Type, gatewayapi.IPAddressType); addrType {
 			case gatewayapi.IPAddressType:
-				statuses = append(statuses, v1alpha1.LoadBalancerIngressStatus{IP: gw.Status.Addresses[0].Value})
+				statuses = append(statuses, v1alpha1.LoadBalancerIngressStatus{IP: statusAddressValue(addr)})
+			case gatewayapi.NamedAddressType:
+				statuses = append(statuses, v1alpha1.LoadBalancerIngressStatus{
+					DomainInternal: network.GetServiceHostname(addr.Value, gw.Namespace),
+				})
 			default:
+				if addrType != gatewayapi.HostnameAddressType {
+					logging.FromContext(ctx).Warnf("Unknown address type %q of Gateway %s/%s, using its value %q as a hostname",
+						addrType, gw.Namespace, gw.Name, addr.Value)
+				}
 				// Should this actually be under Domain? It seems like the rest of the code expects DomainInternal though...
-				statuses = append(statuses, v1alpha1.LoadBalancerIngressStatus{DomainInternal: gw.Status.Addresses[0].Value})
+				statuses = append(statuses, v1alpha1.LoadBalancerIngressStatus{DomainInternal: addr.Value})
 			}
 		} else {
 			return nil, fmt.Errorf("no address found in status of Gateway %s/%s", gwc.Namespace, gwc.Name)
@@ -227,13 +971,51 @@ func (c *Reconciler) collectLBIngressStatus(ing *v1alpha1.Ingress, gwc config.Ga
 	return statuses, nil
 }
 
-// isHTTPRouteReady will check the status conditions of the ingress and return true if
+// serviceLBIngressStatus returns the LoadBalancerIngressStatus of the Service
+// of a Gateway. Gateways fronted by an external load balancer are reached
+// through its address rather than the cluster hostname of the Service, so
+// the first ingress point of the load balancer is used for the external
+// visibility when it has been provisioned.
+func (c *Reconciler) serviceLBIngressStatus(
+	name types.NamespacedName,
+	visibility v1alpha1.IngressVisibility,
+) (v1alpha1.LoadBalancerIngressStatus, error) {
+	status := v1alpha1.LoadBalancerIngressStatus{
+		DomainInternal: network.GetServiceHostname(name.Name, name.Namespace),
+	}
+	if visibility != v1alpha1.IngressVisibilityExternalIP {
+		return status, nil
+	}
+
+	svc, err := c.serviceLister.Services(name.Namespace).Get(name.Name)
+	if apierrs.IsNotFound(err) {
+		return status, nil
+	} else if err != nil {
+		return v1alpha1.LoadBalancerIngressStatus{}, fmt.Errorf("failed to get Service %s: %w", name, err)
+	}
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return status, nil
+	}
+
+	for _, lb := range svc.Status.LoadBalancer.Ingress {
+		switch {
+		case lb.IP != "":
+			return v1alpha1.LoadBalancerIngressStatus{IP: lb.IP}, nil
+		case lb.Hostname != "":
+			return v1alpha1.LoadBalancerIngressStatus{Domain: lb.Hostname}, nil
+		}
+	}
+	// The load balancer isn't provisioned yet
+	return status, nil
+}
+
+// isRouteReady will check the status conditions of the route and return true if
 // all gateways have been admitted.
-func isHTTPRouteReady(r *gatewayapi.HTTPRoute) bool {
-	if r.Status.Parents == nil {
+func isRouteReady(r *gatewayapi.RouteStatus) bool {
+	if r.Parents == nil {
 		return false
 	}
-	for _, gw := range r.Status.Parents {
+	for _, gw := range r.Parents {
 		if !isGatewayAdmitted(gw) {
 			// Return false if _any_ of the gateways isn't admitted yet.
 			return false
@@ -242,6 +1024,18 @@ func isHTTPRouteReady(r *gatewayapi.HTTPRoute) bool {
 	return true
 }
 
+// isRouteProgrammed returns false when any of the Gateways reports it has not
+// programmed the route yet. Gateways that don't report it are trusted to have
+// programmed the routes they accepted.
+func isRouteProgrammed(r *gatewayapi.RouteStatus) bool {
+	for _, gw := range r.Parents {
+		if cond := meta.FindStatusCondition(gw.Conditions, routeConditionProgrammed); cond != nil && cond.Status != metav1.ConditionTrue {
+			return false
+		}
+	}
+	return true
+}
+
 func isGatewayAdmitted(gw gatewayapi.RouteParentStatus) bool {
 	for _, condition := range gw.Conditions {
 		if condition.Type == string(gatewayapi.RouteConditionAccepted) {
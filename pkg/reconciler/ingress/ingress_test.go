@@ -18,15 +18,24 @@ package ingress
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"gomodules.xyz/jsonpatch/v2"
 	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	clientgotesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/utils/ptr"
 
 	fakegwapiclientset "knative.dev/net-gateway-api/pkg/client/injection/client/fake"
@@ -42,11 +51,14 @@ import (
 	"knative.dev/networking/pkg/ingress"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/network"
 
 	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gatewayapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	"sigs.k8s.io/gateway-api/pkg/features"
 
 	. "knative.dev/net-gateway-api/pkg/reconciler/testing"
 	. "knative.dev/pkg/reconciler/testing"
@@ -65,6 +77,13 @@ var (
 	publicGatewayAddress  = "11.22.33.44"
 	publicGatewayHostname = "off.cluster.gateway"
 	privateGatewayAddress = "55.66.77.88"
+
+	preservedRewriteHostMessage = `rewrite host of an Ingress preserving the host: annotation "gateway-api.networking.knative.dev/preserve-host" ` +
+		`can't be used with the rewrite host "goo.ns.svc.cluster.local" of a rule with several hosts`
+
+	invalidRewriteHostMessage = `invalid rewrite host "*.example.com": a lowercase RFC 1123 subdomain must consist of ` +
+		`lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character ` +
+		`(e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`
 )
 
 var (
@@ -196,6 +215,282 @@ func TestReconcile(t *testing.T) {
 			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
 		}, servicesAndEndpoints...),
 		// no extra update
+	}, {
+		Name: "reconcile ready ingress - gateway service behind a load balancer",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, makeItReady, withFinalizer),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			// The first ingress point of the load balancer is reported
+			loadBalancerService(publicName,
+				corev1.LoadBalancerIngress{IP: "34.1.2.3"},
+				corev1.LoadBalancerIngress{Hostname: "lb.example.com"}),
+			loadBalancerService(privateName, corev1.LoadBalancerIngress{IP: "10.1.2.3"}),
+		}, endpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, makeItReady, withFinalizer, func(i *v1alpha1.Ingress) {
+				// The cluster-local visibility keeps the cluster hostname
+				i.Status.MarkLoadBalancerReady(
+					[]v1alpha1.LoadBalancerIngressStatus{{IP: "34.1.2.3"}},
+					[]v1alpha1.LoadBalancerIngressStatus{{DomainInternal: privateSvc}})
+			}),
+		}},
+	}, {
+		Name: "reconcile ready ingress - load balancer hostname",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, makeItReady, withFinalizer),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			loadBalancerService(publicName, corev1.LoadBalancerIngress{Hostname: "lb.example.com"}),
+		}, endpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, makeItReady, withFinalizer, func(i *v1alpha1.Ingress) {
+				i.Status.MarkLoadBalancerReady(
+					[]v1alpha1.LoadBalancerIngressStatus{{Domain: "lb.example.com"}},
+					[]v1alpha1.LoadBalancerIngressStatus{{DomainInternal: privateSvc}})
+			}),
+		}},
+	}, {
+		Name: "reconcile ready ingress - load balancer not provisioned",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, makeItReady, withFinalizer),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			loadBalancerService(publicName),
+		}, endpoints...),
+		// no extra update
+	}, {
+		Name: "reconcile ready ingress - stale routes deleted",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, makeItReady, withFinalizer),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			// The route of a host the Ingress no longer has
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), func(h *gatewayapi.HTTPRoute) {
+				h.Name = "old.example.com"
+			}),
+			// Not owned by the Ingress
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), func(h *gatewayapi.HTTPRoute) {
+				h.Name = "other.example.com"
+				h.OwnerReferences = nil
+			}),
+		}, servicesAndEndpoints...),
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+				Verb:      "delete",
+				Resource:  gatewayapi.SchemeGroupVersion.WithResource("httproutes"),
+			},
+			Name: "old.example.com",
+		}},
+	}, {
+		Name: "reconcile ready ingress - route of a dropped rule deleted",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, makeItReady, withFinalizer),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			// The route of the cluster-local rule the Ingress dropped
+			httpRouteForRule(t, ing(withBasicSpec, withInternalSpec, withGatewayAPIclass), 1, httpRouteReady),
+		}, servicesAndEndpoints...),
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+				Verb:      "delete",
+				Resource:  gatewayapi.SchemeGroupVersion.WithResource("httproutes"),
+			},
+			Name: "foo.svc.cluster.local",
+		}},
+	}, {
+		Name: "reconcile ready ingress - rule without HTTP paths skipped",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, makeItReady, withFinalizer, func(i *v1alpha1.Ingress) {
+				i.Spec.Rules = append(i.Spec.Rules, v1alpha1.IngressRule{
+					Hosts:      []string{"empty.example.com"},
+					Visibility: v1alpha1.IngressVisibilityExternalIP,
+					HTTP:       &v1alpha1.HTTPIngressRuleValue{},
+				})
+			}),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "RuleWithoutPaths", "Skipping the rules without HTTP paths of hosts empty.example.com"),
+		},
+	}, {
+		Name: "reconcile ready ingress - unchanged inputs skip rebuilding the route",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, makeItReady, withFinalizer),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		// no extra update
+	}, {
+		Name: "reconcile ready ingress - changed route spec reverted",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, makeItReady, withFinalizer),
+			// The inputs hash matches, but not the spec hash.
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady, func(h *gatewayapi.HTTPRoute) {
+				h.Spec.Hostnames = append(h.Spec.Hostnames, "unmanaged.example.com")
+			}),
+		}, servicesAndEndpoints...),
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}},
+	}, {
+		Name: "reconcile ready ingress - hosts not matching any listener",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, makeItReady, withFinalizer),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			gw(func(g *gatewayapi.Gateway) {
+				g.Spec.Listeners = []gatewayapi.Listener{{
+					Name:     "http",
+					Port:     80,
+					Protocol: gatewayapi.HTTPProtocolType,
+					Hostname: ptr.To[gatewayapi.Hostname]("*.other.com"),
+				}, {
+					Name:     "tls",
+					Port:     443,
+					Protocol: gatewayapi.TLSProtocolType,
+				}}
+			}),
+		}, servicesAndEndpoints...),
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "UnmatchedHosts", "Hosts example.com don't match the hostname of any listener of Gateway istio-system/istio-gateway"),
+		},
+	}, {
+		Name: "reconcile ready ingress - hosts partially matching the listeners",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withOtherHost, makeItReady, withFinalizer),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass, withOtherHost), httpRouteReady),
+			gw(func(g *gatewayapi.Gateway) {
+				g.Spec.Listeners = []gatewayapi.Listener{{
+					Name:     "http",
+					Port:     80,
+					Protocol: gatewayapi.HTTPProtocolType,
+					Hostname: ptr.To[gatewayapi.Hostname]("*.com"),
+				}}
+			}),
+		}, servicesAndEndpoints...),
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "PartiallyMatchedHosts", "Only hosts example.com are served through Gateway istio-system/istio-gateway, hosts example.org don't match the hostname of any of its listeners"),
+		},
+	}, {
+		Name: "reconcile ingress - route accepted but not programmed",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady, httpRouteNotProgrammed),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("HTTPRouteNotProgrammed", "Waiting for HTTPRoute to be programmed.")
+				i.Status.MarkLoadBalancerNotReady()
+			}),
+		}},
+	}, {
+		Name: "reconcile ready ingress - route accepted and programmed",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, makeItReady, withFinalizer),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady, func(h *gatewayapi.HTTPRoute) {
+				h.Status.Parents[0].Conditions = append(h.Status.Parents[0].Conditions, metav1.Condition{
+					Type:   "Programmed",
+					Status: metav1.ConditionTrue,
+				})
+			}),
+		}, servicesAndEndpoints...),
+		// no extra update
+	}, {
+		Name: "reconcile ready ingress - route update conflict",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withBackendAppendHeaders("K-Foo", "bar"), makeItReady, withFinalizer),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		WithReactors: []clientgotesting.ReactionFunc{conflictOnce("update", "httproutes")},
+		// The conflicting update is applied once more to the latest route.
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: httpRoute(t, ing(withBasicSpec, withGatewayAPIclass, withBackendAppendHeaders("K-Foo", "bar")), httpRouteReady),
+		}, {
+			Object: httpRoute(t, ing(withBasicSpec, withGatewayAPIclass, withBackendAppendHeaders("K-Foo", "bar")), httpRouteReady),
+		}},
+	}, {
+		Name:    "reconcile ready ingress - route taken over before the update conflict",
+		Key:     "ns/name",
+		WantErr: true,
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withBackendAppendHeaders("K-Foo", "bar"), makeItReady, withFinalizer),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		WithReactors: []clientgotesting.ReactionFunc{
+			conflictOnce("update", "httproutes"),
+			// The latest route on the API server has another owner.
+			func(action clientgotesting.Action) (bool, runtime.Object, error) {
+				if !action.Matches("get", "httproutes") {
+					return false, nil, nil
+				}
+				return true, httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady, func(r *gatewayapi.HTTPRoute) {
+					r.OwnerReferences = nil
+				}), nil
+			},
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: httpRoute(t, ing(withBasicSpec, withGatewayAPIclass, withBackendAppendHeaders("K-Foo", "bar")), httpRouteReady),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, withBackendAppendHeaders("K-Foo", "bar"), makeItReady, withFinalizer, func(i *v1alpha1.Ingress) {
+				i.Status.MarkIngressNotReady("ReconcileIngressFailed", "Ingress reconciliation failed")
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "NotOwned", "HTTPRoute example.com not owned by this object"),
+			Eventf(corev1.EventTypeWarning, "UpdateFailed", "Failed to update HTTPRoute: HTTPRoute example.com not owned by name"),
+			Eventf(corev1.EventTypeWarning, "InternalError", "failed to update HTTPRoute: HTTPRoute example.com not owned by name"),
+		},
+	}, {
+		Name: "rules with colliding route names",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withCollidingRule, withGatewayAPIclass),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withCollidingRule, withGatewayAPIclass, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("DuplicateRouteName", `rules [0] and [1] both generate a route named "example.com"`)
+			}),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+			},
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeWarning, "DuplicateRouteName", `rules [0] and [1] both generate a route named "example.com"`),
+		},
+	}, {
+		Name: "host exposed with both visibilities",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withCollidingLocalRule, withGatewayAPIclass, withFinalizer),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withCollidingLocalRule, withGatewayAPIclass, withFinalizer, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("DuplicateRouteName",
+					`rules [0] (ExternalIP) and [1] (ClusterLocal) both generate a route named "example.com", a host can't be exposed with both visibilities`)
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "DuplicateRouteName",
+				`rules [0] (ExternalIP) and [1] (ClusterLocal) both generate a route named "example.com", a host can't be exposed with both visibilities`),
+		},
 	}}
 
 	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
@@ -204,6 +499,7 @@ func TestReconcile(t *testing.T) {
 			// Listers index properties about resources
 			httprouteLister: listers.GetHTTPRouteLister(),
 			gatewayLister:   listers.GetGatewayLister(),
+			serviceLister:   listers.GetServiceLister(),
 			statusManager: &fakeStatusManager{
 				FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
 					return status.ProbeState{Ready: true}, nil
@@ -226,6 +522,176 @@ func TestReconcile(t *testing.T) {
 	}))
 }
 
+// conflictOnce fails the first matching action with a Conflict error, as if
+// another actor had modified the resource since it was read.
+func conflictOnce(verb, resource string) clientgotesting.ReactionFunc {
+	conflicted := false
+	return func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		if conflicted || !action.Matches(verb, resource) {
+			return false, nil, nil
+		}
+		conflicted = true
+		return true, nil, apierrs.NewConflict(gatewayapi.Resource(resource), "", errors.New("the object has been modified"))
+	}
+}
+
+func TestReconcileWithoutLocalGateway(t *testing.T) {
+	cfg := defaultConfig.DeepCopy()
+	cfg.GatewayPlugin.LocalGateways = []config.Gateway{}
+
+	table := TableTest{{
+		Name: "cluster-local rules not served",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withInternalSpec, withGatewayAPIclass, withFinalizer),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withInternalSpec, withGatewayAPIclass, withFinalizer, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkNetworkConfigured()
+				i.Status.MarkLoadBalancerReady(
+					[]v1alpha1.LoadBalancerIngressStatus{{DomainInternal: publicSvc}},
+					[]v1alpha1.LoadBalancerIngressStatus{})
+			}),
+		}},
+	}, {
+		Name: "stale cluster-local routes deleted",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withInternalSpec, withGatewayAPIclass, withFinalizer),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			httpRouteForRule(t, ing(withBasicSpec, withInternalSpec, withGatewayAPIclass), 1, httpRouteReady),
+		}, servicesAndEndpoints...),
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+				Verb:      "delete",
+				Resource:  gatewayapi.SchemeGroupVersion.WithResource("httproutes"),
+			},
+			Name: "foo.svc.cluster.local",
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withInternalSpec, withGatewayAPIclass, withFinalizer, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkNetworkConfigured()
+				i.Status.MarkLoadBalancerReady(
+					[]v1alpha1.LoadBalancerIngressStatus{{DomainInternal: publicSvc}},
+					[]v1alpha1.LoadBalancerIngressStatus{})
+			}),
+		}},
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			gwapiclient:     fakegwapiclientset.Get(ctx),
+			httprouteLister: listers.GetHTTPRouteLister(),
+			gatewayLister:   listers.GetGatewayLister(),
+			serviceLister:   listers.GetServiceLister(),
+			statusManager: &fakeStatusManager{
+				FakeDoProbes: func(_ context.Context, b status.Backends) (status.ProbeState, error) {
+					if _, ok := b.URLs[v1alpha1.IngressVisibilityClusterLocal]; ok {
+						return status.ProbeState{}, fmt.Errorf("unexpected cluster-local probing of %v", b.Key)
+					}
+					return status.ProbeState{Ready: true}, nil
+				},
+				FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+					return status.ProbeState{Ready: true}, true
+				},
+			},
+		}
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				},
+			})
+	}))
+}
+
+// reconcileKindFunc reconciles the Ingresses with ReconcileKind directly,
+// without the defaulting of the generated reconciler.
+type reconcileKindFunc func(ctx context.Context, key string) error
+
+func (f reconcileKindFunc) Reconcile(ctx context.Context, key string) error {
+	return f(ctx, key)
+}
+
+func TestReconcileRuleWithoutHTTP(t *testing.T) {
+	withoutHTTP := func(i *v1alpha1.Ingress) {
+		i.Spec.Rules = append(i.Spec.Rules, v1alpha1.IngressRule{
+			Hosts:      []string{"empty.example.com"},
+			Visibility: v1alpha1.IngressVisibilityExternalIP,
+		})
+	}
+
+	table := TableTest{{
+		Name: "rule without HTTP skipped",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withoutHTTP, withGatewayAPIclass, makeItReady, withFinalizer),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "RuleWithoutPaths", "Skipping the rules without HTTP paths of hosts empty.example.com"),
+		},
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, _ configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			gwapiclient:          fakegwapiclientset.Get(ctx),
+			httprouteLister:      listers.GetHTTPRouteLister(),
+			tlsrouteLister:       listers.GetTLSRouteLister(),
+			referenceGrantLister: listers.GetReferenceGrantLister(),
+			gatewayLister:        listers.GetGatewayLister(),
+			serviceLister:        listers.GetServiceLister(),
+			statusManager: &fakeStatusManager{
+				FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+					return status.ProbeState{Ready: true}, nil
+				},
+				FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+					return status.ProbeState{Ready: true}, true
+				},
+			},
+		}
+		store := &testConfigStore{config: defaultConfig}
+
+		return reconcileKindFunc(func(ctx context.Context, key string) error {
+			namespace, name, err := cache.SplitMetaNamespaceKey(key)
+			if err != nil {
+				return err
+			}
+			ing, err := listers.GetIngressLister().Ingresses(namespace).Get(name)
+			if err != nil {
+				return err
+			}
+			return r.ReconcileKind(store.ToContext(ctx), ing.DeepCopy())
+		})
+	}))
+}
+
+func TestHostnameMatches(t *testing.T) {
+	for _, tc := range []struct {
+		hostname *gatewayapi.Hostname
+		host     string
+		want     bool
+	}{
+		{hostname: nil, host: "example.com", want: true},
+		{hostname: ptr.To[gatewayapi.Hostname]("example.com"), host: "example.com", want: true},
+		{hostname: ptr.To[gatewayapi.Hostname]("example.com"), host: "foo.example.com", want: false},
+		{hostname: ptr.To[gatewayapi.Hostname]("*.example.com"), host: "foo.example.com", want: true},
+		{hostname: ptr.To[gatewayapi.Hostname]("*.example.com"), host: "foo.bar.example.com", want: true},
+		{hostname: ptr.To[gatewayapi.Hostname]("*.example.com"), host: "example.com", want: false},
+		{hostname: ptr.To[gatewayapi.Hostname]("*.example.com"), host: "fooexample.com", want: false},
+	} {
+		if got := hostnameMatches(tc.hostname, tc.host); got != tc.want {
+			t.Errorf("hostnameMatches(%v, %q) = %v, want: %v", ptr.Deref(tc.hostname, ""), tc.host, got, tc.want)
+		}
+	}
+}
+
 func TestReconcileTLS(t *testing.T) {
 	// The gateway API annoyingly has a number of
 	secretName := "name-WE-STICK-A-LONG-UID-HERE"
@@ -243,16 +709,13 @@ func TestReconcileTLS(t *testing.T) {
 			httpRoute(t, ing(withBasicSpec, withGatewayAPIClass, withTLS())),
 			rp(secret(secretName, nsName)),
 		},
-		WantUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: gw(defaultListener, tlsListener("example.com", nsName, secretName)),
-		}},
 		WantPatches: []clientgotesting.PatchActionImpl{{
 			ActionImpl: clientgotesting.ActionImpl{
 				Namespace: "ns",
 			},
 			Name:  "name",
 			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
-		}},
+		}, addListenersPatch(t, gw(defaultListener, tlsListener("example.com", nsName, secretName)), 1)},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: ing(withBasicSpec, withGatewayAPIClass, withTLS(), func(i *v1alpha1.Ingress) {
 				i.Status.InitializeConditions()
@@ -265,13 +728,42 @@ func TestReconcileTLS(t *testing.T) {
 			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com"`),
 		},
 	}, {
-		Name: "Already Configured",
+		Name: "TLS without rules",
 		Key:  "ns/name",
+		// The Ingress validation requires rules, so the status update is
+		// rejected, but it must not report the Ingress ready.
+		WantErr: true,
 		Objects: []runtime.Object{
-			ing(withBasicSpec, withFinalizer, withGatewayAPIClass, withTLS(), makeItReady),
+			ing(withGatewayAPIClass, withTLS()),
 			secret(secretName, nsName),
-			gw(defaultListener, tlsListener("example.com", nsName, secretName)),
-			httpRoute(t, ing(withBasicSpec, withGatewayAPIClass, withTLS()), httpRouteReady),
+			gw(defaultListener),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+			},
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withGatewayAPIClass, withTLS(), func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkLoadBalancerNotReady()
+				i.Status.MarkIngressNotReady("NoRules", "Ingress has no rules routing its hosts.")
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeWarning, "UpdateFailed", `Failed to update status for "name": missing field(s): spec.rules`),
+		},
+	}, {
+		Name: "Already Configured",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withFinalizer, withGatewayAPIClass, withTLS(), makeItReady),
+			secret(secretName, nsName),
+			gw(defaultListener, tlsListener("example.com", nsName, secretName)),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIClass, withTLS()), httpRouteReady),
 			rp(secret(secretName, nsName)),
 		},
 		WantUpdates: []clientgotesting.UpdateActionImpl{
@@ -280,6 +772,32 @@ func TestReconcileTLS(t *testing.T) {
 		WantEvents: []string{
 			// None
 		},
+	}, {
+		Name: "TLS listener not resolved by the Gateway",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withFinalizer, withGatewayAPIClass, withTLS(), makeItReady),
+			secret(secretName, nsName),
+			gw(defaultListener, tlsListener("example.com", nsName, secretName), func(g *gatewayapi.Gateway) {
+				g.Status.Listeners = []gatewayapi.ListenerStatus{{
+					Name: "kni-",
+					Conditions: []metav1.Condition{{
+						Type:    string(gatewayapi.ListenerConditionResolvedRefs),
+						Status:  metav1.ConditionFalse,
+						Reason:  string(gatewayapi.ListenerReasonInvalidCertificateRef),
+						Message: "certificate is invalid",
+					}},
+				}}
+			}),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIClass, withTLS()), httpRouteReady),
+			rp(secret(secretName, nsName)),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withFinalizer, withGatewayAPIClass, withTLS(), makeItReady, func(i *v1alpha1.Ingress) {
+				i.Status.MarkIngressNotReady("GatewayListenerNotResolved",
+					"Listener kni- of Gateway istio-system/istio-gateway has unresolved references: certificate is invalid")
+			}),
+		}},
 	}, {
 		Name:                    "Cleanup Listener",
 		Key:                     "ns/name",
@@ -298,6 +816,187 @@ func TestReconcileTLS(t *testing.T) {
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: gw(defaultListener),
 		}},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+				Verb:      "delete",
+				Resource:  gatewayapi.SchemeGroupVersion.WithResource("httproutes"),
+			},
+			Name: "example.com",
+		}, {
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: nsName,
+				Verb:      "delete",
+				Resource:  gatewayapiv1beta1.SchemeGroupVersion.WithResource("referencegrants"),
+			},
+			Name: rp(secret(secretName, nsName)).Name,
+		}},
+	}, {
+		Name:                    "Cleanup Listener keeps ReferenceGrant not owned",
+		Key:                     "ns/name",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withTLS(), func(i *v1alpha1.Ingress) {
+				i.DeletionTimestamp = &metav1.Time{
+					Time: deleteTime,
+				}
+			}),
+			secret(secretName, nsName),
+			gw(defaultListener, tlsListener("secure.example.com", nsName, secretName)),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIClass, withTLS())),
+			func() *gatewayapiv1beta1.ReferenceGrant {
+				grant := rp(secret(secretName, nsName))
+				grant.OwnerReferences = nil
+				return grant
+			}(),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: gw(defaultListener),
+		}},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+				Verb:      "delete",
+				Resource:  gatewayapi.SchemeGroupVersion.WithResource("httproutes"),
+			},
+			Name: "example.com",
+		}},
+	}, {
+		Name: "Missing TLS secret",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withTLS()),
+			gw(defaultListener),
+		},
+		WantCreates: []runtime.Object{
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIClass, withTLS())),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+			},
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIClass, withTLS(), func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("TLSSecretInvalid", `invalid TLS secret: secret ns/name-WE-STICK-A-LONG-UID-HERE does not exist`)
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com"`),
+			Eventf(corev1.EventTypeWarning, "TLSSecretInvalid", `invalid TLS secret: secret ns/name-WE-STICK-A-LONG-UID-HERE does not exist`),
+		},
+	}, {
+		Name:    "HTTPRoute owned by another controller",
+		Key:     "ns/name",
+		WantErr: true,
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withFinalizer),
+			gw(defaultListener),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIClass), func(r *gatewayapi.HTTPRoute) {
+				r.OwnerReferences = []metav1.OwnerReference{{
+					APIVersion: "example.com/v1",
+					Kind:       "Other",
+					Name:       "other",
+					UID:        "other-uid",
+					Controller: ptr.To(true),
+				}}
+			}),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIClass, withFinalizer, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady(notReconciledReason, notReconciledMessage)
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "NotOwned", "HTTPRoute example.com not owned by this object"),
+			Eventf(corev1.EventTypeWarning, "InternalError", "HTTPRoute example.com not owned by name"),
+		},
+	}, {
+		Name: "invalid rewrite host",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withRewriteHost("*.example.com")),
+			gw(defaultListener),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+			},
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIClass, withRewriteHost("*.example.com"), func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("InvalidRewriteHost", invalidRewriteHostMessage)
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeWarning, "InvalidRewriteHost", invalidRewriteHostMessage),
+		},
+	}, {
+		Name: "rewrite host of several hosts preserving the host",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withRewriteHost("goo.ns.svc.cluster.local"), withSecondHost, withAnnotation(map[string]string{resources.PreserveHostAnnotationKey: "true"})),
+			gw(defaultListener),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+			},
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIClass, withRewriteHost("goo.ns.svc.cluster.local"), withSecondHost, withAnnotation(map[string]string{resources.PreserveHostAnnotationKey: "true"}), func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("InvalidRewriteHost", preservedRewriteHostMessage)
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeWarning, "InvalidRewriteHost", preservedRewriteHostMessage),
+		},
+	}, {
+		Name: "TLS secret of the wrong type",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withTLS()),
+			func() *corev1.Secret {
+				s := secret(secretName, nsName)
+				s.Type = corev1.SecretTypeOpaque
+				return s
+			}(),
+			gw(defaultListener),
+		},
+		WantCreates: []runtime.Object{
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIClass, withTLS())),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+			},
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIClass, withTLS(), func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("TLSSecretInvalid", `invalid TLS secret: secret ns/name-WE-STICK-A-LONG-UID-HERE is of type "Opaque", not "kubernetes.io/tls"`)
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com"`),
+			Eventf(corev1.EventTypeWarning, "TLSSecretInvalid", `invalid TLS secret: secret ns/name-WE-STICK-A-LONG-UID-HERE is of type "Opaque", not "kubernetes.io/tls"`),
+		},
 	}, {
 		Name:    "No Gateway",
 		Key:     "ns/name",
@@ -339,6 +1038,9 @@ func TestReconcileTLS(t *testing.T) {
 			gwapiclient:          fakegwapiclientset.Get(ctx),
 			httprouteLister:      listers.GetHTTPRouteLister(),
 			referenceGrantLister: listers.GetReferenceGrantLister(),
+			secretLister:         listers.GetSecretLister(),
+			serviceLister:        listers.GetServiceLister(),
+			tracker:              &NullTracker{},
 			gatewayLister:        listers.GetGatewayLister(),
 			statusManager: &fakeStatusManager{
 				FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
@@ -374,137 +1076,1885 @@ func TestReconcileTLS(t *testing.T) {
 	}))
 }
 
-func TestReconcileProbing(t *testing.T) {
-	table := TableTest{{
-		Name: "first reconciler probe returns false",
-		Key:  "ns/name",
-		Ctx: withStatusManager(&fakeStatusManager{
-			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				return status.ProbeState{Ready: false}, false
+func TestFinalizeUnconfiguredGateway(t *testing.T) {
+	deleteTime := time.Now().Add(-10 * time.Second)
+	secretName := "name-WE-STICK-A-LONG-UID-HERE"
+	nsName := "ns"
+
+	for _, tc := range []struct {
+		name   string
+		plugin *config.GatewayPlugin
+	}{{
+		name: "no gateway configuration",
+	}, {
+		name:   "no external gateway",
+		plugin: &config.GatewayPlugin{ManageReferenceGrants: true},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			table := TableTest{{
+				Name:                    "deletion isn't blocked",
+				Key:                     "ns/name",
+				SkipNamespaceValidation: true,
+				Objects: []runtime.Object{
+					ing(withBasicSpec, withGatewayAPIClass, withTLS(), func(i *v1alpha1.Ingress) {
+						i.DeletionTimestamp = &metav1.Time{
+							Time: deleteTime,
+						}
+					}),
+					secret(secretName, nsName),
+					httpRoute(t, ing(withBasicSpec, withGatewayAPIClass, withTLS())),
+					rp(secret(secretName, nsName)),
+				},
+				// The routes and grants are deleted, the listeners are left
+				// on the Gateway that is no longer configured
+				WantDeletes: []clientgotesting.DeleteActionImpl{{
+					ActionImpl: clientgotesting.ActionImpl{
+						Namespace: "ns",
+						Verb:      "delete",
+						Resource:  gatewayapi.SchemeGroupVersion.WithResource("httproutes"),
+					},
+					Name: "example.com",
+				}, {
+					ActionImpl: clientgotesting.ActionImpl{
+						Namespace: nsName,
+						Verb:      "delete",
+						Resource:  gatewayapiv1beta1.SchemeGroupVersion.WithResource("referencegrants"),
+					},
+					Name: rp(secret(secretName, nsName)).Name,
+				}},
+			}}
+
+			cfg := &config.Config{
+				Network:       &networkcfg.Config{},
+				GatewayPlugin: tc.plugin,
+			}
+			table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+				r := &Reconciler{
+					gwapiclient:          fakegwapiclientset.Get(ctx),
+					httprouteLister:      listers.GetHTTPRouteLister(),
+					referenceGrantLister: listers.GetReferenceGrantLister(),
+					gatewayLister:        listers.GetGatewayLister(),
+					statusManager:        &fakeStatusManager{},
+				}
+				return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+					listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+					controller.Options{
+						ConfigStore: &testConfigStore{
+							config: cfg,
+						},
+					})
+			}))
+		})
+	}
+}
+
+func TestReconcileTLSAllowedRoutes(t *testing.T) {
+	secretName := "name-WE-STICK-A-LONG-UID-HERE"
+	nsName := "ns"
+
+	for _, tc := range []struct {
+		name     string
+		from     gatewayapi.FromNamespaces
+		selector *metav1.LabelSelector
+		want     *gatewayapi.AllowedRoutes
+	}{{
+		name: "same namespace",
+		from: gatewayapi.NamespacesFromSame,
+		want: &gatewayapi.AllowedRoutes{
+			Namespaces: &gatewayapi.RouteNamespaces{
+				From: ptr.To(gatewayapi.NamespacesFromSame),
 			},
-			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
-				return status.ProbeState{Ready: false}, nil
+			Kinds: []gatewayapi.RouteGroupKind{},
+		},
+	}, {
+		name: "all namespaces",
+		from: gatewayapi.NamespacesFromAll,
+		want: &gatewayapi.AllowedRoutes{
+			Namespaces: &gatewayapi.RouteNamespaces{
+				From: ptr.To(gatewayapi.NamespacesFromAll),
 			},
-		}),
-		Objects: append([]runtime.Object{
-			ing(withBasicSpec, withGatewayAPIclass),
-		}, servicesAndEndpoints...),
-		WantCreates: []runtime.Object{httpRoute(t, ing(withBasicSpec, withGatewayAPIclass))},
-		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: ing(withBasicSpec, withGatewayAPIclass, func(i *v1alpha1.Ingress) {
-				i.Status.InitializeConditions()
-				i.Status.MarkLoadBalancerNotReady()
-			}),
-		}},
+			Kinds: []gatewayapi.RouteGroupKind{},
+		},
+	}, {
+		name: "custom selector",
+		from: gatewayapi.NamespacesFromSelector,
+		selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"knative-ingress": "true"},
+		},
+		want: &gatewayapi.AllowedRoutes{
+			Namespaces: &gatewayapi.RouteNamespaces{
+				From: ptr.To(gatewayapi.NamespacesFromSelector),
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"knative-ingress": "true"},
+				},
+			},
+			Kinds: []gatewayapi.RouteGroupKind{},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := defaultConfig.DeepCopy()
+			cfg.GatewayPlugin.ExternalGateways[0].AllowedRoutesFrom = tc.from
+			cfg.GatewayPlugin.ExternalGateways[0].AllowedRoutesSelector = tc.selector
+
+			table := TableTest{{
+				Name: "Happy TLS",
+				Key:  "ns/name",
+				Objects: []runtime.Object{
+					ing(withBasicSpec, withGatewayAPIClass, withTLS()),
+					secret(secretName, nsName),
+					gw(defaultListener),
+				},
+				WantCreates: []runtime.Object{
+					httpRouteWithConfig(t, cfg, ing(withBasicSpec, withGatewayAPIClass, withTLS()), 0),
+					rp(secret(secretName, nsName)),
+				},
+				WantPatches: []clientgotesting.PatchActionImpl{{
+					ActionImpl: clientgotesting.ActionImpl{
+						Namespace: "ns",
+					},
+					Name:  "name",
+					Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+				}, addListenersPatch(t, gw(defaultListener, tlsListener("example.com", nsName, secretName), withListenerAllowedRoutes(tc.want)), 1)},
+				WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+					Object: ing(withBasicSpec, withGatewayAPIClass, withTLS(), func(i *v1alpha1.Ingress) {
+						i.Status.InitializeConditions()
+						i.Status.MarkIngressNotReady("HTTPRouteNotReady", "Waiting for HTTPRoute becomes Ready.")
+						i.Status.MarkLoadBalancerNotReady()
+					}),
+				}},
+				WantEvents: []string{
+					Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+					Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com"`),
+				},
+			}}
+
+			table.Test(t, GatewayFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher, tr *TableRow) controller.Reconciler {
+				r := &Reconciler{
+					gwapiclient:          fakegwapiclientset.Get(ctx),
+					httprouteLister:      listers.GetHTTPRouteLister(),
+					referenceGrantLister: listers.GetReferenceGrantLister(),
+					secretLister:         listers.GetSecretLister(),
+					serviceLister:        listers.GetServiceLister(),
+					tracker:              &NullTracker{},
+					gatewayLister:        listers.GetGatewayLister(),
+					statusManager: &fakeStatusManager{
+						FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+							return status.ProbeState{Ready: true}, nil
+						},
+						FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+							return status.ProbeState{Ready: true}, true
+						},
+					},
+				}
+				// The fake tracker's `Add` method incorrectly pluralizes "gatewaies" using UnsafeGuessKindToResource,
+				// so create this via explicit call (per note in client-go/testing/fixture.go in tracker.Add)
+				fakeCreates := []runtime.Object{}
+				for _, x := range tr.Objects {
+					myGw, ok := x.(*gatewayapi.Gateway)
+					if ok {
+						fakegwapiclientset.Get(ctx).GatewayV1().Gateways(myGw.Namespace).Create(ctx, myGw, metav1.CreateOptions{})
+						tr.SkipNamespaceValidation = true
+						fakeCreates = append(fakeCreates, myGw)
+					}
+				}
+				tr.WantCreates = append(fakeCreates, tr.WantCreates...)
+
+				return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+					listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+					controller.Options{
+						ConfigStore: &testConfigStore{
+							config: cfg,
+						},
+					})
+			}))
+		})
+	}
+}
+
+func TestReconcileTLSUnmanagedReferenceGrants(t *testing.T) {
+	secretName := "name-WE-STICK-A-LONG-UID-HERE"
+	nsName := "ns"
+
+	cfg := defaultConfig.DeepCopy()
+	cfg.GatewayPlugin.ManageReferenceGrants = false
+
+	// A grant managed by someone else, for all the secrets of the namespace
+	centralGrant := rp(secret(secretName, nsName))
+	centralGrant.Name = "central"
+	centralGrant.OwnerReferences = nil
+	centralGrant.Spec.To[0].Name = nil
+
+	table := TableTest{{
+		Name: "existing grant",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withTLS()),
+			secret(secretName, nsName),
+			gw(defaultListener),
+			centralGrant,
+		},
+		WantCreates: []runtime.Object{
+			httpRouteWithConfig(t, cfg, ing(withBasicSpec, withGatewayAPIClass, withTLS()), 0),
+		},
 		WantPatches: []clientgotesting.PatchActionImpl{{
 			ActionImpl: clientgotesting.ActionImpl{
 				Namespace: "ns",
 			},
 			Name:  "name",
 			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}, addListenersPatch(t, gw(defaultListener, tlsListener("example.com", nsName, secretName)), 1)},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIClass, withTLS(), func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("HTTPRouteNotReady", "Waiting for HTTPRoute becomes Ready.")
+				i.Status.MarkLoadBalancerNotReady()
+			}),
 		}},
 		WantEvents: []string{
 			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
-			Eventf(corev1.EventTypeNormal, "Created", "Created HTTPRoute \"example.com\""),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com"`),
 		},
 	}, {
-		Name: "prober callback all endpoints ready",
-		Key:  "ns/name",
-		Ctx: withStatusManager(&fakeStatusManager{
-			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
-				return status.ProbeState{Ready: true}, nil
+		Name:    "missing grant",
+		Key:     "ns/name",
+		WantErr: true,
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withTLS()),
+			secret(secretName, nsName),
+			gw(defaultListener),
+		},
+		WantCreates: []runtime.Object{
+			httpRouteWithConfig(t, cfg, ing(withBasicSpec, withGatewayAPIClass, withTLS()), 0),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
 			},
-			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				return status.ProbeState{Ready: true}, true
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIClass, withTLS(), func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("ReconcileIngressFailed", "Ingress reconciliation failed")
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com"`),
+			Eventf(corev1.EventTypeWarning, "ReferenceGrantMissing", "no ReferenceGrant in namespace ns allows the Gateways of namespace istio-system to use Secret %s", secretName),
+			Eventf(corev1.EventTypeWarning, "InternalError", "no ReferenceGrant in namespace ns allows the Gateways of namespace istio-system to use Secret %s", secretName),
+		},
+	}}
+
+	table.Test(t, GatewayFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher, tr *TableRow) controller.Reconciler {
+		r := &Reconciler{
+			gwapiclient:          fakegwapiclientset.Get(ctx),
+			httprouteLister:      listers.GetHTTPRouteLister(),
+			referenceGrantLister: listers.GetReferenceGrantLister(),
+			secretLister:         listers.GetSecretLister(),
+			serviceLister:        listers.GetServiceLister(),
+			tracker:              &NullTracker{},
+			gatewayLister:        listers.GetGatewayLister(),
+			statusManager: &fakeStatusManager{
+				FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+					return status.ProbeState{Ready: true}, nil
+				},
+				FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+					return status.ProbeState{Ready: true}, true
+				},
 			},
-		}),
-		Objects: append([]runtime.Object{
-			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
-			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
-		}, servicesAndEndpoints...),
-		WantStatusUpdates: []clientgotesting.UpdateActionImpl{
-			{Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReady)},
+		}
+		// The fake tracker's `Add` method incorrectly pluralizes "gatewaies" using UnsafeGuessKindToResource,
+		// so create this via explicit call (per note in client-go/testing/fixture.go in tracker.Add)
+		fakeCreates := []runtime.Object{}
+		for _, x := range tr.Objects {
+			myGw, ok := x.(*gatewayapi.Gateway)
+			if ok {
+				fakegwapiclientset.Get(ctx).GatewayV1().Gateways(myGw.Namespace).Create(ctx, myGw, metav1.CreateOptions{})
+				tr.SkipNamespaceValidation = true
+				fakeCreates = append(fakeCreates, myGw)
+			}
+		}
+		tr.WantCreates = append(fakeCreates, tr.WantCreates...)
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				},
+			})
+	}))
+}
+
+func TestReconcileHTTPOption(t *testing.T) {
+	secretName := "name-WE-STICK-A-LONG-UID-HERE"
+	nsName := "ns"
+
+	redirected := withHTTPOption(v1alpha1.HTTPOptionRedirected)
+	unset := withHTTPOption("")
+
+	// The Ingresses that set their HTTPOption keep it, the others are
+	// redirected by default.
+	cfg := defaultConfig.DeepCopy()
+	cfg.GatewayPlugin.DefaultHTTPOption = v1alpha1.HTTPOptionRedirected
+
+	// The redirect applies to the external rule only, the cluster-local one
+	// is served over plain HTTP.
+	redirectRoute := &gatewayapi.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example.com-redirect",
+			Namespace: nsName,
+			Labels: map[string]string{
+				networking.VisibilityLabelKey: "",
+				resources.IngressUIDLabelKey:  "",
+			},
+			Annotations: map[string]string{
+				networking.IngressClassAnnotationKey: gatewayAPIIngressClassName,
+			},
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing())},
 		},
-	}, {
-		Name: "updated ingress - new backends used for endpoint probing",
+		Spec: gatewayapi.HTTPRouteSpec{
+			Hostnames: []gatewayapi.Hostname{"example.com"},
+			Rules: []gatewayapi.HTTPRouteRule{{
+				Matches: []gatewayapi.HTTPRouteMatch{{
+					Path: &gatewayapi.HTTPPathMatch{
+						Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
+						Value: ptr.To("/"),
+					},
+				}},
+				Filters: []gatewayapi.HTTPRouteFilter{{
+					Type: gatewayapi.HTTPRouteFilterRequestRedirect,
+					RequestRedirect: &gatewayapi.HTTPRequestRedirectFilter{
+						Scheme:     ptr.To("https"),
+						StatusCode: ptr.To(301),
+					},
+				}},
+			}},
+			CommonRouteSpec: gatewayapi.CommonRouteSpec{ParentRefs: []gatewayapi.ParentReference{{
+				Group:     (*gatewayapi.Group)(&gatewayapi.GroupVersion.Group),
+				Kind:      (*gatewayapi.Kind)(ptr.To("Gateway")),
+				Namespace: ptr.To(gatewayapi.Namespace(testNamespace)),
+				Name:      gatewayapi.ObjectName(publicName),
+			}}},
+		},
+	}
+
+	// The external route is only served by the HTTPS listener of the Ingress
+	httpsOnly := func(h *gatewayapi.HTTPRoute) {
+		if got, want := h.Spec.ParentRefs[0].SectionName, ptr.To(resources.ListenerName(ing())); !cmp.Equal(got, want) {
+			t.Errorf("SectionName = %v, want: %v", got, want)
+		}
+	}
+
+	table := TableTest{{
+		Name: "redirect external, plain internal",
 		Key:  "ns/name",
-		Objects: append([]runtime.Object{
-			ing(withBasicSpec, withSecondRevisionSpec, withGatewayAPIclass, withFinalizer, makeItReady),
-			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
-		}, servicesAndEndpoints...),
-		Ctx: withStatusManager(&fakeStatusManager{
-			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				return status.ProbeState{Ready: true, Version: "previous"}, true
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLS(), redirected),
+			secret(secretName, nsName),
+			gw(defaultListener),
+		},
+		WantCreates: []runtime.Object{
+			httpRouteWithConfig(t, cfg, ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLS(), redirected), 0, httpsOnly),
+			redirectRoute,
+			httpRouteWithConfig(t, cfg, ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLS(), redirected), 1),
+			rp(secret(secretName, nsName)),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
 			},
-			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
-				return status.ProbeState{Ready: false}, nil
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}, addListenersPatch(t, gw(defaultListener, tlsListener("example.com", nsName, secretName)), 1)},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLS(), redirected, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("HTTPRouteNotReady", "Waiting for HTTPRoute becomes Ready.")
+				i.Status.MarkLoadBalancerNotReady()
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com"`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com-redirect"`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "foo.svc.cluster.local"`),
+		},
+	}, {
+		Name: "redirect by default",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLS(), unset),
+			secret(secretName, nsName),
+			gw(defaultListener),
+		},
+		WantCreates: []runtime.Object{
+			httpRouteWithConfig(t, cfg, ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLS(), redirected), 0, httpsOnly),
+			redirectRoute,
+			httpRouteWithConfig(t, cfg, ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLS(), redirected), 1),
+			rp(secret(secretName, nsName)),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
 			},
-		}),
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}, addListenersPatch(t, gw(defaultListener, tlsListener("example.com", nsName, secretName)), 1)},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: ing(
-				withBasicSpec,
-				withSecondRevisionSpec,
-				withGatewayAPIclass,
-				withFinalizer,
-				makeItReady,
-				makeLoadBalancerNotReady,
-			),
+			Object: ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLS(), unset, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("HTTPRouteNotReady", "Waiting for HTTPRoute becomes Ready.")
+				i.Status.MarkLoadBalancerNotReady()
+			}),
 		}},
-		WantUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: HTTPRoute{
-				Name:      "example.com",
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com"`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com-redirect"`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "foo.svc.cluster.local"`),
+		},
+	}, {
+		Name: "no redirect without TLS",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, redirected),
+			gw(defaultListener),
+		},
+		WantCreates: []runtime.Object{
+			httpRouteWithConfig(t, cfg, ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, redirected), 0),
+			httpRouteWithConfig(t, cfg, ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, redirected), 1),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
 				Namespace: "ns",
-				Hostname:  "example.com",
-				Rules: []RuleBuilder{
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
-						Port:      123,
-					},
-					NormalRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Port:      123,
-						Weight:    100,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
-						Port:      123,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
-						Port:      123,
-					},
-				},
-				StatusConditions: []metav1.Condition{{
-					Type:   string(gatewayapi.RouteConditionAccepted),
-					Status: metav1.ConditionTrue,
-				}},
-			}.Build(),
+			},
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, redirected, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("HTTPRouteNotReady", "Waiting for HTTPRoute becomes Ready.")
+				i.Status.MarkLoadBalancerNotReady()
+			}),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com"`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "foo.svc.cluster.local"`),
+		},
 	}, {
-		Name: "steady state ingress - endpoint probing still not ready",
+		Name: "redirect disabled",
 		Key:  "ns/name",
-		Ctx: withStatusManager(&fakeStatusManager{
-			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				return status.ProbeState{
-					Ready:   false,
-					Version: "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
-				}, true
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLS(), withFinalizer, makeItReady),
+			secret(secretName, nsName),
+			gw(defaultListener, tlsListener("example.com", nsName, secretName)),
+			httpRouteWithConfig(t, cfg, ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLS(), redirected), 0, httpRouteReady),
+			redirectRoute,
+			httpRouteWithConfig(t, cfg, ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLS()), 1, httpRouteReady),
+			rp(secret(secretName, nsName)),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: httpRouteWithConfig(t, cfg, ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLS()), 0, httpRouteReady),
+		}},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: nsName,
+				Verb:      "delete",
+				Resource:  gatewayapi.SchemeGroupVersion.WithResource("httproutes"),
 			},
-			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
-				return status.ProbeState{Ready: false}, nil
+			Name: "example.com-redirect",
+		}},
+	}}
+
+	table.Test(t, GatewayFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher, tr *TableRow) controller.Reconciler {
+		r := &Reconciler{
+			gwapiclient:          fakegwapiclientset.Get(ctx),
+			httprouteLister:      listers.GetHTTPRouteLister(),
+			referenceGrantLister: listers.GetReferenceGrantLister(),
+			secretLister:         listers.GetSecretLister(),
+			serviceLister:        listers.GetServiceLister(),
+			tracker:              &NullTracker{},
+			gatewayLister:        listers.GetGatewayLister(),
+			statusManager: &fakeStatusManager{
+				FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+					return status.ProbeState{Ready: true}, nil
+				},
+				FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+					return status.ProbeState{Ready: true}, true
+				},
 			},
-		}),
-		Objects: append([]runtime.Object{
-			ing(withBasicSpec,
+		}
+		// The fake tracker's `Add` method incorrectly pluralizes "gatewaies" using UnsafeGuessKindToResource,
+		// so create this via explicit call (per note in client-go/testing/fixture.go in tracker.Add)
+		fakeCreates := []runtime.Object{}
+		for _, x := range tr.Objects {
+			myGw, ok := x.(*gatewayapi.Gateway)
+			if ok {
+				fakegwapiclientset.Get(ctx).GatewayV1().Gateways(myGw.Namespace).Create(ctx, myGw, metav1.CreateOptions{})
+				tr.SkipNamespaceValidation = true
+				fakeCreates = append(fakeCreates, myGw)
+			}
+		}
+		tr.WantCreates = append(fakeCreates, tr.WantCreates...)
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				},
+			})
+	}))
+}
+
+// TestReconcileRedirectedProbeSchemes checks that when only the external rule
+// of an Ingress is redirected to HTTPS, it is probed over HTTPS while its
+// cluster-local rule is still probed over plain HTTP.
+func TestReconcileRedirectedProbeSchemes(t *testing.T) {
+	secretName := "name-WE-STICK-A-LONG-UID-HERE"
+	nsName := "ns"
+
+	redirected := withHTTPOption(v1alpha1.HTTPOptionRedirected)
+	routeIng := func() *v1alpha1.Ingress {
+		return ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLS(), redirected)
+	}
+	ctx := (&testConfigStore{config: defaultConfig}).ToContext(context.Background())
+	redirectIng := routeIng()
+
+	gatewayEndpoints := func(name, ip string) *corev1.Endpoints {
+		return &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testNamespace,
+				Name:      name,
+			},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: ip}},
+				Ports: []corev1.EndpointPort{{
+					Name: "http2",
+					Port: 80,
+				}, {
+					Name: "https",
+					Port: 443,
+				}},
+			}},
+		}
+	}
+
+	// schemes are the schemes of the probed URLs by visibility
+	schemes := map[v1alpha1.IngressVisibility]sets.Set[string]{}
+
+	table := TableTest{{
+		Name: "redirected external rule, plain cluster-local rule",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLS(), redirected, withFinalizer, makeItReady),
+			secret(secretName, nsName),
+			gw(defaultListener, tlsListener("example.com", nsName, secretName)),
+			httpRouteForRule(t, routeIng(), 0, httpRouteReady),
+			resources.MakeRedirectHTTPRoute(ctx, redirectIng, &redirectIng.Spec.Rules[0]),
+			httpRouteForRule(t, routeIng(), 1, httpRouteReady),
+			rp(secret(secretName, nsName)),
+			gatewayEndpoints(publicName, "10.0.0.10"),
+			gatewayEndpoints(privateName, "10.0.0.20"),
+		}, servicesAndEndpoints...),
+	}}
+
+	table.Test(t, GatewayFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher, tr *TableRow) controller.Reconciler {
+		targetLister := NewProbeTargetLister(logging.FromContext(ctx), listers.GetEndpointsLister(),
+			listers.GetServiceLister(), listers.GetNodeLister(), listers.GetGatewayLister())
+		r := &Reconciler{
+			gwapiclient:          fakegwapiclientset.Get(ctx),
+			httprouteLister:      listers.GetHTTPRouteLister(),
+			referenceGrantLister: listers.GetReferenceGrantLister(),
+			secretLister:         listers.GetSecretLister(),
+			serviceLister:        listers.GetServiceLister(),
+			tracker:              &NullTracker{},
+			gatewayLister:        listers.GetGatewayLister(),
+			statusManager: &fakeStatusManager{
+				FakeDoProbes: func(ctx context.Context, backends status.Backends) (status.ProbeState, error) {
+					targets, err := targetLister.BackendsToProbeTargets(ctx, backends)
+					if err != nil {
+						return status.ProbeState{}, err
+					}
+					for visibility := range backends.URLs {
+						for _, target := range targets {
+							for _, url := range target.URLs {
+								if schemes[visibility] == nil {
+									schemes[visibility] = sets.New[string]()
+								}
+								schemes[visibility].Insert(url.Scheme)
+							}
+						}
+					}
+					return status.ProbeState{Ready: true}, nil
+				},
+				FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+					return status.ProbeState{Ready: true}, true
+				},
+			},
+		}
+		// The fake tracker's `Add` method incorrectly pluralizes "gatewaies" using UnsafeGuessKindToResource,
+		// so create this via explicit call (per note in client-go/testing/fixture.go in tracker.Add)
+		fakeCreates := []runtime.Object{}
+		for _, x := range tr.Objects {
+			myGw, ok := x.(*gatewayapi.Gateway)
+			if ok {
+				fakegwapiclientset.Get(ctx).GatewayV1().Gateways(myGw.Namespace).Create(ctx, myGw, metav1.CreateOptions{})
+				tr.SkipNamespaceValidation = true
+				fakeCreates = append(fakeCreates, myGw)
+			}
+		}
+		tr.WantCreates = append(fakeCreates, tr.WantCreates...)
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: defaultConfig,
+				},
+			})
+	}))
+
+	want := map[v1alpha1.IngressVisibility]sets.Set[string]{
+		v1alpha1.IngressVisibilityExternalIP:   sets.New("https"),
+		v1alpha1.IngressVisibilityClusterLocal: sets.New("http"),
+	}
+	if !cmp.Equal(schemes, want) {
+		t.Error("Unexpected probe schemes (-want, +got):", cmp.Diff(want, schemes))
+	}
+}
+
+func TestReconcileRouteAnnotations(t *testing.T) {
+	cfg := defaultConfig.DeepCopy()
+	cfg.GatewayPlugin.ExternalGateways[0].RouteAnnotations = map[string]string{
+		"example.com/load-balancing": "round-robin",
+	}
+
+	// annotatedRoute makes the HTTPRoute of the Ingress with the annotations
+	// configured for the Gateway.
+	annotatedRoute := func(i *v1alpha1.Ingress, opts ...HTTPRouteOption) runtime.Object {
+		t.Helper()
+		ingress.InsertProbe(i)
+		ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+		route, err := resources.MakeHTTPRoute(ctx, i, &i.Spec.Rules[0])
+		if err != nil {
+			t.Fatal("MakeHTTPRoute() =", err)
+		}
+		if got := route.Annotations["example.com/load-balancing"]; got != "round-robin" {
+			t.Errorf("Annotation = %q, want: round-robin", got)
+		}
+		for _, opt := range opts {
+			opt(route)
+		}
+		return route
+	}
+
+	table := TableTest{{
+		Name: "annotations on new routes",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass),
+			gw(defaultListener),
+		},
+		WantCreates: []runtime.Object{
+			annotatedRoute(ing(withBasicSpec, withGatewayAPIClass)),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+			},
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIClass, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("HTTPRouteNotReady", "Waiting for HTTPRoute becomes Ready.")
+				i.Status.MarkLoadBalancerNotReady()
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com"`),
+		},
+	}, {
+		Name: "annotations added to existing routes",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withFinalizer, makeItReady),
+			gw(defaultListener),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIClass), httpRouteReady),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: annotatedRoute(ing(withBasicSpec, withGatewayAPIClass), httpRouteReady),
+		}},
+	}, {
+		Name: "annotations survive updates",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withBackendAppendHeaders("K-Foo", "bar"), withFinalizer, makeItReady),
+			gw(defaultListener),
+			annotatedRoute(ing(withBasicSpec, withGatewayAPIClass), httpRouteReady),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: annotatedRoute(ing(withBasicSpec, withGatewayAPIClass, withBackendAppendHeaders("K-Foo", "bar")), httpRouteReady),
+		}},
+	}, {
+		Name: "annotated routes up to date",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withFinalizer, makeItReady),
+			gw(defaultListener),
+			annotatedRoute(ing(withBasicSpec, withGatewayAPIClass), httpRouteReady),
+		},
+	}}
+
+	table.Test(t, GatewayFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher, tr *TableRow) controller.Reconciler {
+		r := &Reconciler{
+			gwapiclient:          fakegwapiclientset.Get(ctx),
+			httprouteLister:      listers.GetHTTPRouteLister(),
+			referenceGrantLister: listers.GetReferenceGrantLister(),
+			secretLister:         listers.GetSecretLister(),
+			serviceLister:        listers.GetServiceLister(),
+			tracker:              &NullTracker{},
+			gatewayLister:        listers.GetGatewayLister(),
+			statusManager: &fakeStatusManager{
+				FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+					return status.ProbeState{Ready: true}, nil
+				},
+				FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+					return status.ProbeState{Ready: true}, true
+				},
+			},
+		}
+		// The fake tracker's `Add` method incorrectly pluralizes "gatewaies" using UnsafeGuessKindToResource,
+		// so create this via explicit call (per note in client-go/testing/fixture.go in tracker.Add)
+		fakeCreates := []runtime.Object{}
+		for _, x := range tr.Objects {
+			myGw, ok := x.(*gatewayapi.Gateway)
+			if ok {
+				fakegwapiclientset.Get(ctx).GatewayV1().Gateways(myGw.Namespace).Create(ctx, myGw, metav1.CreateOptions{})
+				tr.SkipNamespaceValidation = true
+				fakeCreates = append(fakeCreates, myGw)
+			}
+		}
+		tr.WantCreates = append(fakeCreates, tr.WantCreates...)
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				},
+			})
+	}))
+}
+
+func TestReconcileGatewayClassFeatures(t *testing.T) {
+	cfg := defaultConfig.DeepCopy()
+	cfg.GatewayPlugin.ExternalGateways[0].Class = "istio"
+	cfg.GatewayPlugin.ExternalGateways[0].SupportedFeaturesFromClass = true
+
+	// classConfig is the configuration with the features advertised by the
+	// GatewayClass.
+	classConfig := cfg.DeepCopy()
+	classConfig.GatewayPlugin.ExternalGateways[0].SupportedFeatures = sets.New(features.SupportHTTPRouteRequestTimeout)
+
+	gatewayClass := func(features ...gatewayapi.FeatureName) *gatewayapi.GatewayClass {
+		gc := &gatewayapi.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio"},
+			Spec:       gatewayapi.GatewayClassSpec{ControllerName: "istio.io/gateway-controller"},
+		}
+		for _, f := range features {
+			gc.Status.SupportedFeatures = append(gc.Status.SupportedFeatures, gatewayapi.SupportedFeature{Name: f})
+		}
+		return gc
+	}
+	routeWithConfig := func(c *config.Config, i *v1alpha1.Ingress, opts ...HTTPRouteOption) runtime.Object {
+		t.Helper()
+		ingress.InsertProbe(i)
+		ctx := (&testConfigStore{config: c}).ToContext(context.Background())
+		route, err := resources.MakeHTTPRoute(ctx, i, &i.Spec.Rules[0])
+		if err != nil {
+			t.Fatal("MakeHTTPRoute() =", err)
+		}
+		for _, opt := range opts {
+			opt(route)
+		}
+		return route
+	}
+
+	table := TableTest{{
+		Name: "features advertised by the class",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass),
+			gw(defaultListener),
+			gatewayClass(gatewayapi.FeatureName(features.SupportHTTPRouteRequestTimeout)),
+		},
+		WantCreates: []runtime.Object{
+			routeWithConfig(classConfig, ing(withBasicSpec, withGatewayAPIClass), func(h *gatewayapi.HTTPRoute) {
+				// The request timeouts of the routes are disabled
+				if h.Spec.Rules[0].Timeouts == nil {
+					t.Error("Timeouts = nil, want the request timeout disabled")
+				}
+			}),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+			},
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIClass, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("HTTPRouteNotReady", "Waiting for HTTPRoute becomes Ready.")
+				i.Status.MarkLoadBalancerNotReady()
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com"`),
+		},
+	}, {
+		Name: "features newly advertised by the class",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withFinalizer, makeItReady),
+			gw(defaultListener),
+			gatewayClass(gatewayapi.FeatureName(features.SupportHTTPRouteRequestTimeout)),
+			routeWithConfig(cfg, ing(withBasicSpec, withGatewayAPIClass), httpRouteReady),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: routeWithConfig(classConfig, ing(withBasicSpec, withGatewayAPIClass), httpRouteReady),
+		}},
+	}, {
+		Name: "missing class",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withFinalizer, makeItReady),
+			gw(defaultListener),
+			routeWithConfig(cfg, ing(withBasicSpec, withGatewayAPIClass), httpRouteReady),
+		},
+	}}
+
+	table.Test(t, GatewayFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher, tr *TableRow) controller.Reconciler {
+		r := &Reconciler{
+			gwapiclient:          fakegwapiclientset.Get(ctx),
+			httprouteLister:      listers.GetHTTPRouteLister(),
+			referenceGrantLister: listers.GetReferenceGrantLister(),
+			secretLister:         listers.GetSecretLister(),
+			serviceLister:        listers.GetServiceLister(),
+			tracker:              &NullTracker{},
+			gatewayLister:        listers.GetGatewayLister(),
+			gatewayClassLister:   listers.GetGatewayClassLister(),
+			statusManager: &fakeStatusManager{
+				FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+					return status.ProbeState{Ready: true}, nil
+				},
+				FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+					return status.ProbeState{Ready: true}, true
+				},
+			},
+		}
+		// The fake tracker's `Add` method incorrectly pluralizes "gatewaies" using UnsafeGuessKindToResource,
+		// so create this via explicit call (per note in client-go/testing/fixture.go in tracker.Add)
+		fakeCreates := []runtime.Object{}
+		for _, x := range tr.Objects {
+			myGw, ok := x.(*gatewayapi.Gateway)
+			if ok {
+				fakegwapiclientset.Get(ctx).GatewayV1().Gateways(myGw.Namespace).Create(ctx, myGw, metav1.CreateOptions{})
+				tr.SkipNamespaceValidation = true
+				fakeCreates = append(fakeCreates, myGw)
+			}
+		}
+		tr.WantCreates = append(fakeCreates, tr.WantCreates...)
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				},
+			})
+	}))
+}
+
+func TestReconcileTLSOptions(t *testing.T) {
+	const (
+		secretName = "name-WE-STICK-A-LONG-UID-HERE"
+		nsName     = "ns"
+	)
+	cfg := defaultConfig.DeepCopy()
+	cfg.GatewayPlugin.ExternalGateways[0].TLSOptions = map[string]string{
+		"example.com/min-tls-version": "1.2",
+		"example.com/cipher-suites":   "ECDHE-ECDSA-AES128-GCM-SHA256",
+	}
+
+	// tlsListenerWithOptions is the TLS listener of the Ingress with the
+	// options configured for the Gateway.
+	tlsListenerWithOptions := func(g *gatewayapi.Gateway) {
+		tlsListener("example.com", nsName, secretName)(g)
+		g.Spec.Listeners[len(g.Spec.Listeners)-1].TLS.Options = map[gatewayapi.AnnotationKey]gatewayapi.AnnotationValue{
+			"example.com/min-tls-version": "1.2",
+			"example.com/cipher-suites":   "ECDHE-ECDSA-AES128-GCM-SHA256",
+		}
+	}
+
+	unmanagedListener := func(g *gatewayapi.Gateway) {
+		g.Spec.Listeners = append(g.Spec.Listeners, gatewayapi.Listener{
+			Name:     "unmanaged",
+			Port:     8443,
+			Protocol: "HTTPS",
+		})
+	}
+
+	table := TableTest{{
+		Name: "options on new listeners",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withTLS()),
+			secret(secretName, nsName),
+			gw(defaultListener),
+		},
+		WantCreates: []runtime.Object{
+			httpRouteWithConfig(t, cfg, ing(withBasicSpec, withGatewayAPIClass, withTLS()), 0),
+			rp(secret(secretName, nsName)),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+			},
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}, addListenersPatch(t, gw(defaultListener, tlsListenerWithOptions), 1)},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIClass, withTLS(), func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("HTTPRouteNotReady", "Waiting for HTTPRoute becomes Ready.")
+				i.Status.MarkLoadBalancerNotReady()
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com"`),
+		},
+	}, {
+		Name: "options added to existing listeners",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withTLS(), withFinalizer, makeItReady),
+			secret(secretName, nsName),
+			gw(defaultListener, tlsListener("example.com", nsName, secretName)),
+			httpRouteWithConfig(t, cfg, ing(withBasicSpec, withGatewayAPIClass, withTLS()), 0, httpRouteReady),
+			rp(secret(secretName, nsName)),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			replaceListenerPatch(t, gw(defaultListener, tlsListenerWithOptions), 1),
+		},
+	}, {
+		Name: "only the changed listener is patched",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withTLS(), withFinalizer, makeItReady),
+			secret(secretName, nsName),
+			gw(defaultListener, tlsListener("example.com", nsName, secretName), unmanagedListener),
+			httpRouteWithConfig(t, cfg, ing(withBasicSpec, withGatewayAPIClass, withTLS()), 0, httpRouteReady),
+			rp(secret(secretName, nsName)),
+		},
+		// Neither the listener before nor the one after the changed listener
+		// are part of the patch.
+		WantPatches: []clientgotesting.PatchActionImpl{
+			replaceListenerPatch(t, gw(defaultListener, tlsListenerWithOptions, unmanagedListener), 1),
+		},
+	}, {
+		Name: "listeners with options up to date",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withTLS(), withFinalizer, makeItReady),
+			secret(secretName, nsName),
+			gw(defaultListener, tlsListenerWithOptions),
+			httpRouteWithConfig(t, cfg, ing(withBasicSpec, withGatewayAPIClass, withTLS()), 0, httpRouteReady),
+			rp(secret(secretName, nsName)),
+		},
+	}}
+
+	table.Test(t, GatewayFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher, tr *TableRow) controller.Reconciler {
+		r := &Reconciler{
+			gwapiclient:          fakegwapiclientset.Get(ctx),
+			httprouteLister:      listers.GetHTTPRouteLister(),
+			referenceGrantLister: listers.GetReferenceGrantLister(),
+			secretLister:         listers.GetSecretLister(),
+			serviceLister:        listers.GetServiceLister(),
+			tracker:              &NullTracker{},
+			gatewayLister:        listers.GetGatewayLister(),
+			statusManager: &fakeStatusManager{
+				FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+					return status.ProbeState{Ready: true}, nil
+				},
+				FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+					return status.ProbeState{Ready: true}, true
+				},
+			},
+		}
+		// The fake tracker's `Add` method incorrectly pluralizes "gatewaies" using UnsafeGuessKindToResource,
+		// so create this via explicit call (per note in client-go/testing/fixture.go in tracker.Add)
+		fakeCreates := []runtime.Object{}
+		for _, x := range tr.Objects {
+			myGw, ok := x.(*gatewayapi.Gateway)
+			if ok {
+				fakegwapiclientset.Get(ctx).GatewayV1().Gateways(myGw.Namespace).Create(ctx, myGw, metav1.CreateOptions{})
+				tr.SkipNamespaceValidation = true
+				fakeCreates = append(fakeCreates, myGw)
+			}
+		}
+		tr.WantCreates = append(fakeCreates, tr.WantCreates...)
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				},
+			})
+	}))
+}
+
+func TestReconcileRouteNamespace(t *testing.T) {
+	cfg := defaultConfig.DeepCopy()
+	cfg.GatewayPlugin.RouteNamespace = "gateways"
+	cfgCtx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+
+	// routeInNamespace is the HTTPRoute of the Ingress in the route namespace
+	routeInNamespace := func(i *v1alpha1.Ingress, opts ...HTTPRouteOption) *gatewayapi.HTTPRoute {
+		ingress.InsertProbe(i)
+		route, err := resources.MakeHTTPRoute(cfgCtx, i, &i.Spec.Rules[0])
+		if err != nil {
+			t.Fatal("MakeHTTPRoute() =", err)
+		}
+		for _, opt := range opts {
+			opt(route)
+		}
+		return route
+	}
+	backendGrant := resources.MakeBackendReferenceGrant(cfgCtx, ing(withBasicSpec, withGatewayAPIclass))
+
+	table := TableTest{{
+		Name:                    "first reconcile",
+		Key:                     "ns/name",
+		SkipNamespaceValidation: true,
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass),
+		}, servicesAndEndpoints...),
+		WantCreates: []runtime.Object{
+			backendGrant,
+			routeInNamespace(ing(withBasicSpec, withGatewayAPIclass)),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("HTTPRouteNotReady", "Waiting for HTTPRoute becomes Ready.")
+				i.Status.MarkLoadBalancerNotReady()
+			}),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+			},
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com"`),
+		},
+	}, {
+		Name:                    "route moved from the Ingress namespace",
+		Key:                     "ns/name",
+		SkipNamespaceValidation: true,
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, makeItReady, withFinalizer),
+			backendGrant,
+			routeInNamespace(ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			// The route created before the route namespace was configured
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+				Verb:      "delete",
+				Resource:  gatewayapi.SchemeGroupVersion.WithResource("httproutes"),
+			},
+			Name: "example.com",
+		}},
+	}}
+
+	table.Test(t, GatewayFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher, tr *TableRow) controller.Reconciler {
+		r := &Reconciler{
+			gwapiclient:          fakegwapiclientset.Get(ctx),
+			httprouteLister:      listers.GetHTTPRouteLister(),
+			referenceGrantLister: listers.GetReferenceGrantLister(),
+			secretLister:         listers.GetSecretLister(),
+			serviceLister:        listers.GetServiceLister(),
+			tracker:              &NullTracker{},
+			gatewayLister:        listers.GetGatewayLister(),
+			statusManager: &fakeStatusManager{
+				FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+					return status.ProbeState{Ready: true}, nil
+				},
+				FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+					return status.ProbeState{Ready: true}, true
+				},
+			},
+		}
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				},
+			})
+	}))
+}
+
+func TestReconcileTLSPassthrough(t *testing.T) {
+	table := TableTest{{
+		Name: "first reconcile creates TLSRoute and listener",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withTLSPassthrough),
+			gw(defaultListener),
+		},
+		WantCreates: []runtime.Object{
+			tlsRoute(t, ing(withBasicSpec, withGatewayAPIClass, withTLSPassthrough)),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+			},
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}, addListenersPatch(t, gw(defaultListener, passthroughListener("example.com", "ns")), 1)},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIClass, withTLSPassthrough, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("HTTPRouteNotReady", "Waiting for HTTPRoute becomes Ready.")
+				i.Status.MarkLoadBalancerNotReady()
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeNormal, "Created", `Created TLSRoute "example.com"`),
+		},
+	}, {
+		Name: "cluster-local rules keep using HTTPRoutes",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLSPassthrough, withFinalizer),
+			gw(defaultListener, passthroughListener("example.com", "ns")),
+			tlsRoute(t, ing(withBasicSpec, withGatewayAPIClass, withTLSPassthrough), tlsRouteReady),
+		},
+		WantCreates: []runtime.Object{
+			httpRouteForRule(t, ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLSPassthrough), 1),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withInternalSpec, withGatewayAPIClass, withTLSPassthrough, withFinalizer, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkNetworkConfigured()
+				i.Status.MarkIngressNotReady("HTTPRouteNotReady", "Waiting for HTTPRoute becomes Ready.")
+			}, makeLoadBalancerPartiallyReady(v1alpha1.IngressVisibilityClusterLocal, v1alpha1.IngressVisibilityExternalIP)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "foo.svc.cluster.local"`),
+		},
+	}, {
+		Name: "already configured",
+		Key:  "ns/name",
+		Objects: []runtime.Object{
+			ing(withBasicSpec, withGatewayAPIClass, withTLSPassthrough, withFinalizer, makeItReady),
+			gw(defaultListener, passthroughListener("example.com", "ns")),
+			tlsRoute(t, ing(withBasicSpec, withGatewayAPIClass, withTLSPassthrough), tlsRouteReady),
+		},
+	}}
+
+	table.Test(t, GatewayFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher, tr *TableRow) controller.Reconciler {
+		r := &Reconciler{
+			gwapiclient:          fakegwapiclientset.Get(ctx),
+			httprouteLister:      listers.GetHTTPRouteLister(),
+			tlsrouteLister:       listers.GetTLSRouteLister(),
+			referenceGrantLister: listers.GetReferenceGrantLister(),
+			secretLister:         listers.GetSecretLister(),
+			serviceLister:        listers.GetServiceLister(),
+			tracker:              &NullTracker{},
+			gatewayLister:        listers.GetGatewayLister(),
+			statusManager: &fakeStatusManager{
+				FakeDoProbes: func(_ context.Context, b status.Backends) (status.ProbeState, error) {
+					if !b.TLSPassthrough {
+						return status.ProbeState{}, fmt.Errorf("expected TLS passthrough probing of %v", b.Key)
+					}
+					return status.ProbeState{Ready: true}, nil
+				},
+				FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+					return status.ProbeState{Ready: true}, true
+				},
+			},
+		}
+		// The fake tracker's `Add` method incorrectly pluralizes "gatewaies" using UnsafeGuessKindToResource,
+		// so create this via explicit call (per note in client-go/testing/fixture.go in tracker.Add)
+		fakeCreates := []runtime.Object{}
+		for _, x := range tr.Objects {
+			myGw, ok := x.(*gatewayapi.Gateway)
+			if ok {
+				fakegwapiclientset.Get(ctx).GatewayV1().Gateways(myGw.Namespace).Create(ctx, myGw, metav1.CreateOptions{})
+				tr.SkipNamespaceValidation = true
+				fakeCreates = append(fakeCreates, myGw)
+			}
+		}
+		tr.WantCreates = append(fakeCreates, tr.WantCreates...)
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: defaultConfig,
+				},
+			})
+	}))
+}
+
+func TestReconcileProbing(t *testing.T) {
+	table := TableTest{{
+		Name: "first reconciler probe returns false",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: false}, false
+			},
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: false}, nil
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass),
+		}, servicesAndEndpoints...),
+		WantCreates: []runtime.Object{httpRoute(t, ing(withBasicSpec, withGatewayAPIclass))},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkLoadBalancerNotReady()
+			}),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+			},
+			Name:  "name",
+			Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeNormal, "Created", "Created HTTPRoute \"example.com\""),
+		},
+	}, {
+		Name: "prober callback all endpoints ready",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: true}, nil
+			},
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: true}, true
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{
+			{Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReady)},
+		},
+	}, {
+		Name: "no probes with none probe strategy",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				t.Error("DoProbes called with the none probe strategy")
+				return status.ProbeState{}, nil
+			},
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{}, false
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions,
+				withAnnotation(map[string]string{ProbeStrategyAnnotationKey: "none"})),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass,
+				withAnnotation(map[string]string{ProbeStrategyAnnotationKey: "none"})), httpRouteReady),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer,
+				withAnnotation(map[string]string{ProbeStrategyAnnotationKey: "none"}), makeItReady),
+		}},
+	}, {
+		Name: "updated ingress - new backends used for endpoint probing",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withSecondRevisionSpec, withGatewayAPIclass, withFinalizer, makeItReady),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: true, Version: "previous"}, true
+			},
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: false}, nil
+			},
+		}),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(
+				withBasicSpec,
+				withSecondRevisionSpec,
+				withGatewayAPIclass,
+				withFinalizer,
+				makeItReady,
+				makeLoadBalancerNotReady,
+			),
+		}},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: HTTPRoute{
+				Name:      "example.com",
+				Namespace: "ns",
+				Hostname:  "example.com",
+				Rules: []RuleBuilder{
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Port:      123,
+					},
+					NormalRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Port:      123,
+						Weight:    100,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Path:      "/.well-known/knative/revision/ns/second-revision",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Port:      123,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Path:      "/.well-known/knative/revision/ns/goo",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Port:      123,
+					},
+				},
+				StatusConditions: []metav1.Condition{{
+					Type:   string(gatewayapi.RouteConditionAccepted),
+					Status: metav1.ConditionTrue,
+				}},
+			}.Build(),
+		}},
+	}, {
+		Name: "updated ingress - new backends not endpoint probed with gateway probe strategy",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withSecondRevisionSpec, withGatewayAPIclass, withFinalizer, makeItReady,
+				withAnnotation(map[string]string{ProbeStrategyAnnotationKey: "gateway"})),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: true, Version: "previous"}, true
+			},
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: false}, nil
+			},
+		}),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(
+				withBasicSpec,
+				withSecondRevisionSpec,
+				withGatewayAPIclass,
+				withFinalizer,
+				withAnnotation(map[string]string{ProbeStrategyAnnotationKey: "gateway"}),
+				makeItReady,
+				makeLoadBalancerNotReady,
+			),
+		}},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: httpRoute(t, ing(withBasicSpec, withSecondRevisionSpec, withGatewayAPIclass,
+				withAnnotation(map[string]string{ProbeStrategyAnnotationKey: "gateway"})), httpRouteReady),
+		}},
+	}, {
+		Name:    "updated ingress - invalid probe strategy",
+		Key:     "ns/name",
+		WantErr: true,
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withSecondRevisionSpec, withGatewayAPIclass, withFinalizer, makeItReady,
+				withAnnotation(map[string]string{ProbeStrategyAnnotationKey: "bogus"})),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: true, Version: "previous"}, true
+			},
+		}),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(
+				withBasicSpec,
+				withSecondRevisionSpec,
+				withGatewayAPIclass,
+				withFinalizer,
+				withAnnotation(map[string]string{ProbeStrategyAnnotationKey: "bogus"}),
+				makeItReady,
+				func(i *v1alpha1.Ingress) {
+					i.Status.MarkIngressNotReady("ReconcileIngressFailed", "Ingress reconciliation failed")
+				},
+			),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "InternalError", `annotation "gateway-api.networking.knative.dev/probe-strategy" must be one of endpoint, gateway or none, was: "bogus"`),
+		},
+	}, {
+		Name:    "updated ingress - invalid max concurrent probes",
+		Key:     "ns/name",
+		WantErr: true,
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withSecondRevisionSpec, withGatewayAPIclass, withFinalizer, makeItReady,
+				withAnnotation(map[string]string{MaxConcurrentProbesAnnotationKey: "0"})),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: true, Version: "previous"}, true
+			},
+		}),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(
+				withBasicSpec,
+				withSecondRevisionSpec,
+				withGatewayAPIclass,
+				withFinalizer,
+				withAnnotation(map[string]string{MaxConcurrentProbesAnnotationKey: "0"}),
+				makeItReady,
+				func(i *v1alpha1.Ingress) {
+					i.Status.MarkIngressNotReady("ReconcileIngressFailed", "Ingress reconciliation failed")
+				},
+			),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "InternalError", `annotation "gateway-api.networking.knative.dev/max-concurrent-probes" must be a positive integer, was: "0"`),
+		},
+	}, {
+		Name: "steady state ingress - endpoint probing still not ready",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{
+					Ready:   false,
+					Version: "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+				}, true
+			},
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: false}, nil
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec,
+				withSecondRevisionSpec,
+				withGatewayAPIclass,
+				withFinalizer,
+				makeItReady,
+				makeLoadBalancerNotReady,
+			),
+			HTTPRoute{
+				Name:      "example.com",
+				Namespace: "ns",
+				Hostname:  "example.com",
+				Rules: []RuleBuilder{
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Port:      123,
+					},
+					NormalRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Port:      123,
+						Weight:    100,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Path:      "/.well-known/knative/revision/ns/second-revision",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Port:      123,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Path:      "/.well-known/knative/revision/ns/goo",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Port:      123,
+					},
+				},
+				StatusConditions: []metav1.Condition{{
+					Type:   string(gatewayapi.RouteConditionAccepted),
+					Status: metav1.ConditionTrue,
+				}},
+			}.Build(),
+		}, servicesAndEndpoints...),
+	}, {
+		Name: "endpoints are ready - transition to new backends",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				state := status.ProbeState{Ready: true, Version: "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2"}
+				return state, true
+			},
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: false}, nil
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec,
+				withSecondRevisionSpec,
+				withGatewayAPIclass,
+				withFinalizer,
+				makeItReady,
+				makeLoadBalancerNotReady,
+			),
+			HTTPRoute{
+				Name:      "example.com",
+				Namespace: "ns",
+				Hostname:  "example.com",
+				Rules: []RuleBuilder{
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Port:      123,
+					},
+					NormalRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Port:      123,
+						Weight:    100,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Path:      "/.well-known/knative/revision/ns/second-revision",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Port:      123,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Path:      "/.well-known/knative/revision/ns/goo",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Port:      123,
+					},
+				},
+				StatusConditions: []metav1.Condition{{
+					Type:   string(gatewayapi.RouteConditionAccepted),
+					Status: metav1.ConditionTrue,
+				}},
+			}.Build(),
+		}, servicesAndEndpoints...),
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: HTTPRoute{
+				Name:      "example.com",
+				Namespace: "ns",
+				Hostname:  "example.com",
+				Rules: []RuleBuilder{
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Hash:      "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Port:      123,
+					},
+					NormalRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Port:      123,
+						Weight:    100,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Path:      "/.well-known/knative/revision/ns/second-revision",
+						Hash:      "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Port:      123,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Path:      "/.well-known/knative/revision/ns/goo",
+						Hash:      "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Port:      123,
+					},
+				},
+				StatusConditions: []metav1.Condition{{
+					Type:   string(gatewayapi.RouteConditionAccepted),
+					Status: metav1.ConditionTrue,
+				}},
+			}.Build(),
+		}},
+	}, {
+		Name: "steady state - transition probing still not ready",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				state := status.ProbeState{Ready: false, Version: "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2"}
+				return state, true
+			},
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: false}, nil
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec,
+				withSecondRevisionSpec,
+				withGatewayAPIclass,
+				withFinalizer,
+				makeItReady,
+				makeLoadBalancerNotReady,
+			),
+			&gatewayapi.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "example.com",
+					Namespace: "ns",
+					Annotations: map[string]string{
+						networking.IngressClassAnnotationKey: gatewayAPIIngressClassName,
+					},
+					Labels: map[string]string{
+						networking.VisibilityLabelKey: "",
+						resources.IngressUIDLabelKey:  "",
+					},
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion:         "networking.internal.knative.dev/v1alpha1",
+						Kind:               "Ingress",
+						Name:               "name",
+						Controller:         ptr.To(true),
+						BlockOwnerDeletion: ptr.To(true),
+					}},
+				},
+				Spec: gatewayapi.HTTPRouteSpec{
+					CommonRouteSpec: gatewayapi.CommonRouteSpec{
+						ParentRefs: []gatewayapi.ParentReference{{
+							Group:     ptr.To[gatewayapi.Group]("gateway.networking.k8s.io"),
+							Kind:      ptr.To[gatewayapi.Kind]("Gateway"),
+							Namespace: ptr.To[gatewayapi.Namespace]("istio-system"),
+							Name:      "istio-gateway",
+						}},
+					},
+					Hostnames: []gatewayapi.Hostname{"example.com"},
+					Rules: []gatewayapi.HTTPRouteRule{{
+						Matches: []gatewayapi.HTTPRouteMatch{{
+							Path: &gatewayapi.HTTPPathMatch{
+								Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
+								Value: ptr.To("/"),
+							},
+							Headers: []gatewayapi.HTTPHeaderMatch{{
+								Type:  ptr.To(gatewayapi.HeaderMatchExact),
+								Name:  header.HashKey,
+								Value: header.HashValueOverride,
+							}},
+						}},
+						Filters: []gatewayapi.HTTPRouteFilter{{
+							Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
+							RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
+								Set: []gatewayapi.HTTPHeader{{
+									Name:  header.HashKey,
+									Value: "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+								}},
+							},
+						}},
+						BackendRefs: []gatewayapi.HTTPBackendRef{{
+							Filters: []gatewayapi.HTTPRouteFilter{{
+								Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
+								RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
+									Set: []gatewayapi.HTTPHeader{{
+										Name:  "K-Serving-Revision",
+										Value: "second-revision",
+									}, {
+										Name:  "K-Serving-Namespace",
+										Value: "ns",
+									}},
+								},
+							}},
+							BackendRef: gatewayapi.BackendRef{
+								BackendObjectReference: gatewayapi.BackendObjectReference{
+									Group: ptr.To[gatewayapi.Group](""),
+									Kind:  ptr.To[gatewayapi.Kind]("Service"),
+									Name:  "second-revision",
+									Port:  ptr.To[gatewayapi.PortNumber](123),
+								},
+								Weight: ptr.To[int32](100),
+							},
+						}},
+					}, {
+						Matches: []gatewayapi.HTTPRouteMatch{{
+							Path: &gatewayapi.HTTPPathMatch{
+								Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
+								Value: ptr.To("/"),
+							},
+						}},
+						BackendRefs: []gatewayapi.HTTPBackendRef{{
+							Filters: []gatewayapi.HTTPRouteFilter{{
+								Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
+								RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
+									Set: []gatewayapi.HTTPHeader{{
+										Name:  "K-Serving-Revision",
+										Value: "second-revision",
+									}, {
+										Name:  "K-Serving-Namespace",
+										Value: "ns",
+									}},
+								},
+							}},
+							BackendRef: gatewayapi.BackendRef{
+								BackendObjectReference: gatewayapi.BackendObjectReference{
+									Group: ptr.To[gatewayapi.Group](""),
+									Kind:  ptr.To[gatewayapi.Kind]("Service"),
+									Name:  "second-revision",
+									Port:  ptr.To[gatewayapi.PortNumber](123),
+								},
+								Weight: ptr.To[int32](100),
+							},
+						}},
+					}, {
+						Matches: []gatewayapi.HTTPRouteMatch{{
+							Path: &gatewayapi.HTTPPathMatch{
+								Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
+								Value: ptr.To("/.well-known/knative/revision/ns/second-revision"),
+							},
+							Headers: []gatewayapi.HTTPHeaderMatch{{
+								Type:  ptr.To(gatewayapi.HeaderMatchExact),
+								Name:  header.HashKey,
+								Value: header.HashValueOverride,
+							}},
+						}},
+						Filters: []gatewayapi.HTTPRouteFilter{{
+							Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
+							RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
+								Set: []gatewayapi.HTTPHeader{{
+									Name:  header.HashKey,
+									Value: "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+								}},
+							},
+						}},
+						BackendRefs: []gatewayapi.HTTPBackendRef{{
+							Filters: []gatewayapi.HTTPRouteFilter{{
+								Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
+								RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
+									Set: []gatewayapi.HTTPHeader{{
+										Name:  "K-Serving-Namespace",
+										Value: "ns",
+									}, {
+										Name:  "K-Serving-Revision",
+										Value: "second-revision",
+									}},
+								},
+							}},
+							BackendRef: gatewayapi.BackendRef{
+								Weight: ptr.To[int32](100),
+								BackendObjectReference: gatewayapi.BackendObjectReference{
+									Group: ptr.To[gatewayapi.Group](""),
+									Kind:  ptr.To[gatewayapi.Kind]("Service"),
+									Name:  "second-revision",
+									Port:  ptr.To[gatewayapi.PortNumber](123),
+								},
+							},
+						}},
+					}, {
+						Matches: []gatewayapi.HTTPRouteMatch{{
+							Path: &gatewayapi.HTTPPathMatch{
+								Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
+								Value: ptr.To("/.well-known/knative/revision/ns/goo"),
+							},
+							Headers: []gatewayapi.HTTPHeaderMatch{{
+								Type:  ptr.To(gatewayapi.HeaderMatchExact),
+								Name:  header.HashKey,
+								Value: header.HashValueOverride,
+							}},
+						}},
+						Filters: []gatewayapi.HTTPRouteFilter{{
+							Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
+							RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
+								Set: []gatewayapi.HTTPHeader{{
+									Name:  header.HashKey,
+									Value: "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+								}},
+							},
+						}},
+						BackendRefs: []gatewayapi.HTTPBackendRef{{
+							Filters: []gatewayapi.HTTPRouteFilter{{
+								Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
+								RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
+									Set: []gatewayapi.HTTPHeader{{
+										Name:  "K-Serving-Namespace",
+										Value: "ns",
+									}, {
+										Name:  "K-Serving-Revision",
+										Value: "goo",
+									}},
+								},
+							}},
+							BackendRef: gatewayapi.BackendRef{
+								Weight: ptr.To[int32](100),
+								BackendObjectReference: gatewayapi.BackendObjectReference{
+									Group: ptr.To[gatewayapi.Group](""),
+									Kind:  ptr.To[gatewayapi.Kind]("Service"),
+									Name:  "goo",
+									Port:  ptr.To[gatewayapi.PortNumber](123),
+								},
+							},
+						}},
+					}},
+				},
+				Status: gatewayapi.HTTPRouteStatus{
+					RouteStatus: gatewayapi.RouteStatus{
+						Parents: []gatewayapi.RouteParentStatus{{
+							Conditions: []metav1.Condition{{
+								Type:   string(gatewayapi.RouteConditionAccepted),
+								Status: metav1.ConditionTrue,
+							}},
+						}},
+					},
+				},
+			},
+		},
+			servicesAndEndpoints...),
+	}, {
+		Name: "transition probe complete - drop probes",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				state := status.ProbeState{Ready: true, Version: "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2"}
+				return state, true
+			},
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: false}, nil
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec,
 				withSecondRevisionSpec,
 				withGatewayAPIclass,
 				withFinalizer,
@@ -518,13 +2968,13 @@ func TestReconcileProbing(t *testing.T) {
 				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "goo",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Name:      "second-revision",
+						Hash:      "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
 						Port:      123,
 					},
 					NormalRule{
 						Namespace: "ns",
-						Name:      "goo",
+						Name:      "second-revision",
 						Port:      123,
 						Weight:    100,
 					},
@@ -532,15 +2982,35 @@ func TestReconcileProbing(t *testing.T) {
 						Namespace: "ns",
 						Name:      "second-revision",
 						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Hash:      "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
 						Port:      123,
 					},
+				},
+				StatusConditions: []metav1.Condition{{
+					Type:   string(gatewayapi.RouteConditionAccepted),
+					Status: metav1.ConditionTrue,
+				}},
+			}.Build(),
+		},
+			servicesAndEndpoints...),
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: HTTPRoute{
+				Name:       "example.com",
+				Namespace:  "ns",
+				Hostname:   "example.com",
+				InputsHash: inputsHash(t, ing(withBasicSpec, withSecondRevisionSpec, withGatewayAPIclass), 0),
+				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "goo",
-						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Name:      "second-revision",
+						Hash:      "9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Port:      123,
+					},
+					NormalRule{
+						Namespace: "ns",
+						Name:      "second-revision",
 						Port:      123,
+						Weight:    100,
 					},
 				},
 				StatusConditions: []metav1.Condition{{
@@ -548,19 +3018,27 @@ func TestReconcileProbing(t *testing.T) {
 					Status: metav1.ConditionTrue,
 				}},
 			}.Build(),
-		}, servicesAndEndpoints...),
+		}},
 	}, {
-		Name: "endpoints are ready - transition to new backends",
+		Name: "dropping probes complete - mark ingress ready",
 		Key:  "ns/name",
 		Ctx: withStatusManager(&fakeStatusManager{
 			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				state := status.ProbeState{Ready: true, Version: "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2"}
+				state := status.ProbeState{Ready: true, Version: "9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2"}
 				return state, true
 			},
 			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
-				return status.ProbeState{Ready: false}, nil
+				return status.ProbeState{Ready: true}, nil
 			},
 		}),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec,
+				withSecondRevisionSpec,
+				withGatewayAPIclass,
+				withFinalizer,
+				makeItReady,
+			),
+		}},
 		Objects: append([]runtime.Object{
 			ing(withBasicSpec,
 				withSecondRevisionSpec,
@@ -576,27 +3054,67 @@ func TestReconcileProbing(t *testing.T) {
 				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "goo",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Name:      "second-revision",
+						Hash:      "9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
 						Port:      123,
 					},
 					NormalRule{
 						Namespace: "ns",
-						Name:      "goo",
+						Name:      "second-revision",
 						Port:      123,
 						Weight:    100,
 					},
+				},
+				StatusConditions: []metav1.Condition{{
+					Type:   string(gatewayapi.RouteConditionAccepted),
+					Status: metav1.ConditionTrue,
+				}},
+			}.Build(),
+		},
+			servicesAndEndpoints...),
+	}, {
+		Name: "probes complete - drop lingering endpoint probes",
+		// A transition interrupted by a restart may leave endpoint probes
+		// behind once the route is ready with its final version
+		Key: "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				state := status.ProbeState{Ready: true, Version: "9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2"}
+				return state, true
+			},
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: false}, nil
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec,
+				withSecondRevisionSpec,
+				withGatewayAPIclass,
+				withFinalizer,
+				makeItReady,
+				makeLoadBalancerNotReady,
+			),
+			HTTPRoute{
+				Name:      "example.com",
+				Namespace: "ns",
+				Hostname:  "example.com",
+				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "second-revision",
-						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Hash:      "9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Port:      123,
+					},
+					NormalRule{
+						Namespace: "ns",
+						Name:      "second-revision",
 						Port:      123,
+						Weight:    100,
 					},
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "goo",
-						Path:      "/.well-known/knative/revision/ns/goo",
+						Name:      "second-revision",
+						Path:      "/.well-known/knative/revision/ns/second-revision",
 						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
 						Port:      123,
 					},
@@ -606,17 +3124,19 @@ func TestReconcileProbing(t *testing.T) {
 					Status: metav1.ConditionTrue,
 				}},
 			}.Build(),
-		}, servicesAndEndpoints...),
+		},
+			servicesAndEndpoints...),
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: HTTPRoute{
-				Name:      "example.com",
-				Namespace: "ns",
-				Hostname:  "example.com",
+				Name:       "example.com",
+				Namespace:  "ns",
+				Hostname:   "example.com",
+				InputsHash: inputsHash(t, ing(withBasicSpec, withSecondRevisionSpec, withGatewayAPIclass), 0),
 				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "second-revision",
-						Hash:      "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Hash:      "9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
 						Port:      123,
 					},
 					NormalRule{
@@ -625,20 +3145,6 @@ func TestReconcileProbing(t *testing.T) {
 						Port:      123,
 						Weight:    100,
 					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
-						Port:      123,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
-						Port:      123,
-					},
 				},
 				StatusConditions: []metav1.Condition{{
 					Type:   string(gatewayapi.RouteConditionAccepted),
@@ -647,11 +3153,13 @@ func TestReconcileProbing(t *testing.T) {
 			}.Build(),
 		}},
 	}, {
-		Name: "steady state - transition probing still not ready",
-		Key:  "ns/name",
+		Name: "endpoints are ready - wrong hash",
+		// When the endpoints are ready but the hash is incorrect we do
+		// not transition the backend
+		Key: "ns/name",
 		Ctx: withStatusManager(&fakeStatusManager{
 			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				state := status.ProbeState{Ready: false, Version: "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2"}
+				state := status.ProbeState{Ready: true, Version: "bad-hash"}
 				return state, true
 			},
 			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
@@ -666,231 +3174,205 @@ func TestReconcileProbing(t *testing.T) {
 				makeItReady,
 				makeLoadBalancerNotReady,
 			),
-			&gatewayapi.HTTPRoute{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "example.com",
-					Namespace: "ns",
-					Annotations: map[string]string{
-						networking.IngressClassAnnotationKey: gatewayAPIIngressClassName,
+			HTTPRoute{
+				Name:      "example.com",
+				Namespace: "ns",
+				Hostname:  "example.com",
+				Rules: []RuleBuilder{
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Port:      123,
 					},
-					Labels: map[string]string{
-						networking.VisibilityLabelKey: "",
+					NormalRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Port:      123,
+						Weight:    100,
 					},
-					OwnerReferences: []metav1.OwnerReference{{
-						APIVersion:         "networking.internal.knative.dev/v1alpha1",
-						Kind:               "Ingress",
-						Name:               "name",
-						Controller:         ptr.To(true),
-						BlockOwnerDeletion: ptr.To(true),
-					}},
-				},
-				Spec: gatewayapi.HTTPRouteSpec{
-					CommonRouteSpec: gatewayapi.CommonRouteSpec{
-						ParentRefs: []gatewayapi.ParentReference{{
-							Group:     ptr.To[gatewayapi.Group]("gateway.networking.k8s.io"),
-							Kind:      ptr.To[gatewayapi.Kind]("Gateway"),
-							Namespace: ptr.To[gatewayapi.Namespace]("istio-system"),
-							Name:      "istio-gateway",
-						}},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Path:      "/.well-known/knative/revision/ns/second-revision",
+						Port:      123,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Path:      "/.well-known/knative/revision/ns/goo",
+						Port:      123,
 					},
-					Hostnames: []gatewayapi.Hostname{"example.com"},
-					Rules: []gatewayapi.HTTPRouteRule{{
-						Matches: []gatewayapi.HTTPRouteMatch{{
-							Path: &gatewayapi.HTTPPathMatch{
-								Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
-								Value: ptr.To("/"),
-							},
-							Headers: []gatewayapi.HTTPHeaderMatch{{
-								Type:  ptr.To(gatewayapi.HeaderMatchExact),
-								Name:  header.HashKey,
-								Value: header.HashValueOverride,
-							}},
-						}},
-						Filters: []gatewayapi.HTTPRouteFilter{{
-							Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
-							RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
-								Set: []gatewayapi.HTTPHeader{{
-									Name:  header.HashKey,
-									Value: "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
-								}},
-							},
-						}},
-						BackendRefs: []gatewayapi.HTTPBackendRef{{
-							Filters: []gatewayapi.HTTPRouteFilter{{
-								Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
-								RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
-									Set: []gatewayapi.HTTPHeader{{
-										Name:  "K-Serving-Revision",
-										Value: "second-revision",
-									}, {
-										Name:  "K-Serving-Namespace",
-										Value: "ns",
-									}},
-								},
-							}},
-							BackendRef: gatewayapi.BackendRef{
-								BackendObjectReference: gatewayapi.BackendObjectReference{
-									Group: ptr.To[gatewayapi.Group](""),
-									Kind:  ptr.To[gatewayapi.Kind]("Service"),
-									Name:  "second-revision",
-									Port:  ptr.To[gatewayapi.PortNumber](123),
-								},
-								Weight: ptr.To[int32](100),
-							},
-						}},
-					}, {
-						Matches: []gatewayapi.HTTPRouteMatch{{
-							Path: &gatewayapi.HTTPPathMatch{
-								Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
-								Value: ptr.To("/"),
-							},
-						}},
-						BackendRefs: []gatewayapi.HTTPBackendRef{{
-							Filters: []gatewayapi.HTTPRouteFilter{{
-								Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
-								RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
-									Set: []gatewayapi.HTTPHeader{{
-										Name:  "K-Serving-Revision",
-										Value: "second-revision",
-									}, {
-										Name:  "K-Serving-Namespace",
-										Value: "ns",
-									}},
-								},
-							}},
-							BackendRef: gatewayapi.BackendRef{
-								BackendObjectReference: gatewayapi.BackendObjectReference{
-									Group: ptr.To[gatewayapi.Group](""),
-									Kind:  ptr.To[gatewayapi.Kind]("Service"),
-									Name:  "second-revision",
-									Port:  ptr.To[gatewayapi.PortNumber](123),
-								},
-								Weight: ptr.To[int32](100),
-							},
-						}},
-					}, {
-						Matches: []gatewayapi.HTTPRouteMatch{{
-							Path: &gatewayapi.HTTPPathMatch{
-								Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
-								Value: ptr.To("/.well-known/knative/revision/ns/second-revision"),
-							},
-							Headers: []gatewayapi.HTTPHeaderMatch{{
-								Type:  ptr.To(gatewayapi.HeaderMatchExact),
-								Name:  header.HashKey,
-								Value: header.HashValueOverride,
-							}},
-						}},
-						Filters: []gatewayapi.HTTPRouteFilter{{
-							Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
-							RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
-								Set: []gatewayapi.HTTPHeader{{
-									Name:  header.HashKey,
-									Value: "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
-								}},
-							},
-						}},
-						BackendRefs: []gatewayapi.HTTPBackendRef{{
-							Filters: []gatewayapi.HTTPRouteFilter{{
-								Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
-								RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
-									Set: []gatewayapi.HTTPHeader{{
-										Name:  "K-Serving-Namespace",
-										Value: "ns",
-									}, {
-										Name:  "K-Serving-Revision",
-										Value: "second-revision",
-									}},
-								},
-							}},
-							BackendRef: gatewayapi.BackendRef{
-								Weight: ptr.To[int32](100),
-								BackendObjectReference: gatewayapi.BackendObjectReference{
-									Group: ptr.To[gatewayapi.Group](""),
-									Kind:  ptr.To[gatewayapi.Kind]("Service"),
-									Name:  "second-revision",
-									Port:  ptr.To[gatewayapi.PortNumber](123),
-								},
-							},
-						}},
-					}, {
-						Matches: []gatewayapi.HTTPRouteMatch{{
-							Path: &gatewayapi.HTTPPathMatch{
-								Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
-								Value: ptr.To("/.well-known/knative/revision/ns/goo"),
-							},
-							Headers: []gatewayapi.HTTPHeaderMatch{{
-								Type:  ptr.To(gatewayapi.HeaderMatchExact),
-								Name:  header.HashKey,
-								Value: header.HashValueOverride,
-							}},
-						}},
-						Filters: []gatewayapi.HTTPRouteFilter{{
-							Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
-							RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
-								Set: []gatewayapi.HTTPHeader{{
-									Name:  header.HashKey,
-									Value: "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
-								}},
-							},
-						}},
-						BackendRefs: []gatewayapi.HTTPBackendRef{{
-							Filters: []gatewayapi.HTTPRouteFilter{{
-								Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
-								RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
-									Set: []gatewayapi.HTTPHeader{{
-										Name:  "K-Serving-Namespace",
-										Value: "ns",
-									}, {
-										Name:  "K-Serving-Revision",
-										Value: "goo",
-									}},
-								},
-							}},
-							BackendRef: gatewayapi.BackendRef{
-								Weight: ptr.To[int32](100),
-								BackendObjectReference: gatewayapi.BackendObjectReference{
-									Group: ptr.To[gatewayapi.Group](""),
-									Kind:  ptr.To[gatewayapi.Kind]("Service"),
-									Name:  "goo",
-									Port:  ptr.To[gatewayapi.PortNumber](123),
-								},
-							},
-						}},
-					}},
 				},
-				Status: gatewayapi.HTTPRouteStatus{
-					RouteStatus: gatewayapi.RouteStatus{
-						Parents: []gatewayapi.RouteParentStatus{{
-							Conditions: []metav1.Condition{{
-								Type:   string(gatewayapi.RouteConditionAccepted),
-								Status: metav1.ConditionTrue,
-							}},
-						}},
+				StatusConditions: []metav1.Condition{{
+					Type:   string(gatewayapi.RouteConditionAccepted),
+					Status: metav1.ConditionTrue,
+				}},
+			}.Build(),
+		}, servicesAndEndpoints...),
+	}, {
+		Name: "updated ingress - while endpoint probing in progress",
+		// Here we want the existing probe to stop and then new backends added
+		// to the endpoint probes
+		Key: "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: false, Version: "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2"}, true
+			},
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: false}, nil
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(
+				withBasicSpec,
+				withThirdRevisionSpec,
+				withGatewayAPIclass,
+				withFinalizer,
+				makeItReady,
+				makeLoadBalancerNotReady),
+			HTTPRoute{
+				Name:      "example.com",
+				Namespace: "ns",
+				Hostname:  "example.com",
+				Rules: []RuleBuilder{
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+					},
+					NormalRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Port:      123,
+						Weight:    100,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Path:      "/.well-known/knative/revision/ns/second-revision",
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Path:      "/.well-known/knative/revision/ns/goo",
 					},
 				},
-			},
+				StatusConditions: []metav1.Condition{{
+					Type:   string(gatewayapi.RouteConditionAccepted),
+					Status: metav1.ConditionTrue,
+				}},
+			}.Build(),
 		},
 			servicesAndEndpoints...),
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: HTTPRoute{
+				Name:      "example.com",
+				Namespace: "ns",
+				Hostname:  "example.com",
+				Rules: []RuleBuilder{
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Hash:      "ep-40e40e812e47b79d9bae1f1d0ecec5bcb481030dad90a1aa6200f3389c31d374",
+					},
+					NormalRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Port:      123,
+						Weight:    100,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "third-revision",
+						Hash:      "ep-40e40e812e47b79d9bae1f1d0ecec5bcb481030dad90a1aa6200f3389c31d374",
+						Path:      "/.well-known/knative/revision/ns/third-revision",
+						Port:      123,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Hash:      "ep-40e40e812e47b79d9bae1f1d0ecec5bcb481030dad90a1aa6200f3389c31d374",
+						Path:      "/.well-known/knative/revision/ns/goo",
+						Port:      123,
+					},
+				},
+				StatusConditions: []metav1.Condition{{
+					Type:   string(gatewayapi.RouteConditionAccepted),
+					Status: metav1.ConditionTrue,
+				}},
+			}.Build(),
+		}},
 	}, {
-		Name: "transition probe complete - drop probes",
+		Name: "updated ingress - backend headers change",
 		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec,
+				withGatewayAPIclass,
+				withFinalizer,
+				makeItReady,
+				withBackendAppendHeaders("key", "value")),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
 		Ctx: withStatusManager(&fakeStatusManager{
 			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				state := status.ProbeState{Ready: true, Version: "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2"}
-				return state, true
+				return status.ProbeState{Ready: true, Version: "previous"}, true
 			},
 			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
 				return status.ProbeState{Ready: false}, nil
 			},
 		}),
-		Objects: append([]runtime.Object{
-			ing(withBasicSpec,
-				withSecondRevisionSpec,
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec,
 				withGatewayAPIclass,
 				withFinalizer,
 				makeItReady,
 				makeLoadBalancerNotReady,
+				withBackendAppendHeaders("key", "value"),
 			),
+		}},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: HTTPRoute{
+				Name:       "example.com",
+				Namespace:  "ns",
+				InputsHash: inputsHash(t, ing(withBasicSpec, withGatewayAPIclass, withBackendAppendHeaders("key", "value")), 0),
+				Rules: []RuleBuilder{
+					EndpointProbeRule{
+						Name:      "goo",
+						Namespace: "ns",
+						Hash:      "3531718c72349578ea2293f8ec1cd980d551f70295c1b0b4c10abfc0b2a248f8",
+						Headers:   []string{"key", "value"},
+						Port:      123,
+					},
+					NormalRule{
+						Name:      "goo",
+						Namespace: "ns",
+						Headers:   []string{"key", "value"},
+						Port:      123,
+						Weight:    100,
+					},
+				},
+				StatusConditions: []metav1.Condition{{
+					Type:   string(gatewayapi.RouteConditionAccepted),
+					Status: metav1.ConditionTrue,
+				}},
+			}.Build(),
+		}},
+	}, {
+		Name: "multiple visibility - updated ingress - new backends used for endpoint probing",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(
+				withBasicSpec,
+				withInternalSpec,
+				withSecondRevisionSpec,
+				withGatewayAPIclass,
+				withFinalizer,
+				makeItReady),
 			HTTPRoute{
 				Name:      "example.com",
 				Namespace: "ns",
@@ -898,22 +3380,39 @@ func TestReconcileProbing(t *testing.T) {
 				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "second-revision",
-						Hash:      "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Name:      "goo",
+						Hash:      "first-hash",
 						Port:      123,
 					},
 					NormalRule{
 						Namespace: "ns",
-						Name:      "second-revision",
+						Name:      "goo",
 						Port:      123,
 						Weight:    100,
 					},
+				},
+				StatusConditions: []metav1.Condition{{
+					Type:   string(gatewayapi.RouteConditionAccepted),
+					Status: metav1.ConditionTrue,
+				}},
+			}.Build(),
+			HTTPRoute{
+				Name:         "foo.svc.cluster.local",
+				Namespace:    "ns",
+				Hostnames:    []string{"foo.svc", "foo.svc.cluster.local"},
+				ClusterLocal: true,
+				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "second-revision",
-						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
-						Port:      123,
+						Name:      "goo",
+						Hash:      "first-hash",
+						Port:      124,
+					},
+					NormalRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Port:      124,
+						Weight:    100,
 					},
 				},
 				StatusConditions: []metav1.Condition{{
@@ -921,25 +3420,96 @@ func TestReconcileProbing(t *testing.T) {
 					Status: metav1.ConditionTrue,
 				}},
 			}.Build(),
-		},
-			servicesAndEndpoints...),
+		}, servicesAndEndpoints...),
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: true, Version: "previous"}, true
+			},
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: false}, nil
+			},
+		}),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(
+				withBasicSpec,
+				withInternalSpec,
+				withSecondRevisionSpec,
+				withGatewayAPIclass,
+				withFinalizer,
+				makeItReady,
+				makeLoadBalancerNotReady,
+			),
+		}},
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: HTTPRoute{
 				Name:      "example.com",
 				Namespace: "ns",
 				Hostname:  "example.com",
 				Rules: []RuleBuilder{
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      123,
+					},
+					NormalRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Port:      123,
+						Weight:    100,
+					},
 					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "second-revision",
-						Hash:      "9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Path:      "/.well-known/knative/revision/ns/second-revision",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      123,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Path:      "/.well-known/knative/revision/ns/goo",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      123,
 					},
+				},
+				StatusConditions: []metav1.Condition{{
+					Type:   string(gatewayapi.RouteConditionAccepted),
+					Status: metav1.ConditionTrue,
+				}},
+			}.Build(),
+		}, {
+			Object: HTTPRoute{
+				Name:         "foo.svc.cluster.local",
+				Namespace:    "ns",
+				Hostnames:    []string{"foo.svc", "foo.svc.cluster.local"},
+				ClusterLocal: true,
+				Rules: []RuleBuilder{
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      124,
+					},
 					NormalRule{
 						Namespace: "ns",
-						Name:      "second-revision",
-						Port:      123,
-						Weight:    100,
+						Name:      "goo",
+						Port:      124,
+						Weight:    100,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Path:      "/.well-known/knative/revision/ns/second-revision",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      124,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Path:      "/.well-known/knative/revision/ns/goo",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      124,
 					},
 				},
 				StatusConditions: []metav1.Condition{{
@@ -949,27 +3519,22 @@ func TestReconcileProbing(t *testing.T) {
 			}.Build(),
 		}},
 	}, {
-		Name: "dropping probes complete - mark ingress ready",
+		Name: "multiple visibility - steady state ingress - endpoint probing still not ready",
 		Key:  "ns/name",
 		Ctx: withStatusManager(&fakeStatusManager{
 			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				state := status.ProbeState{Ready: true, Version: "9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2"}
-				return state, true
+				return status.ProbeState{
+					Ready:   false,
+					Version: "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+				}, true
 			},
 			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
-				return status.ProbeState{Ready: true}, nil
+				return status.ProbeState{Ready: false}, nil
 			},
 		}),
-		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: ing(withBasicSpec,
-				withSecondRevisionSpec,
-				withGatewayAPIclass,
-				withFinalizer,
-				makeItReady,
-			),
-		}},
 		Objects: append([]runtime.Object{
 			ing(withBasicSpec,
+				withInternalSpec,
 				withSecondRevisionSpec,
 				withGatewayAPIclass,
 				withFinalizer,
@@ -983,32 +3548,81 @@ func TestReconcileProbing(t *testing.T) {
 				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "second-revision",
-						Hash:      "9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Name:      "goo",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      123,
 					},
 					NormalRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Port:      123,
+						Weight:    100,
+					},
+					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "second-revision",
+						Path:      "/.well-known/knative/revision/ns/second-revision",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      123,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Path:      "/.well-known/knative/revision/ns/goo",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      123,
+					},
+				},
+				StatusConditions: []metav1.Condition{{
+					Type:   string(gatewayapi.RouteConditionAccepted),
+					Status: metav1.ConditionTrue,
+				}},
+			}.Build(),
+			HTTPRoute{
+				Name:         "foo.svc.cluster.local",
+				Namespace:    "ns",
+				Hostnames:    []string{"foo.svc", "foo.svc.cluster.local"},
+				ClusterLocal: true,
+				Rules: []RuleBuilder{
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      124,
+					},
+					NormalRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Port:      124,
 						Weight:    100,
 					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Path:      "/.well-known/knative/revision/ns/second-revision",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      124,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Path:      "/.well-known/knative/revision/ns/goo",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      124,
+					},
 				},
 				StatusConditions: []metav1.Condition{{
 					Type:   string(gatewayapi.RouteConditionAccepted),
 					Status: metav1.ConditionTrue,
 				}},
 			}.Build(),
-		},
-			servicesAndEndpoints...),
+		}, servicesAndEndpoints...),
 	}, {
-		Name: "endpoints are ready - wrong hash",
-		// When the endpoints are ready but the hash is incorrect we do
-		// not transition the backend
-		Key: "ns/name",
+		Name: "multiple visibility - endpoints are ready - transition to new backends",
+		Key:  "ns/name",
 		Ctx: withStatusManager(&fakeStatusManager{
 			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				state := status.ProbeState{Ready: true, Version: "bad-hash"}
+				state := status.ProbeState{Ready: true, Version: "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970"}
 				return state, true
 			},
 			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
@@ -1016,7 +3630,9 @@ func TestReconcileProbing(t *testing.T) {
 			},
 		}),
 		Objects: append([]runtime.Object{
-			ing(withBasicSpec,
+			ing(
+				withBasicSpec,
+				withInternalSpec,
 				withSecondRevisionSpec,
 				withGatewayAPIclass,
 				withFinalizer,
@@ -1031,7 +3647,7 @@ func TestReconcileProbing(t *testing.T) {
 					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "goo",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      123,
 					},
 					NormalRule{
@@ -1043,15 +3659,15 @@ func TestReconcileProbing(t *testing.T) {
 					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "second-revision",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
 						Path:      "/.well-known/knative/revision/ns/second-revision",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      123,
 					},
 					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "goo",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
 						Path:      "/.well-known/knative/revision/ns/goo",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      123,
 					},
 				},
@@ -1060,55 +3676,37 @@ func TestReconcileProbing(t *testing.T) {
 					Status: metav1.ConditionTrue,
 				}},
 			}.Build(),
-		}, servicesAndEndpoints...),
-	}, {
-		Name: "updated ingress - while endpoint probing in progress",
-		// Here we want the existing probe to stop and then new backends added
-		// to the endpoint probes
-		Key: "ns/name",
-		Ctx: withStatusManager(&fakeStatusManager{
-			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				return status.ProbeState{Ready: false, Version: "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2"}, true
-			},
-			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
-				return status.ProbeState{Ready: false}, nil
-			},
-		}),
-		Objects: append([]runtime.Object{
-			ing(
-				withBasicSpec,
-				withThirdRevisionSpec,
-				withGatewayAPIclass,
-				withFinalizer,
-				makeItReady,
-				makeLoadBalancerNotReady),
 			HTTPRoute{
-				Name:      "example.com",
-				Namespace: "ns",
-				Hostname:  "example.com",
+				Name:         "foo.svc.cluster.local",
+				Namespace:    "ns",
+				Hostnames:    []string{"foo.svc", "foo.svc.cluster.local"},
+				ClusterLocal: true,
 				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "goo",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      124,
 					},
 					NormalRule{
 						Namespace: "ns",
 						Name:      "goo",
-						Port:      123,
+						Port:      124,
 						Weight:    100,
 					},
 					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "second-revision",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
 						Path:      "/.well-known/knative/revision/ns/second-revision",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      124,
 					},
 					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "goo",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
 						Path:      "/.well-known/knative/revision/ns/goo",
+						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      124,
 					},
 				},
 				StatusConditions: []metav1.Condition{{
@@ -1116,8 +3714,7 @@ func TestReconcileProbing(t *testing.T) {
 					Status: metav1.ConditionTrue,
 				}},
 			}.Build(),
-		},
-			servicesAndEndpoints...),
+		}, servicesAndEndpoints...),
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: HTTPRoute{
 				Name:      "example.com",
@@ -1126,27 +3723,28 @@ func TestReconcileProbing(t *testing.T) {
 				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "goo",
-						Hash:      "ep-40e40e812e47b79d9bae1f1d0ecec5bcb481030dad90a1aa6200f3389c31d374",
+						Name:      "second-revision",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      123,
 					},
 					NormalRule{
 						Namespace: "ns",
-						Name:      "goo",
+						Name:      "second-revision",
 						Port:      123,
 						Weight:    100,
 					},
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "third-revision",
-						Hash:      "ep-40e40e812e47b79d9bae1f1d0ecec5bcb481030dad90a1aa6200f3389c31d374",
-						Path:      "/.well-known/knative/revision/ns/third-revision",
+						Name:      "second-revision",
+						Path:      "/.well-known/knative/revision/ns/second-revision",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      123,
 					},
 					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "goo",
-						Hash:      "ep-40e40e812e47b79d9bae1f1d0ecec5bcb481030dad90a1aa6200f3389c31d374",
 						Path:      "/.well-known/knative/revision/ns/goo",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      123,
 					},
 				},
@@ -1155,54 +3753,39 @@ func TestReconcileProbing(t *testing.T) {
 					Status: metav1.ConditionTrue,
 				}},
 			}.Build(),
-		}},
-	}, {
-		Name: "updated ingress - backend headers change",
-		Key:  "ns/name",
-		Objects: append([]runtime.Object{
-			ing(withBasicSpec,
-				withGatewayAPIclass,
-				withFinalizer,
-				makeItReady,
-				withBackendAppendHeaders("key", "value")),
-			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
-		}, servicesAndEndpoints...),
-		Ctx: withStatusManager(&fakeStatusManager{
-			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				return status.ProbeState{Ready: true, Version: "previous"}, true
-			},
-			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
-				return status.ProbeState{Ready: false}, nil
-			},
-		}),
-		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: ing(withBasicSpec,
-				withGatewayAPIclass,
-				withFinalizer,
-				makeItReady,
-				makeLoadBalancerNotReady,
-				withBackendAppendHeaders("key", "value"),
-			),
-		}},
-		WantUpdates: []clientgotesting.UpdateActionImpl{{
+		}, {
 			Object: HTTPRoute{
-				Name:      "example.com",
-				Namespace: "ns",
+				Name:         "foo.svc.cluster.local",
+				Namespace:    "ns",
+				Hostnames:    []string{"foo.svc", "foo.svc.cluster.local"},
+				ClusterLocal: true,
 				Rules: []RuleBuilder{
 					EndpointProbeRule{
-						Name:      "goo",
 						Namespace: "ns",
-						Hash:      "3531718c72349578ea2293f8ec1cd980d551f70295c1b0b4c10abfc0b2a248f8",
-						Headers:   []string{"key", "value"},
-						Port:      123,
+						Name:      "second-revision",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      124,
 					},
 					NormalRule{
-						Name:      "goo",
 						Namespace: "ns",
-						Headers:   []string{"key", "value"},
-						Port:      123,
+						Name:      "second-revision",
+						Port:      124,
 						Weight:    100,
 					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Path:      "/.well-known/knative/revision/ns/second-revision",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      124,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Path:      "/.well-known/knative/revision/ns/goo",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      124,
+					},
 				},
 				StatusConditions: []metav1.Condition{{
 					Type:   string(gatewayapi.RouteConditionAccepted),
@@ -1211,8 +3794,17 @@ func TestReconcileProbing(t *testing.T) {
 			}.Build(),
 		}},
 	}, {
-		Name: "multiple visibility - updated ingress - new backends used for endpoint probing",
+		Name: "multiple visibility - steady state - transition probing still not ready",
 		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				state := status.ProbeState{Ready: false, Version: "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970"}
+				return state, true
+			},
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: false}, nil
+			},
+		}),
 		Objects: append([]runtime.Object{
 			ing(
 				withBasicSpec,
@@ -1220,7 +3812,9 @@ func TestReconcileProbing(t *testing.T) {
 				withSecondRevisionSpec,
 				withGatewayAPIclass,
 				withFinalizer,
-				makeItReady),
+				makeItReady,
+				makeLoadBalancerNotReady,
+			),
 			HTTPRoute{
 				Name:      "example.com",
 				Namespace: "ns",
@@ -1228,15 +3822,29 @@ func TestReconcileProbing(t *testing.T) {
 				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "goo",
-						Hash:      "first-hash",
+						Name:      "second-revision",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      123,
+					},
+					NormalRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Port:      123,
+						Weight:    100,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Path:      "/.well-known/knative/revision/ns/second-revision",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      123,
 					},
-					NormalRule{
+					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "goo",
+						Path:      "/.well-known/knative/revision/ns/goo",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      123,
-						Weight:    100,
 					},
 				},
 				StatusConditions: []metav1.Condition{{
@@ -1252,16 +3860,30 @@ func TestReconcileProbing(t *testing.T) {
 				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "goo",
-						Hash:      "first-hash",
+						Name:      "second-revision",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      124,
 					},
 					NormalRule{
 						Namespace: "ns",
-						Name:      "goo",
+						Name:      "second-revision",
 						Port:      124,
 						Weight:    100,
 					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Path:      "/.well-known/knative/revision/ns/second-revision",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      124,
+					},
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "goo",
+						Path:      "/.well-known/knative/revision/ns/goo",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      124,
+					},
 				},
 				StatusConditions: []metav1.Condition{{
 					Type:   string(gatewayapi.RouteConditionAccepted),
@@ -1269,16 +3891,20 @@ func TestReconcileProbing(t *testing.T) {
 				}},
 			}.Build(),
 		}, servicesAndEndpoints...),
+	}, {
+		Name: "multiple visibility - transition complete - drop probes",
+		Key:  "ns/name",
 		Ctx: withStatusManager(&fakeStatusManager{
 			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				return status.ProbeState{Ready: true, Version: "previous"}, true
+				state := status.ProbeState{Ready: true, Version: "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970"}
+				return state, true
 			},
 			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
 				return status.ProbeState{Ready: false}, nil
 			},
 		}),
-		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: ing(
+		Objects: append([]runtime.Object{
+			ing(
 				withBasicSpec,
 				withInternalSpec,
 				withSecondRevisionSpec,
@@ -1287,22 +3913,20 @@ func TestReconcileProbing(t *testing.T) {
 				makeItReady,
 				makeLoadBalancerNotReady,
 			),
-		}},
-		WantUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: HTTPRoute{
+			HTTPRoute{
 				Name:      "example.com",
 				Namespace: "ns",
 				Hostname:  "example.com",
 				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "goo",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Name:      "second-revision",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      123,
 					},
 					NormalRule{
 						Namespace: "ns",
-						Name:      "goo",
+						Name:      "second-revision",
 						Port:      123,
 						Weight:    100,
 					},
@@ -1310,14 +3934,14 @@ func TestReconcileProbing(t *testing.T) {
 						Namespace: "ns",
 						Name:      "second-revision",
 						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      123,
 					},
 					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "goo",
 						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      123,
 					},
 				},
@@ -1326,8 +3950,7 @@ func TestReconcileProbing(t *testing.T) {
 					Status: metav1.ConditionTrue,
 				}},
 			}.Build(),
-		}, {
-			Object: HTTPRoute{
+			HTTPRoute{
 				Name:         "foo.svc.cluster.local",
 				Namespace:    "ns",
 				Hostnames:    []string{"foo.svc", "foo.svc.cluster.local"},
@@ -1335,13 +3958,13 @@ func TestReconcileProbing(t *testing.T) {
 				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "goo",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Name:      "second-revision",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      124,
 					},
 					NormalRule{
 						Namespace: "ns",
-						Name:      "goo",
+						Name:      "second-revision",
 						Port:      124,
 						Weight:    100,
 					},
@@ -1349,16 +3972,68 @@ func TestReconcileProbing(t *testing.T) {
 						Namespace: "ns",
 						Name:      "second-revision",
 						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      124,
 					},
 					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "goo",
 						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      124,
+					},
+				},
+				StatusConditions: []metav1.Condition{{
+					Type:   string(gatewayapi.RouteConditionAccepted),
+					Status: metav1.ConditionTrue,
+				}},
+			}.Build(),
+		}, servicesAndEndpoints...),
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: HTTPRoute{
+				Name:       "example.com",
+				Namespace:  "ns",
+				Hostname:   "example.com",
+				InputsHash: inputsHash(t, ing(withBasicSpec, withInternalSpec, withSecondRevisionSpec, withGatewayAPIclass), 0),
+				Rules: []RuleBuilder{
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Hash:      "ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Port:      123,
+					},
+					NormalRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Port:      123,
+						Weight:    100,
+					},
+				},
+				StatusConditions: []metav1.Condition{{
+					Type:   string(gatewayapi.RouteConditionAccepted),
+					Status: metav1.ConditionTrue,
+				}},
+			}.Build(),
+		}, {
+			Object: HTTPRoute{
+				Name:         "foo.svc.cluster.local",
+				Namespace:    "ns",
+				Hostnames:    []string{"foo.svc", "foo.svc.cluster.local"},
+				ClusterLocal: true,
+				InputsHash:   inputsHash(t, ing(withBasicSpec, withInternalSpec, withSecondRevisionSpec, withGatewayAPIclass), 1),
+				Rules: []RuleBuilder{
+					EndpointProbeRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Hash:      "ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      124,
 					},
+					NormalRule{
+						Namespace: "ns",
+						Name:      "second-revision",
+						Port:      124,
+						Weight:    100,
+					},
 				},
 				StatusConditions: []metav1.Condition{{
 					Type:   string(gatewayapi.RouteConditionAccepted),
@@ -1367,21 +4042,20 @@ func TestReconcileProbing(t *testing.T) {
 			}.Build(),
 		}},
 	}, {
-		Name: "multiple visibility - steady state ingress - endpoint probing still not ready",
+		Name: "multiple visibility - dropping probes complete - mark ingress ready",
 		Key:  "ns/name",
 		Ctx: withStatusManager(&fakeStatusManager{
 			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				return status.ProbeState{
-					Ready:   false,
-					Version: "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
-				}, true
+				state := status.ProbeState{Ready: true, Version: "ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970"}
+				return state, true
 			},
 			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
-				return status.ProbeState{Ready: false}, nil
+				return status.ProbeState{Ready: true}, nil
 			},
 		}),
 		Objects: append([]runtime.Object{
-			ing(withBasicSpec,
+			ing(
+				withBasicSpec,
 				withInternalSpec,
 				withSecondRevisionSpec,
 				withGatewayAPIclass,
@@ -1396,29 +4070,15 @@ func TestReconcileProbing(t *testing.T) {
 				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "goo",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Name:      "second-revision",
+						Hash:      "ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      123,
 					},
 					NormalRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Port:      123,
-						Weight:    100,
-					},
-					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "second-revision",
-						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      123,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      123,
+						Weight:    100,
 					},
 				},
 				StatusConditions: []metav1.Condition{{
@@ -1434,29 +4094,15 @@ func TestReconcileProbing(t *testing.T) {
 				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "goo",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Name:      "second-revision",
+						Hash:      "ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      124,
 					},
 					NormalRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Port:      124,
-						Weight:    100,
-					},
-					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "second-revision",
-						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      124,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
 						Port:      124,
+						Weight:    100,
 					},
 				},
 				StatusConditions: []metav1.Condition{{
@@ -1465,21 +4111,31 @@ func TestReconcileProbing(t *testing.T) {
 				}},
 			}.Build(),
 		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(
+				withBasicSpec,
+				withInternalSpec,
+				withSecondRevisionSpec,
+				withGatewayAPIclass,
+				withFinalizer,
+				makeItReady,
+			),
+		}},
 	}, {
-		Name: "multiple visibility - endpoints are ready - transition to new backends",
-		Key:  "ns/name",
+		Name: "multiple visibility - steady state ingress - probe state flips while reconciliing",
+		// Probes are tied to the HTTPRoute so they can have different hashes
+		Key: "ns/name",
 		Ctx: withStatusManager(&fakeStatusManager{
-			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				state := status.ProbeState{Ready: true, Version: "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970"}
-				return state, true
-			},
+			FakeIsProbeActive: ProbeIsReadyAfter{
+				Attempts: 1,
+				Hash:     "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+			}.Build(),
 			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
 				return status.ProbeState{Ready: false}, nil
 			},
 		}),
 		Objects: append([]runtime.Object{
-			ing(
-				withBasicSpec,
+			ing(withBasicSpec,
 				withInternalSpec,
 				withSecondRevisionSpec,
 				withGatewayAPIclass,
@@ -1564,44 +4220,6 @@ func TestReconcileProbing(t *testing.T) {
 			}.Build(),
 		}, servicesAndEndpoints...),
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: HTTPRoute{
-				Name:      "example.com",
-				Namespace: "ns",
-				Hostname:  "example.com",
-				Rules: []RuleBuilder{
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      123,
-					},
-					NormalRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Port:      123,
-						Weight:    100,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      123,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      123,
-					},
-				},
-				StatusConditions: []metav1.Condition{{
-					Type:   string(gatewayapi.RouteConditionAccepted),
-					Status: metav1.ConditionTrue,
-				}},
-			}.Build(),
-		}, {
 			Object: HTTPRoute{
 				Name:         "foo.svc.cluster.local",
 				Namespace:    "ns",
@@ -1642,21 +4260,41 @@ func TestReconcileProbing(t *testing.T) {
 			}.Build(),
 		}},
 	}, {
-		Name: "multiple visibility - steady state - transition probing still not ready",
-		Key:  "ns/name",
+		Name: "stale informer cache",
+		// A stale httproute in the informer cache can result in probing to get stuck
+		//
+		// The following events need to happen
+		// 1. Endpoint probe (version: ep-*) succeeds for an HTTPRoute (generation 2)
+		// 2. We trigger an HTTPRoute update to move new route backends into the
+		//    main rules. API server has HTTPRoute with generation 3.
+		// 3. Start probing (version: tr-*)
+		// 4. Some event triggers reconciliation of the parent Ingress. The hash
+		//    remains the same so there are no spec changes.
+		// 5. Informer cache still has HTTPRoute (generation 2)
+		Key: "ns/name",
 		Ctx: withStatusManager(&fakeStatusManager{
 			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				state := status.ProbeState{Ready: false, Version: "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970"}
-				return state, true
+				return status.ProbeState{
+					Ready:   false,
+					Version: "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
+				}, false
 			},
-			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
-				return status.ProbeState{Ready: false}, nil
+			FakeDoProbes: func(ctx context.Context, s status.Backends) (status.ProbeState, error) {
+				state := status.ProbeState{}
+				expectedHash := "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2"
+
+				if s.Version != expectedHash {
+					panic(fmt.Sprintf("Expected DoProbes to be called with the same hash got: %q want: %q",
+						s.Version,
+						expectedHash,
+					))
+				}
+
+				return state, nil
 			},
 		}),
 		Objects: append([]runtime.Object{
-			ing(
-				withBasicSpec,
-				withInternalSpec,
+			ing(withBasicSpec,
 				withSecondRevisionSpec,
 				withGatewayAPIclass,
 				withFinalizer,
@@ -1670,13 +4308,13 @@ func TestReconcileProbing(t *testing.T) {
 				Rules: []RuleBuilder{
 					EndpointProbeRule{
 						Namespace: "ns",
-						Name:      "second-revision",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Name:      "goo",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
 						Port:      123,
 					},
 					NormalRule{
 						Namespace: "ns",
-						Name:      "second-revision",
+						Name:      "goo",
 						Port:      123,
 						Weight:    100,
 					},
@@ -1684,14 +4322,14 @@ func TestReconcileProbing(t *testing.T) {
 						Namespace: "ns",
 						Name:      "second-revision",
 						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
 						Port:      123,
 					},
 					EndpointProbeRule{
 						Namespace: "ns",
 						Name:      "goo",
 						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
+						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
 						Port:      123,
 					},
 				},
@@ -1700,553 +4338,902 @@ func TestReconcileProbing(t *testing.T) {
 					Status: metav1.ConditionTrue,
 				}},
 			}.Build(),
-			HTTPRoute{
-				Name:         "foo.svc.cluster.local",
-				Namespace:    "ns",
-				Hostnames:    []string{"foo.svc", "foo.svc.cluster.local"},
-				ClusterLocal: true,
-				Rules: []RuleBuilder{
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      124,
-					},
-					NormalRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Port:      124,
-						Weight:    100,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      124,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      124,
-					},
+		}, servicesAndEndpoints...),
+		WantUpdates: nil, // No updates
+	}, {
+		Name: "multiple visibility - external probes ready - cluster-local probes failing",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{}, false
+			},
+			FakeDoProbes: probeReadyForVisibility(v1alpha1.IngressVisibilityExternalIP),
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withInternalSpec, withGatewayAPIclass, withFinalizer, makeItReady),
+			httpRoute(t, ing(withBasicSpec, withInternalSpec, withGatewayAPIclass), httpRouteReady),
+			httpRouteForRule(t, ing(withBasicSpec, withInternalSpec, withGatewayAPIclass), 1, httpRouteReady),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withInternalSpec, withGatewayAPIclass, withFinalizer, makeItReady,
+				makeLoadBalancerPartiallyReady(v1alpha1.IngressVisibilityClusterLocal, v1alpha1.IngressVisibilityExternalIP)),
+		}},
+	}, {
+		Name: "multiple visibility - cluster-local probes ready - external probes failing",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{}, false
+			},
+			FakeDoProbes: probeReadyForVisibility(v1alpha1.IngressVisibilityClusterLocal),
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withInternalSpec, withGatewayAPIclass, withFinalizer, makeItReady),
+			httpRoute(t, ing(withBasicSpec, withInternalSpec, withGatewayAPIclass), httpRouteReady),
+			httpRouteForRule(t, ing(withBasicSpec, withInternalSpec, withGatewayAPIclass), 1, httpRouteReady),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withInternalSpec, withGatewayAPIclass, withFinalizer, makeItReady,
+				makeLoadBalancerPartiallyReady(v1alpha1.IngressVisibilityExternalIP, v1alpha1.IngressVisibilityClusterLocal)),
+		}},
+	}, {
+		Name: "multiple visibility - all probes failing",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{}, false
+			},
+			FakeDoProbes: probeReadyForVisibility(),
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withInternalSpec, withGatewayAPIclass, withFinalizer, makeItReady),
+			httpRoute(t, ing(withBasicSpec, withInternalSpec, withGatewayAPIclass), httpRouteReady),
+			httpRouteForRule(t, ing(withBasicSpec, withInternalSpec, withGatewayAPIclass), 1, httpRouteReady),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withInternalSpec, withGatewayAPIclass, withFinalizer, makeItReady,
+				makeLoadBalancerNotReady),
+		}},
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		statusManager := ctx.Value(fakeStatusKey).(status.Manager)
+		r := &Reconciler{
+			gwapiclient: fakegwapiclientset.Get(ctx),
+			// Listers index properties about resources
+			httprouteLister: listers.GetHTTPRouteLister(),
+			gatewayLister:   listers.GetGatewayLister(),
+			serviceLister:   listers.GetServiceLister(),
+			statusManager:   statusManager,
+		}
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: defaultConfig,
 				},
-				StatusConditions: []metav1.Condition{{
-					Type:   string(gatewayapi.RouteConditionAccepted),
-					Status: metav1.ConditionTrue,
-				}},
-			}.Build(),
+			})
+	}))
+}
+
+func TestReconcileProbeHeaders(t *testing.T) {
+	cfg := defaultConfig.DeepCopy()
+	cfg.GatewayPlugin.ExternalGateways[0].ProbeHeaders = map[string]string{"X-Gateway-Auth": "secret"}
+
+	table := TableTest{{
+		Name: "probes are sent with the headers of their gateway",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{}, false
+			},
+			FakeDoProbes: func(_ context.Context, backends status.Backends) (status.ProbeState, error) {
+				want := cfg.GatewayPlugin.ExternalGateway().ProbeHeaders
+				if _, ok := backends.URLs[v1alpha1.IngressVisibilityClusterLocal]; ok {
+					want = nil
+				}
+				if diff := cmp.Diff(want, backends.Headers); diff != "" {
+					t.Errorf("Unexpected probe headers for %v (-want, +got): %s", backends.Key, diff)
+				}
+				return status.ProbeState{Ready: true}, nil
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withInternalSpec, withGatewayAPIclass, withFinalizer, makeItReady),
+			httpRoute(t, ing(withBasicSpec, withInternalSpec, withGatewayAPIclass), httpRouteReady),
+			httpRouteForRule(t, ing(withBasicSpec, withInternalSpec, withGatewayAPIclass), 1, httpRouteReady),
+		}, servicesAndEndpoints...),
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			gwapiclient:     fakegwapiclientset.Get(ctx),
+			httprouteLister: listers.GetHTTPRouteLister(),
+			gatewayLister:   listers.GetGatewayLister(),
+			serviceLister:   listers.GetServiceLister(),
+			statusManager:   ctx.Value(fakeStatusKey).(status.Manager),
+		}
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				},
+			})
+	}))
+}
+
+type ProbeIsReadyAfter struct {
+	Attempts int
+	Hash     string
+}
+
+func (p ProbeIsReadyAfter) Build() func(types.NamespacedName) (status.ProbeState, bool) {
+	return func(types.NamespacedName) (status.ProbeState, bool) {
+		ready := p.Attempts <= 0
+		p.Attempts--
+		return status.ProbeState{Ready: ready, Version: p.Hash}, true
+	}
+}
+
+// probeReadyForVisibility returns a DoProbes func reporting the probes of the
+// given visibilities as ready.
+func probeReadyForVisibility(ready ...v1alpha1.IngressVisibility) func(context.Context, status.Backends) (status.ProbeState, error) {
+	return func(_ context.Context, backends status.Backends) (status.ProbeState, error) {
+		for visibility := range backends.URLs {
+			if !slices.Contains(ready, visibility) {
+				return status.ProbeState{Ready: false}, nil
+			}
+		}
+		return status.ProbeState{Ready: true}, nil
+	}
+}
+
+func makeLoadBalancerNotReady(i *v1alpha1.Ingress) {
+	i.Status.MarkLoadBalancerNotReady()
+}
+
+func makeLoadBalancerPartiallyReady(notReady, ready v1alpha1.IngressVisibility) IngressOption {
+	return func(i *v1alpha1.Ingress) {
+		i.GetConditionSet().Manage(&i.Status).MarkUnknown(v1alpha1.IngressConditionLoadBalancerReady,
+			"PartiallyReady", "Waiting for %s routes to be ready, %s routes are ready", notReady, ready)
+	}
+}
+
+func TestReconcileProbingOffClusterGateway(t *testing.T) {
+	table := TableTest{{
+		Name: "prober callback all endpoints ready",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: true}, nil
+			},
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: true}, true
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			gw(defaultListener, setStatusPublicAddressIP),
+			gw(privateGw, defaultListener, setStatusPrivateAddress),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{
+			{Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReadyOffClusterGateway)},
+		},
+	}, {
+		Name: "gateway has hostname in address",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: true}, nil
+			},
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: true}, true
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			gw(defaultListener, setStatusPublicAddressHostname),
+			gw(privateGw, defaultListener, setStatusPrivateAddress),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{
+			{Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReadyOffClusterGatewayHostname)},
+		},
+	}, {
+		Name: "gateway has IPv6 address",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: true}, nil
+			},
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: true}, true
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			gw(defaultListener, setStatusPublicAddressIPv6),
+			gw(privateGw, defaultListener, setStatusPrivateAddress),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkNetworkConfigured()
+				i.Status.MarkLoadBalancerReady(
+					[]v1alpha1.LoadBalancerIngressStatus{{
+						IP: "2001:db8::1",
+					}},
+					[]v1alpha1.LoadBalancerIngressStatus{{
+						IP: privateGatewayAddress,
+					}})
+			}),
+		}},
+	}, {
+		Name: "gateway has named address",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: true}, nil
+			},
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: true}, true
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			gw(defaultListener, setStatusPublicAddressNamed),
+			gw(privateGw, defaultListener, setStatusPrivateAddress),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkNetworkConfigured()
+				i.Status.MarkLoadBalancerReady(
+					[]v1alpha1.LoadBalancerIngressStatus{{
+						DomainInternal: network.GetServiceHostname("gateway-svc", testNamespace),
+					}},
+					[]v1alpha1.LoadBalancerIngressStatus{{
+						IP: privateGatewayAddress,
+					}})
+			}),
+		}},
+	}, {
+		Name: "gateway not ready",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: true}, nil
+			},
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: true}, true
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			gw(defaultListener),
+			gw(privateGw, defaultListener),
 		}, servicesAndEndpoints...),
+		WantErr: true,
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{
+			{Object: ing(
+				withBasicSpec,
+				withGatewayAPIClass,
+				withFinalizer,
+				func(i *v1alpha1.Ingress) {
+					i.Status.InitializeConditions()
+					i.Status.MarkLoadBalancerNotReady()
+					i.Status.MarkNetworkConfigured()
+					i.Status.MarkIngressNotReady("ReconcileIngressFailed", "Ingress reconciliation failed")
+				},
+			)},
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "InternalError", `no address found in status of Gateway istio-system/istio-gateway`),
+		},
 	}, {
-		Name: "multiple visibility - transition complete - drop probes",
+		Name: "gateway doesn't exist",
 		Key:  "ns/name",
 		Ctx: withStatusManager(&fakeStatusManager{
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: true}, nil
+			},
 			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				state := status.ProbeState{Ready: true, Version: "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970"}
-				return state, true
+				return status.ProbeState{Ready: true}, true
 			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{Object: ing(
+			withBasicSpec,
+			withGatewayAPIClass,
+			withFinalizer,
+			func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkLoadBalancerFailed("GatewayDoesNotExist", "could not find Gateway istio-system/istio-gateway")
+				i.Status.MarkNetworkConfigured()
+			})}},
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		statusManager := ctx.Value(fakeStatusKey).(status.Manager)
+		r := &Reconciler{
+			gwapiclient: fakegwapiclientset.Get(ctx),
+			// Listers index properties about resources
+			httprouteLister: listers.GetHTTPRouteLister(),
+			gatewayLister:   listers.GetGatewayLister(),
+			serviceLister:   listers.GetServiceLister(),
+			statusManager:   statusManager,
+		}
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: configNoService,
+				},
+			})
+	}))
+}
+
+func TestReconcileGatewayAddressTypes(t *testing.T) {
+	table := TableTest{{
+		Name: "gateway has an address of the preferred type",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
 			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
-				return status.ProbeState{Ready: false}, nil
+				return status.ProbeState{Ready: true}, nil
+			},
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: true}, true
 			},
 		}),
 		Objects: append([]runtime.Object{
-			ing(
-				withBasicSpec,
-				withInternalSpec,
-				withSecondRevisionSpec,
-				withGatewayAPIclass,
-				withFinalizer,
-				makeItReady,
-				makeLoadBalancerNotReady,
-			),
-			HTTPRoute{
-				Name:      "example.com",
-				Namespace: "ns",
-				Hostname:  "example.com",
-				Rules: []RuleBuilder{
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      123,
-					},
-					NormalRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Port:      123,
-						Weight:    100,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      123,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      123,
-					},
-				},
-				StatusConditions: []metav1.Condition{{
-					Type:   string(gatewayapi.RouteConditionAccepted),
-					Status: metav1.ConditionTrue,
-				}},
-			}.Build(),
-			HTTPRoute{
-				Name:         "foo.svc.cluster.local",
-				Namespace:    "ns",
-				Hostnames:    []string{"foo.svc", "foo.svc.cluster.local"},
-				ClusterLocal: true,
-				Rules: []RuleBuilder{
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      124,
-					},
-					NormalRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Port:      124,
-						Weight:    100,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      124,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      124,
-					},
-				},
-				StatusConditions: []metav1.Condition{{
-					Type:   string(gatewayapi.RouteConditionAccepted),
-					Status: metav1.ConditionTrue,
-				}},
-			}.Build(),
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			gw(defaultListener, setStatusPublicAddressHostname, setStatusPublicAddressIP),
+			gw(privateGw, defaultListener, setStatusPrivateAddress),
 		}, servicesAndEndpoints...),
-		WantUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: HTTPRoute{
-				Name:      "example.com",
-				Namespace: "ns",
-				Hostname:  "example.com",
-				Rules: []RuleBuilder{
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Hash:      "ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      123,
-					},
-					NormalRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Port:      123,
-						Weight:    100,
-					},
-				},
-				StatusConditions: []metav1.Condition{{
-					Type:   string(gatewayapi.RouteConditionAccepted),
-					Status: metav1.ConditionTrue,
-				}},
-			}.Build(),
-		}, {
-			Object: HTTPRoute{
-				Name:         "foo.svc.cluster.local",
-				Namespace:    "ns",
-				Hostnames:    []string{"foo.svc", "foo.svc.cluster.local"},
-				ClusterLocal: true,
-				Rules: []RuleBuilder{
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Hash:      "ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      124,
-					},
-					NormalRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Port:      124,
-						Weight:    100,
-					},
-				},
-				StatusConditions: []metav1.Condition{{
-					Type:   string(gatewayapi.RouteConditionAccepted),
-					Status: metav1.ConditionTrue,
-				}},
-			}.Build(),
-		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{
+			{Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReadyOffClusterGateway)},
+		},
 	}, {
-		Name: "multiple visibility - dropping probes complete - mark ingress ready",
+		Name: "gateway has no address of the preferred types",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: true}, nil
+			},
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: true}, true
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			gw(defaultListener, setStatusPublicAddressHostname),
+			gw(privateGw, defaultListener, setStatusPrivateAddress),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{
+			{Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReadyOffClusterGatewayHostname)},
+		},
+	}}
+
+	cfg := configNoService.DeepCopy()
+	cfg.GatewayPlugin.ExternalGateways[0].AddressTypes = []gatewayapi.AddressType{gatewayapi.IPAddressType}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		statusManager := ctx.Value(fakeStatusKey).(status.Manager)
+		r := &Reconciler{
+			gwapiclient: fakegwapiclientset.Get(ctx),
+			// Listers index properties about resources
+			httprouteLister: listers.GetHTTPRouteLister(),
+			gatewayLister:   listers.GetGatewayLister(),
+			serviceLister:   listers.GetServiceLister(),
+			statusManager:   statusManager,
+		}
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				},
+			})
+	}))
+}
+
+func TestReconcileProbingGracePeriod(t *testing.T) {
+	table := TableTest{{
+		Name: "ready ingress keeps status within grace period",
 		Key:  "ns/name",
 		Ctx: withStatusManager(&fakeStatusManager{
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: false, LastReady: time.Now()}, nil
+			},
 			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				state := status.ProbeState{Ready: true, Version: "ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970"}
-				return state, true
+				return status.ProbeState{Ready: true}, true
 			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReady),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		// Requeued to check again once the grace period elapses
+		WantErr: true,
+	}, {
+		Name: "ready ingress marked not ready after grace period",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
 			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
-				return status.ProbeState{Ready: true}, nil
+				return status.ProbeState{Ready: false, LastReady: time.Now().Add(-time.Hour)}, nil
+			},
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: true}, true
 			},
 		}),
 		Objects: append([]runtime.Object{
-			ing(
-				withBasicSpec,
-				withInternalSpec,
-				withSecondRevisionSpec,
-				withGatewayAPIclass,
-				withFinalizer,
-				makeItReady,
-				makeLoadBalancerNotReady,
-			),
-			HTTPRoute{
-				Name:      "example.com",
-				Namespace: "ns",
-				Hostname:  "example.com",
-				Rules: []RuleBuilder{
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Hash:      "ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      123,
-					},
-					NormalRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Port:      123,
-						Weight:    100,
-					},
-				},
-				StatusConditions: []metav1.Condition{{
-					Type:   string(gatewayapi.RouteConditionAccepted),
-					Status: metav1.ConditionTrue,
-				}},
-			}.Build(),
-			HTTPRoute{
-				Name:         "foo.svc.cluster.local",
-				Namespace:    "ns",
-				Hostnames:    []string{"foo.svc", "foo.svc.cluster.local"},
-				ClusterLocal: true,
-				Rules: []RuleBuilder{
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Hash:      "ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      124,
-					},
-					NormalRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Port:      124,
-						Weight:    100,
-					},
-				},
-				StatusConditions: []metav1.Condition{{
-					Type:   string(gatewayapi.RouteConditionAccepted),
-					Status: metav1.ConditionTrue,
-				}},
-			}.Build(),
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReady),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
 		}, servicesAndEndpoints...),
-		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: ing(
-				withBasicSpec,
-				withInternalSpec,
-				withSecondRevisionSpec,
-				withGatewayAPIclass,
-				withFinalizer,
-				makeItReady,
-			),
-		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{
+			{Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReady, makeLoadBalancerNotReady)},
+		},
 	}, {
-		Name: "multiple visibility - steady state ingress - probe state flips while reconciliing",
-		// Probes are tied to the HTTPRoute so they can have different hashes
-		Key: "ns/name",
+		Name: "ingress never ready ignores grace period",
+		Key:  "ns/name",
 		Ctx: withStatusManager(&fakeStatusManager{
-			FakeIsProbeActive: ProbeIsReadyAfter{
-				Attempts: 1,
-				Hash:     "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-			}.Build(),
 			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
-				return status.ProbeState{Ready: false}, nil
+				return status.ProbeState{Ready: false, LastReady: time.Now()}, nil
+			},
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: true}, true
 			},
 		}),
 		Objects: append([]runtime.Object{
-			ing(withBasicSpec,
-				withInternalSpec,
-				withSecondRevisionSpec,
-				withGatewayAPIclass,
-				withFinalizer,
-				makeItReady,
-				makeLoadBalancerNotReady,
-			),
-			HTTPRoute{
-				Name:      "example.com",
-				Namespace: "ns",
-				Hostname:  "example.com",
-				Rules: []RuleBuilder{
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      123,
-					},
-					NormalRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Port:      123,
-						Weight:    100,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      123,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      123,
-					},
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkNetworkConfigured()
+				i.Status.MarkLoadBalancerNotReady()
+			}),
+		}},
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		statusManager := ctx.Value(fakeStatusKey).(status.Manager)
+		r := &Reconciler{
+			gwapiclient: fakegwapiclientset.Get(ctx),
+			// Listers index properties about resources
+			httprouteLister: listers.GetHTTPRouteLister(),
+			gatewayLister:   listers.GetGatewayLister(),
+			serviceLister:   listers.GetServiceLister(),
+			statusManager:   statusManager,
+		}
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: configWithGracePeriod,
 				},
-				StatusConditions: []metav1.Condition{{
-					Type:   string(gatewayapi.RouteConditionAccepted),
-					Status: metav1.ConditionTrue,
-				}},
-			}.Build(),
-			HTTPRoute{
-				Name:         "foo.svc.cluster.local",
-				Namespace:    "ns",
-				Hostnames:    []string{"foo.svc", "foo.svc.cluster.local"},
-				ClusterLocal: true,
-				Rules: []RuleBuilder{
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      124,
-					},
-					NormalRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Port:      124,
-						Weight:    100,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      124,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "ep-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      124,
-					},
+			})
+	}))
+}
+
+func TestReconcileGatewayNotProgrammed(t *testing.T) {
+	notProgrammed := func(g *gatewayapi.Gateway) {
+		g.Status.Conditions = []metav1.Condition{{
+			Type:   string(gatewayapi.GatewayConditionProgrammed),
+			Status: metav1.ConditionFalse,
+			Reason: string(gatewayapi.GatewayReasonPending),
+		}}
+	}
+	programmed := func(g *gatewayapi.Gateway) {
+		g.Status.Conditions = []metav1.Condition{{
+			Type:   string(gatewayapi.GatewayConditionProgrammed),
+			Status: metav1.ConditionTrue,
+			Reason: string(gatewayapi.GatewayReasonProgrammed),
+		}}
+	}
+	notProgrammedSince := func(d time.Duration) IngressOption {
+		return func(i *v1alpha1.Ingress) {
+			i.Status.Annotations = map[string]string{
+				GatewayNotProgrammedSinceAnnotationKey: time.Now().Add(-d).UTC().Format(time.RFC3339),
+			}
+		}
+	}
+	timeoutMessage := "Gateway istio-system/istio-gateway has not been Programmed for more than 5m0s."
+
+	table := TableTest{{
+		Name: "gateway not programmed within the timeout",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions, notProgrammedSince(time.Minute)),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			gw(defaultListener, notProgrammed),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, notProgrammedSince(time.Minute), func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkNetworkConfigured()
+				i.Status.MarkLoadBalancerNotReady()
+				i.Status.MarkIngressNotReady("GatewayNotProgrammed", "Waiting for Gateway istio-system/istio-gateway to be Programmed.")
+			}),
+		}},
+		// Requeued to check the Gateway again
+		WantErr: true,
+	}, {
+		Name: "gateway not programmed past the timeout",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions, notProgrammedSince(time.Hour)),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			gw(defaultListener, notProgrammed),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, notProgrammedSince(time.Hour), func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkNetworkConfigured()
+				i.Status.MarkLoadBalancerFailed("GatewayNotProgrammedTimeout", timeoutMessage)
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "GatewayNotProgrammedTimeout", timeoutMessage),
+		},
+		WantErr: true,
+	}, {
+		Name: "gateway still not programmed past the timeout",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, notProgrammedSince(time.Hour), func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkNetworkConfigured()
+				i.Status.MarkLoadBalancerFailed("GatewayNotProgrammedTimeout", timeoutMessage)
+			}),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			gw(defaultListener, notProgrammed),
+		}, servicesAndEndpoints...),
+		// The event was emitted when the Ingress started failing
+		WantErr: true,
+	}, {
+		Name: "gateway programmed again",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions, notProgrammedSince(time.Hour)),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			gw(defaultListener, programmed),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReady),
+		}},
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			gwapiclient: fakegwapiclientset.Get(ctx),
+			// Listers index properties about resources
+			httprouteLister: listers.GetHTTPRouteLister(),
+			gatewayLister:   listers.GetGatewayLister(),
+			serviceLister:   listers.GetServiceLister(),
+			statusManager: &fakeStatusManager{
+				FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+					return status.ProbeState{Ready: true}, nil
 				},
-				StatusConditions: []metav1.Condition{{
-					Type:   string(gatewayapi.RouteConditionAccepted),
-					Status: metav1.ConditionTrue,
-				}},
-			}.Build(),
-		}, servicesAndEndpoints...),
-		WantUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: HTTPRoute{
-				Name:         "foo.svc.cluster.local",
-				Namespace:    "ns",
-				Hostnames:    []string{"foo.svc", "foo.svc.cluster.local"},
-				ClusterLocal: true,
-				Rules: []RuleBuilder{
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      124,
-					},
-					NormalRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Port:      124,
-						Weight:    100,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      124,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "tr-ff3cee4d49fbd4547b85c63d56e88eb866d4043951761f069d6afe14a2e61970",
-						Port:      124,
-					},
+				FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+					return status.ProbeState{Ready: true}, true
 				},
-				StatusConditions: []metav1.Condition{{
-					Type:   string(gatewayapi.RouteConditionAccepted),
-					Status: metav1.ConditionTrue,
-				}},
-			}.Build(),
+			},
+		}
+		cfg := defaultConfig.DeepCopy()
+		cfg.GatewayPlugin.GatewayProgrammedTimeout = 5 * time.Minute
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				},
+			})
+	}))
+}
+
+func TestReconcileDeferredRoutes(t *testing.T) {
+	cfg := defaultConfig.DeepCopy()
+	cfg.GatewayPlugin.DeferRoutesUntilGatewayExists = true
+
+	finalizerPatch := clientgotesting.PatchActionImpl{
+		ActionImpl: clientgotesting.ActionImpl{
+			Namespace: "ns",
+		},
+		Name:  "name",
+		Patch: []byte(`{"metadata":{"finalizers":["ingresses.networking.internal.knative.dev"],"resourceVersion":""}}`),
+	}
+
+	table := TableTest{{
+		Name: "gateway missing",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass),
+		}, servicesAndEndpoints...),
+		// No route is created until the Gateway exists
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkLoadBalancerNotReady()
+				i.Status.MarkIngressNotReady("GatewayNotFound", "Waiting for Gateway istio-system/istio-gateway to exist.")
+			}),
 		}},
+		WantPatches: []clientgotesting.PatchActionImpl{finalizerPatch},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+		},
 	}, {
-		Name: "stale informer cache",
-		// A stale httproute in the informer cache can result in probing to get stuck
-		//
-		// The following events need to happen
-		// 1. Endpoint probe (version: ep-*) succeeds for an HTTPRoute (generation 2)
-		// 2. We trigger an HTTPRoute update to move new route backends into the
-		//    main rules. API server has HTTPRoute with generation 3.
-		// 3. Start probing (version: tr-*)
-		// 4. Some event triggers reconciliation of the parent Ingress. The hash
-		//    remains the same so there are no spec changes.
-		// 5. Informer cache still has HTTPRoute (generation 2)
-		Key: "ns/name",
-		Ctx: withStatusManager(&fakeStatusManager{
-			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				return status.ProbeState{
-					Ready:   false,
-					Version: "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
-				}, false
+		Name: "gateway exists",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass),
+			gw(defaultListener),
+		}, servicesAndEndpoints...),
+		WantCreates: []runtime.Object{httpRouteWithConfig(t, cfg, ing(withBasicSpec, withGatewayAPIclass), 0)},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("HTTPRouteNotReady", "Waiting for HTTPRoute becomes Ready.")
+				i.Status.MarkLoadBalancerNotReady()
+			}),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{finalizerPatch},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", `Updated "name" finalizers`),
+			Eventf(corev1.EventTypeNormal, "Created", "Created HTTPRoute \"example.com\""),
+		},
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			gwapiclient: fakegwapiclientset.Get(ctx),
+			// Listers index properties about resources
+			httprouteLister: listers.GetHTTPRouteLister(),
+			gatewayLister:   listers.GetGatewayLister(),
+			serviceLister:   listers.GetServiceLister(),
+			tracker:         &NullTracker{},
+			statusManager: &fakeStatusManager{
+				FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+					return status.ProbeState{Ready: true}, nil
+				},
+				FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+					return status.ProbeState{Ready: true}, true
+				},
 			},
-			FakeDoProbes: func(ctx context.Context, s status.Backends) (status.ProbeState, error) {
-				state := status.ProbeState{}
-				expectedHash := "tr-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2"
+		}
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				},
+			})
+	}))
+}
 
-				if s.Version != expectedHash {
-					panic(fmt.Sprintf("Expected DoProbes to be called with the same hash got: %q want: %q",
-						s.Version,
-						expectedHash,
-					))
-				}
+func TestReconcileClassTransition(t *testing.T) {
+	withOtherClass := withAnnotation(map[string]string{
+		networking.IngressClassAnnotationKey: "fake-controller",
+	})
 
-				return state, nil
-			},
-		}),
+	table := TableTest{{
+		Name: "abandon ingress whose class changed away",
+		Key:  "ns/name",
 		Objects: append([]runtime.Object{
-			ing(withBasicSpec,
-				withSecondRevisionSpec,
-				withGatewayAPIclass,
-				withFinalizer,
-				makeItReady,
-				makeLoadBalancerNotReady,
-			),
-			HTTPRoute{
-				Name:      "example.com",
+			ing(withBasicSpec, withOtherClass, withFinalizer, makeItReady),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			gw(defaultListener),
+		}, servicesAndEndpoints...),
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
 				Namespace: "ns",
-				Hostname:  "example.com",
-				Rules: []RuleBuilder{
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
-						Port:      123,
-					},
-					NormalRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Port:      123,
-						Weight:    100,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "second-revision",
-						Path:      "/.well-known/knative/revision/ns/second-revision",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
-						Port:      123,
-					},
-					EndpointProbeRule{
-						Namespace: "ns",
-						Name:      "goo",
-						Path:      "/.well-known/knative/revision/ns/goo",
-						Hash:      "ep-9333a9a68409bb44f2a5f538d2d7c617e5338b6b6c1ebc5e00a19612a5c962c2",
-						Port:      123,
-					},
+				Verb:      "delete",
+				Resource:  gatewayapi.SchemeGroupVersion.WithResource("httproutes"),
+			},
+			Name: "example.com",
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Abandoned", `Deleted the routes of the Ingress, its class is now "fake-controller"`),
+		},
+	}, {
+		Name: "ingress of another class already abandoned",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withOtherClass, withFinalizer, makeItReady),
+			gw(defaultListener),
+		}, servicesAndEndpoints...),
+	}, {
+		Name: "adopt ingress whose class changed to gateway-api",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			// The status and finalizer were left by the previous controller
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+			gw(defaultListener),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReady),
+		}},
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			gwapiclient: fakegwapiclientset.Get(ctx),
+			// Listers index properties about resources
+			httprouteLister:      listers.GetHTTPRouteLister(),
+			tlsrouteLister:       listers.GetTLSRouteLister(),
+			referenceGrantLister: listers.GetReferenceGrantLister(),
+			gatewayLister:        listers.GetGatewayLister(),
+			serviceLister:        listers.GetServiceLister(),
+			statusManager: &fakeStatusManager{
+				FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+					return status.ProbeState{Ready: true}, nil
 				},
-				StatusConditions: []metav1.Condition{{
-					Type:   string(gatewayapi.RouteConditionAccepted),
-					Status: metav1.ConditionTrue,
-				}},
-			}.Build(),
+				FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+					return status.ProbeState{Ready: true}, true
+				},
+			},
+		}
+		store := &testConfigStore{
+			config: defaultConfig,
+		}
+		ingr := ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: store,
+			})
+		return &classTransitionReconciler{
+			leaderAwareReconciler: ingr.(leaderAwareReconciler),
+			reconciler:            r,
+			ingressLister:         listers.GetIngressLister(),
+			configStore:           store,
+			recorder:              controller.GetEventRecorder(ctx),
+		}
+	}))
+}
+
+func TestReconcileSplitHTTPRoutes(t *testing.T) {
+	cfg := defaultConfig.DeepCopy()
+	cfg.GatewayPlugin.MaxHTTPRouteRules = 2
+
+	withSecondPath := func(i *v1alpha1.Ingress) {
+		path := *i.Spec.Rules[0].HTTP.Paths[0].DeepCopy()
+		path.Path = "/second"
+		i.Spec.Rules[0].HTTP.Paths = append(i.Spec.Rules[0].HTTP.Paths, path)
+	}
+	// splitRoutes returns the HTTPRoutes of the parts of the first rule
+	splitRoutes := func(i *v1alpha1.Ingress, opts ...HTTPRouteOption) []runtime.Object {
+		ingress.InsertProbe(i)
+		ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+		var routes []runtime.Object
+		for part, rule := range resources.SplitHTTPRouteRule(&i.Spec.Rules[0], cfg.GatewayPlugin.MaxHTTPRouteRules) {
+			route, err := resources.MakeHTTPRoute(ctx, i, &rule, resources.WithHTTPRoutePart(part))
+			if err != nil {
+				t.Fatal("MakeHTTPRoute() =", err)
+			}
+			for _, opt := range opts {
+				opt(route)
+			}
+			routes = append(routes, route)
+		}
+		return routes
+	}
+	splitName := resources.HTTPRouteName(&v1alpha1.IngressRule{Hosts: []string{"example.com"}}, 1)
+
+	table := TableTest{{
+		Name: "first reconcile splits the paths across routes",
+		Key:  "ns/name",
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withSecondPath, withGatewayAPIclass, withFinalizer),
 		}, servicesAndEndpoints...),
-		WantUpdates: nil, // No updates
+		WantCreates: splitRoutes(ing(withBasicSpec, withSecondPath, withGatewayAPIclass)),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withSecondPath, withGatewayAPIclass, withFinalizer, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkIngressNotReady("HTTPRouteNotReady", "Waiting for HTTPRoute becomes Ready.")
+				i.Status.MarkLoadBalancerNotReady()
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Created", `Created HTTPRoute "example.com"`),
+			Eventf(corev1.EventTypeNormal, "Created", "Created HTTPRoute %q", splitName),
+		},
+	}, {
+		Name: "split routes ready",
+		Key:  "ns/name",
+		Objects: append(append([]runtime.Object{
+			ing(withBasicSpec, withSecondPath, withGatewayAPIclass, withFinalizer, withInitialConditions),
+			gw(defaultListener),
+		}, splitRoutes(ing(withBasicSpec, withSecondPath, withGatewayAPIclass), httpRouteReady)...), servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withSecondPath, withGatewayAPIclass, withFinalizer, makeItReady),
+		}},
+	}, {
+		Name: "paths fit in a single route again",
+		Key:  "ns/name",
+		Objects: append(append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReady),
+			gw(defaultListener),
+		}, splitRoutes(ing(withBasicSpec, withSecondPath, withGatewayAPIclass), httpRouteReady)...), servicesAndEndpoints...),
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: httpRouteWithConfig(t, cfg, ing(withBasicSpec, withGatewayAPIclass), 0, httpRouteReady),
+		}},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "ns",
+				Verb:      "delete",
+				Resource:  gatewayapi.SchemeGroupVersion.WithResource("httproutes"),
+			},
+			Name: splitName,
+		}},
 	}}
 
 	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
-		statusManager := ctx.Value(fakeStatusKey).(status.Manager)
 		r := &Reconciler{
 			gwapiclient: fakegwapiclientset.Get(ctx),
 			// Listers index properties about resources
 			httprouteLister: listers.GetHTTPRouteLister(),
 			gatewayLister:   listers.GetGatewayLister(),
-			statusManager:   statusManager,
+			serviceLister:   listers.GetServiceLister(),
+			statusManager: &fakeStatusManager{
+				FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+					return status.ProbeState{Ready: true}, nil
+				},
+				FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+					return status.ProbeState{Ready: true}, true
+				},
+			},
 		}
 		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
 			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
 			controller.Options{
 				ConfigStore: &testConfigStore{
-					config: defaultConfig,
+					config: cfg,
 				},
 			})
 	}))
 }
 
-type ProbeIsReadyAfter struct {
-	Attempts int
-	Hash     string
-}
-
-func (p ProbeIsReadyAfter) Build() func(types.NamespacedName) (status.ProbeState, bool) {
-	return func(types.NamespacedName) (status.ProbeState, bool) {
-		ready := p.Attempts <= 0
-		p.Attempts--
-		return status.ProbeState{Ready: ready, Version: p.Hash}, true
-	}
-}
-
-func makeLoadBalancerNotReady(i *v1alpha1.Ingress) {
-	i.Status.MarkLoadBalancerNotReady()
-}
-
-func TestReconcileProbingOffClusterGateway(t *testing.T) {
+func TestReconcileProbingEvents(t *testing.T) {
 	table := TableTest{{
-		Name: "prober callback all endpoints ready",
+		Name: "probes not ready emits event",
 		Key:  "ns/name",
 		Ctx: withStatusManager(&fakeStatusManager{
 			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
-				return status.ProbeState{Ready: true}, nil
+				return status.ProbeState{Ready: false}, nil
 			},
 			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				return status.ProbeState{Ready: true}, true
+				return status.ProbeState{Ready: false}, true
 			},
 		}),
 		Objects: append([]runtime.Object{
 			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
 			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
-			gw(defaultListener, setStatusPublicAddressIP),
-			gw(privateGw, defaultListener, setStatusPrivateAddress),
 		}, servicesAndEndpoints...),
-		WantStatusUpdates: []clientgotesting.UpdateActionImpl{
-			{Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReadyOffClusterGateway)},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkNetworkConfigured()
+				i.Status.MarkLoadBalancerNotReady()
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "ProbesNotReady",
+				"Waiting for probes to succeed: hosts [example.com] via istio-gateway.istio-system.svc.cluster.local"),
 		},
 	}, {
-		Name: "gateway has hostname in address",
+		Name: "ready probes emit no event",
 		Key:  "ns/name",
 		Ctx: withStatusManager(&fakeStatusManager{
 			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
@@ -2259,48 +5246,92 @@ func TestReconcileProbingOffClusterGateway(t *testing.T) {
 		Objects: append([]runtime.Object{
 			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
 			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
-			gw(defaultListener, setStatusPublicAddressHostname),
-			gw(privateGw, defaultListener, setStatusPrivateAddress),
 		}, servicesAndEndpoints...),
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{
-			{Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReadyOffClusterGatewayHostname)},
+			{Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReady)},
 		},
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		statusManager := ctx.Value(fakeStatusKey).(status.Manager)
+		r := &Reconciler{
+			gwapiclient: fakegwapiclientset.Get(ctx),
+			// Listers index properties about resources
+			httprouteLister: listers.GetHTTPRouteLister(),
+			gatewayLister:   listers.GetGatewayLister(),
+			serviceLister:   listers.GetServiceLister(),
+			statusManager:   statusManager,
+		}
+		// The next failing reconcile is due for an event
+		r.probeFailures.counts = map[types.NamespacedName]int{
+			{Namespace: "ns", Name: "name"}: probeEventInterval - 1,
+		}
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: defaultConfig,
+				},
+			})
+	}))
+}
+
+func TestReconcileProbeFailureAnnotation(t *testing.T) {
+	const failure = "http://example.com/healthz: context deadline exceeded"
+
+	withProbeFailure := func(failure string) IngressOption {
+		return func(i *v1alpha1.Ingress) {
+			i.Status.Annotations = map[string]string{LastProbeFailureAnnotationKey: failure}
+		}
+	}
+
+	table := TableTest{{
+		Name: "failed probe recorded",
+		Key:  "ns/name",
+		Ctx: withStatusManager(&fakeStatusManager{
+			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
+				return status.ProbeState{Ready: false, LastFailure: failure}, nil
+			},
+			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+				return status.ProbeState{Ready: false, LastFailure: failure}, true
+			},
+		}),
+		Objects: append([]runtime.Object{
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withProbeFailure(failure), func(i *v1alpha1.Ingress) {
+				i.Status.InitializeConditions()
+				i.Status.MarkNetworkConfigured()
+				i.Status.MarkLoadBalancerNotReady()
+			}),
+		}},
 	}, {
-		Name: "gateway not ready",
+		Name: "long failure truncated",
 		Key:  "ns/name",
 		Ctx: withStatusManager(&fakeStatusManager{
 			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
-				return status.ProbeState{Ready: true}, nil
+				return status.ProbeState{Ready: false, LastFailure: strings.Repeat("x", 1000)}, nil
 			},
 			FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
-				return status.ProbeState{Ready: true}, true
+				return status.ProbeState{Ready: false, LastFailure: strings.Repeat("x", 1000)}, true
 			},
 		}),
 		Objects: append([]runtime.Object{
 			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
 			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
-			gw(defaultListener),
-			gw(privateGw, defaultListener),
 		}, servicesAndEndpoints...),
-		WantErr: true,
-		WantStatusUpdates: []clientgotesting.UpdateActionImpl{
-			{Object: ing(
-				withBasicSpec,
-				withGatewayAPIClass,
-				withFinalizer,
-				func(i *v1alpha1.Ingress) {
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer,
+				withProbeFailure(strings.Repeat("x", maxProbeFailureLength-3)+"..."), func(i *v1alpha1.Ingress) {
 					i.Status.InitializeConditions()
-					i.Status.MarkLoadBalancerNotReady()
 					i.Status.MarkNetworkConfigured()
-					i.Status.MarkIngressNotReady("ReconcileIngressFailed", "Ingress reconciliation failed")
-				},
-			)},
-		},
-		WantEvents: []string{
-			Eventf(corev1.EventTypeWarning, "InternalError", `no address found in status of Gateway istio-system/istio-gateway`),
-		},
+					i.Status.MarkLoadBalancerNotReady()
+				}),
+		}},
 	}, {
-		Name: "gateway doesn't exist",
+		Name: "failure cleared once the probes succeed",
 		Key:  "ns/name",
 		Ctx: withStatusManager(&fakeStatusManager{
 			FakeDoProbes: func(context.Context, status.Backends) (status.ProbeState, error) {
@@ -2311,18 +5342,16 @@ func TestReconcileProbingOffClusterGateway(t *testing.T) {
 			},
 		}),
 		Objects: append([]runtime.Object{
-			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withInitialConditions),
-			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
-		}, servicesAndEndpoints...),
-		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{Object: ing(
-			withBasicSpec,
-			withGatewayAPIClass,
-			withFinalizer,
-			func(i *v1alpha1.Ingress) {
+			ing(withBasicSpec, withGatewayAPIclass, withFinalizer, withProbeFailure(failure), func(i *v1alpha1.Ingress) {
 				i.Status.InitializeConditions()
-				i.Status.MarkLoadBalancerFailed("GatewayDoesNotExist", "could not find Gateway istio-system/istio-gateway")
 				i.Status.MarkNetworkConfigured()
-			})}},
+				i.Status.MarkLoadBalancerNotReady()
+			}),
+			httpRoute(t, ing(withBasicSpec, withGatewayAPIclass), httpRouteReady),
+		}, servicesAndEndpoints...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{
+			{Object: ing(withBasicSpec, withGatewayAPIclass, withFinalizer, makeItReady)},
+		},
 	}}
 
 	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
@@ -2332,13 +5361,14 @@ func TestReconcileProbingOffClusterGateway(t *testing.T) {
 			// Listers index properties about resources
 			httprouteLister: listers.GetHTTPRouteLister(),
 			gatewayLister:   listers.GetGatewayLister(),
+			serviceLister:   listers.GetServiceLister(),
 			statusManager:   statusManager,
 		}
 		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakeingressclient.Get(ctx),
 			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, gatewayAPIIngressClassName,
 			controller.Options{
 				ConfigStore: &testConfigStore{
-					config: configNoService,
+					config: defaultConfig,
 				},
 			})
 	}))
@@ -2368,6 +5398,23 @@ func makeItReadyOffClusterGatewayHostname(i *v1alpha1.Ingress) {
 		}})
 }
 
+// loadBalancerService makes a LoadBalancer Service of a Gateway with the
+// given ingress points.
+func loadBalancerService(name string, ingress ...corev1.LoadBalancerIngress) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeLoadBalancer,
+		},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{Ingress: ingress},
+		},
+	}
+}
+
 func makeItReady(i *v1alpha1.Ingress) {
 	i.Status.InitializeConditions()
 	i.Status.MarkNetworkConfigured()
@@ -2381,16 +5428,69 @@ func makeItReady(i *v1alpha1.Ingress) {
 }
 
 func httpRoute(t *testing.T, i *v1alpha1.Ingress, opts ...HTTPRouteOption) runtime.Object {
+	t.Helper()
+	return httpRouteWithConfig(t, defaultConfig, i, 0, opts...)
+}
+
+// inputsHash returns the inputs hash of the HTTPRoute of the rule.
+func inputsHash(t *testing.T, i *v1alpha1.Ingress, rule int) string {
 	t.Helper()
 	ingress.InsertProbe(i)
 	ctx := (&testConfigStore{config: defaultConfig}).ToContext(context.Background())
-	httpRoute, _ := resources.MakeHTTPRoute(ctx, i, &i.Spec.Rules[0])
+	hash, err := resources.HTTPRouteInputsHash(ctx, i, &i.Spec.Rules[rule], 0)
+	if err != nil {
+		t.Fatal("HTTPRouteInputsHash() =", err)
+	}
+	return hash
+}
+
+func httpRouteForRule(t *testing.T, i *v1alpha1.Ingress, rule int, opts ...HTTPRouteOption) runtime.Object {
+	t.Helper()
+	return httpRouteWithConfig(t, defaultConfig, i, rule, opts...)
+}
+
+// httpRouteWithConfig returns the HTTPRoute of the rule built with the
+// configuration.
+func httpRouteWithConfig(t *testing.T, cfg *config.Config, i *v1alpha1.Ingress, rule int, opts ...HTTPRouteOption) *gatewayapi.HTTPRoute {
+	t.Helper()
+	ingress.InsertProbe(i)
+	ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+	httpRoute, _ := resources.MakeHTTPRoute(ctx, i, &i.Spec.Rules[rule])
 	for _, opt := range opts {
 		opt(httpRoute)
 	}
 	return httpRoute
 }
 
+func tlsRoute(t *testing.T, i *v1alpha1.Ingress, opts ...func(*gatewayapiv1alpha2.TLSRoute)) runtime.Object {
+	t.Helper()
+	ingress.InsertProbe(i)
+	ctx := (&testConfigStore{config: defaultConfig}).ToContext(context.Background())
+	tlsRoute, err := resources.MakeTLSRoute(ctx, i, &i.Spec.Rules[0])
+	if err != nil {
+		t.Fatal("MakeTLSRoute() =", err)
+	}
+	for _, opt := range opts {
+		opt(tlsRoute)
+	}
+	return tlsRoute
+}
+
+func tlsRouteReady(r *gatewayapiv1alpha2.TLSRoute) {
+	r.Status.Parents = []gatewayapi.RouteParentStatus{{
+		Conditions: []metav1.Condition{{
+			Type:   string(gatewayapi.RouteConditionAccepted),
+			Status: metav1.ConditionTrue,
+		}},
+	}}
+}
+
+func withTLSPassthrough(i *v1alpha1.Ingress) {
+	withAnnotation(map[string]string{
+		resources.TLSModeAnnotationKey: resources.TLSModePassthrough,
+	})(i)
+}
+
 func httpRouteReady(h *gatewayapi.HTTPRoute) {
 	h.Status.Parents = []gatewayapi.RouteParentStatus{{
 		Conditions: []metav1.Condition{{
@@ -2400,8 +5500,23 @@ func httpRouteReady(h *gatewayapi.HTTPRoute) {
 	}}
 }
 
+// httpRouteNotProgrammed adds a Programmed=False condition to the parents of
+// the route, as set by Gateways that program their dataplane asynchronously.
+func httpRouteNotProgrammed(h *gatewayapi.HTTPRoute) {
+	for i := range h.Status.Parents {
+		h.Status.Parents[i].Conditions = append(h.Status.Parents[i].Conditions, metav1.Condition{
+			Type:   "Programmed",
+			Status: metav1.ConditionFalse,
+		})
+	}
+}
+
 type HTTPRouteOption func(h *gatewayapi.HTTPRoute)
 
+func withOtherHost(i *v1alpha1.Ingress) {
+	i.Spec.Rules[0].Hosts = append(i.Spec.Rules[0].Hosts, "example.org")
+}
+
 func withGatewayAPIclass(i *v1alpha1.Ingress) {
 	withAnnotation(map[string]string{
 		networking.IngressClassAnnotationKey: gatewayAPIIngressClassName,
@@ -2489,6 +5604,20 @@ func setStatusPublicAddressIP(g *gatewayapi.Gateway) {
 	})
 }
 
+func setStatusPublicAddressIPv6(g *gatewayapi.Gateway) {
+	g.Status.Addresses = append(g.Status.Addresses, gatewayapi.GatewayStatusAddress{
+		Type:  ptr.To[gatewayapi.AddressType](gatewayapi.IPAddressType),
+		Value: "[2001:DB8::1]",
+	})
+}
+
+func setStatusPublicAddressNamed(g *gatewayapi.Gateway) {
+	g.Status.Addresses = append(g.Status.Addresses, gatewayapi.GatewayStatusAddress{
+		Type:  ptr.To[gatewayapi.AddressType](gatewayapi.NamedAddressType),
+		Value: "gateway-svc",
+	})
+}
+
 func setStatusPublicAddressHostname(g *gatewayapi.Gateway) {
 	g.Status.Addresses = append(g.Status.Addresses, gatewayapi.GatewayStatusAddress{
 		Type:  ptr.To[gatewayapi.AddressType](gatewayapi.HostnameAddressType),
@@ -2528,6 +5657,101 @@ func tlsListener(hostname, nsName, secretName string) GatewayOption {
 	}
 }
 
+func passthroughListener(hostname, nsName string) GatewayOption {
+	return func(g *gatewayapi.Gateway) {
+		g.Spec.Listeners = append(g.Spec.Listeners, gatewayapi.Listener{
+			Name:     gatewayapi.SectionName("kni-"),
+			Hostname: (*gatewayapi.Hostname)(&hostname),
+			Port:     443,
+			Protocol: "TLS",
+			TLS: &gatewayapi.GatewayTLSConfig{
+				Mode: (*gatewayapi.TLSModeType)(ptr.To("Passthrough")),
+			},
+			AllowedRoutes: &gatewayapi.AllowedRoutes{
+				Namespaces: &gatewayapi.RouteNamespaces{
+					From: (*gatewayapi.FromNamespaces)(ptr.To("Selector")),
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"kubernetes.io/metadata.name": nsName,
+						},
+					},
+				},
+				Kinds: []gatewayapi.RouteGroupKind{{
+					Group: ptr.To[gatewayapi.Group]("gateway.networking.k8s.io"),
+					Kind:  "TLSRoute",
+				}},
+			},
+		})
+	}
+}
+
+// withCollidingRule adds a rule whose HTTPRoute has the same name as the first one's
+func withCollidingRule(i *v1alpha1.Ingress) {
+	rule := *i.Spec.Rules[0].DeepCopy()
+	rule.Hosts = []string{"abc.com", "example.com"}
+	i.Spec.Rules = append(i.Spec.Rules, rule)
+}
+
+// withCollidingLocalRule adds a cluster-local rule for the host of the first rule
+func withCollidingLocalRule(i *v1alpha1.Ingress) {
+	rule := *i.Spec.Rules[0].DeepCopy()
+	rule.Visibility = v1alpha1.IngressVisibilityClusterLocal
+	i.Spec.Rules = append(i.Spec.Rules, rule)
+}
+
+// withListenerAllowedRoutes replaces the AllowedRoutes of the last listener
+func withListenerAllowedRoutes(ar *gatewayapi.AllowedRoutes) GatewayOption {
+	return func(g *gatewayapi.Gateway) {
+		g.Spec.Listeners[len(g.Spec.Listeners)-1].AllowedRoutes = ar
+	}
+}
+
+// addListenersPatch returns the JSON patch appending the listeners of the
+// Gateway from the given index on.
+func addListenersPatch(t *testing.T, g *gatewayapi.Gateway, from int) clientgotesting.PatchActionImpl {
+	var ops []jsonpatch.Operation
+	for i := from; i < len(g.Spec.Listeners); i++ {
+		ops = append(ops, jsonpatch.Operation{
+			Operation: "add",
+			Path:      "/spec/listeners/-",
+			Value:     &g.Spec.Listeners[i],
+		})
+	}
+	return gatewayPatch(t, g, ops)
+}
+
+// replaceListenerPatch returns the JSON patch replacing the listener of the
+// Gateway at the given index.
+func replaceListenerPatch(t *testing.T, g *gatewayapi.Gateway, i int) clientgotesting.PatchActionImpl {
+	path := fmt.Sprintf("/spec/listeners/%d", i)
+	return gatewayPatch(t, g, []jsonpatch.Operation{{
+		Operation: "test",
+		Path:      path + "/name",
+		Value:     g.Spec.Listeners[i].Name,
+	}, {
+		Operation: "replace",
+		Path:      path,
+		Value:     &g.Spec.Listeners[i],
+	}})
+}
+
+func gatewayPatch(t *testing.T, g *gatewayapi.Gateway, ops []jsonpatch.Operation) clientgotesting.PatchActionImpl {
+	t.Helper()
+	b, err := json.Marshal(ops)
+	if err != nil {
+		t.Fatal("failed to marshal the Gateway patch:", err)
+	}
+	return clientgotesting.PatchActionImpl{
+		ActionImpl: clientgotesting.ActionImpl{
+			Namespace: g.Namespace,
+			Resource:  gatewayapi.SchemeGroupVersion.WithResource("gateways"),
+		},
+		Name:      g.Name,
+		PatchType: types.JSONPatchType,
+		Patch:     b,
+	}
+}
+
 var withInitialConditions = func(i *v1alpha1.Ingress) {
 	i.Status.InitializeConditions()
 }
@@ -2566,6 +5790,9 @@ func rp(to *corev1.Secret) *gatewayapiv1beta1.ReferenceGrant {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      to.Name + "-" + testNamespace,
 			Namespace: to.Namespace,
+			Labels: map[string]string{
+				resources.IngressUIDLabelKey: "",
+			},
 			OwnerReferences: []metav1.OwnerReference{{
 				APIVersion:         "networking.internal.knative.dev/v1alpha1",
 				Kind:               "Ingress",
@@ -2601,9 +5828,16 @@ var (
 				Service:        &types.NamespacedName{Namespace: "istio-system", Name: "knative-local-gateway"},
 				NamespacedName: types.NamespacedName{Namespace: "istio-system", Name: "knative-local-gateway"},
 			}},
+			ManageReferenceGrants: true,
 		},
 	}
 
+	configWithGracePeriod = func() *config.Config {
+		c := defaultConfig.DeepCopy()
+		c.GatewayPlugin.ReadyGracePeriod = time.Minute
+		return c
+	}()
+
 	configNoService = &config.Config{
 		Network: &networkcfg.Config{},
 		GatewayPlugin: &config.GatewayPlugin{
@@ -2613,6 +5847,7 @@ var (
 			LocalGateways: []config.Gateway{{
 				NamespacedName: types.NamespacedName{Namespace: "istio-system", Name: "knative-local-gateway"},
 			}},
+			ManageReferenceGrants: true,
 		},
 	}
 )
@@ -20,31 +20,88 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"net"
+	"net/netip"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/utils/ptr"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/network"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
 	gatewaylisters "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1"
 
 	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
 	"knative.dev/net-gateway-api/pkg/status"
 )
 
-func NewProbeTargetLister(logger *zap.SugaredLogger, endpointsLister corev1listers.EndpointsLister, gatewayLister gatewaylisters.GatewayLister) status.ProbeTargetLister {
+var (
+	// Istio uses "http2" for the http port
+	// Contour uses "http-80" for the http port
+	httpPortNames  = sets.New("http", "http2", "http-80")
+	httpsPortNames = sets.New("https", "https-443")
+	// Ports only serving HTTP/2 over cleartext, e.g. of internal Gateways,
+	// are named or have the application protocol "h2c"
+	h2cPortNames = sets.New("h2c", "kubernetes.io/h2c")
+
+	// schemePortNames are the names of the ports probed with each scheme
+	schemePortNames = map[string]sets.Set[string]{
+		"http":  httpPortNames,
+		"https": httpsPortNames,
+		"h2c":   h2cPortNames.Union(httpPortNames),
+	}
+)
+
+// resolvedAddressesTTL is how long the IP addresses resolved from the
+// hostname addresses of the Gateways are reused for their probes.
+const resolvedAddressesTTL = 30 * time.Second
+
+func NewProbeTargetLister(logger *zap.SugaredLogger, endpointsLister corev1listers.EndpointsLister, serviceLister corev1listers.ServiceLister,
+	nodeLister corev1listers.NodeLister, gatewayLister gatewaylisters.GatewayLister) status.ProbeTargetLister {
 	return &gatewayPodTargetLister{
 		logger:          logger,
 		endpointsLister: endpointsLister,
+		serviceLister:   serviceLister,
+		nodeLister:      nodeLister,
 		gatewayLister:   gatewayLister,
+		resolver:        net.DefaultResolver,
 	}
 }
 
 type gatewayPodTargetLister struct {
 	logger          *zap.SugaredLogger
 	endpointsLister corev1listers.EndpointsLister
+	serviceLister   corev1listers.ServiceLister
+	nodeLister      corev1listers.NodeLister
 	gatewayLister   gatewaylisters.GatewayLister
+
+	// resolver resolves the hostname addresses of the Gateways configured
+	// with ProbeResolveHostname, whose results are cached in resolved.
+	resolver   hostResolver
+	resolvedMu sync.Mutex
+	resolved   map[string]resolvedAddresses
+}
+
+// hostResolver resolves a hostname to its IP addresses, like net.Resolver.
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// resolvedAddresses are the IP addresses of a hostname, reused until they
+// expire.
+type resolvedAddresses struct {
+	ips     sets.Set[string]
+	expires time.Time
 }
 
 func (l *gatewayPodTargetLister) BackendsToProbeTargets(ctx context.Context, backends status.Backends) ([]status.ProbeTarget, error) {
@@ -61,20 +118,31 @@ func (l *gatewayPodTargetLister) BackendsToProbeTargets(ctx context.Context, bac
 			gateway = pluginConfig.ExternalGateway()
 		}
 
-		if service := gateway.Service; service != nil {
+		if service := gateway.Service; service != nil && gateway.ProbeNodePort {
+			pt, err := l.nodePortProbeTarget(gateway, visibility == v1alpha1.IngressVisibilityExternalIP && probeHTTPS(backends), backends)
+			if err != nil {
+				return nil, err
+			}
+
+			for url := range urls {
+				url.Scheme = pt.scheme
+				pt.URLs = append(pt.URLs, &url)
+			}
+
+			if len(pt.URLs) > 0 {
+				foundTargets += len(pt.PodIPs)
+				targets = append(targets, pt.ProbeTarget)
+			}
+		} else if service != nil {
 			eps, err := l.endpointsLister.Endpoints(service.Namespace).Get(service.Name)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get endpoints: %w", err)
 			}
+			sampled := samplePodIPs(eps.Subsets, gateway.ProbeSampleSize, probeSampleSeed(backends))
 			for _, sub := range eps.Subsets {
-				scheme := "http"
-				// Istio uses "http2" for the http port
-				// Contour uses "http-80" for the http port
-				matchSchemes := sets.New("http", "http2", "http-80")
-				if visibility == v1alpha1.IngressVisibilityExternalIP && backends.HTTPOption == v1alpha1.HTTPOptionRedirected {
-					scheme = "https"
-					matchSchemes = sets.New("https", "https-443")
-				}
+				https := (visibility == v1alpha1.IngressVisibilityExternalIP && probeHTTPS(backends)) || onlyPorts(sub.Ports, httpsPortNames)
+				scheme := probeGatewayScheme(gateway, https, onlyPorts(sub.Ports, h2cPortNames))
+				matchSchemes := schemePortNames[scheme]
 				pt := status.ProbeTarget{PodIPs: sets.New[string]()}
 
 				portNumber := sub.Ports[0].Port
@@ -91,7 +159,13 @@ func (l *gatewayPodTargetLister) BackendsToProbeTargets(ctx context.Context, bac
 				pt.PodPort = strconv.Itoa(int(portNumber))
 
 				for _, address := range sub.Addresses {
-					pt.PodIPs.Insert(address.IP)
+					if sampled == nil || sampled.Has(address.IP) {
+						pt.PodIPs.Insert(address.IP)
+					}
+				}
+				if sampled != nil && pt.PodIPs.Len() == 0 {
+					// None of the pods of the subset were sampled
+					continue
 				}
 
 				for url := range urls {
@@ -114,26 +188,40 @@ func (l *gatewayPodTargetLister) BackendsToProbeTargets(ctx context.Context, bac
 
 			// In order to avoid searching through Gateway listeners and
 			// deciding which host gets which listener port, we only support
-			// listener ports of 80 and 443 when omitting a Gateway service.
-			// However, if users wish to do more advanced listener
-			// configurations, this current implementation won't support it.
+			// listener ports of 80 and 443 when omitting a Gateway service,
+			// unless the port is set in the configuration of the Gateway.
 			// See: https://github.com/knative-extensions/net-gateway-api/issues/695
 
-			scheme := "http"
+			scheme := probeGatewayScheme(gateway, visibility == v1alpha1.IngressVisibilityExternalIP && probeHTTPS(backends), false)
 			podPort := "80"
-			if visibility == v1alpha1.IngressVisibilityExternalIP && backends.HTTPOption == v1alpha1.HTTPOptionRedirected {
-				scheme = "https"
+			if scheme == "https" {
 				podPort = "443"
 			}
+			if gateway.ProbePort != 0 {
+				podPort = strconv.Itoa(int(gateway.ProbePort))
+			}
 
-			if len(gw.Status.Addresses) == 0 {
+			addr, ok := gatewayStatusAddress(gw, gateway)
+			if !ok {
 				return nil, fmt.Errorf("no addresses available in status of Gateway %s/%s", gw.Namespace, gw.Name)
 			}
 
+			host := statusAddressValue(addr)
+			addrType := ptr.Deref(addr.Type, gatewayapi.IPAddressType)
+			if addrType == gatewayapi.NamedAddressType {
+				// Like in the status of the Ingress, a named address is a
+				// Service in the namespace of the Gateway
+				host = network.GetServiceHostname(addr.Value, gw.Namespace)
+			}
 			pt := status.ProbeTarget{
-				PodIPs:  sets.New[string](gw.Status.Addresses[0].Value),
+				PodIPs:  sets.New[string](host),
 				PodPort: podPort,
 			}
+			if gateway.ProbeResolveHostname && (addrType == gatewayapi.HostnameAddressType || addrType == gatewayapi.NamedAddressType) {
+				if pt.PodIPs, err = l.resolveHostname(ctx, host); err != nil {
+					return nil, err
+				}
+			}
 
 			for url := range urls {
 				url.Scheme = scheme
@@ -151,3 +239,226 @@ func (l *gatewayPodTargetLister) BackendsToProbeTargets(ctx context.Context, bac
 	}
 	return targets, nil
 }
+
+// resolveHostname returns the IP addresses of the hostname, cached for
+// resolvedAddressesTTL so that the probes of every Ingress don't resolve it.
+func (l *gatewayPodTargetLister) resolveHostname(ctx context.Context, host string) (sets.Set[string], error) {
+	l.resolvedMu.Lock()
+	cached, ok := l.resolved[host]
+	l.resolvedMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.ips.Clone(), nil
+	}
+
+	addrs, err := l.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the address %q of the Gateway: %w", host, err)
+	}
+	ips := sets.New[string]()
+	for _, addr := range addrs {
+		if ip, err := netip.ParseAddr(addr); err == nil {
+			ips.Insert(ip.Unmap().String())
+		}
+	}
+	if ips.Len() == 0 {
+		return nil, fmt.Errorf("the address %q of the Gateway resolved to no IP address", host)
+	}
+
+	l.resolvedMu.Lock()
+	if l.resolved == nil {
+		l.resolved = make(map[string]resolvedAddresses)
+	}
+	l.resolved[host] = resolvedAddresses{ips: ips, expires: time.Now().Add(resolvedAddressesTTL)}
+	l.resolvedMu.Unlock()
+	return ips.Clone(), nil
+}
+
+// nodePortTarget is a probe target of the nodes with the scheme of the
+// probed node port.
+type nodePortTarget struct {
+	status.ProbeTarget
+	scheme string
+}
+
+// nodePortProbeTarget returns the probe target of the node port of the
+// Gateway Service on the nodes of the cluster, for Gateways that are only
+// reachable through their node port.
+func (l *gatewayPodTargetLister) nodePortProbeTarget(gateway config.Gateway, https bool, backends status.Backends) (nodePortTarget, error) {
+	svc, err := l.serviceLister.Services(gateway.Service.Namespace).Get(gateway.Service.Name)
+	if err != nil {
+		return nodePortTarget{}, fmt.Errorf("failed to get service: %w", err)
+	}
+
+	scheme := probeGatewayScheme(gateway,
+		https || onlyServicePorts(svc.Spec.Ports, httpsPortNames),
+		onlyServicePorts(svc.Spec.Ports, h2cPortNames))
+	matchSchemes := schemePortNames[scheme]
+
+	var nodePort int32
+	for _, port := range svc.Spec.Ports {
+		if matchSchemes.Has(port.Name) {
+			// Prefer to match the name exactly
+			nodePort = port.NodePort
+			break
+		}
+		if nodePort == 0 && port.AppProtocol != nil && matchSchemes.Has(*port.AppProtocol) {
+			nodePort = port.NodePort
+		}
+	}
+	if nodePort == 0 {
+		return nodePortTarget{}, fmt.Errorf("service %s/%s has no %s node port", svc.Namespace, svc.Name, scheme)
+	}
+
+	nodes, err := l.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nodePortTarget{}, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	ips := sets.New[string]()
+	for _, node := range nodes {
+		if ip := nodeInternalIP(node); ip != "" && nodeReady(node) {
+			ips.Insert(ip)
+		}
+	}
+	if sampled := sampleIPs(ips, gateway.ProbeSampleSize, probeSampleSeed(backends)); sampled != nil {
+		ips = sampled
+	}
+
+	return nodePortTarget{
+		ProbeTarget: status.ProbeTarget{
+			PodIPs:  ips,
+			PodPort: strconv.Itoa(int(nodePort)),
+		},
+		scheme: scheme,
+	}, nil
+}
+
+// nodeInternalIP returns the first internal IP address of the node, or an
+// empty string if it has none.
+func nodeInternalIP(node *corev1.Node) string {
+	for _, addr := range node.Status.Addresses {
+		if addr.Type == corev1.NodeInternalIP {
+			return addr.Address
+		}
+	}
+	return ""
+}
+
+// nodeReady returns true if the node is ready, so that probing it doesn't
+// fail because of the node itself.
+func nodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// probeGatewayScheme returns the scheme the gateway is probed with, as set by
+// its configuration or as detected otherwise. HTTPS takes precedence over h2c.
+func probeGatewayScheme(gateway config.Gateway, https, h2c bool) string {
+	switch {
+	case gateway.ProbeScheme != "":
+		return gateway.ProbeScheme
+	case https:
+		return "https"
+	case h2c:
+		return "h2c"
+	}
+	return "http"
+}
+
+// probeHTTPS returns true if external backends are only reachable over TLS.
+func probeHTTPS(backends status.Backends) bool {
+	return backends.HTTPOption == v1alpha1.HTTPOptionRedirected || backends.TLSPassthrough
+}
+
+// samplePodIPs returns a sample of n of the pod IPs of the subsets, or nil
+// when all of them are probed. The sample only depends on the seed, so that
+// the same pods are probed until the seed changes.
+func samplePodIPs(subsets []corev1.EndpointSubset, n int, seed uint64) sets.Set[string] {
+	ips := sets.New[string]()
+	for _, sub := range subsets {
+		for _, address := range sub.Addresses {
+			ips.Insert(address.IP)
+		}
+	}
+	return sampleIPs(ips, n, seed)
+}
+
+// sampleIPs returns a sample of n of the IPs, or nil when all of them are
+// probed.
+func sampleIPs(ips sets.Set[string], n int, seed uint64) sets.Set[string] {
+	if n == 0 || ips.Len() <= n {
+		return nil
+	}
+
+	sample := sets.List(ips)
+	r := rand.New(rand.NewPCG(seed, 0)) //nolint:gosec // sampling isn't security sensitive
+	r.Shuffle(len(sample), func(i, j int) {
+		sample[i], sample[j] = sample[j], sample[i]
+	})
+	return sets.New(sample[:n]...)
+}
+
+// probeSampleSeed returns the seed of the pods sampled to probe the version
+// of the backends.
+func probeSampleSeed(backends status.Backends) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(backends.Key.String() + "/" + backends.Version))
+	return h.Sum64()
+}
+
+// onlyPorts returns true when all the ports have one of the names, or
+// application protocols, e.g. the HTTPS ones, in which case the gateway can
+// only be probed with the matching scheme.
+func onlyPorts(ports []corev1.EndpointPort, names sets.Set[string]) bool {
+	for _, port := range ports {
+		if !names.Has(port.Name) && (port.AppProtocol == nil || !names.Has(*port.AppProtocol)) {
+			return false
+		}
+	}
+	return len(ports) > 0
+}
+
+// onlyServicePorts is onlyPorts for the ports of a Service.
+func onlyServicePorts(ports []corev1.ServicePort, names sets.Set[string]) bool {
+	for _, port := range ports {
+		if !names.Has(port.Name) && (port.AppProtocol == nil || !names.Has(*port.AppProtocol)) {
+			return false
+		}
+	}
+	return len(ports) > 0
+}
+
+// gatewayStatusAddress returns the address in the status of the Gateway that
+// the Ingresses report and probe, the first one of the most preferred of the
+// address types of its configuration, or else its first address.
+func gatewayStatusAddress(gw *gatewayapi.Gateway, gwc config.Gateway) (gatewayapi.GatewayStatusAddress, bool) {
+	if len(gw.Status.Addresses) == 0 {
+		return gatewayapi.GatewayStatusAddress{}, false
+	}
+	for _, addrType := range gwc.AddressTypes {
+		for _, addr := range gw.Status.Addresses {
+			// The address type defaults to IPAddress when unset
+			if ptr.Deref(addr.Type, gatewayapi.IPAddressType) == addrType {
+				return addr, true
+			}
+		}
+	}
+	return gw.Status.Addresses[0], true
+}
+
+// statusAddressValue returns the value of a Gateway status address suitable
+// for net.JoinHostPort. IP addresses, which may be reported in brackets when
+// they are IPv6, are returned in their canonical unbracketed form.
+func statusAddressValue(addr gatewayapi.GatewayStatusAddress) string {
+	if addr.Type != nil && *addr.Type != gatewayapi.IPAddressType {
+		return addr.Value
+	}
+	ip, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(addr.Value, "["), "]"))
+	if err != nil {
+		return addr.Value
+	}
+	return ip.String()
+}
@@ -29,13 +29,17 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
 
 	"knative.dev/net-gateway-api/pkg/status"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/network"
 
 	. "knative.dev/net-gateway-api/pkg/reconciler/testing"
 )
@@ -154,6 +158,37 @@ func TestBackendsToProbeTargets(t *testing.T) {
 				Path:   "/",
 			}},
 		}},
+	}, {
+		name: "endpoints with only https ports to probe (http enabled)",
+		objects: []runtime.Object{
+			privateEndpointsOneAddr,
+			publicHTTPSOnlyEndpoints,
+		},
+		backends: status.Backends{
+			HTTPOption: v1alpha1.HTTPOptionEnabled,
+			URLs: map[v1alpha1.IngressVisibility]status.URLSet{
+				v1alpha1.IngressVisibilityExternalIP: sets.New(
+					url.URL{Host: "example.com", Path: "/"},
+				),
+			},
+		},
+		want: []status.ProbeTarget{{
+			PodIPs:  sets.New("1.2.3.4"),
+			PodPort: "8443",
+			URLs: []*url.URL{{
+				Scheme: "https",
+				Host:   "example.com",
+				Path:   "/",
+			}},
+		}, {
+			PodIPs:  sets.New("2.3.4.5"),
+			PodPort: "9443",
+			URLs: []*url.URL{{
+				Scheme: "https",
+				Host:   "example.com",
+				Path:   "/",
+			}},
+		}},
 	}, {
 		name: "endpoint with multiple addresses and subsets to probe",
 		objects: []runtime.Object{
@@ -303,14 +338,439 @@ func TestBackendsToProbeTargets(t *testing.T) {
 	}
 }
 
-func TestListProbeTargetsNoService(t *testing.T) {
-	tests := []struct {
+func TestBackendsToProbeTargetsSampling(t *testing.T) {
+	// Two subsets of three pods each
+	eps := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      publicName,
+		},
+	}
+	for _, ips := range [][]string{{"1.1.1.1", "1.1.1.2", "1.1.1.3"}, {"2.2.2.1", "2.2.2.2", "2.2.2.3"}} {
+		sub := corev1.EndpointSubset{Ports: []corev1.EndpointPort{{Name: "http", Port: 8080}}}
+		for _, ip := range ips {
+			sub.Addresses = append(sub.Addresses, corev1.EndpointAddress{IP: ip})
+		}
+		eps.Subsets = append(eps.Subsets, sub)
+	}
+	allIPs := sets.New("1.1.1.1", "1.1.1.2", "1.1.1.3", "2.2.2.1", "2.2.2.2", "2.2.2.3")
+
+	tl := NewListers([]runtime.Object{eps})
+	l := &gatewayPodTargetLister{
+		endpointsLister: tl.GetEndpointsLister(),
+	}
+	cfg := defaultConfig.DeepCopy()
+	cfg.GatewayPlugin.ExternalGateways[0].ProbeSampleSize = 2
+	ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+
+	sample := func(version string) sets.Set[string] {
+		t.Helper()
+		targets, err := l.BackendsToProbeTargets(ctx, status.Backends{
+			Key:     types.NamespacedName{Namespace: "ns", Name: "name"},
+			Version: version,
+			URLs: map[v1alpha1.IngressVisibility]status.URLSet{
+				v1alpha1.IngressVisibilityExternalIP: sets.New(url.URL{Host: "example.com", Path: "/"}),
+			},
+		})
+		if err != nil {
+			t.Fatal("BackendsToProbeTargets() =", err)
+		}
+		ips := sets.New[string]()
+		for _, target := range targets {
+			if target.PodIPs.Len() == 0 {
+				t.Errorf("Target %+v without pods", target)
+			}
+			ips = ips.Union(target.PodIPs)
+		}
+		return ips
+	}
+
+	for _, version := range []string{"v1", "v2", "v3"} {
+		got := sample(version)
+		if got.Len() != 2 || !allIPs.IsSuperset(got) {
+			t.Errorf("Sampled %v, want 2 of %v", sets.List(got), sets.List(allIPs))
+		}
+		if again := sample(version); !again.Equal(got) {
+			t.Errorf("Sampled %v then %v for the same version", sets.List(got), sets.List(again))
+		}
+	}
+
+	// All the pods are probed when there are no more than the sample size
+	cfg.GatewayPlugin.ExternalGateways[0].ProbeSampleSize = 6
+	if got := sample("v1"); !got.Equal(allIPs) {
+		t.Errorf("Sampled %v, want all of %v", sets.List(got), sets.List(allIPs))
+	}
+}
+
+func TestBackendsToProbeTargetsNodePort(t *testing.T) {
+	node := func(name, ip string, ready corev1.ConditionStatus) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Addresses: []corev1.NodeAddress{
+					{Type: corev1.NodeHostName, Address: name},
+					{Type: corev1.NodeInternalIP, Address: ip},
+				},
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+	}
+	nodes := []runtime.Object{
+		node("node-1", "10.0.0.1", corev1.ConditionTrue),
+		node("node-2", "10.0.0.2", corev1.ConditionTrue),
+		node("node-3", "10.0.0.3", corev1.ConditionFalse),
+	}
+	nodePortService := func(ports ...corev1.ServicePort) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: publicName},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeNodePort,
+				Ports: ports,
+			},
+		}
+	}
+	urls := func(scheme string) []*url.URL {
+		return []*url.URL{{Scheme: scheme, Host: "example.com", Path: "/"}}
+	}
+	backends := func(option v1alpha1.HTTPOption) status.Backends {
+		return status.Backends{
+			Key:        types.NamespacedName{Namespace: "ns", Name: "name"},
+			Version:    "v1",
+			HTTPOption: option,
+			URLs: map[v1alpha1.IngressVisibility]status.URLSet{
+				v1alpha1.IngressVisibilityExternalIP: sets.New(url.URL{Host: "example.com", Path: "/"}),
+			},
+		}
+	}
+
+	cases := []struct {
 		name     string
-		ing      *v1alpha1.Ingress
 		objects  []runtime.Object
 		backends status.Backends
 		want     []status.ProbeTarget
 		wantErr  error
+	}{{
+		name: "http node port",
+		objects: append([]runtime.Object{nodePortService(
+			corev1.ServicePort{Name: "http2", Port: 80, NodePort: 30080},
+			corev1.ServicePort{Name: "https", Port: 443, NodePort: 30443},
+		)}, nodes...),
+		backends: backends(v1alpha1.HTTPOptionEnabled),
+		want: []status.ProbeTarget{{
+			PodIPs:  sets.New("10.0.0.1", "10.0.0.2"),
+			PodPort: "30080",
+			URLs:    urls("http"),
+		}},
+	}, {
+		name: "https node port when redirected",
+		objects: append([]runtime.Object{nodePortService(
+			corev1.ServicePort{Name: "http2", Port: 80, NodePort: 30080},
+			corev1.ServicePort{Name: "tls", AppProtocol: ptr.To("https"), Port: 443, NodePort: 30443},
+		)}, nodes...),
+		backends: backends(v1alpha1.HTTPOptionRedirected),
+		want: []status.ProbeTarget{{
+			PodIPs:  sets.New("10.0.0.1", "10.0.0.2"),
+			PodPort: "30443",
+			URLs:    urls("https"),
+		}},
+	}, {
+		name: "no node port",
+		objects: append([]runtime.Object{nodePortService(
+			corev1.ServicePort{Name: "http2", Port: 80},
+		)}, nodes...),
+		backends: backends(v1alpha1.HTTPOptionEnabled),
+		wantErr:  errors.New("service istio-system/istio-gateway has no http node port"),
+	}, {
+		name:     "no ready nodes",
+		objects:  []runtime.Object{nodePortService(corev1.ServicePort{Name: "http2", Port: 80, NodePort: 30080}), nodes[2]},
+		backends: backends(v1alpha1.HTTPOptionEnabled),
+		wantErr:  errors.New("no gateway pods available"),
+	}, {
+		name:     "no service",
+		objects:  nodes,
+		backends: backends(v1alpha1.HTTPOptionEnabled),
+		wantErr:  fmt.Errorf("failed to get service: %w", errors.New(`service "istio-gateway" not found`)),
+	}}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			tl := NewListers(test.objects)
+			l := &gatewayPodTargetLister{
+				endpointsLister: tl.GetEndpointsLister(),
+				serviceLister:   tl.GetServiceLister(),
+				nodeLister:      tl.GetNodeLister(),
+			}
+
+			cfg := defaultConfig.DeepCopy()
+			cfg.GatewayPlugin.ExternalGateways[0].ProbeNodePort = true
+			ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+
+			got, gotErr := l.BackendsToProbeTargets(ctx, test.backends)
+			if (gotErr != nil) != (test.wantErr != nil) {
+				t.Fatalf("BackendsToProbeTargets() = %v, wanted %v", gotErr, test.wantErr)
+			} else if gotErr != nil && gotErr.Error() != test.wantErr.Error() {
+				t.Fatalf("BackendsToProbeTargets() = %v, wanted %v", gotErr, test.wantErr)
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Error("BackendsToProbeTargets(-want, +got) =", diff)
+			}
+		})
+	}
+}
+
+func TestBackendsToProbeTargetsProbeScheme(t *testing.T) {
+	backends := func(option v1alpha1.HTTPOption) status.Backends {
+		return status.Backends{
+			HTTPOption: option,
+			URLs: map[v1alpha1.IngressVisibility]status.URLSet{
+				v1alpha1.IngressVisibilityExternalIP: sets.New(url.URL{Host: "example.com", Path: "/"}),
+			},
+		}
+	}
+	urls := func(scheme string) []*url.URL {
+		return []*url.URL{{Scheme: scheme, Host: "example.com", Path: "/"}}
+	}
+	customPortEndpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      publicName,
+		},
+		Subsets: []corev1.EndpointSubset{{
+			Ports: []corev1.EndpointPort{{
+				Name: "web-secure",
+				Port: 8443,
+			}},
+			Addresses: []corev1.EndpointAddress{{
+				IP: "1.2.3.4",
+			}},
+		}},
+	}
+
+	cases := []struct {
+		name     string
+		scheme   string
+		objects  []runtime.Object
+		backends status.Backends
+		want     []status.ProbeTarget
+	}{{
+		name:     "https with custom port names",
+		scheme:   "https",
+		objects:  []runtime.Object{customPortEndpoints},
+		backends: backends(v1alpha1.HTTPOptionEnabled),
+		want: []status.ProbeTarget{{
+			PodIPs:  sets.New("1.2.3.4"),
+			PodPort: "8443",
+			URLs:    urls("https"),
+		}},
+	}, {
+		name:     "https over http port names",
+		scheme:   "https",
+		objects:  []runtime.Object{publicEndpointsOneAddr},
+		backends: backends(v1alpha1.HTTPOptionEnabled),
+		want: []status.ProbeTarget{{
+			PodIPs:  sets.New("1.2.3.4"),
+			PodPort: "8080",
+			URLs:    urls("https"),
+		}},
+	}, {
+		name:     "http over https port names",
+		scheme:   "http",
+		objects:  []runtime.Object{publicHTTPSOnlyEndpoints},
+		backends: backends(v1alpha1.HTTPOptionEnabled),
+		want: []status.ProbeTarget{{
+			PodIPs:  sets.New("1.2.3.4"),
+			PodPort: "8443",
+			URLs:    urls("http"),
+		}, {
+			PodIPs:  sets.New("2.3.4.5"),
+			PodPort: "9443",
+			URLs:    urls("http"),
+		}},
+	}, {
+		name:     "http over redirected ingress",
+		scheme:   "http",
+		objects:  []runtime.Object{publicEndpointsOneAddr},
+		backends: backends(v1alpha1.HTTPOptionRedirected),
+		want: []status.ProbeTarget{{
+			PodIPs:  sets.New("1.2.3.4"),
+			PodPort: "8080",
+			URLs:    urls("http"),
+		}},
+	}, {
+		name:     "detected without override",
+		objects:  []runtime.Object{publicHTTPSOnlyEndpoints},
+		backends: backends(v1alpha1.HTTPOptionEnabled),
+		want: []status.ProbeTarget{{
+			PodIPs:  sets.New("1.2.3.4"),
+			PodPort: "8443",
+			URLs:    urls("https"),
+		}, {
+			PodIPs:  sets.New("2.3.4.5"),
+			PodPort: "9443",
+			URLs:    urls("https"),
+		}},
+	}}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			tl := NewListers(test.objects)
+			l := &gatewayPodTargetLister{
+				endpointsLister: tl.GetEndpointsLister(),
+			}
+
+			cfg := defaultConfig.DeepCopy()
+			cfg.GatewayPlugin.ExternalGateways[0].ProbeScheme = test.scheme
+			ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+
+			got, err := l.BackendsToProbeTargets(ctx, test.backends)
+			if err != nil {
+				t.Fatal("BackendsToProbeTargets() =", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Error("BackendsToProbeTargets(-want, +got) =", diff)
+			}
+		})
+	}
+}
+
+func TestBackendsToProbeTargetsH2C(t *testing.T) {
+	backends := status.Backends{
+		URLs: map[v1alpha1.IngressVisibility]status.URLSet{
+			v1alpha1.IngressVisibilityClusterLocal: sets.New(url.URL{Host: "foo.ns.svc.cluster.local", Path: "/"}),
+		},
+	}
+	urls := func(scheme string) []*url.URL {
+		return []*url.URL{{Scheme: scheme, Host: "foo.ns.svc.cluster.local", Path: "/"}}
+	}
+	privateEndpoints := func(port corev1.EndpointPort) *corev1.Endpoints {
+		return &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testNamespace,
+				Name:      privateName,
+			},
+			Subsets: []corev1.EndpointSubset{{
+				Ports: []corev1.EndpointPort{port},
+				Addresses: []corev1.EndpointAddress{{
+					IP: "1.2.3.4",
+				}},
+			}},
+		}
+	}
+
+	cases := []struct {
+		name      string
+		scheme    string
+		port      int32
+		noService bool
+		objects   []runtime.Object
+		want      []status.ProbeTarget
+	}{{
+		name: "detected from the application protocol",
+		objects: []runtime.Object{privateEndpoints(corev1.EndpointPort{
+			Name:        "http2",
+			Port:        8081,
+			AppProtocol: ptr.To("kubernetes.io/h2c"),
+		})},
+		want: []status.ProbeTarget{{
+			PodIPs:  sets.New("1.2.3.4"),
+			PodPort: "8081",
+			URLs:    urls("h2c"),
+		}},
+	}, {
+		name: "detected from the port name",
+		objects: []runtime.Object{privateEndpoints(corev1.EndpointPort{
+			Name: "h2c",
+			Port: 8081,
+		})},
+		want: []status.ProbeTarget{{
+			PodIPs:  sets.New("1.2.3.4"),
+			PodPort: "8081",
+			URLs:    urls("h2c"),
+		}},
+	}, {
+		name:   "h2c over http port names",
+		scheme: "h2c",
+		objects: []runtime.Object{privateEndpoints(corev1.EndpointPort{
+			Name: "http2",
+			Port: 8080,
+		})},
+		want: []status.ProbeTarget{{
+			PodIPs:  sets.New("1.2.3.4"),
+			PodPort: "8080",
+			URLs:    urls("h2c"),
+		}},
+	}, {
+		name:   "http over h2c port names",
+		scheme: "http",
+		objects: []runtime.Object{privateEndpoints(corev1.EndpointPort{
+			Name: "h2c",
+			Port: 8081,
+		})},
+		want: []status.ProbeTarget{{
+			PodIPs:  sets.New("1.2.3.4"),
+			PodPort: "8081",
+			URLs:    urls("http"),
+		}},
+	}, {
+		name:      "gateway address",
+		scheme:    "h2c",
+		noService: true,
+		objects:   []runtime.Object{gw(privateGw, defaultListener, setStatusPrivateAddress)},
+		want: []status.ProbeTarget{{
+			PodIPs:  sets.New(privateGatewayAddress),
+			PodPort: "80",
+			URLs:    urls("h2c"),
+		}},
+	}, {
+		name:      "gateway address with port",
+		scheme:    "h2c",
+		port:      8081,
+		noService: true,
+		objects:   []runtime.Object{gw(privateGw, defaultListener, setStatusPrivateAddress)},
+		want: []status.ProbeTarget{{
+			PodIPs:  sets.New(privateGatewayAddress),
+			PodPort: "8081",
+			URLs:    urls("h2c"),
+		}},
+	}}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			tl := NewListers(test.objects)
+			l := &gatewayPodTargetLister{
+				endpointsLister: tl.GetEndpointsLister(),
+				gatewayLister:   tl.GetGatewayLister(),
+			}
+
+			cfg := defaultConfig.DeepCopy()
+			if test.noService {
+				cfg = configNoService.DeepCopy()
+			}
+			cfg.GatewayPlugin.LocalGateways[0].ProbeScheme = test.scheme
+			cfg.GatewayPlugin.LocalGateways[0].ProbePort = test.port
+			ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+
+			got, err := l.BackendsToProbeTargets(ctx, backends)
+			if err != nil {
+				t.Fatal("BackendsToProbeTargets() =", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Error("BackendsToProbeTargets(-want, +got) =", diff)
+			}
+		})
+	}
+}
+
+func TestListProbeTargetsNoService(t *testing.T) {
+	tests := []struct {
+		name         string
+		ing          *v1alpha1.Ingress
+		objects      []runtime.Object
+		addressTypes []gatewayapi.AddressType
+		backends     status.Backends
+		want         []status.ProbeTarget
+		wantErr      error
 	}{{
 		name: "gateway has single http default listener",
 		backends: status.Backends{
@@ -386,6 +846,106 @@ func TestListProbeTargetsNoService(t *testing.T) {
 				}},
 			},
 		},
+	}, {
+		name: "gateway has IPv6 address",
+		objects: []runtime.Object{
+			gw(defaultListener, setStatusPublicAddressIPv6),
+		},
+		backends: status.Backends{
+			URLs: map[v1alpha1.IngressVisibility]status.URLSet{
+				v1alpha1.IngressVisibilityExternalIP: sets.New(
+					url.URL{Host: "example.com", Path: "/"},
+				),
+			},
+		},
+		ing: ing(withBasicSpec, withGatewayAPIClass),
+		want: []status.ProbeTarget{
+			{
+				// Unbracketed so that the prober can join it with the port
+				PodIPs:  sets.New("2001:db8::1"),
+				PodPort: "80",
+				URLs: []*url.URL{{
+					Scheme: "http",
+					Host:   "example.com",
+					Path:   "/",
+				}},
+			},
+		},
+	}, {
+		name: "gateway has named address",
+		objects: []runtime.Object{
+			gw(defaultListener, setStatusPublicAddressNamed),
+		},
+		backends: status.Backends{
+			URLs: map[v1alpha1.IngressVisibility]status.URLSet{
+				v1alpha1.IngressVisibilityExternalIP: sets.New(
+					url.URL{Host: "example.com", Path: "/"},
+				),
+			},
+		},
+		ing: ing(withBasicSpec, withGatewayAPIClass),
+		want: []status.ProbeTarget{
+			{
+				// The Service in the namespace of the Gateway
+				PodIPs:  sets.New(network.GetServiceHostname("gateway-svc", testNamespace)),
+				PodPort: "80",
+				URLs: []*url.URL{{
+					Scheme: "http",
+					Host:   "example.com",
+					Path:   "/",
+				}},
+			},
+		},
+	}, {
+		name: "gateway has preferred address type",
+		objects: []runtime.Object{
+			gw(defaultListener, setStatusPublicAddressHostname, setStatusPublicAddressIP),
+		},
+		addressTypes: []gatewayapi.AddressType{gatewayapi.IPAddressType, gatewayapi.HostnameAddressType},
+		backends: status.Backends{
+			URLs: map[v1alpha1.IngressVisibility]status.URLSet{
+				v1alpha1.IngressVisibilityExternalIP: sets.New(
+					url.URL{Host: "example.com", Path: "/"},
+				),
+			},
+		},
+		ing: ing(withBasicSpec, withGatewayAPIClass),
+		want: []status.ProbeTarget{
+			{
+				PodIPs:  sets.New(publicGatewayAddress),
+				PodPort: "80",
+				URLs: []*url.URL{{
+					Scheme: "http",
+					Host:   "example.com",
+					Path:   "/",
+				}},
+			},
+		},
+	}, {
+		name: "gateway has no address of the preferred types",
+		objects: []runtime.Object{
+			gw(defaultListener, setStatusPublicAddressIP, setStatusPublicAddressHostname),
+		},
+		addressTypes: []gatewayapi.AddressType{gatewayapi.NamedAddressType},
+		backends: status.Backends{
+			URLs: map[v1alpha1.IngressVisibility]status.URLSet{
+				v1alpha1.IngressVisibilityExternalIP: sets.New(
+					url.URL{Host: "example.com", Path: "/"},
+				),
+			},
+		},
+		ing: ing(withBasicSpec, withGatewayAPIClass),
+		want: []status.ProbeTarget{
+			{
+				PodIPs:  sets.New(publicGatewayAddress),
+				PodPort: "80",
+				URLs: []*url.URL{{
+					Scheme: "http",
+					Host:   "example.com",
+					Path:   "/",
+				}},
+			},
+		},
 	}, {
 		name: "gateway has no addresses in status",
 		objects: []runtime.Object{
@@ -414,6 +974,7 @@ func TestListProbeTargetsNoService(t *testing.T) {
 			}
 
 			cfg := configNoService.DeepCopy()
+			cfg.GatewayPlugin.ExternalGateways[0].AddressTypes = test.addressTypes
 			ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
 
 			got, gotErr := l.BackendsToProbeTargets(ctx, test.backends)
@@ -430,6 +991,114 @@ func TestListProbeTargetsNoService(t *testing.T) {
 	}
 }
 
+// stubResolver resolves the hostnames to their addresses, counting the
+// lookups.
+type stubResolver struct {
+	addrs   map[string][]string
+	lookups int
+}
+
+func (r *stubResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	r.lookups++
+	addrs, ok := r.addrs[host]
+	if !ok {
+		return nil, fmt.Errorf("no such host %q", host)
+	}
+	return addrs, nil
+}
+
+func TestListProbeTargetsResolveHostname(t *testing.T) {
+	backends := status.Backends{
+		URLs: map[v1alpha1.IngressVisibility]status.URLSet{
+			v1alpha1.IngressVisibilityExternalIP: sets.New(
+				url.URL{Host: "example.com", Path: "/"},
+			),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		resolve bool
+		addrs   map[string][]string
+		want    sets.Set[string]
+		wantErr string
+	}{{
+		name: "hostname probed when not resolved",
+		want: sets.New(publicGatewayHostname),
+	}, {
+		name:    "hostname resolved to its addresses",
+		resolve: true,
+		addrs: map[string][]string{
+			publicGatewayHostname: {"10.0.0.1", "10.0.0.2", "::ffff:10.0.0.2"},
+		},
+		want: sets.New("10.0.0.1", "10.0.0.2"),
+	}, {
+		name:    "hostname not resolved",
+		resolve: true,
+		wantErr: `failed to resolve the address "off.cluster.gateway" of the Gateway: no such host "off.cluster.gateway"`,
+	}, {
+		name:    "hostname resolved to no IP address",
+		resolve: true,
+		addrs: map[string][]string{
+			publicGatewayHostname: {"not-an-ip"},
+		},
+		wantErr: `the address "off.cluster.gateway" of the Gateway resolved to no IP address`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tl := NewListers([]runtime.Object{gw(defaultListener, setStatusPublicAddressHostname)})
+			resolver := &stubResolver{addrs: test.addrs}
+			l := &gatewayPodTargetLister{
+				gatewayLister: tl.GetGatewayLister(),
+				resolver:      resolver,
+			}
+
+			cfg := configNoService.DeepCopy()
+			cfg.GatewayPlugin.ExternalGateways[0].ProbeResolveHostname = test.resolve
+			ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+
+			// The resolved addresses are reused by the following probes
+			for range 2 {
+				got, err := l.BackendsToProbeTargets(ctx, backends)
+				if test.wantErr != "" {
+					if err == nil || err.Error() != test.wantErr {
+						t.Fatalf("ListProbeTargets() = %v, wanted %s", err, test.wantErr)
+					}
+					continue
+				} else if err != nil {
+					t.Fatal("ListProbeTargets() =", err)
+				}
+
+				want := []status.ProbeTarget{{
+					PodIPs:  test.want,
+					PodPort: "80",
+					URLs: []*url.URL{{
+						Scheme: "http",
+						Host:   "example.com",
+						Path:   "/",
+					}},
+				}}
+				if !cmp.Equal(want, got) {
+					t.Error("ListProbeTargets (-want, +got) =", cmp.Diff(want, got))
+				}
+			}
+
+			wantLookups := 0
+			if test.resolve {
+				wantLookups = 1
+				if test.wantErr != "" {
+					// The failures aren't cached
+					wantLookups = 2
+				}
+			}
+			if resolver.lookups != wantLookups {
+				t.Errorf("LookupHost() calls = %d, want %d", resolver.lookups, wantLookups)
+			}
+		})
+	}
+}
+
 var (
 	privateEndpointsOneAddr = &corev1.Endpoints{
 		ObjectMeta: metav1.ObjectMeta{
@@ -479,6 +1148,31 @@ var (
 		}},
 	}
 
+	publicHTTPSOnlyEndpoints = &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      publicName,
+		},
+		Subsets: []corev1.EndpointSubset{{
+			Ports: []corev1.EndpointPort{{
+				Name: "https",
+				Port: 8443,
+			}},
+			Addresses: []corev1.EndpointAddress{{
+				IP: "1.2.3.4",
+			}},
+		}, {
+			Ports: []corev1.EndpointPort{{
+				Name:        "tls",
+				Port:        9443,
+				AppProtocol: ptr.To("https"),
+			}},
+			Addresses: []corev1.EndpointAddress{{
+				IP: "2.3.4.5",
+			}},
+		}},
+	}
+
 	privateEndpointsMultiAddrMultiSubset = &corev1.Endpoints{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: testNamespace,
@@ -600,6 +1294,16 @@ func withBackendAppendHeaders(key, val string) IngressOption {
 	}
 }
 
+func withRewriteHost(host string) IngressOption {
+	return func(i *v1alpha1.Ingress) {
+		i.Spec.Rules[0].HTTP.Paths[0].RewriteHost = host
+	}
+}
+
+func withSecondHost(i *v1alpha1.Ingress) {
+	i.Spec.Rules[0].Hosts = append(i.Spec.Rules[0].Hosts, "example.org")
+}
+
 func withInternalSpec(i *v1alpha1.Ingress) {
 	i.Spec.Rules = append(i.Spec.Rules, v1alpha1.IngressRule{
 		Hosts:      []string{"foo.svc", "foo.svc.cluster.local"},
@@ -0,0 +1,66 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/resources"
+	"knative.dev/pkg/metrics"
+)
+
+var (
+	gatewayListenersM = stats.Int64(
+		"gateway_listeners",
+		"The number of listeners managed for Ingresses on a Gateway",
+		stats.UnitDimensionless)
+
+	gatewayKey = tag.MustNewKey("gateway")
+)
+
+func init() {
+	if err := metrics.RegisterResourceView(&view.View{
+		Description: gatewayListenersM.Description(),
+		Measure:     gatewayListenersM,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{gatewayKey},
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// recordGatewayListeners records the number of listeners the Gateway has for
+// Ingresses.
+func recordGatewayListeners(ctx context.Context, gw *gatewayapi.Gateway) {
+	count := 0
+	for _, l := range gw.Spec.Listeners {
+		if resources.IsIngressListener(l.Name) {
+			count++
+		}
+	}
+
+	ctx, err := tag.New(ctx, tag.Upsert(gatewayKey, gw.Namespace+"/"+gw.Name))
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, gatewayListenersM.M(int64(count)))
+}
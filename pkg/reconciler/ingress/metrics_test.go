@@ -0,0 +1,63 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+
+	"knative.dev/pkg/metrics"
+)
+
+func TestRecordGatewayListeners(t *testing.T) {
+	metrics.InitForTesting()
+
+	gw := &gatewayapi.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "istio-gateway"},
+		Spec: gatewayapi.GatewaySpec{
+			Listeners: []gatewayapi.Listener{
+				{Name: "http"},
+				{Name: "kni-one"},
+				{Name: "kni-two"},
+			},
+		},
+	}
+
+	for _, want := range []int{2, 1} {
+		recordGatewayListeners(context.Background(), gw)
+
+		rows, err := view.RetrieveData(gatewayListenersM.Name())
+		if err != nil {
+			t.Fatal("RetrieveData() =", err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("got %d rows, want 1", len(rows))
+		}
+		if got := rows[0].Tags[0].Value; got != "istio-system/istio-gateway" {
+			t.Errorf("gateway tag = %q, want %q", got, "istio-system/istio-gateway")
+		}
+		if got := rows[0].Data.(*view.LastValueData).Value; got != float64(want) {
+			t.Errorf("listeners = %v, want %d", got, want)
+		}
+
+		gw.Spec.Listeners = gw.Spec.Listeners[:2]
+	}
+}
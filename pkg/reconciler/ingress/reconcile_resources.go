@@ -18,19 +18,34 @@ package ingress
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"net/url"
+	"os"
 	"slices"
+	"strconv"
 	"strings"
 
+	"go.uber.org/zap"
+	"gomodules.xyz/jsonpatch/v2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/utils/ptr"
 	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	"sigs.k8s.io/gateway-api/pkg/features"
 
 	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
 	"knative.dev/net-gateway-api/pkg/reconciler/ingress/resources"
@@ -38,9 +53,171 @@ import (
 	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/networking/pkg/http/header"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/tracker"
 )
 
-const listenerPrefix = "kni-"
+const (
+	// ProbeStrategyAnnotationKey is the annotation on the Ingress selecting
+	// how the changes of its backends are probed. It is one of:
+	//   - "endpoint" (the default): the new backends are probed through
+	//     dedicated rules before any traffic is shifted to them.
+	//   - "gateway": only the hosts of the routes are probed, for debugging or
+	//     for Gateways that can't route the endpoint probes.
+	//   - "none": nothing is probed, the Ingress is ready as soon as its routes
+	//     are accepted, for services that accept eventual routing.
+	ProbeStrategyAnnotationKey = "gateway-api.networking.knative.dev/probe-strategy"
+
+	probeStrategyEndpoint = "endpoint"
+	probeStrategyGateway  = "gateway"
+	probeStrategyNone     = "none"
+
+	// MaxConcurrentProbesAnnotationKey is the annotation on the Ingress
+	// capping the number of its probes queued or in flight at once, so that
+	// an Ingress with many backends doesn't crowd out the probes of the
+	// others. The probes are not capped by default.
+	MaxConcurrentProbesAnnotationKey = "gateway-api.networking.knative.dev/max-concurrent-probes"
+)
+
+// probeStrategy returns how the Ingress requests its backends to be probed,
+// defaulting to probing the endpoints.
+func probeStrategy(ing *netv1alpha1.Ingress) (string, error) {
+	switch strategy := ing.GetAnnotations()[ProbeStrategyAnnotationKey]; strategy {
+	case "":
+		return probeStrategyEndpoint, nil
+	case probeStrategyEndpoint, probeStrategyGateway, probeStrategyNone:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("annotation %q must be one of %s, %s or %s, was: %q",
+			ProbeStrategyAnnotationKey, probeStrategyEndpoint, probeStrategyGateway, probeStrategyNone, strategy)
+	}
+}
+
+// maxConcurrentProbes returns the cap on the concurrent probes of the
+// Ingress, or zero when they are not capped.
+func maxConcurrentProbes(ing *netv1alpha1.Ingress) (int, error) {
+	value, ok := ing.GetAnnotations()[MaxConcurrentProbesAnnotationKey]
+	if !ok {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("annotation %q must be a positive integer, was: %q", MaxConcurrentProbesAnnotationKey, value)
+	}
+	return n, nil
+}
+
+// withGatewayClassFeatures returns the context with the configured Gateways
+// discovering their supported features from their GatewayClass also
+// supporting the features advertised in its status. The Ingress is reconciled
+// again when the GatewayClass changes. The configured features are kept when
+// the GatewayClass doesn't exist.
+func (c *Reconciler) withGatewayClassFeatures(ctx context.Context, ing *netv1alpha1.Ingress) (context.Context, error) {
+	cfg := config.FromContext(ctx)
+	gateways := append(slices.Clone(cfg.GatewayPlugin.ExternalGateways), cfg.GatewayPlugin.LocalGateways...)
+	if !slices.ContainsFunc(gateways, func(gw config.Gateway) bool { return gw.SupportedFeaturesFromClass }) {
+		return ctx, nil
+	}
+
+	gpc := cfg.GatewayPlugin.DeepCopy()
+	for _, gws := range [][]config.Gateway{gpc.ExternalGateways, gpc.LocalGateways} {
+		for i := range gws {
+			gw := &gws[i]
+			if !gw.SupportedFeaturesFromClass {
+				continue
+			}
+			if err := c.tracker.TrackReference(tracker.Reference{
+				APIVersion: gatewayapi.GroupVersion.String(),
+				Kind:       "GatewayClass",
+				Name:       gw.Class,
+			}, ing); err != nil {
+				return ctx, fmt.Errorf("failed to track GatewayClass: %w", err)
+			}
+			class, err := c.gatewayClassLister.Get(gw.Class)
+			if apierrs.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return ctx, err
+			}
+			if gw.SupportedFeatures == nil {
+				gw.SupportedFeatures = sets.New[features.FeatureName]()
+			}
+			for _, feature := range class.Status.SupportedFeatures {
+				gw.SupportedFeatures.Insert(features.FeatureName(feature.Name))
+			}
+		}
+	}
+	return config.ToContext(ctx, &config.Config{Network: cfg.Network, GatewayPlugin: gpc}), nil
+}
+
+// probeClientCertificate returns the function loading the client certificate
+// of the probes through the Gateway from its Secret, or nil when it has none.
+// The Secret is read at each handshake so that its rotations are picked up.
+func (c *Reconciler) probeClientCertificate(gw config.Gateway) func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	name := gw.ProbeClientCertificate
+	if name == nil {
+		return nil
+	}
+	return func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		secret, err := c.secretLister.Secrets(name.Namespace).Get(name.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the probe client certificate %s: %w", name, err)
+		}
+		cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, fmt.Errorf("invalid probe client certificate %s: %w", name, err)
+		}
+		return &cert, nil
+	}
+}
+
+// clusterCAFile is the CA of the cluster mounted with the token of the
+// service account of the controller.
+var clusterCAFile = "/var/run/secrets/kubernetes.io/serviceaccount/" + corev1.ServiceAccountRootCAKey
+
+// probeRootCAs returns the function loading the CAs verifying the
+// certificates of the Gateway in its probes, or nil when they aren't
+// verified. The CAs are read from the Secret of the Gateway at each handshake
+// so that its rotations are picked up, or are the roots of the system and
+// the cluster CA when it has none.
+func (c *Reconciler) probeRootCAs(gw config.Gateway) func() (*x509.CertPool, error) {
+	if !gw.ProbeVerifyTLS {
+		return nil
+	}
+	name := gw.ProbeCACertificate
+	if name == nil {
+		return clusterRootCAs
+	}
+	return func() (*x509.CertPool, error) {
+		secret, err := c.secretLister.Secrets(name.Namespace).Get(name.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the probe CA certificate %s: %w", name, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(secret.Data[corev1.ServiceAccountRootCAKey]) {
+			return nil, fmt.Errorf("invalid probe CA certificate %s: no certificate under %q", name, corev1.ServiceAccountRootCAKey)
+		}
+		return pool, nil
+	}
+}
+
+// clusterRootCAs returns the roots of the system and the CA of the cluster,
+// when it is mounted.
+func clusterRootCAs() (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the system roots: %w", err)
+	}
+	ca, err := os.ReadFile(clusterCAFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return pool, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read the cluster CA: %w", err)
+	}
+	pool.AppendCertsFromPEM(ca)
+	return pool, nil
+}
 
 func probeTargets(
 	hash string,
@@ -50,12 +227,16 @@ func probeTargets(
 ) status.Backends {
 	backends := status.Backends{
 		Version: hash,
-		Key:     resources.HTTPRouteKey(ing, rule),
+		Key:     types.NamespacedName{Name: r.Name, Namespace: r.Namespace},
 		CallbackKey: types.NamespacedName{
 			Name:      ing.Name,
 			Namespace: ing.Namespace,
 		},
 	}
+	if resources.RedirectsToHTTPS(ing, rule) {
+		// The route is only served by the HTTPS listener of the Ingress
+		backends.HTTPOption = netv1alpha1.HTTPOptionRedirected
+	}
 
 	visibility := rule.Visibility
 	if visibility == "" {
@@ -88,18 +269,20 @@ func probeTargets(
 	return backends
 }
 
-// reconcileHTTPRoute reconciles HTTPRoute.
+// reconcileHTTPRoute reconciles the HTTPRoute of a part of the paths of the
+// rule, as split by resources.SplitHTTPRouteRule.
 func (c *Reconciler) reconcileHTTPRoute(
 	ctx context.Context,
 	hash string,
 	ing *netv1alpha1.Ingress,
 	rule *netv1alpha1.IngressRule,
+	part int,
 ) (*gatewayapi.HTTPRoute, status.Backends, error) {
 	recorder := controller.GetEventRecorder(ctx)
 
-	httproute, err := c.httprouteLister.HTTPRoutes(ing.Namespace).Get(resources.LongestHost(rule.Hosts))
+	httproute, err := c.httprouteLister.HTTPRoutes(resources.HTTPRouteNamespace(ctx, ing)).Get(resources.HTTPRouteName(rule, part))
 	if apierrs.IsNotFound(err) {
-		desired, err := resources.MakeHTTPRoute(ctx, ing, rule)
+		desired, err := resources.MakeHTTPRoute(ctx, ing, rule, resources.WithHTTPRoutePart(part))
 		if err != nil {
 			return nil, status.Backends{}, err
 		}
@@ -115,7 +298,22 @@ func (c *Reconciler) reconcileHTTPRoute(
 		return nil, status.Backends{}, err
 	}
 
-	return c.reconcileHTTPRouteUpdate(ctx, hash, ing, rule, httproute.DeepCopy())
+	if err := checkHTTPRouteOwned(ctx, ing, httproute); err != nil {
+		return nil, status.Backends{}, err
+	}
+
+	return c.reconcileHTTPRouteUpdate(ctx, hash, ing, rule, part, httproute.DeepCopy())
+}
+
+// checkHTTPRouteOwned returns an error when the HTTPRoute isn't controlled by
+// the Ingress, e.g. when it was left over by another ingress implementation,
+// so that it isn't updated against the will of its owner.
+func checkHTTPRouteOwned(ctx context.Context, ing *netv1alpha1.Ingress, httproute *gatewayapi.HTTPRoute) error {
+	if resources.IsOwnedHTTPRoute(ctx, ing, httproute) {
+		return nil
+	}
+	controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeWarning, "NotOwned", "HTTPRoute %s not owned by this object", httproute.Name)
+	return fmt.Errorf("HTTPRoute %s not owned by %s", httproute.Name, ing.Name)
 }
 
 func (c *Reconciler) reconcileHTTPRouteUpdate(
@@ -123,6 +321,7 @@ func (c *Reconciler) reconcileHTTPRouteUpdate(
 	hash string,
 	ing *netv1alpha1.Ingress,
 	rule *netv1alpha1.IngressRule,
+	part int,
 	httproute *gatewayapi.HTTPRoute,
 ) (*gatewayapi.HTTPRoute, status.Backends, error) {
 	const (
@@ -134,8 +333,9 @@ func (c *Reconciler) reconcileHTTPRouteUpdate(
 		desired *gatewayapi.HTTPRoute
 		err     error
 
-		original = httproute.DeepCopy()
-		recorder = controller.GetEventRecorder(ctx)
+		original     = httproute.DeepCopy()
+		recorder     = controller.GetEventRecorder(ctx)
+		probeHeaders = config.FromContext(ctx).GatewayPlugin.EndpointProbeHeaders
 
 		probeKey = types.NamespacedName{
 			Name:      httproute.Name,
@@ -150,41 +350,96 @@ func (c *Reconciler) reconcileHTTPRouteUpdate(
 	probeHash := strings.TrimPrefix(probe.Version, endpointPrefix)
 	probeHash = strings.TrimPrefix(probeHash, transitionPrefix)
 
-	newBackends, oldBackends := computeBackends(httproute, rule)
+	// The decisions below are logged at debug level to follow the probing
+	// of the changes of a route.
+	logger := logging.FromContext(ctx).With(
+		zap.String("httproute", probeKey.String()),
+		zap.String("hash", hash),
+		zap.String("probeVersion", probe.Version),
+		zap.Bool("probeReady", probe.Ready),
+	)
+
+	// Without a probe in progress, a route built from the same inputs is
+	// already up to date (e.g. when resyncing after a restart), unless its
+	// spec was changed since.
+	if probe.Version == "" {
+		upToDate, err := httpRouteUpToDate(ctx, ing, rule, part, httproute)
+		if err != nil {
+			return nil, status.Backends{}, err
+		}
+		if upToDate {
+			logger.Debugw("HTTPRoute is up to date", zap.String("branch", "noop"),
+				zap.String("inputsHash", httproute.Annotations[resources.InputsHashAnnotationKey]))
+			return httproute, probeTargets(hash, ing, rule, httproute), nil
+		}
+	}
+
+	gw := config.FromContext(ctx).GatewayPlugin.ExternalGateway()
+	if rule.Visibility == netv1alpha1.IngressVisibilityClusterLocal {
+		gw = config.FromContext(ctx).GatewayPlugin.LocalGateway()
+	}
+	newBackends, oldBackends := computeBackends(gw, httproute, rule)
+
+	strategy, err := probeStrategy(ing)
+	if err != nil {
+		return nil, status.Backends{}, err
+	}
+
+	logger = logger.With(
+		zap.Int("newBackends", len(newBackends)),
+		zap.Int("oldBackends", len(oldBackends)),
+	)
 
 	if wasTransitionProbe && probeHash == hash && probe.Ready {
-		desired, err = resources.MakeHTTPRoute(ctx, ing, rule)
+		logger.Debugw("Transition probes are ready, finishing the transition", zap.String("branch", "wasTransitionProbe"))
+		desired, err = resources.MakeHTTPRoute(ctx, ing, rule, resources.WithHTTPRoutePart(part))
 	} else if wasEndpointProbe && probeHash == hash && probe.Ready {
+		logger.Debugw("Endpoint probes are ready, shifting the traffic", zap.String("branch", "wasEndpointProbe"))
 		hash = transitionPrefix + hash
 
-		desired, err = resources.MakeHTTPRoute(ctx, ing, rule)
+		desired, err = resources.MakeHTTPRoute(ctx, ing, rule, resources.WithHTTPRoutePart(part))
+		if err != nil {
+			return nil, status.Backends{}, err
+		}
 		resources.UpdateProbeHash(desired, hash)
+		// The route is not the output of MakeHTTPRoute until the transition ends
+		resources.RemoveHashes(desired)
 
 		resources.RemoveEndpointProbes(httproute)
 		for _, backend := range newBackends {
-			resources.AddEndpointProbe(desired, hash, backend)
+			resources.AddEndpointProbe(desired, hash, backend, gw, probeHeaders)
 		}
 		for _, backend := range oldBackends {
-			resources.AddOldBackend(desired, hash, backend)
+			resources.AddOldBackend(desired, hash, backend, probeHeaders)
 		}
+	} else if probe.Version == hash && probe.Ready && resources.HasEndpointProbes(httproute) {
+		// The route is ready with its final version, endpoint probes left
+		// over from an interrupted transition aren't needed anymore
+		logger.Debugw("Removing leftover endpoint probes", zap.String("branch", "leftoverEndpointProbes"))
+		desired, err = resources.MakeHTTPRoute(ctx, ing, rule, resources.WithHTTPRoutePart(part))
 	} else if probeHash == hash {
 		// Hash is the same but probes are not ready - continue
+		logger.Debugw("Waiting for the probes", zap.String("branch", "probing"))
 		return httproute, probeTargets(probe.Version, ing, rule, httproute), nil
-	} else if len(newBackends) > 0 {
+	} else if len(newBackends) > 0 && strategy == probeStrategyEndpoint {
 		// Ingress changed with new backends
+		logger.Debugw("Probing the new backends", zap.String("branch", "newBackends"))
 		hash = endpointPrefix + hash
 		desired = httproute.DeepCopy()
+		resources.RemoveHashes(desired)
 		resources.UpdateProbeHash(desired, hash)
 		resources.RemoveEndpointProbes(desired)
 		for _, backend := range newBackends {
-			resources.AddEndpointProbe(desired, hash, backend)
+			resources.AddEndpointProbe(desired, hash, backend, gw, probeHeaders)
 		}
 		for _, backend := range oldBackends {
-			resources.AddOldBackend(desired, hash, backend)
+			resources.AddOldBackend(desired, hash, backend, probeHeaders)
 		}
 	} else {
-		// Ingress changed with the same backends
-		desired, err = resources.MakeHTTPRoute(ctx, ing, rule)
+		// Ingress changed with the same backends, or the new backends
+		// aren't probed through dedicated rules
+		logger.Debugw("Ingress changed, updating the HTTPRoute", zap.String("branch", "hashChange"))
+		desired, err = resources.MakeHTTPRoute(ctx, ing, rule, resources.WithHTTPRoutePart(part))
 	}
 
 	if err != nil {
@@ -192,7 +447,7 @@ func (c *Reconciler) reconcileHTTPRouteUpdate(
 	}
 
 	if !equality.Semantic.DeepEqual(original.Spec, desired.Spec) ||
-		!equality.Semantic.DeepEqual(original.Annotations, desired.Annotations) ||
+		!equalIgnoringHashes(original.Annotations, desired.Annotations) ||
 		!equality.Semantic.DeepEqual(original.Labels, desired.Labels) {
 		// Don't modify the informers copy.
 		original.Spec = desired.Spec
@@ -201,6 +456,12 @@ func (c *Reconciler) reconcileHTTPRouteUpdate(
 
 		updated, err := c.gwapiclient.GatewayV1().HTTPRoutes(original.Namespace).
 			Update(ctx, original, metav1.UpdateOptions{})
+		if apierrs.IsConflict(err) {
+			// The route changed since it was read, apply the desired route
+			// to its latest version instead of retrying the whole
+			// reconcile.
+			updated, err = c.updateLatestHTTPRoute(ctx, ing, desired)
+		}
 		if err != nil {
 			recorder.Eventf(ing, corev1.EventTypeWarning, "UpdateFailed", "Failed to update HTTPRoute: %v", err)
 			return nil, status.Backends{}, fmt.Errorf("failed to update HTTPRoute: %w", err)
@@ -211,73 +472,336 @@ func (c *Reconciler) reconcileHTTPRouteUpdate(
 	return httproute, probeTargets(hash, ing, rule, httproute), nil
 }
 
-func (c *Reconciler) reconcileTLS(
-	ctx context.Context, tls *netv1alpha1.IngressTLS, ing *netv1alpha1.Ingress,
-) (
-	[]*gatewayapi.Listener, error,
-) {
-	recorder := controller.GetEventRecorder(ctx)
-	externalGw := config.FromContext(ctx).GatewayPlugin.ExternalGateway()
+// updateLatestHTTPRoute updates the latest version of the HTTPRoute read from
+// the API server with the desired one, as long as the Ingress still owns it.
+func (c *Reconciler) updateLatestHTTPRoute(
+	ctx context.Context,
+	ing *netv1alpha1.Ingress,
+	desired *gatewayapi.HTTPRoute,
+) (*gatewayapi.HTTPRoute, error) {
+	var updated *gatewayapi.HTTPRoute
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// The lister may not have observed the conflicting change yet.
+		latest, err := c.gwapiclient.GatewayV1().HTTPRoutes(desired.Namespace).Get(ctx, desired.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if err := checkHTTPRouteOwned(ctx, ing, latest); err != nil {
+			return err
+		}
 
-	gateway := metav1.PartialObjectMetadata{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Gateway",
-			APIVersion: gatewayapi.GroupVersion.String(),
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      externalGw.Name,
-			Namespace: externalGw.Namespace,
-		},
+		latest.Spec = desired.Spec
+		latest.Annotations = desired.Annotations
+		latest.Labels = desired.Labels
+
+		updated, err = c.gwapiclient.GatewayV1().HTTPRoutes(latest.Namespace).Update(ctx, latest, metav1.UpdateOptions{})
+		return err
+	})
+	return updated, err
+}
+
+// httpRouteUpToDate returns whether the HTTPRoute was built by MakeHTTPRoute
+// from the current inputs of the part of the rule, and left unchanged since.
+func httpRouteUpToDate(
+	ctx context.Context,
+	ing *netv1alpha1.Ingress,
+	rule *netv1alpha1.IngressRule,
+	part int,
+	httproute *gatewayapi.HTTPRoute,
+) (bool, error) {
+	inputsHash, err := resources.HTTPRouteInputsHash(ctx, ing, rule, part)
+	if err != nil {
+		return false, err
 	}
-	secret := metav1.PartialObjectMetadata{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Secret",
-			APIVersion: corev1.SchemeGroupVersion.Version,
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      tls.SecretName,
-			Namespace: tls.SecretNamespace,
-		},
+	if httproute.Annotations[resources.InputsHashAnnotationKey] != inputsHash {
+		return false, nil
+	}
+	specHash, err := resources.HTTPRouteSpecHash(httproute.Spec)
+	if err != nil {
+		return false, err
 	}
+	return httproute.Annotations[resources.SpecHashAnnotationKey] == specHash, nil
+}
 
-	desired := resources.MakeReferenceGrant(ctx, ing, secret, gateway)
+// equalIgnoringHashes compares HTTPRoute annotations without the hashes of
+// their inputs and spec, so that routes aren't updated only to record them.
+func equalIgnoringHashes(a, b map[string]string) bool {
+	filter := func(key string) bool {
+		return key == resources.InputsHashAnnotationKey || key == resources.SpecHashAnnotationKey
+	}
+	return equality.Semantic.DeepEqual(kmeta.FilterMap(a, filter), kmeta.FilterMap(b, filter))
+}
 
-	rp, err := c.referenceGrantLister.ReferenceGrants(desired.Namespace).Get(desired.Name)
+// reconcileRedirectHTTPRoute reconciles the HTTPRoute redirecting the plain
+// HTTP requests of an external rule to HTTPS.
+func (c *Reconciler) reconcileRedirectHTTPRoute(
+	ctx context.Context,
+	ing *netv1alpha1.Ingress,
+	rule *netv1alpha1.IngressRule,
+) (*gatewayapi.HTTPRoute, error) {
+	recorder := controller.GetEventRecorder(ctx)
+	desired := resources.MakeRedirectHTTPRoute(ctx, ing, rule)
 
+	httproute, err := c.httprouteLister.HTTPRoutes(desired.Namespace).Get(desired.Name)
 	if apierrs.IsNotFound(err) {
-		rp, err = c.gwapiclient.GatewayV1beta1().ReferenceGrants(desired.Namespace).Create(ctx, desired, metav1.CreateOptions{})
+		httproute, err = c.gwapiclient.GatewayV1().HTTPRoutes(desired.Namespace).Create(ctx, desired, metav1.CreateOptions{})
 		if err != nil {
-			recorder.Eventf(ing, corev1.EventTypeWarning, "CreationFailed", "Failed to create ReferenceGrant: %v", err)
-			return nil, fmt.Errorf("failed to create ReferenceGrant: %w", err)
+			recorder.Eventf(ing, corev1.EventTypeWarning, "CreationFailed", "Failed to create HTTPRoute: %v", err)
+			return nil, fmt.Errorf("failed to create HTTPRoute: %w", err)
 		}
+		recorder.Eventf(ing, corev1.EventTypeNormal, "Created", "Created HTTPRoute %q", httproute.GetName())
+		return httproute, nil
 	} else if err != nil {
 		return nil, err
 	}
 
-	if !metav1.IsControlledBy(rp, ing) {
-		recorder.Eventf(ing, corev1.EventTypeWarning, "NotOwned", "ReferenceGrant %s not owned by this object", desired.Name)
-		return nil, fmt.Errorf("ReferenceGrant %s not owned by %s", rp.Name, ing.Name)
+	if err := checkHTTPRouteOwned(ctx, ing, httproute); err != nil {
+		return nil, err
 	}
 
-	if !equality.Semantic.DeepEqual(rp.Spec, desired.Spec) {
-		update := rp.DeepCopy()
+	if !equality.Semantic.DeepEqual(httproute.Spec, desired.Spec) ||
+		!equality.Semantic.DeepEqual(httproute.Annotations, desired.Annotations) ||
+		!equality.Semantic.DeepEqual(httproute.Labels, desired.Labels) {
+		// Don't modify the informers copy.
+		update := httproute.DeepCopy()
 		update.Spec = desired.Spec
+		update.Annotations = desired.Annotations
+		update.Labels = desired.Labels
 
-		_, err := c.gwapiclient.GatewayV1beta1().ReferenceGrants(update.Namespace).Update(ctx, update, metav1.UpdateOptions{})
+		httproute, err = c.gwapiclient.GatewayV1().HTTPRoutes(update.Namespace).Update(ctx, update, metav1.UpdateOptions{})
 		if err != nil {
-			recorder.Eventf(ing, corev1.EventTypeWarning, "UpdateFailed", "Failed to update ReferenceGrant: %v", err)
-			return nil, fmt.Errorf("failed to update ReferenceGrant: %w", err)
+			recorder.Eventf(ing, corev1.EventTypeWarning, "UpdateFailed", "Failed to update HTTPRoute: %v", err)
+			return nil, fmt.Errorf("failed to update HTTPRoute: %w", err)
 		}
 	}
+	return httproute, nil
+}
+
+func tlsProbeTargets(
+	hash string,
+	ing *netv1alpha1.Ingress,
+	rule *netv1alpha1.IngressRule,
+	r *gatewayapiv1alpha2.TLSRoute,
+) status.Backends {
+	backends := status.Backends{
+		Version: hash,
+		Key:     resources.HTTPRouteKey(ing, rule),
+		CallbackKey: types.NamespacedName{
+			Name:      ing.Name,
+			Namespace: ing.Namespace,
+		},
+		TLSPassthrough: true,
+	}
+
+	for _, hostname := range r.Spec.Hostnames {
+		backends.AddURL(netv1alpha1.IngressVisibilityExternalIP, url.URL{Host: string(hostname)})
+	}
+	return backends
+}
+
+// reconcileTLSRoute reconciles the TLSRoute of a rule exposed with TLS passthrough.
+func (c *Reconciler) reconcileTLSRoute(
+	ctx context.Context,
+	hash string,
+	ing *netv1alpha1.Ingress,
+	rule *netv1alpha1.IngressRule,
+) (*gatewayapiv1alpha2.TLSRoute, status.Backends, error) {
+	recorder := controller.GetEventRecorder(ctx)
+
+	desired, err := resources.MakeTLSRoute(ctx, ing, rule)
+	if err != nil {
+		return nil, status.Backends{}, err
+	}
+
+	tlsroute, err := c.tlsrouteLister.TLSRoutes(ing.Namespace).Get(desired.Name)
+	if apierrs.IsNotFound(err) {
+		tlsroute, err = c.gwapiclient.GatewayV1alpha2().TLSRoutes(desired.Namespace).Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
+			recorder.Eventf(ing, corev1.EventTypeWarning, "CreationFailed", "Failed to create TLSRoute: %v", err)
+			return nil, status.Backends{}, fmt.Errorf("failed to create TLSRoute: %w", err)
+		}
+
+		recorder.Eventf(ing, corev1.EventTypeNormal, "Created", "Created TLSRoute %q", tlsroute.GetName())
+		return tlsroute, tlsProbeTargets(hash, ing, rule, tlsroute), nil
+	} else if err != nil {
+		return nil, status.Backends{}, err
+	}
+
+	if !equality.Semantic.DeepEqual(tlsroute.Spec, desired.Spec) ||
+		!equality.Semantic.DeepEqual(tlsroute.Annotations, desired.Annotations) ||
+		!equality.Semantic.DeepEqual(tlsroute.Labels, desired.Labels) {
+		// Don't modify the informers copy.
+		update := tlsroute.DeepCopy()
+		update.Spec = desired.Spec
+		update.Annotations = desired.Annotations
+		update.Labels = desired.Labels
+
+		tlsroute, err = c.gwapiclient.GatewayV1alpha2().TLSRoutes(update.Namespace).
+			Update(ctx, update, metav1.UpdateOptions{})
+		if err != nil {
+			recorder.Eventf(ing, corev1.EventTypeWarning, "UpdateFailed", "Failed to update TLSRoute: %v", err)
+			return nil, status.Backends{}, fmt.Errorf("failed to update TLSRoute: %w", err)
+		}
+	}
+
+	return tlsroute, tlsProbeTargets(hash, ing, rule, tlsroute), nil
+}
+
+// reconcileResiliencyPolicy reconciles the resiliency policy referenced by the
+// HTTPRoute of the rule. It is a no-op unless a policy template is configured.
+func (c *Reconciler) reconcileResiliencyPolicy(
+	ctx context.Context,
+	ing *netv1alpha1.Ingress,
+	rule *netv1alpha1.IngressRule,
+) error {
+	template := config.FromContext(ctx).GatewayPlugin.ResiliencyPolicyTemplate
+	if template == nil {
+		return nil
+	}
+
+	recorder := controller.GetEventRecorder(ctx)
+	gvk := template.GroupVersionKind()
+	mapping, err := c.restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the resource of %s: %w", gvk, err)
+	}
+	client := c.dynamicClient.Resource(mapping.Resource).Namespace(ing.Namespace)
+
+	desired, err := resources.MakeResiliencyPolicy(ctx, ing, rule)
+	if err != nil {
+		return err
+	}
+
+	existing, err := client.Get(ctx, resources.LongestHost(rule.Hosts), metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		if desired == nil {
+			return nil
+		}
+		if _, err := client.Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			recorder.Eventf(ing, corev1.EventTypeWarning, "CreationFailed", "Failed to create %s: %v", gvk.Kind, err)
+			return fmt.Errorf("failed to create %s: %w", gvk.Kind, err)
+		}
+		recorder.Eventf(ing, corev1.EventTypeNormal, "Created", "Created %s %q", gvk.Kind, desired.GetName())
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if !metav1.IsControlledBy(existing, ing) {
+		return fmt.Errorf("%s %q is not owned by Ingress %q", gvk.Kind, existing.GetName(), ing.Name)
+	}
+
+	if desired == nil {
+		// The Ingress no longer requests a policy
+		if err := client.Delete(ctx, existing.GetName(), metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %s: %w", gvk.Kind, err)
+		}
+		return nil
+	}
+
+	if !equality.Semantic.DeepEqual(existing.Object["spec"], desired.Object["spec"]) ||
+		!equality.Semantic.DeepEqual(existing.GetLabels(), desired.GetLabels()) {
+		// Don't modify the fetched copy.
+		update := existing.DeepCopy()
+		update.Object["spec"] = desired.Object["spec"]
+		update.SetLabels(desired.GetLabels())
+
+		if _, err := client.Update(ctx, update, metav1.UpdateOptions{}); err != nil {
+			recorder.Eventf(ing, corev1.EventTypeWarning, "UpdateFailed", "Failed to update %s: %v", gvk.Kind, err)
+			return fmt.Errorf("failed to update %s: %w", gvk.Kind, err)
+		}
+	}
+	return nil
+}
+
+// makePassthroughListeners returns the TLS passthrough listeners for the
+// external hosts of the Ingress.
+func makePassthroughListeners(gw config.Gateway, ing *netv1alpha1.Ingress) []*gatewayapi.Listener {
+	mode := gatewayapi.TLSModePassthrough
+
+	var listeners []*gatewayapi.Listener
+	for _, rule := range ing.Spec.Rules {
+		if rule.Visibility == netv1alpha1.IngressVisibilityClusterLocal {
+			continue
+		}
+		for _, h := range rule.Hosts {
+			listeners = append(listeners, &gatewayapi.Listener{
+				Name:     resources.ListenerName(ing),
+				Hostname: (*gatewayapi.Hostname)(ptr.To(h)),
+				Port:     443,
+				Protocol: gatewayapi.TLSProtocolType,
+				TLS: &gatewayapi.GatewayTLSConfig{
+					Mode: &mode,
+				},
+				AllowedRoutes: makeAllowedRoutes(gw, ing, []gatewayapi.RouteGroupKind{{
+					Group: (*gatewayapi.Group)(&gatewayapi.GroupVersion.Group),
+					Kind:  "TLSRoute",
+				}}),
+			})
+		}
+	}
+	return listeners
+}
+
+// errTLSSecretInvalid is returned when the secret of an Ingress TLS can't be
+// used by the Gateway.
+var errTLSSecretInvalid = errors.New("invalid TLS secret")
+
+// validateTLSSecret returns an error wrapping errTLSSecretInvalid unless the
+// secret of the TLS exists and is a TLS secret.
+func (c *Reconciler) validateTLSSecret(tls *netv1alpha1.IngressTLS, ing *netv1alpha1.Ingress) error {
+	if err := c.tracker.TrackReference(tracker.Reference{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Namespace:  tls.SecretNamespace,
+		Name:       tls.SecretName,
+	}, ing); err != nil {
+		return fmt.Errorf("failed to track TLS secret: %w", err)
+	}
+
+	secret, err := c.secretLister.Secrets(tls.SecretNamespace).Get(tls.SecretName)
+	if apierrs.IsNotFound(err) {
+		return fmt.Errorf("%w: secret %s/%s does not exist", errTLSSecretInvalid, tls.SecretNamespace, tls.SecretName)
+	} else if err != nil {
+		return err
+	}
+
+	if secret.Type != corev1.SecretTypeTLS {
+		return fmt.Errorf("%w: secret %s/%s is of type %q, not %q",
+			errTLSSecretInvalid, tls.SecretNamespace, tls.SecretName, secret.Type, corev1.SecretTypeTLS)
+	}
+	return nil
+}
+
+func (c *Reconciler) reconcileTLS(
+	ctx context.Context, tls *netv1alpha1.IngressTLS, ing *netv1alpha1.Ingress,
+) (
+	[]*gatewayapi.Listener, error,
+) {
+	recorder := controller.GetEventRecorder(ctx)
+	externalGw := config.FromContext(ctx).GatewayPlugin.ExternalGateway()
+
+	if err := c.validateTLSSecret(tls, ing); err != nil {
+		return nil, err
+	}
+
+	desired := makeSecretReferenceGrant(ctx, tls, ing)
+	if config.FromContext(ctx).GatewayPlugin.ManageReferenceGrants {
+		if err := c.reconcileReferenceGrant(ctx, ing, desired); err != nil {
+			return nil, err
+		}
+	} else if err := c.verifyReferenceGrant(desired); err != nil {
+		recorder.Eventf(ing, corev1.EventTypeWarning, "ReferenceGrantMissing", "%v", err)
+		return nil, err
+	}
 
 	// Gateway API loves typed pointers and constants, so we need to copy the constants
 	// to something we can reference
 	mode := gatewayapi.TLSModeTerminate
-	selector := gatewayapi.NamespacesFromSelector
 	listeners := make([]*gatewayapi.Listener, 0, len(tls.Hosts))
 	for _, h := range tls.Hosts {
 		listener := gatewayapi.Listener{
-			Name:     gatewayapi.SectionName(listenerPrefix + ing.GetUID()),
+			Name:     resources.ListenerName(ing),
 			Hostname: (*gatewayapi.Hostname)(&h),
 			Port:     443,
 			Protocol: gatewayapi.HTTPSProtocolType,
@@ -289,23 +813,140 @@ func (c *Reconciler) reconcileTLS(
 					Name:      gatewayapi.ObjectName(tls.SecretName),
 					Namespace: (*gatewayapi.Namespace)(&tls.SecretNamespace),
 				}},
+				Options: makeTLSOptions(externalGw),
 			},
-			AllowedRoutes: &gatewayapi.AllowedRoutes{
-				Namespaces: &gatewayapi.RouteNamespaces{
-					From: &selector,
-					Selector: &metav1.LabelSelector{
-						MatchLabels: map[string]string{
-							corev1.LabelMetadataName: ing.Namespace,
-						},
-					},
-				},
-				Kinds: []gatewayapi.RouteGroupKind{},
-			},
+			AllowedRoutes: makeAllowedRoutes(externalGw, ing, []gatewayapi.RouteGroupKind{}),
 		}
 		listeners = append(listeners, &listener)
 	}
 
-	return listeners, err
+	return listeners, nil
+}
+
+// reconcileReferenceGrant creates or updates the desired ReferenceGrant.
+func (c *Reconciler) reconcileReferenceGrant(
+	ctx context.Context, ing *netv1alpha1.Ingress, desired *gatewayapiv1beta1.ReferenceGrant,
+) error {
+	recorder := controller.GetEventRecorder(ctx)
+
+	rp, err := c.referenceGrantLister.ReferenceGrants(desired.Namespace).Get(desired.Name)
+
+	if apierrs.IsNotFound(err) {
+		rp, err = c.gwapiclient.GatewayV1beta1().ReferenceGrants(desired.Namespace).Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
+			recorder.Eventf(ing, corev1.EventTypeWarning, "CreationFailed", "Failed to create ReferenceGrant: %v", err)
+			return fmt.Errorf("failed to create ReferenceGrant: %w", err)
+		}
+	} else if err != nil {
+		return err
+	}
+
+	if !metav1.IsControlledBy(rp, ing) {
+		recorder.Eventf(ing, corev1.EventTypeWarning, "NotOwned", "ReferenceGrant %s not owned by this object", desired.Name)
+		return fmt.Errorf("ReferenceGrant %s not owned by %s", rp.Name, ing.Name)
+	}
+
+	if !equality.Semantic.DeepEqual(rp.Spec, desired.Spec) {
+		update := rp.DeepCopy()
+		update.Spec = desired.Spec
+
+		_, err := c.gwapiclient.GatewayV1beta1().ReferenceGrants(update.Namespace).Update(ctx, update, metav1.UpdateOptions{})
+		if err != nil {
+			recorder.Eventf(ing, corev1.EventTypeWarning, "UpdateFailed", "Failed to update ReferenceGrant: %v", err)
+			return fmt.Errorf("failed to update ReferenceGrant: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// verifyReferenceGrant returns an error unless a ReferenceGrant allows what
+// the desired one would.
+func (c *Reconciler) verifyReferenceGrant(desired *gatewayapiv1beta1.ReferenceGrant) error {
+	grants, err := c.referenceGrantLister.ReferenceGrants(desired.Namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	from, to := desired.Spec.From[0], desired.Spec.To[0]
+	for _, grant := range grants {
+		if slices.Contains(grant.Spec.From, from) && slices.ContainsFunc(grant.Spec.To, func(t gatewayapiv1beta1.ReferenceGrantTo) bool {
+			return t.Group == to.Group && t.Kind == to.Kind && (t.Name == nil || *t.Name == *to.Name)
+		}) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no ReferenceGrant in namespace %s allows the Gateways of namespace %s to use Secret %s",
+		desired.Namespace, from.Namespace, *to.Name)
+}
+
+// makeSecretReferenceGrant returns the ReferenceGrant allowing the external
+// Gateway to use the secret of the Ingress TLS.
+func makeSecretReferenceGrant(ctx context.Context, tls *netv1alpha1.IngressTLS, ing *netv1alpha1.Ingress) *gatewayapiv1beta1.ReferenceGrant {
+	externalGw := config.FromContext(ctx).GatewayPlugin.ExternalGateway()
+
+	gateway := metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: gatewayapi.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      externalGw.Name,
+			Namespace: externalGw.Namespace,
+		},
+	}
+	secret := metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: corev1.SchemeGroupVersion.Version,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tls.SecretName,
+			Namespace: tls.SecretNamespace,
+		},
+	}
+
+	return resources.MakeReferenceGrant(ctx, ing, secret, gateway)
+}
+
+// makeTLSOptions returns the options of the TLS configuration of the listeners
+// managed on the Gateway, or nil when it has none.
+func makeTLSOptions(gw config.Gateway) map[gatewayapi.AnnotationKey]gatewayapi.AnnotationValue {
+	if len(gw.TLSOptions) == 0 {
+		return nil
+	}
+	options := make(map[gatewayapi.AnnotationKey]gatewayapi.AnnotationValue, len(gw.TLSOptions))
+	for k, v := range gw.TLSOptions {
+		options[gatewayapi.AnnotationKey(k)] = gatewayapi.AnnotationValue(v)
+	}
+	return options
+}
+
+// makeAllowedRoutes returns the routes allowed to attach to a listener managed
+// for the Ingress, following the policy configured for the Gateway.
+func makeAllowedRoutes(gw config.Gateway, ing *netv1alpha1.Ingress, kinds []gatewayapi.RouteGroupKind) *gatewayapi.AllowedRoutes {
+	from := gw.AllowedRoutesFrom
+	if from == "" {
+		from = gatewayapi.NamespacesFromSelector
+	}
+
+	namespaces := &gatewayapi.RouteNamespaces{From: &from}
+	if from == gatewayapi.NamespacesFromSelector {
+		if gw.AllowedRoutesSelector != nil {
+			namespaces.Selector = gw.AllowedRoutesSelector.DeepCopy()
+		} else {
+			namespaces.Selector = &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					corev1.LabelMetadataName: ing.Namespace,
+				},
+			}
+		}
+	}
+
+	return &gatewayapi.AllowedRoutes{
+		Namespaces: namespaces,
+		Kinds:      kinds,
+	}
 }
 
 func (c *Reconciler) reconcileGatewayListeners(
@@ -330,7 +971,10 @@ func (c *Reconciler) reconcileGatewayListeners(
 	// TODO: how do we track and remove listeners if they are removed from the KIngress spec?
 	// Tracked in https://github.com/knative-sandbox/net-gateway-api/issues/319
 
-	updated := false
+	// Only the listeners that changed are patched, guarded by a test of their
+	// name so that a concurrent change to the Gateway's listeners makes the
+	// patch fail rather than overwrite an unrelated listener.
+	var patch []jsonpatch.Operation
 	for i, l := range gw.Spec.Listeners {
 		desired, ok := lmap[string(l.Name)]
 		if !ok {
@@ -343,24 +987,145 @@ func (c *Reconciler) reconcileGatewayListeners(
 			continue
 		}
 		update.Spec.Listeners[i] = *desired
-		updated = true
+		path := fmt.Sprintf("/spec/listeners/%d", i)
+		patch = append(patch, jsonpatch.Operation{
+			Operation: "test",
+			Path:      path + "/name",
+			Value:     l.Name,
+		}, jsonpatch.Operation{
+			Operation: "replace",
+			Path:      path,
+			Value:     desired,
+		})
 	}
 
-	for _, l := range lmap {
+	for _, l := range listeners {
+		if _, ok := lmap[string(l.Name)]; !ok {
+			continue
+		}
 		// Add all remaining listeners
 		update.Spec.Listeners = append(update.Spec.Listeners, *l)
-		updated = true
+		patch = append(patch, jsonpatch.Operation{
+			Operation: "add",
+			Path:      "/spec/listeners/-",
+			Value:     l,
+		})
 	}
 
-	if updated {
-		_, err := c.gwapiclient.GatewayV1().Gateways(update.Namespace).Update(
-			ctx, update, metav1.UpdateOptions{})
+	if len(patch) > 0 {
+		b, err := json.Marshal(patch)
+		if err != nil {
+			return fmt.Errorf("failed to marshal the listeners patch of Gateway %s: %w", gwName, err)
+		}
+		_, err = c.gwapiclient.GatewayV1().Gateways(update.Namespace).Patch(
+			ctx, update.Name, types.JSONPatchType, b, metav1.PatchOptions{})
 		if err != nil {
 			recorder.Eventf(ing, corev1.EventTypeWarning, "GatewayUpdateFailed", "Failed to update Gateway %s: %v", gwName, err)
 			return fmt.Errorf("failed to update Gateway %s/%s: %w", update.Namespace, update.Name, err)
 		}
 	}
 
+	recordGatewayListeners(ctx, update)
+	return nil
+}
+
+// unresolvedListener returns a message for the first of the listeners whose
+// references the Gateway reports as not resolved, e.g. a missing certificate
+// secret, or an empty string if there is none. The Gateway is tracked so that
+// the Ingress is reconciled when its status changes.
+func (c *Reconciler) unresolvedListener(
+	listeners []*gatewayapi.Listener,
+	ing *netv1alpha1.Ingress, gwName types.NamespacedName,
+) (string, error) {
+	if err := c.tracker.TrackReference(tracker.Reference{
+		APIVersion: gatewayapi.GroupVersion.String(),
+		Kind:       "Gateway",
+		Namespace:  gwName.Namespace,
+		Name:       gwName.Name,
+	}, ing); err != nil {
+		return "", fmt.Errorf("failed to track Gateway: %w", err)
+	}
+
+	gw, err := c.gatewayLister.Gateways(gwName.Namespace).Get(gwName.Name)
+	if err != nil {
+		return "", err
+	}
+
+	for _, l := range listeners {
+		i := slices.IndexFunc(gw.Status.Listeners, func(ls gatewayapi.ListenerStatus) bool {
+			return ls.Name == l.Name
+		})
+		if i < 0 {
+			continue
+		}
+		cond := meta.FindStatusCondition(gw.Status.Listeners[i].Conditions, string(gatewayapi.ListenerConditionResolvedRefs))
+		// Conditions of an older generation may be about a previous version
+		// of the listener
+		if cond != nil && cond.Status == metav1.ConditionFalse && cond.ObservedGeneration == gw.Generation {
+			return fmt.Sprintf("Listener %s of Gateway %s has unresolved references: %s", l.Name, gwName, cond.Message), nil
+		}
+	}
+	return "", nil
+}
+
+// clearHTTPRoutes deletes the HTTPRoutes controlled by the Ingress except
+// the ones in keep.
+func (c *Reconciler) clearHTTPRoutes(ctx context.Context, ing *netv1alpha1.Ingress, keep sets.Set[types.NamespacedName]) error {
+	recorder := controller.GetEventRecorder(ctx)
+
+	routes, err := resources.OwnedHTTPRoutes(ctx, ing, c.httprouteLister)
+	if err != nil {
+		return err
+	}
+
+	for _, route := range routes {
+		if keep.Has(types.NamespacedName{Namespace: route.Namespace, Name: route.Name}) {
+			continue
+		}
+
+		err := c.gwapiclient.GatewayV1().HTTPRoutes(route.Namespace).Delete(ctx, route.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrs.IsNotFound(err) {
+			recorder.Eventf(ing, corev1.EventTypeWarning, "DeleteFailed", "Failed to delete HTTPRoute %s: %v", route.Name, err)
+			return fmt.Errorf("failed to delete HTTPRoute %s/%s: %w", route.Namespace, route.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// clearReferenceGrants deletes the ReferenceGrants created for the TLS
+// secrets of the Ingress.
+func (c *Reconciler) clearReferenceGrants(ctx context.Context, ing *netv1alpha1.Ingress) error {
+	recorder := controller.GetEventRecorder(ctx)
+
+	// The grants are found by owner rather than by name, as their names
+	// depend on the namespace of the external Gateway, which may no longer be
+	// configured.
+	namespaces := sets.New[string]()
+	for _, tls := range ing.GetIngressTLSForVisibility(netv1alpha1.IngressVisibilityExternalIP) {
+		namespaces.Insert(tls.SecretNamespace)
+	}
+
+	for _, namespace := range sets.List(namespaces) {
+		grants, err := c.referenceGrantLister.ReferenceGrants(namespace).List(labels.Everything())
+		if err != nil {
+			return err
+		}
+
+		for _, rp := range grants {
+			if !metav1.IsControlledBy(rp, ing) {
+				// Not ours to delete
+				continue
+			}
+
+			err := c.gwapiclient.GatewayV1beta1().ReferenceGrants(rp.Namespace).Delete(ctx, rp.Name, metav1.DeleteOptions{})
+			if err != nil && !apierrs.IsNotFound(err) {
+				recorder.Eventf(ing, corev1.EventTypeWarning, "DeleteFailed", "Failed to delete ReferenceGrant %s: %v", rp.Name, err)
+				return fmt.Errorf("failed to delete ReferenceGrant %s/%s: %w", rp.Namespace, rp.Name, err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -375,7 +1140,7 @@ func (c *Reconciler) clearGatewayListeners(ctx context.Context, ing *netv1alpha1
 		return err
 	}
 
-	listenerName := listenerPrefix + string(ing.GetUID())
+	listenerName := resources.ListenerName(ing)
 	update := gw.DeepCopy()
 
 	numListeners := len(update.Spec.Listeners)
@@ -383,7 +1148,7 @@ func (c *Reconciler) clearGatewayListeners(ctx context.Context, ing *netv1alpha1
 		// March backwards down the list removing items by swapping in the last item and trimming the list
 		// A generic list.remove(func) would be nice here.
 		l := update.Spec.Listeners[i]
-		if string(l.Name) == listenerName {
+		if l.Name == listenerName {
 			update.Spec.Listeners[i] = update.Spec.Listeners[len(update.Spec.Listeners)-1]
 			update.Spec.Listeners = update.Spec.Listeners[:len(update.Spec.Listeners)-1]
 		}
@@ -401,12 +1166,14 @@ func (c *Reconciler) clearGatewayListeners(ctx context.Context, ing *netv1alpha1
 }
 
 func computeBackends(
+	gw config.Gateway,
 	route *gatewayapi.HTTPRoute,
 	rule *netv1alpha1.IngressRule,
 ) ([]netv1alpha1.IngressBackendSplit, []gatewayapi.HTTPBackendRef) {
 	newBackends := []netv1alpha1.IngressBackendSplit{}
 	oldBackends := []gatewayapi.HTTPBackendRef{}
 	oldNames := sets.Set[types.NamespacedName]{}
+	newNames := sets.Set[types.NamespacedName]{}
 
 oldbackends:
 	for _, rule := range route.Spec.Rules {
@@ -428,6 +1195,10 @@ oldbackends:
 			} else {
 				nn.Namespace = route.Namespace
 			}
+			// Backends of several rules are only probed once
+			if oldNames.Has(nn) {
+				continue
+			}
 			oldNames.Insert(nn)
 			oldBackends = append(oldBackends, backend)
 		}
@@ -442,15 +1213,19 @@ newbackends:
 			}
 		}
 
-		for _, split := range path.Splits {
+		// Splits that aren't routed don't need to be probed
+		for _, split := range resources.RoutedSplits(gw, path) {
 			service := types.NamespacedName{
 				Name:      split.ServiceName,
 				Namespace: split.ServiceNamespace,
 			}
 
-			if oldNames.Has(service) {
+			// Backends already routed are unchanged, and the ones of several
+			// paths are only probed once
+			if oldNames.Has(service) || newNames.Has(service) {
 				continue
 			}
+			newNames.Insert(service)
 
 			newBackends = append(newBackends, split)
 		}
@@ -0,0 +1,433 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/networking/pkg/http/header"
+	"knative.dev/networking/pkg/ingress"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+	gwapifake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/resources"
+	"knative.dev/net-gateway-api/pkg/status"
+)
+
+func TestComputeBackends(t *testing.T) {
+	backendRef := func(name string) gatewayapi.HTTPBackendRef {
+		return gatewayapi.HTTPBackendRef{BackendRef: gatewayapi.BackendRef{
+			BackendObjectReference: gatewayapi.BackendObjectReference{
+				Name: gatewayapi.ObjectName(name),
+				Port: ptr.To[gatewayapi.PortNumber](80),
+			},
+		}}
+	}
+	split := func(name string, percent int) v1alpha1.IngressBackendSplit {
+		return v1alpha1.IngressBackendSplit{
+			IngressBackend: v1alpha1.IngressBackend{
+				ServiceName:      name,
+				ServiceNamespace: "ns",
+				ServicePort:      intstr.FromInt(80),
+			},
+			Percent: percent,
+		}
+	}
+
+	route := &gatewayapi.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "ns"},
+		Spec: gatewayapi.HTTPRouteSpec{
+			Rules: []gatewayapi.HTTPRouteRule{{
+				BackendRefs: []gatewayapi.HTTPBackendRef{backendRef("goo"), backendRef("doo")},
+			}, {
+				// The same backend routed by another path
+				BackendRefs: []gatewayapi.HTTPBackendRef{backendRef("goo")},
+			}, {
+				// Probes aren't routed backends
+				Matches: []gatewayapi.HTTPRouteMatch{{
+					Headers: []gatewayapi.HTTPHeaderMatch{{
+						Name:  header.HashKey,
+						Value: header.HashValueOverride,
+					}},
+				}},
+				BackendRefs: []gatewayapi.HTTPBackendRef{backendRef("probed")},
+			}},
+		},
+	}
+	rule := &v1alpha1.IngressRule{
+		HTTP: &v1alpha1.HTTPIngressRuleValue{
+			Paths: []v1alpha1.HTTPIngressPath{{
+				Path:   "/a",
+				Splits: []v1alpha1.IngressBackendSplit{split("goo", 50), split("new", 50)},
+			}, {
+				Path:   "/b",
+				Splits: []v1alpha1.IngressBackendSplit{split("new", 20), split("doo", 30), split("probed", 50)},
+			}},
+		},
+	}
+
+	newBackends, oldBackends := computeBackends(config.Gateway{}, route, rule)
+
+	if diff := cmp.Diff([]v1alpha1.IngressBackendSplit{split("new", 50), split("probed", 50)}, newBackends); diff != "" {
+		t.Error("Unexpected new backends (-want +got):", diff)
+	}
+	if diff := cmp.Diff([]gatewayapi.HTTPBackendRef{backendRef("goo"), backendRef("doo")}, oldBackends); diff != "" {
+		t.Error("Unexpected old backends (-want +got):", diff)
+	}
+}
+
+func TestReconcileHTTPRouteUpdateLogging(t *testing.T) {
+	const hash = "hash"
+
+	withNewBackend := func(i *v1alpha1.Ingress) {
+		i.Spec.Rules[0].HTTP.Paths[0].Splits[0].ServiceName = "new"
+	}
+
+	tests := []struct {
+		name  string
+		ing   *v1alpha1.Ingress
+		probe status.ProbeState
+		want  string
+	}{{
+		name: "route built from the same inputs",
+		ing:  ing(withBasicSpec, withGatewayAPIclass),
+		want: "noop",
+	}, {
+		name:  "probes not ready",
+		ing:   ing(withBasicSpec, withGatewayAPIclass),
+		probe: status.ProbeState{Version: hash},
+		want:  "probing",
+	}, {
+		name:  "same backends",
+		ing:   ing(withBasicSpec, withGatewayAPIclass),
+		probe: status.ProbeState{Version: "previous", Ready: true},
+		want:  "hashChange",
+	}, {
+		name:  "new backends",
+		ing:   ing(withBasicSpec, withGatewayAPIclass, withNewBackend),
+		probe: status.ProbeState{Version: "previous", Ready: true},
+		want:  "newBackends",
+	}, {
+		name:  "endpoint probes ready",
+		ing:   ing(withBasicSpec, withGatewayAPIclass, withNewBackend),
+		probe: status.ProbeState{Version: "ep-" + hash, Ready: true},
+		want:  "wasEndpointProbe",
+	}, {
+		name:  "transition probes ready",
+		ing:   ing(withBasicSpec, withGatewayAPIclass, withNewBackend),
+		probe: status.ProbeState{Version: "tr-" + hash, Ready: true},
+		want:  "wasTransitionProbe",
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			ctx := logging.WithLogger(context.Background(), zap.New(core).Sugar())
+			ctx = (&testConfigStore{config: defaultConfig}).ToContext(ctx)
+
+			ingress.InsertProbe(tc.ing)
+			// The route of the Ingress before its backends changed
+			route := httpRoute(t, ing(withBasicSpec, withGatewayAPIclass)).(*gatewayapi.HTTPRoute)
+			r := &Reconciler{
+				gwapiclient: gwapifake.NewSimpleClientset(route),
+				statusManager: &fakeStatusManager{
+					FakeIsProbeActive: func(types.NamespacedName) (status.ProbeState, bool) {
+						return tc.probe, tc.probe.Version != ""
+					},
+				},
+			}
+
+			if _, _, err := r.reconcileHTTPRouteUpdate(ctx, hash, tc.ing, &tc.ing.Spec.Rules[0], 0, route.DeepCopy()); err != nil {
+				t.Fatal("reconcileHTTPRouteUpdate() =", err)
+			}
+
+			branches := logs.FilterFieldKey("branch").All()
+			if len(branches) != 1 {
+				t.Fatalf("Logged %d branches, want: 1", len(branches))
+			}
+			entry := branches[0]
+			if entry.Level != zapcore.DebugLevel {
+				t.Errorf("Level = %v, want: %v", entry.Level, zapcore.DebugLevel)
+			}
+			if got := entry.ContextMap()["branch"]; got != tc.want {
+				t.Errorf("branch = %v, want: %s", got, tc.want)
+			}
+			if got := entry.ContextMap()["httproute"]; got != "ns/example.com" {
+				t.Errorf("httproute = %v, want: ns/example.com", got)
+			}
+		})
+	}
+}
+
+func TestReconcileResiliencyPolicyResource(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "policy.example.com", Version: "v1", Kind: "RetryBudget"}
+	// The plural of the kind can't be guessed
+	gvr := gvk.GroupVersion().WithResource("retrybudgetpolicies")
+
+	cfg := defaultConfig.DeepCopy()
+	cfg.GatewayPlugin.ResiliencyPolicyTemplate = &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": gvk.GroupVersion().String(),
+		"kind":       gvk.Kind,
+	}}
+	ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+	ctx = controller.WithEventRecorder(ctx, record.NewFakeRecorder(10))
+
+	ing := ing(withBasicSpec, withGatewayAPIclass, withAnnotation(map[string]string{
+		resources.ResiliencyPolicyAnnotationKey: "",
+	}))
+
+	for _, tc := range []struct {
+		name    string
+		mapped  bool
+		wantErr bool
+	}{{
+		name:   "mapped kind",
+		mapped: true,
+	}, {
+		name:    "unknown kind",
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			mapper := meta.NewDefaultRESTMapper(nil)
+			if tc.mapped {
+				mapper.AddSpecific(gvk, gvr, gvr.GroupVersion().WithResource("retrybudgetpolicy"), meta.RESTScopeNamespace)
+			}
+			client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{gvr: gvk.Kind + "List"})
+			r := &Reconciler{dynamicClient: client, restMapper: mapper}
+
+			err := r.reconcileResiliencyPolicy(ctx, ing, &ing.Spec.Rules[0])
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("reconcileResiliencyPolicy() = %v, want error: %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			for _, action := range client.Actions() {
+				if got := action.GetResource(); got != gvr {
+					t.Errorf("%s resource = %v, want: %v", action.GetVerb(), got, gvr)
+				}
+			}
+			if len(client.Actions()) == 0 {
+				t.Error("No action on the policies")
+			}
+		})
+	}
+}
+
+func TestProbeClientCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("Failed to generate key:", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "prober"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal("Failed to create certificate:", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal("Failed to marshal key:", err)
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving", Name: "probe-client"},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		},
+	})
+	indexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving", Name: "not-a-certificate"},
+		Data: map[string][]byte{
+			corev1.TLSCertKey: []byte("garbage"),
+		},
+	})
+	r := &Reconciler{secretLister: corev1listers.NewSecretLister(indexer)}
+
+	if got := r.probeClientCertificate(config.Gateway{}); got != nil {
+		t.Error("probeClientCertificate() of a Gateway without certificate is not nil")
+	}
+
+	for _, tc := range []struct {
+		name    string
+		secret  string
+		wantErr string
+	}{{
+		name:   "certificate",
+		secret: "probe-client",
+	}, {
+		name:    "missing secret",
+		secret:  "missing",
+		wantErr: "failed to get the probe client certificate knative-serving/missing",
+	}, {
+		name:    "invalid secret",
+		secret:  "not-a-certificate",
+		wantErr: "invalid probe client certificate knative-serving/not-a-certificate",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			getClientCertificate := r.probeClientCertificate(config.Gateway{
+				ProbeClientCertificate: &types.NamespacedName{Namespace: "knative-serving", Name: tc.secret},
+			})
+			cert, err := getClientCertificate(&tls.CertificateRequestInfo{})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("GetClientCertificate() = %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("GetClientCertificate() =", err)
+			}
+			if len(cert.Certificate) != 1 || !bytes.Equal(cert.Certificate[0], der) {
+				t.Error("GetClientCertificate() didn't return the certificate of the Secret")
+			}
+		})
+	}
+}
+
+func TestProbeRootCAs(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("Failed to generate key:", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gateway-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal("Failed to create certificate:", err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal("Failed to parse certificate:", err)
+	}
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	// The cluster CA is the same one in these tests
+	defer func(file string) { clusterCAFile = file }(clusterCAFile)
+	clusterCAFile = filepath.Join(t.TempDir(), corev1.ServiceAccountRootCAKey)
+	if err := os.WriteFile(clusterCAFile, caPEM, 0o600); err != nil {
+		t.Fatal("Failed to write the cluster CA:", err)
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving", Name: "gateway-ca"},
+		Data: map[string][]byte{
+			corev1.ServiceAccountRootCAKey: caPEM,
+		},
+	})
+	indexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving", Name: "not-a-certificate"},
+		Data: map[string][]byte{
+			corev1.ServiceAccountRootCAKey: []byte("garbage"),
+		},
+	})
+	r := &Reconciler{secretLister: corev1listers.NewSecretLister(indexer)}
+
+	if got := r.probeRootCAs(config.Gateway{}); got != nil {
+		t.Error("probeRootCAs() of a Gateway without verification is not nil")
+	}
+
+	for _, tc := range []struct {
+		name    string
+		secret  string
+		wantErr string
+	}{{
+		name: "cluster CA",
+	}, {
+		name:   "CA of the secret",
+		secret: "gateway-ca",
+	}, {
+		name:    "missing secret",
+		secret:  "missing",
+		wantErr: "failed to get the probe CA certificate knative-serving/missing",
+	}, {
+		name:    "invalid secret",
+		secret:  "not-a-certificate",
+		wantErr: "invalid probe CA certificate knative-serving/not-a-certificate",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			gw := config.Gateway{ProbeVerifyTLS: true}
+			if tc.secret != "" {
+				gw.ProbeCACertificate = &types.NamespacedName{Namespace: "knative-serving", Name: tc.secret}
+			}
+			roots, err := r.probeRootCAs(gw)()
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("GetRootCAs() = %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("GetRootCAs() =", err)
+			}
+			if _, err := ca.Verify(x509.VerifyOptions{Roots: roots}); err != nil {
+				t.Error("GetRootCAs() didn't return the CA:", err)
+			}
+		})
+	}
+}
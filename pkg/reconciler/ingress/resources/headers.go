@@ -0,0 +1,121 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/networking/pkg/http/header"
+)
+
+const (
+	// RemoveRequestHeadersAnnotationKey is the annotation on the Ingress with
+	// the request headers removed before the requests reach the backend of a
+	// split, as a JSON object of the Service names of the splits to the
+	// header names, e.g. {"rev-1": ["X-Debug"]}.
+	RemoveRequestHeadersAnnotationKey = "gateway-api.networking.knative.dev/remove-request-headers"
+
+	// RemoveResponseHeadersAnnotationKey is the annotation on the Ingress with
+	// the response headers removed from the responses of the backend of a
+	// split, in the format of RemoveRequestHeadersAnnotationKey. It is ignored
+	// by Gateways that don't support modifying the response headers.
+	RemoveResponseHeadersAnnotationKey = "gateway-api.networking.knative.dev/remove-response-headers"
+)
+
+// headerRemovals are the header names removed for the backends of the splits,
+// by Service name.
+type headerRemovals struct {
+	Request  map[string][]string
+	Response map[string][]string
+}
+
+// probeHeaders are the request headers the probes rely on, which must reach
+// the backends.
+var probeHeaders = sets.New(
+	http.CanonicalHeaderKey(header.HashKey),
+	http.CanonicalHeaderKey(header.ProbeKey),
+)
+
+// ValidateHeaderRemovals checks the header removal annotations of the
+// Ingress.
+func ValidateHeaderRemovals(ing *netv1alpha1.Ingress) error {
+	_, err := makeHeaderRemovals(ing)
+	return err
+}
+
+// makeHeaderRemovals returns the header removals of the splits requested by
+// the annotations of the Ingress.
+func makeHeaderRemovals(ing *netv1alpha1.Ingress) (headerRemovals, error) {
+	request, err := parseHeaderRemovals(ing, RemoveRequestHeadersAnnotationKey)
+	if err != nil {
+		return headerRemovals{}, err
+	}
+	for _, names := range request {
+		for _, name := range names {
+			if probeHeaders.Has(http.CanonicalHeaderKey(name)) {
+				return headerRemovals{}, fmt.Errorf("annotation %q must not remove %q, it is used for probing",
+					RemoveRequestHeadersAnnotationKey, name)
+			}
+		}
+	}
+
+	response, err := parseHeaderRemovals(ing, RemoveResponseHeadersAnnotationKey)
+	if err != nil {
+		return headerRemovals{}, err
+	}
+
+	return headerRemovals{Request: request, Response: response}, nil
+}
+
+// parseHeaderRemovals parses the header names by Service name of the
+// annotation, or returns nil if it isn't set.
+func parseHeaderRemovals(ing *netv1alpha1.Ingress, key string) (map[string][]string, error) {
+	value, ok := ing.GetAnnotations()[key]
+	if !ok {
+		return nil, nil
+	}
+
+	var removals map[string][]string
+	if err := json.Unmarshal([]byte(value), &removals); err != nil {
+		return nil, fmt.Errorf("annotation %q must be a JSON object of Service names to header names: %w", key, err)
+	}
+
+	for service, names := range removals {
+		if errs := validation.IsDNS1035Label(service); len(errs) > 0 {
+			return nil, fmt.Errorf("annotation %q has an invalid Service name %q: %s", key, service, strings.Join(errs, ", "))
+		}
+		for _, name := range names {
+			if errs := validation.IsHTTPHeaderName(name); len(errs) > 0 {
+				return nil, fmt.Errorf("annotation %q has an invalid header name %q: %s", key, name, strings.Join(errs, ", "))
+			}
+		}
+		// Sort the names as the order doesn't matter
+		names = slices.Clone(names)
+		slices.Sort(names)
+		removals[service] = slices.Compact(names)
+	}
+
+	return removals, nil
+}
@@ -18,9 +18,40 @@ package resources
 
 import (
 	"cmp"
+	"context"
 	"slices"
+	"strings"
+
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
+	"knative.dev/networking/pkg/apis/networking"
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/kmeta"
 )
 
+const listenerPrefix = "kni-"
+
+// IngressClassName is the class of the Ingresses implemented with Gateway API.
+const IngressClassName = "gateway-api.ingress.networking.knative.dev"
+
+// IngressUIDLabelKey is the label key for the UID of the Ingress of the
+// generated resources. Unlike owner references it also ties the resources in
+// other namespaces to their Ingress, so that the ones it left behind can be
+// found and deleted.
+const IngressUIDLabelKey = networking.PublicGroupName + "/ingress-uid"
+
+// ListenerName returns the name of the Gateway listeners of the Ingress.
+func ListenerName(ing *netv1alpha1.Ingress) gatewayapi.SectionName {
+	return gatewayapi.SectionName(listenerPrefix + ing.GetUID())
+}
+
+// IsIngressListener returns whether the Gateway listener name is one of those
+// created for an Ingress.
+func IsIngressListener(name gatewayapi.SectionName) bool {
+	return strings.HasPrefix(string(name), listenerPrefix)
+}
+
 // LongestHost returns the most specific host.
 // The length is:
 // 1. the length of the hostnames.
@@ -37,3 +68,24 @@ func LongestHost[S ~[]E, E cmp.Ordered](hosts S) E {
 	slices.Sort(hosts)
 	return hosts[len(hosts)-1]
 }
+
+// ownershipLabels returns the labels tying the generated resources to the
+// Ingress.
+func ownershipLabels(ing *netv1alpha1.Ingress) map[string]string {
+	return map[string]string{IngressUIDLabelKey: string(ing.GetUID())}
+}
+
+// routeLabels returns the labels of the Ingress propagated to its routes. The
+// IngressLabelKey label is always propagated.
+func routeLabels(ctx context.Context, ing *netv1alpha1.Ingress) map[string]string {
+	gpc := config.FromContext(ctx).GatewayPlugin
+	return kmeta.FilterMap(ing.GetLabels(), func(key string) bool {
+		if key == networking.IngressLabelKey {
+			return false
+		}
+		if gpc.RouteLabelsAllowlist.Len() > 0 && !gpc.RouteLabelsAllowlist.Has(key) {
+			return true
+		}
+		return gpc.RouteLabelsDenylist.Has(key)
+	})
+}
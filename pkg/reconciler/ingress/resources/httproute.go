@@ -17,17 +17,27 @@ limitations under the License.
 package resources
 
 import (
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/utils/ptr"
 	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+	gatewaylisters "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1"
 	"sigs.k8s.io/gateway-api/pkg/features"
 
 	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
@@ -35,8 +45,51 @@ import (
 	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/networking/pkg/http/header"
 	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/network"
 )
 
+// defaultClusterDomain is the cluster domain KIngress cluster-local hosts
+// may be written with.
+const defaultClusterDomain = "cluster.local"
+
+// clusterDomainName returns the domain of the cluster, it's a variable so
+// tests can use a custom cluster domain.
+var clusterDomainName = network.GetClusterDomainName
+
+// ErrInvalidRewriteHost is returned when a path of an Ingress rewrites the
+// host to a value that isn't a precise hostname, e.g. a wildcard.
+var ErrInvalidRewriteHost = errors.New("invalid rewrite host")
+
+// ErrPreservedRewriteHost is returned when a path of an Ingress preserving
+// the original Host header rewrites the host of a rule with several hosts,
+// which can't be told apart once rewritten.
+var ErrPreservedRewriteHost = errors.New("rewrite host of an Ingress preserving the host")
+
+// PreserveHostAnnotationKey is the annotation on the Ingress that, when
+// "true", keeps the original Host header of the requests of its paths that
+// rewrite the host. The requests are still routed to the rewrite host, with
+// the original host in the K-Original-Host header, which the Knative data
+// path restores as the Host header before the requests reach the backends.
+// It is the rewrite host otherwise.
+const PreserveHostAnnotationKey = "gateway-api.networking.knative.dev/preserve-host"
+
+// InputsHashAnnotationKey is the annotation on an HTTPRoute with the hash of
+// the inputs MakeHTTPRoute built it from, as returned by HTTPRouteInputsHash.
+// It is only set on routes that are exactly the output of MakeHTTPRoute, so
+// they don't need to be rebuilt while the hash and the spec hash match.
+const InputsHashAnnotationKey = "gateway-api.networking.knative.dev/inputs-hash"
+
+// SpecHashAnnotationKey is the annotation on an HTTPRoute with the hash of the
+// spec MakeHTTPRoute built, as returned by HTTPRouteSpecHash, so that the
+// changes made to the route by others are reverted.
+const SpecHashAnnotationKey = "gateway-api.networking.knative.dev/spec-hash"
+
+// IngressNamespaceLabelKey is the label key for the namespace of the Ingress
+// of the HTTPRoutes placed in the configured route namespace. Owner references
+// can't cross namespaces, so together with networking.IngressLabelKey it
+// identifies the Ingress of these routes.
+const IngressNamespaceLabelKey = "gateway-api.networking.knative.dev/ingress-namespace"
+
 func UpdateProbeHash(r *gatewayapi.HTTPRoute, hash string) {
 	// Note: we use indices and references to avoid mutating copies
 	for rIdx := range r.Spec.Rules {
@@ -71,8 +124,7 @@ func RemoveEndpointProbes(r *gatewayapi.HTTPRoute) {
 outer:
 	for _, rule := range rules {
 		for _, match := range rule.Matches {
-			if match.Path != nil && match.Path.Value != nil &&
-				strings.HasPrefix(*match.Path.Value, "/.well-known/knative") {
+			if isEndpointProbeMatch(match) {
 				continue outer
 			}
 			r.Spec.Rules = append(r.Spec.Rules, rule)
@@ -80,7 +132,26 @@ outer:
 	}
 }
 
-func AddEndpointProbe(r *gatewayapi.HTTPRoute, hash string, backend netv1alpha1.IngressBackendSplit) {
+// HasEndpointProbes returns whether the route has endpoint probe rules.
+func HasEndpointProbes(r *gatewayapi.HTTPRoute) bool {
+	for _, rule := range r.Spec.Rules {
+		if slices.ContainsFunc(rule.Matches, isEndpointProbeMatch) {
+			return true
+		}
+	}
+	return false
+}
+
+func isEndpointProbeMatch(match gatewayapi.HTTPRouteMatch) bool {
+	return match.Path != nil && match.Path.Value != nil &&
+		strings.HasPrefix(*match.Path.Value, "/.well-known/knative")
+}
+
+// AddEndpointProbe adds a rule probing the backend of the split with the
+// configured headers identifying its revision. The backend is referenced with
+// the group and kind of the backends of the gateway of the route.
+func AddEndpointProbe(r *gatewayapi.HTTPRoute, hash string, backend netv1alpha1.IngressBackendSplit, gw config.Gateway, names config.EndpointProbeHeaders) {
+	group, kind := backendGroupKind(gw)
 	rule := gatewayapi.HTTPRouteRule{
 		Matches: []gatewayapi.HTTPRouteMatch{{
 			Path: &gatewayapi.HTTPPathMatch{
@@ -106,8 +177,8 @@ func AddEndpointProbe(r *gatewayapi.HTTPRoute, hash string, backend netv1alpha1.
 			BackendRef: gatewayapi.BackendRef{
 				Weight: ptr.To[int32](100),
 				BackendObjectReference: gatewayapi.BackendObjectReference{
-					Group: ptr.To[gatewayapi.Group](""),
-					Kind:  ptr.To[gatewayapi.Kind]("Service"),
+					Group: ptr.To(group),
+					Kind:  ptr.To(kind),
 					Name:  gatewayapi.ObjectName(backend.ServiceName),
 					//nolint:gosec // port numbers are bounded
 					Port: ptr.To(gatewayapi.PortNumber(backend.ServicePort.IntValue())),
@@ -116,6 +187,10 @@ func AddEndpointProbe(r *gatewayapi.HTTPRoute, hash string, backend netv1alpha1.
 		}},
 	}
 
+	if backend.ServiceNamespace != "" && backend.ServiceNamespace != r.Namespace {
+		rule.BackendRefs[0].Namespace = ptr.To(gatewayapi.Namespace(backend.ServiceNamespace))
+	}
+
 	if len(backend.AppendHeaders) > 0 {
 		headers := make([]gatewayapi.HTTPHeader, 0, len(backend.AppendHeaders))
 
@@ -137,15 +212,18 @@ func AddEndpointProbe(r *gatewayapi.HTTPRoute, hash string, backend netv1alpha1.
 			},
 		)
 	}
+	setEndpointProbeHeaders(&rule.BackendRefs[0], names, cmp.Or(backend.ServiceNamespace, r.Namespace))
 
 	r.Spec.Rules = append(r.Spec.Rules, rule)
 }
 
-func AddOldBackend(r *gatewayapi.HTTPRoute, hash string, old gatewayapi.HTTPBackendRef) {
+// AddOldBackend adds a rule probing the backend of a previous version of the
+// route with the configured headers identifying its revision.
+func AddOldBackend(r *gatewayapi.HTTPRoute, hash string, old gatewayapi.HTTPBackendRef, names config.EndpointProbeHeaders) {
 	backend := *old.DeepCopy()
 	backend.Weight = ptr.To[int32](100)
 
-	// KIngress only supports AppendHeaders so there's only this filter
+	// Sort the headers set by the request header filters, as in new routes
 	for _, filters := range backend.Filters {
 		if filters.RequestHeaderModifier != nil {
 			slices.SortFunc(filters.RequestHeaderModifier.Set, func(a, b gatewayapi.HTTPHeader) int {
@@ -154,11 +232,17 @@ func AddOldBackend(r *gatewayapi.HTTPRoute, hash string, old gatewayapi.HTTPBack
 		}
 	}
 
+	namespace := r.Namespace
+	if backend.Namespace != nil {
+		namespace = string(*backend.Namespace)
+	}
+	setEndpointProbeHeaders(&backend, names, namespace)
+
 	rule := gatewayapi.HTTPRouteRule{
 		Matches: []gatewayapi.HTTPRouteMatch{{
 			Path: &gatewayapi.HTTPPathMatch{
 				Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
-				Value: ptr.To(fmt.Sprintf("/.well-known/knative/revision/%s/%s", r.Namespace, backend.Name)),
+				Value: ptr.To(fmt.Sprintf("/.well-known/knative/revision/%s/%s", namespace, backend.Name)),
 			},
 			Headers: []gatewayapi.HTTPHeaderMatch{{
 				Type:  ptr.To(gatewayapi.HeaderMatchExact),
@@ -181,6 +265,45 @@ func AddOldBackend(r *gatewayapi.HTTPRoute, hash string, old gatewayapi.HTTPBack
 	r.Spec.Rules = append(r.Spec.Rules, rule)
 }
 
+// setEndpointProbeHeaders sets the headers identifying the namespace and the
+// revision of the probed backend, unless the request header filter of the
+// backend already sets them.
+func setEndpointProbeHeaders(backend *gatewayapi.HTTPBackendRef, names config.EndpointProbeHeaders, namespace string) {
+	var filter *gatewayapi.HTTPHeaderFilter
+	for _, f := range backend.Filters {
+		if f.RequestHeaderModifier != nil {
+			filter = f.RequestHeaderModifier
+			break
+		}
+	}
+
+	var missing []gatewayapi.HTTPHeader
+	for _, h := range []gatewayapi.HTTPHeader{
+		{Name: gatewayapi.HTTPHeaderName(names.Namespace), Value: namespace},
+		{Name: gatewayapi.HTTPHeaderName(names.Revision), Value: string(backend.Name)},
+	} {
+		if h.Name == "" || (filter != nil && slices.ContainsFunc(filter.Set, func(s gatewayapi.HTTPHeader) bool {
+			return strings.EqualFold(string(s.Name), string(h.Name))
+		})) {
+			continue
+		}
+		missing = append(missing, h)
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	if filter == nil {
+		filter = &gatewayapi.HTTPHeaderFilter{}
+		backend.Filters = append(backend.Filters, gatewayapi.HTTPRouteFilter{
+			Type:                  gatewayapi.HTTPRouteFilterRequestHeaderModifier,
+			RequestHeaderModifier: filter,
+		})
+	}
+	filter.Set = append(filter.Set, missing...)
+	slices.SortFunc(filter.Set, compareHTTPHeader)
+}
+
 func HTTPRouteKey(ing *netv1alpha1.Ingress, rule *netv1alpha1.IngressRule) types.NamespacedName {
 	return types.NamespacedName{
 		Name:      LongestHost(rule.Hosts),
@@ -188,59 +311,486 @@ func HTTPRouteKey(ing *netv1alpha1.Ingress, rule *netv1alpha1.IngressRule) types
 	}
 }
 
+// HTTPRouteNamespace returns the namespace of the HTTPRoutes of the Ingress,
+// which is the configured route namespace when set.
+func HTTPRouteNamespace(ctx context.Context, ing *netv1alpha1.Ingress) string {
+	if gpc := config.FromContext(ctx).GatewayPlugin; gpc != nil && gpc.RouteNamespace != "" {
+		return gpc.RouteNamespace
+	}
+	return ing.Namespace
+}
+
+// HTTPRouteName returns the name of the HTTPRoute of a part of the rule, as
+// split by SplitHTTPRouteRule. The first part is named after the longest host
+// of the rule, like the routes of rules that aren't split.
+func HTTPRouteName(rule *netv1alpha1.IngressRule, part int) string {
+	if part == 0 {
+		return LongestHost(rule.Hosts)
+	}
+	return kmeta.ChildName(LongestHost(rule.Hosts), fmt.Sprintf("-part-%d", part))
+}
+
+// MaxHTTPRouteHostnames is the maximum number of hostnames of an HTTPRoute
+// allowed by the Gateway API.
+const MaxHTTPRouteHostnames = 16
+
+// SplitHTTPRouteRule splits the hosts of the rule across rules with at most
+// MaxHTTPRouteHostnames hosts each, and their paths across rules making at
+// most maxRules HTTPRoute rules each, for Gateways capping the number of rules
+// of an HTTPRoute. The probe paths inserted for the paths of the rule are
+// kept in the part of their path, so that the HTTPRoute of each part is
+// probed. The paths aren't split when maxRules is zero.
+func SplitHTTPRouteRule(rule *netv1alpha1.IngressRule, maxRules int) []netv1alpha1.IngressRule {
+	hosts := splitHosts(rule.Hosts)
+	if len(hosts) == 1 {
+		return splitHTTPRoutePaths(rule, maxRules)
+	}
+
+	var parts []netv1alpha1.IngressRule
+	for _, h := range hosts {
+		part := *rule
+		part.Hosts = h
+		parts = append(parts, splitHTTPRoutePaths(&part, maxRules)...)
+	}
+	return parts
+}
+
+// splitHosts splits the sorted hosts in groups of at most
+// MaxHTTPRouteHostnames, starting from the most specific ones, so that the
+// first group holds the longest host the routes are named after.
+func splitHosts(hosts []string) [][]string {
+	if len(hosts) <= MaxHTTPRouteHostnames {
+		return [][]string{hosts}
+	}
+
+	sorted := slices.Clone(hosts)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	groups := make([][]string, 0, (len(sorted)+MaxHTTPRouteHostnames-1)/MaxHTTPRouteHostnames)
+	for end := len(sorted); end > 0; end -= MaxHTTPRouteHostnames {
+		groups = append(groups, sorted[max(end-MaxHTTPRouteHostnames, 0):end:end])
+	}
+	return groups
+}
+
+// splitHTTPRoutePaths splits the paths of the rule across rules making at
+// most maxRules HTTPRoute rules each.
+func splitHTTPRoutePaths(rule *netv1alpha1.IngressRule, maxRules int) []netv1alpha1.IngressRule {
+	if maxRules == 0 || rule.HTTP == nil || len(rule.HTTP.Paths) <= maxRules {
+		return []netv1alpha1.IngressRule{*rule}
+	}
+
+	probes, paths := splitProbePaths(rule.HTTP.Paths)
+	perPart := maxRules
+	if len(probes) > 0 {
+		perPart = max(maxRules/2, 1)
+	}
+
+	parts := make([]netv1alpha1.IngressRule, 0, (len(paths)+perPart-1)/perPart)
+	for start := 0; start < len(paths); start += perPart {
+		end := min(start+perPart, len(paths))
+
+		part := *rule
+		part.HTTP = &netv1alpha1.HTTPIngressRuleValue{}
+		if len(probes) > 0 {
+			part.HTTP.Paths = append(part.HTTP.Paths, probes[start:end]...)
+		}
+		part.HTTP.Paths = append(part.HTTP.Paths, paths[start:end]...)
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// splitProbePaths returns the probe paths inserted before the paths of the
+// rule by ingress.InsertProbe, if any, and the paths they probe.
+func splitProbePaths(paths []netv1alpha1.HTTPIngressPath) ([]netv1alpha1.HTTPIngressPath, []netv1alpha1.HTTPIngressPath) {
+	n := len(paths) / 2
+	if n == 0 || len(paths)%2 != 0 {
+		return nil, paths
+	}
+	for i := range n {
+		if !isProbePath(paths[i]) || isProbePath(paths[n+i]) {
+			return nil, paths
+		}
+	}
+	return paths[:n], paths[n:]
+}
+
+// HTTPRouteOption customizes the HTTPRoute made by MakeHTTPRoute.
+type HTTPRouteOption func(*httpRouteOptions)
+
+type httpRouteOptions struct {
+	part int
+}
+
+// WithHTTPRoutePart makes the HTTPRoute of a part of the rule, as split by
+// SplitHTTPRouteRule, named by HTTPRouteName.
+func WithHTTPRoutePart(part int) HTTPRouteOption {
+	return func(o *httpRouteOptions) {
+		o.part = part
+	}
+}
+
 // MakeHTTPRoute creates HTTPRoute to set up routing rules.
 func MakeHTTPRoute(
 	ctx context.Context,
 	ing *netv1alpha1.Ingress,
 	rule *netv1alpha1.IngressRule,
+	opts ...HTTPRouteOption,
 ) (*gatewayapi.HTTPRoute, error) {
+	var options httpRouteOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	pluginConfig := config.FromContext(ctx).GatewayPlugin
+	gateway := pluginConfig.ExternalGateway()
+	if rule.Visibility == netv1alpha1.IngressVisibilityClusterLocal {
+		gateway = pluginConfig.LocalGateway()
+	}
+
+	meta, backendNamespace := httpRouteMeta(ctx, ing, rule, HTTPRouteName(rule, options.part))
+
+	annotations, err := makeRouteAnnotations(ctx, ing, rule, gateway, backendNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	inputsHash, err := HTTPRouteInputsHash(ctx, ing, rule, options.part)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := makeHTTPRouteSpec(gateway, ing, rule, backendNamespace, annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	specHash, err := HTTPRouteSpecHash(spec)
+	if err != nil {
+		return nil, err
+	}
+	meta.Annotations[InputsHashAnnotationKey] = inputsHash
+	meta.Annotations[SpecHashAnnotationKey] = specHash
+
+	route := &gatewayapi.HTTPRoute{
+		ObjectMeta: meta,
+		Spec:       spec,
+	}
+	return route, nil
+}
+
+// RedirectsToHTTPS returns whether the plain HTTP requests of the rule are
+// redirected to HTTPS. The HTTPOption of the Ingress only applies to its
+// external rules, as they are the ones served with its certificates, so
+// cluster-local rules are always served over plain HTTP.
+func RedirectsToHTTPS(ing *netv1alpha1.Ingress, rule *netv1alpha1.IngressRule) bool {
+	return ing.Spec.HTTPOption == netv1alpha1.HTTPOptionRedirected &&
+		rule.Visibility != netv1alpha1.IngressVisibilityClusterLocal &&
+		len(ing.GetIngressTLSForVisibility(netv1alpha1.IngressVisibilityExternalIP)) > 0
+}
+
+// RedirectHTTPRouteName returns the name of the HTTPRoute redirecting the
+// plain HTTP requests of the rule to HTTPS.
+func RedirectHTTPRouteName(rule *netv1alpha1.IngressRule) string {
+	return kmeta.ChildName(LongestHost(rule.Hosts), "-redirect")
+}
+
+// MakeRedirectHTTPRoute creates the HTTPRoute redirecting the plain HTTP
+// requests of the rule to HTTPS. It attaches to the listeners the rule would
+// be served by without the redirect, while the route of the rule is pinned to
+// the HTTPS listener of the Ingress.
+func MakeRedirectHTTPRoute(
+	ctx context.Context,
+	ing *netv1alpha1.Ingress,
+	rule *netv1alpha1.IngressRule,
+) *gatewayapi.HTTPRoute {
+	meta, _ := httpRouteMeta(ctx, ing, rule, RedirectHTTPRouteName(rule))
+
+	hostnames := make([]gatewayapi.Hostname, 0, len(rule.Hosts))
+	for _, hostname := range rule.Hosts {
+		if !slices.Contains(hostnames, gatewayapi.Hostname(hostname)) {
+			hostnames = append(hostnames, gatewayapi.Hostname(hostname))
+		}
+	}
+
+	return &gatewayapi.HTTPRoute{
+		ObjectMeta: meta,
+		Spec: gatewayapi.HTTPRouteSpec{
+			Hostnames: hostnames,
+			Rules: []gatewayapi.HTTPRouteRule{{
+				Matches: []gatewayapi.HTTPRouteMatch{{
+					Path: &gatewayapi.HTTPPathMatch{
+						Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
+						Value: ptr.To("/"),
+					},
+				}},
+				Filters: []gatewayapi.HTTPRouteFilter{{
+					Type: gatewayapi.HTTPRouteFilterRequestRedirect,
+					RequestRedirect: &gatewayapi.HTTPRequestRedirectFilter{
+						Scheme:     ptr.To("https"),
+						StatusCode: ptr.To(cmp.Or(config.FromContext(ctx).GatewayPlugin.HTTPSRedirectStatusCode, http.StatusMovedPermanently)),
+					},
+				}},
+			}},
+			CommonRouteSpec: gatewayapi.CommonRouteSpec{ParentRefs: []gatewayapi.ParentReference{
+				gatewayParentRef(config.FromContext(ctx).GatewayPlugin.ExternalGateway()),
+			}},
+		},
+	}
+}
+
+// httpRouteMeta returns the metadata of an HTTPRoute of the rule, and the
+// namespace of its backends when the route is outside of the namespace of the
+// Ingress.
+func httpRouteMeta(
+	ctx context.Context,
+	ing *netv1alpha1.Ingress,
+	rule *netv1alpha1.IngressRule,
+	name string,
+) (metav1.ObjectMeta, *gatewayapi.Namespace) {
+	pluginConfig := config.FromContext(ctx).GatewayPlugin
 	visibility := ""
+	gateway := pluginConfig.ExternalGateway()
 	if rule.Visibility == netv1alpha1.IngressVisibilityClusterLocal {
 		visibility = "cluster-local"
+		gateway = pluginConfig.LocalGateway()
 	}
 
-	return &gatewayapi.HTTPRoute{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      LongestHost(rule.Hosts),
-			Namespace: ing.Namespace,
-			Labels: kmeta.UnionMaps(ing.Labels, map[string]string{
-				networking.VisibilityLabelKey: visibility,
-			}),
-			Annotations: kmeta.FilterMap(ing.GetAnnotations(), func(key string) bool {
+	namespace := HTTPRouteNamespace(ctx, ing)
+	objectLabels := kmeta.UnionMaps(routeLabels(ctx, ing), ownershipLabels(ing), map[string]string{
+		networking.VisibilityLabelKey: visibility,
+	})
+	ownerRefs := []metav1.OwnerReference{*kmeta.NewControllerRef(ing)}
+
+	// Routes in another namespace refer to the backends in the namespace of
+	// the Ingress, and are tied to it by labels rather than owner references.
+	var backendNamespace *gatewayapi.Namespace
+	if namespace != ing.Namespace {
+		backendNamespace = ptr.To(gatewayapi.Namespace(ing.Namespace))
+		objectLabels[networking.IngressLabelKey] = ing.Name
+		objectLabels[IngressNamespaceLabelKey] = ing.Namespace
+		ownerRefs = nil
+	}
+
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: namespace,
+		Labels:    objectLabels,
+		// The annotations of the Ingress take precedence over the ones
+		// configured for the Gateway.
+		Annotations: kmeta.UnionMaps(
+			gateway.RouteAnnotations,
+			kmeta.FilterMap(ing.GetAnnotations(), func(key string) bool {
 				return key == corev1.LastAppliedConfigAnnotation
 			}),
-			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing)},
-		},
-		Spec: makeHTTPRouteSpec(ctx, rule),
-	}, nil
+		),
+		OwnerReferences: ownerRefs,
+	}, backendNamespace
 }
 
-func makeHTTPRouteSpec(
+// OwnedHTTPRoutes returns the HTTPRoutes controlled by the Ingress, sorted by
+// namespace and name. Routes in the namespace of the Ingress aren't looked up
+// by their labels, which may be filtered by the configuration. Routes in the
+// configured route namespace are looked up by the labels identifying their
+// Ingress.
+func OwnedHTTPRoutes(ctx context.Context, ing *netv1alpha1.Ingress, lister gatewaylisters.HTTPRouteLister) ([]*gatewayapi.HTTPRoute, error) {
+	routes, err := lister.HTTPRoutes(ing.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make([]*gatewayapi.HTTPRoute, 0, len(routes))
+	for _, route := range routes {
+		if metav1.IsControlledBy(route, ing) {
+			owned = append(owned, route)
+		}
+	}
+
+	if ns := HTTPRouteNamespace(ctx, ing); ns != ing.Namespace {
+		routes, err := lister.HTTPRoutes(ns).List(labels.SelectorFromSet(labels.Set{
+			networking.IngressLabelKey: ing.Name,
+			IngressNamespaceLabelKey:   ing.Namespace,
+		}))
+		if err != nil {
+			return nil, err
+		}
+		owned = append(owned, routes...)
+	}
+
+	slices.SortFunc(owned, func(a, b *gatewayapi.HTTPRoute) int {
+		if c := strings.Compare(a.Namespace, b.Namespace); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return owned, nil
+}
+
+// IsOwnedHTTPRoute returns whether the HTTPRoute is controlled by the
+// Ingress: by an owner reference in the namespace of the Ingress, or by the
+// labels identifying the Ingress in the configured route namespace.
+func IsOwnedHTTPRoute(ctx context.Context, ing *netv1alpha1.Ingress, route *gatewayapi.HTTPRoute) bool {
+	if route.Namespace == ing.Namespace {
+		return metav1.IsControlledBy(route, ing)
+	}
+	return route.Namespace == HTTPRouteNamespace(ctx, ing) &&
+		route.Labels[networking.IngressLabelKey] == ing.Name &&
+		route.Labels[IngressNamespaceLabelKey] == ing.Namespace
+}
+
+// HTTPRouteInputsHash returns a hash of the inputs MakeHTTPRoute builds the
+// HTTPRoute of the part of the rule from.
+func HTTPRouteInputsHash(
 	ctx context.Context,
+	ing *netv1alpha1.Ingress,
 	rule *netv1alpha1.IngressRule,
-) gatewayapi.HTTPRouteSpec {
-	hostnames := make([]gatewayapi.Hostname, 0, len(rule.Hosts))
-	for _, hostname := range rule.Hosts {
-		hostnames = append(hostnames, gatewayapi.Hostname(hostname))
+	part int,
+) (string, error) {
+	b, err := json.Marshal(struct {
+		UID           types.UID
+		Name          string
+		Namespace     string
+		Labels        map[string]string
+		Annotations   map[string]string
+		Spec          netv1alpha1.IngressSpec
+		Rule          *netv1alpha1.IngressRule
+		Part          int
+		ClusterDomain string
+		// The whole configuration is hashed rather than the fields the
+		// routes are built from, so that none of them is missed.
+		GatewayPlugin *config.GatewayPlugin
+	}{
+		UID:           ing.UID,
+		Name:          ing.Name,
+		Namespace:     ing.Namespace,
+		Labels:        ing.Labels,
+		Annotations:   ing.Annotations,
+		Spec:          ing.Spec,
+		Rule:          rule,
+		Part:          part,
+		ClusterDomain: clusterDomainName(),
+		GatewayPlugin: config.FromContext(ctx).GatewayPlugin,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash HTTPRoute inputs: %w", err)
 	}
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
 
-	pluginConfig := config.FromContext(ctx).GatewayPlugin
+// HTTPRouteSpecHash returns a hash of the spec of an HTTPRoute.
+func HTTPRouteSpecHash(spec gatewayapi.HTTPRouteSpec) (string, error) {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash HTTPRoute spec: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
 
-	var gateway config.Gateway
+// RemoveHashes removes the hash annotations of a route that isn't the output
+// of MakeHTTPRoute anymore.
+func RemoveHashes(route *gatewayapi.HTTPRoute) {
+	delete(route.Annotations, InputsHashAnnotationKey)
+	delete(route.Annotations, SpecHashAnnotationKey)
+}
 
-	if rule.Visibility == netv1alpha1.IngressVisibilityClusterLocal {
-		gateway = pluginConfig.LocalGateway()
-	} else {
-		gateway = pluginConfig.ExternalGateway()
+// routeAnnotations are the options of the routes of an Ingress set by its
+// annotations, limited to the features supported by the gateway.
+type routeAnnotations struct {
+	// filters are the mirror and resiliency policy filters of the rules.
+	filters      []gatewayapi.HTTPRouteFilter
+	session      *gatewayapi.SessionPersistence
+	removals     headerRemovals
+	paths        annotatedPaths
+	preserveHost bool
+	redirect     *gatewayapi.HTTPRouteFilter
+}
+
+// makeRouteAnnotations parses the annotations of the Ingress for the routes
+// of the rule through the gateway. The backends they reference are in the
+// backendNamespace when set.
+func makeRouteAnnotations(
+	ctx context.Context,
+	ing *netv1alpha1.Ingress,
+	rule *netv1alpha1.IngressRule,
+	gateway config.Gateway,
+	backendNamespace *gatewayapi.Namespace,
+) (routeAnnotations, error) {
+	var annotations routeAnnotations
+
+	mirror, err := makeMirrorFilter(ing, rule, gateway, backendNamespace)
+	if err != nil {
+		return annotations, err
+	}
+	if mirror != nil && gateway.SupportedFeatures.Has(features.SupportHTTPRouteRequestMirror) {
+		annotations.filters = append(annotations.filters, *mirror)
 	}
 
-	rules := makeHTTPRouteRule(gateway, rule)
+	policy, err := MakeResiliencyPolicy(ctx, ing, rule)
+	if err != nil {
+		return annotations, err
+	}
+	if filter := makeResiliencyPolicyFilter(policy); filter != nil {
+		annotations.filters = append(annotations.filters, *filter)
+	}
 
-	gatewayRef := gatewayapi.ParentReference{
-		Group:     (*gatewayapi.Group)(&gatewayapi.GroupVersion.Group),
-		Kind:      (*gatewayapi.Kind)(ptr.To("Gateway")),
-		Namespace: ptr.To(gatewayapi.Namespace(gateway.Namespace)),
-		Name:      gatewayapi.ObjectName(gateway.Name),
+	if annotations.session, err = makeSessionPersistence(ing); err != nil {
+		return annotations, err
+	}
+	if !gateway.SupportedFeatures.Has(SupportHTTPRouteSessionPersistence) {
+		annotations.session = nil
+	}
+
+	if annotations.removals, err = makeHeaderRemovals(ing); err != nil {
+		return annotations, err
+	}
+	if !gateway.SupportedFeatures.Has(features.SupportHTTPRouteResponseHeaderModification) {
+		annotations.removals.Response = nil
+	}
+
+	if annotations.paths, err = makeAnnotatedPaths(ing); err != nil {
+		return annotations, err
+	}
+
+	if annotations.preserveHost, err = preservesHost(ing); err != nil {
+		return annotations, err
+	}
+
+	if annotations.redirect, err = makeRedirectFilter(ing); err != nil {
+		return annotations, err
+	}
+	return annotations, nil
+}
+
+func makeHTTPRouteSpec(
+	gateway config.Gateway,
+	ing *netv1alpha1.Ingress,
+	rule *netv1alpha1.IngressRule,
+	backendNamespace *gatewayapi.Namespace,
+	annotations routeAnnotations,
+) (gatewayapi.HTTPRouteSpec, error) {
+	hostnames := make([]gatewayapi.Hostname, 0, len(rule.Hosts))
+	for _, hostname := range rule.Hosts {
+		if rule.Visibility == netv1alpha1.IngressVisibilityClusterLocal {
+			hostname = clusterLocalHostname(hostname, clusterDomainName())
+		}
+		if !slices.Contains(hostnames, gatewayapi.Hostname(hostname)) {
+			hostnames = append(hostnames, gatewayapi.Hostname(hostname))
+		}
+	}
+
+	rules, err := makeHTTPRouteRule(gateway, rule, ing.Namespace, backendNamespace, annotations)
+	if err != nil {
+		return gatewayapi.HTTPRouteSpec{}, err
+	}
+
+	gatewayRef := gatewayParentRef(gateway)
+	if RedirectsToHTTPS(ing, rule) {
+		// The plain HTTP requests are redirected by another route, the rule
+		// is only served by the HTTPS listener of the Ingress.
+		gatewayRef.SectionName = ptr.To(ListenerName(ing))
+		gatewayRef.Port = nil
 	}
 
 	return gatewayapi.HTTPRouteSpec{
@@ -249,17 +799,97 @@ func makeHTTPRouteSpec(
 		CommonRouteSpec: gatewayapi.CommonRouteSpec{ParentRefs: []gatewayapi.ParentReference{
 			gatewayRef,
 		}},
+	}, nil
+}
+
+// ValidatePreserveHost checks the preserve host annotation of the Ingress is
+// a boolean, and that the rules with paths rewriting the host have a single
+// host when it is "true".
+func ValidatePreserveHost(ing *netv1alpha1.Ingress) error {
+	_, err := preservesHost(ing)
+	return err
+}
+
+// preservesHost returns whether the Ingress keeps the original Host header of
+// the requests of its paths rewriting the host.
+func preservesHost(ing *netv1alpha1.Ingress) (bool, error) {
+	value, ok := ing.GetAnnotations()[PreserveHostAnnotationKey]
+	if !ok {
+		return false, nil
+	}
+	preserve, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("annotation %q must be a boolean, was: %q", PreserveHostAnnotationKey, value)
+	}
+	if !preserve {
+		return false, nil
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil || len(rule.Hosts) == 1 {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.RewriteHost != "" {
+				return false, fmt.Errorf("%w: annotation %q can't be used with the rewrite host %q of a rule with several hosts",
+					ErrPreservedRewriteHost, PreserveHostAnnotationKey, path.RewriteHost)
+			}
+		}
+	}
+	return true, nil
+}
+
+// gatewayParentRef returns the reference of the routes to the Gateway.
+func gatewayParentRef(gateway config.Gateway) gatewayapi.ParentReference {
+	return gatewayapi.ParentReference{
+		Group:     (*gatewayapi.Group)(&gatewayapi.GroupVersion.Group),
+		Kind:      (*gatewayapi.Kind)(ptr.To("Gateway")),
+		Namespace: ptr.To(gatewayapi.Namespace(gateway.Namespace)),
+		Name:      gatewayapi.ObjectName(gateway.Name),
+		// Pin the route to a listener when configured, so that it doesn't
+		// attach to unrelated ones.
+		SectionName: gateway.SectionName,
+		Port:        gateway.Port,
+	}
+}
+
+// splitBackendNamespace returns the namespace of the backend ref of the split,
+// explicit when the split is in another namespace than the Ingress.
+func splitBackendNamespace(split netv1alpha1.IngressBackendSplit, namespace string, backendNamespace *gatewayapi.Namespace) *gatewayapi.Namespace {
+	if split.ServiceNamespace != "" && split.ServiceNamespace != namespace {
+		return ptr.To(gatewayapi.Namespace(split.ServiceNamespace))
 	}
+	return backendNamespace
 }
 
-func makeHTTPRouteRule(gw config.Gateway, rule *netv1alpha1.IngressRule) []gatewayapi.HTTPRouteRule {
+// makeHTTPRouteRule makes the rules of the paths of the Ingress rule. The
+// backend refs have the backendNamespace when set, for routes outside of the
+// namespace of the Ingress, or the namespace of their split when it is
+// another one than the namespace of the Ingress, and the header removals of
+// their split. The paths other than the probes are redirected instead of
+// routed to their backends when the annotations have a redirect, and the
+// paths rewriting the host keep the original one in the K-Original-Host
+// header when the annotations preserve the host.
+func makeHTTPRouteRule(
+	gw config.Gateway,
+	rule *netv1alpha1.IngressRule,
+	namespace string,
+	backendNamespace *gatewayapi.Namespace,
+	annotations routeAnnotations,
+) ([]gatewayapi.HTTPRouteRule, error) {
 	rules := []gatewayapi.HTTPRouteRule{}
 
-	for _, path := range rule.HTTP.Paths {
+	ingressPaths := slices.Clone(rule.HTTP.Paths)
+	slices.SortStableFunc(ingressPaths, comparePathSpecificity)
+
+	for _, path := range ingressPaths {
 		backendRefs := make([]gatewayapi.HTTPBackendRef, 0, len(path.Splits))
 		var preFilters []gatewayapi.HTTPRouteFilter
 
-		if path.AppendHeaders != nil {
+		// The original host of the requests is restored by the Knative data
+		// path from the K-Original-Host header, unless the path sets it.
+		_, hasOriginalHost := path.AppendHeaders[header.OriginalHostKey]
+		preserveHost := path.RewriteHost != "" && annotations.preserveHost && !hasOriginalHost
+		if path.AppendHeaders != nil || preserveHost {
 			headers := []gatewayapi.HTTPHeader{}
 			for k, v := range path.AppendHeaders {
 				header := gatewayapi.HTTPHeader{
@@ -268,6 +898,12 @@ func makeHTTPRouteRule(gw config.Gateway, rule *netv1alpha1.IngressRule) []gatew
 				}
 				headers = append(headers, header)
 			}
+			if preserveHost {
+				headers = append(headers, gatewayapi.HTTPHeader{
+					Name:  header.OriginalHostKey,
+					Value: rule.Hosts[0],
+				})
+			}
 
 			// Sort HTTPHeader as the order is random.
 			slices.SortFunc(headers, compareHTTPHeader)
@@ -281,6 +917,9 @@ func makeHTTPRouteRule(gw config.Gateway, rule *netv1alpha1.IngressRule) []gatew
 		}
 
 		if path.RewriteHost != "" {
+			if errs := validation.IsDNS1123Subdomain(path.RewriteHost); len(errs) > 0 {
+				return nil, fmt.Errorf("%w %q: %s", ErrInvalidRewriteHost, path.RewriteHost, strings.Join(errs, ", "))
+			}
 			preFilters = append(preFilters, gatewayapi.HTTPRouteFilter{
 				Type: gatewayapi.HTTPRouteFilterURLRewrite,
 				URLRewrite: &gatewayapi.HTTPURLRewriteFilter{
@@ -289,7 +928,13 @@ func makeHTTPRouteRule(gw config.Gateway, rule *netv1alpha1.IngressRule) []gatew
 			})
 		}
 
-		for _, split := range path.Splits {
+		// These are rule filters so that they apply once to all the splits.
+		// Probes are not mirrored nor subject to the resiliency policy.
+		if !isProbePath(path) {
+			preFilters = append(preFilters, annotations.filters...)
+		}
+
+		for _, split := range RoutedSplits(gw, path) {
 			headers := []gatewayapi.HTTPHeader{}
 			for k, v := range split.AppendHeaders {
 				header := gatewayapi.HTTPHeader{
@@ -303,16 +948,18 @@ func makeHTTPRouteRule(gw config.Gateway, rule *netv1alpha1.IngressRule) []gatew
 			slices.SortFunc(headers, compareHTTPHeader)
 
 			name := split.ServiceName
+			group, kind := backendGroupKind(gw)
 			backendRef := gatewayapi.HTTPBackendRef{
 				BackendRef: gatewayapi.BackendRef{
 					BackendObjectReference: gatewayapi.BackendObjectReference{
-						Name:  gatewayapi.ObjectName(name),
-						Group: (*gatewayapi.Group)(ptr.To("")),
-						Kind:  (*gatewayapi.Kind)(ptr.To("Service")),
+						Name:      gatewayapi.ObjectName(name),
+						Namespace: splitBackendNamespace(split, namespace, backendNamespace),
+						Group:     &group,
+						Kind:      &kind,
 						//nolint:gosec // port numbers are bounded
 						Port: ptr.To(gatewayapi.PortNumber(split.ServicePort.IntValue())),
 					},
-					Weight: ptr.To(int32(split.Percent)), //nolint:gosec // percent is bounded [0,100]
+					Weight: ptr.To(splitWeight(gw, split)),
 				},
 				Filters: []gatewayapi.HTTPRouteFilter{
 					{
@@ -323,6 +970,19 @@ func makeHTTPRouteRule(gw config.Gateway, rule *netv1alpha1.IngressRule) []gatew
 					},
 				},
 			}
+
+			// Probes must reach the backends with their headers intact
+			if !isProbePath(path) {
+				backendRef.Filters[0].RequestHeaderModifier.Remove = annotations.removals.Request[name]
+				if remove := annotations.removals.Response[name]; len(remove) > 0 {
+					backendRef.Filters = append(backendRef.Filters, gatewayapi.HTTPRouteFilter{
+						Type: gatewayapi.HTTPRouteFilterResponseHeaderModifier,
+						ResponseHeaderModifier: &gatewayapi.HTTPHeaderFilter{
+							Remove: remove,
+						},
+					})
+				}
+			}
 			backendRefs = append(backendRefs, backendRef)
 		}
 
@@ -334,6 +994,10 @@ func makeHTTPRouteRule(gw config.Gateway, rule *netv1alpha1.IngressRule) []gatew
 			Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
 			Value: ptr.To(pathPrefix),
 		}
+		// Probes are matched by prefix, they are sent to the health check path
+		if annotations.paths.Exact.Has(pathPrefix) && !isProbePath(path) {
+			pathMatch.Type = ptr.To(gatewayapi.PathMatchExact)
+		}
 
 		var headerMatchList []gatewayapi.HTTPHeaderMatch
 		for k, v := range path.Headers {
@@ -356,15 +1020,85 @@ func makeHTTPRouteRule(gw config.Gateway, rule *netv1alpha1.IngressRule) []gatew
 			Matches:     matches,
 		}
 
+		// Denied paths aren't routed to their backends
+		deny := annotations.paths.Denied.Has(pathPrefix) && !isProbePath(path)
+		if deny {
+			rule.BackendRefs = nil
+			rule.Filters = denyFilters(gw)
+		}
+
+		// Redirected paths aren't routed to their backends either, while
+		// probes must still reach them
+		redirected := annotations.redirect != nil && !isProbePath(path) && !deny
+		if redirected {
+			rule.BackendRefs = nil
+			rule.Filters = []gatewayapi.HTTPRouteFilter{*annotations.redirect.DeepCopy()}
+		}
+
+		// Probes aren't sticky, they must reach the backends they target
+		if annotations.session != nil && !isProbePath(path) && !deny && !redirected {
+			rule.SessionPersistence = annotations.session.DeepCopy()
+		}
+
 		if gw.SupportedFeatures.Has(features.SupportHTTPRouteRequestTimeout) {
 			rule.Timeouts = &gatewayapi.HTTPRouteTimeouts{
 				Request: ptr.To[gatewayapi.Duration]("0s"),
 			}
 		}
 
+		// Gateways only use the first of the rules with identical matches,
+		// which can happen for paths left behind by upgrades, so their
+		// backends are merged into a single rule.
+		if i := slices.IndexFunc(rules, func(r gatewayapi.HTTPRouteRule) bool {
+			return equality.Semantic.DeepEqual(r.Matches, rule.Matches)
+		}); i >= 0 {
+			if !equality.Semantic.DeepEqual(rules[i].Filters, rule.Filters) {
+				return nil, fmt.Errorf("paths with identical matches for %q have different filters", pathPrefix)
+			}
+			rules[i].BackendRefs = append(rules[i].BackendRefs, rule.BackendRefs...)
+			continue
+		}
+
 		rules = append(rules, rule)
 	}
-	return rules
+	return rules, nil
+}
+
+// backendGroupKind returns the group and kind of the backends of the routes
+// attached to the gateway, Services unless configured otherwise.
+func backendGroupKind(gw config.Gateway) (gatewayapi.Group, gatewayapi.Kind) {
+	if gw.BackendKind == "" {
+		return "", "Service"
+	}
+	return gw.BackendGroup, gw.BackendKind
+}
+
+// RoutedSplits returns the splits of the path that are backends of its
+// route through the Gateway.
+func RoutedSplits(gw config.Gateway, path netv1alpha1.HTTPIngressPath) []netv1alpha1.IngressBackendSplit {
+	if gw.ZeroWeightBackends != config.ZeroWeightDrop {
+		return path.Splits
+	}
+	splits := make([]netv1alpha1.IngressBackendSplit, 0, len(path.Splits))
+	for _, split := range path.Splits {
+		if split.Percent > 0 {
+			splits = append(splits, split)
+		}
+	}
+	if len(splits) == 0 {
+		// Keep the path routed to something
+		return path.Splits
+	}
+	return splits
+}
+
+// splitWeight returns the weight of the backend of the split, its percent
+// scaled by the weight scale of the Gateway.
+func splitWeight(gw config.Gateway, split netv1alpha1.IngressBackendSplit) int32 {
+	if split.Percent == 0 && gw.ZeroWeightBackends == config.ZeroWeightMinimal {
+		return 1
+	}
+	return int32(split.Percent) * max(gw.WeightScale, 1) //nolint:gosec // percent is bounded [0,100]
 }
 
 type HTTPHeaderList []gatewayapi.HTTPHeader
@@ -395,6 +1129,31 @@ func (h HTTPHeaderMatchList) Swap(i, j int) {
 	h[i], h[j] = h[j], h[i]
 }
 
+// comparePathSpecificity orders the probe paths first, then the paths by
+// descending length, so that Gateways matching the rules in order don't
+// shadow the specific prefixes with the broader ones.
+func comparePathSpecificity(a, b netv1alpha1.HTTPIngressPath) int {
+	if probeA, probeB := isProbePath(a), isProbePath(b); probeA != probeB {
+		if probeA {
+			return -1
+		}
+		return 1
+	}
+	// An empty path is matched as "/"
+	return cmp.Compare(max(len(b.Path), 1), max(len(a.Path), 1))
+}
+
 func compareHTTPHeader(a, b gatewayapi.HTTPHeader) int {
 	return strings.Compare(string(a.Name), string(b.Name))
 }
+
+// clusterLocalHostname returns the cluster-local host using the given cluster
+// domain, e.g. "hello.default.svc.cluster.local" becomes
+// "hello.default.svc.example.internal" for the "example.internal" domain.
+// The shorter variants of the host don't depend on the domain and are kept.
+func clusterLocalHostname(host, domain string) string {
+	if name, ok := strings.CutSuffix(host, ".svc."+defaultClusterDomain); ok && domain != defaultClusterDomain {
+		return name + ".svc." + domain
+	}
+	return host
+}
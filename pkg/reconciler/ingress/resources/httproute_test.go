@@ -18,7 +18,10 @@ package resources
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -26,14 +29,17 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/utils/ptr"
 	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/networking/pkg/http/header"
 	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/network"
 	"knative.dev/pkg/reconciler"
 	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+	gatewaylisters "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1"
 	"sigs.k8s.io/gateway-api/pkg/features"
 )
 
@@ -105,10 +111,11 @@ var (
 
 func TestMakeHTTPRoute(t *testing.T) {
 	for _, tc := range []struct {
-		name         string
-		ing          *v1alpha1.Ingress
-		expected     []*gatewayapi.HTTPRoute
-		changeConfig func(gw *config.Config)
+		name          string
+		ing           *v1alpha1.Ingress
+		expected      []*gatewayapi.HTTPRoute
+		changeConfig  func(gw *config.Config)
+		clusterDomain string
 	}{
 		{
 			name: "single external domain with split and cluster local",
@@ -429,8 +436,8 @@ func TestMakeHTTPRoute(t *testing.T) {
 									BackendObjectReference: gatewayapi.BackendObjectReference{
 										Group: (*gatewayapi.Group)(ptr.To("")),
 										Kind:  (*gatewayapi.Kind)(ptr.To("Service")),
-										Port:  ptr.To[gatewayapi.PortNumber](123),
-										Name:  gatewayapi.ObjectName("goo"),
+										Port:  ptr.To[gatewayapi.PortNumber](124),
+										Name:  gatewayapi.ObjectName("doo"),
 									},
 									Weight: ptr.To(int32(100)),
 								},
@@ -445,12 +452,12 @@ func TestMakeHTTPRoute(t *testing.T) {
 								{
 									Path: &gatewayapi.HTTPPathMatch{
 										Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
-										Value: ptr.To("/"),
+										Value: ptr.To("/doo"),
 									},
 									Headers: []gatewayapi.HTTPHeaderMatch{{
 										Type:  ptr.To(gatewayapi.HeaderMatchExact),
 										Name:  gatewayapi.HTTPHeaderName("tag"),
-										Value: "goo",
+										Value: "doo",
 									}},
 								},
 							},
@@ -460,8 +467,8 @@ func TestMakeHTTPRoute(t *testing.T) {
 									BackendObjectReference: gatewayapi.BackendObjectReference{
 										Group: (*gatewayapi.Group)(ptr.To("")),
 										Kind:  (*gatewayapi.Kind)(ptr.To("Service")),
-										Port:  ptr.To[gatewayapi.PortNumber](124),
-										Name:  gatewayapi.ObjectName("doo"),
+										Port:  ptr.To[gatewayapi.PortNumber](123),
+										Name:  gatewayapi.ObjectName("goo"),
 									},
 									Weight: ptr.To(int32(100)),
 								},
@@ -476,12 +483,12 @@ func TestMakeHTTPRoute(t *testing.T) {
 								{
 									Path: &gatewayapi.HTTPPathMatch{
 										Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
-										Value: ptr.To("/doo"),
+										Value: ptr.To("/"),
 									},
 									Headers: []gatewayapi.HTTPHeaderMatch{{
 										Type:  ptr.To(gatewayapi.HeaderMatchExact),
 										Name:  gatewayapi.HTTPHeaderName("tag"),
-										Value: "doo",
+										Value: "goo",
 									}},
 								},
 							},
@@ -556,6 +563,199 @@ func TestMakeHTTPRoute(t *testing.T) {
 					},
 				},
 			}},
+		}, {
+			name: "path with host rewrite and splits",
+			ing: func() *v1alpha1.Ingress {
+				ing := baseIngress(nil)
+				ing.Spec.Rules[0].HTTP.Paths[0].RewriteHost = "hello-example.example.com"
+				return ing
+			}(),
+			expected: []*gatewayapi.HTTPRoute{baseRoute(nil, []gatewayapi.HTTPRouteFilter{{
+				Type: gatewayapi.HTTPRouteFilterURLRewrite,
+				URLRewrite: &gatewayapi.HTTPURLRewriteFilter{
+					Hostname: (*gatewayapi.PreciseHostname)(ptr.To("hello-example.example.com")),
+				},
+			}})},
+		}, {
+			name: "path with host rewrite preserving the host",
+			ing: func() *v1alpha1.Ingress {
+				ing := baseIngress(map[string]string{PreserveHostAnnotationKey: "true"})
+				ing.Spec.Rules[0].HTTP.Paths[0].RewriteHost = "hello-example.example.com"
+				return ing
+			}(),
+			// The original host is kept in a header next to the rewrite.
+			expected: []*gatewayapi.HTTPRoute{baseRoute(map[string]string{PreserveHostAnnotationKey: "true"}, []gatewayapi.HTTPRouteFilter{{
+				Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
+				RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
+					Set: []gatewayapi.HTTPHeader{{
+						Name:  header.OriginalHostKey,
+						Value: testHosts[0],
+					}},
+				},
+			}, {
+				Type: gatewayapi.HTTPRouteFilterURLRewrite,
+				URLRewrite: &gatewayapi.HTTPURLRewriteFilter{
+					Hostname: (*gatewayapi.PreciseHostname)(ptr.To("hello-example.example.com")),
+				},
+			}})},
+		}, {
+			name:     "preserving the host without host rewrite",
+			ing:      baseIngress(map[string]string{PreserveHostAnnotationKey: "true"}),
+			expected: []*gatewayapi.HTTPRoute{baseRoute(map[string]string{PreserveHostAnnotationKey: "true"}, nil)},
+		}, {
+			name: "denied path",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.ExternalGateways[0].DenyFilter = &gatewayapi.LocalObjectReference{
+					Group: "gateway.envoyproxy.io",
+					Kind:  "HTTPRouteFilter",
+					Name:  "not-found",
+				}
+			},
+			ing: baseIngress(map[string]string{DenyPathsAnnotationKey: "/admin, /"}),
+			expected: func() []*gatewayapi.HTTPRoute {
+				route := baseRoute(map[string]string{DenyPathsAnnotationKey: "/admin, /"}, []gatewayapi.HTTPRouteFilter{{
+					Type: gatewayapi.HTTPRouteFilterExtensionRef,
+					ExtensionRef: &gatewayapi.LocalObjectReference{
+						Group: "gateway.envoyproxy.io",
+						Kind:  "HTTPRouteFilter",
+						Name:  "not-found",
+					},
+				}})
+				route.Spec.Rules[0].BackendRefs = nil
+				return []*gatewayapi.HTTPRoute{route}
+			}(),
+		}, {
+			name: "denied path without deny filter",
+			ing:  baseIngress(map[string]string{DenyPathsAnnotationKey: "/"}),
+			expected: func() []*gatewayapi.HTTPRoute {
+				route := baseRoute(map[string]string{DenyPathsAnnotationKey: "/"}, nil)
+				route.Spec.Rules[0].BackendRefs = nil
+				return []*gatewayapi.HTTPRoute{route}
+			}(),
+		}, {
+			name: "redirected path",
+			ing: func() *v1alpha1.Ingress {
+				ing := baseIngress(map[string]string{
+					RedirectAnnotationKey:           "https://new.example.com:8443/new-path",
+					RedirectStatusCodeAnnotationKey: "301",
+				})
+				rule := &ing.Spec.Rules[0]
+				probe := *rule.HTTP.Paths[0].DeepCopy()
+				probe.Path = "/probe"
+				probe.Headers = map[string]v1alpha1.HeaderMatch{header.HashKey: {Exact: "override"}}
+				rule.HTTP.Paths = append(rule.HTTP.Paths, probe)
+				return ing
+			}(),
+			expected: func() []*gatewayapi.HTTPRoute {
+				route := baseRoute(map[string]string{
+					RedirectAnnotationKey:           "https://new.example.com:8443/new-path",
+					RedirectStatusCodeAnnotationKey: "301",
+				}, []gatewayapi.HTTPRouteFilter{{
+					Type: gatewayapi.HTTPRouteFilterRequestRedirect,
+					RequestRedirect: &gatewayapi.HTTPRequestRedirectFilter{
+						Scheme:   ptr.To("https"),
+						Hostname: ptr.To[gatewayapi.PreciseHostname]("new.example.com"),
+						Port:     ptr.To[gatewayapi.PortNumber](8443),
+						Path: &gatewayapi.HTTPPathModifier{
+							Type:            gatewayapi.FullPathHTTPPathModifier,
+							ReplaceFullPath: ptr.To("/new-path"),
+						},
+						StatusCode: ptr.To(301),
+					},
+				}})
+				// The probes still reach the backends
+				probe := *route.Spec.Rules[0].DeepCopy()
+				probe.Filters = nil
+				probe.Matches = []gatewayapi.HTTPRouteMatch{{
+					Path: &gatewayapi.HTTPPathMatch{
+						Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
+						Value: ptr.To("/probe"),
+					},
+					Headers: []gatewayapi.HTTPHeaderMatch{{
+						Type:  ptr.To(gatewayapi.HeaderMatchExact),
+						Name:  header.HashKey,
+						Value: "override",
+					}},
+				}}
+				route.Spec.Rules[0].BackendRefs = nil
+				route.Spec.Rules = append([]gatewayapi.HTTPRouteRule{probe}, route.Spec.Rules...)
+				return []*gatewayapi.HTTPRoute{route}
+			}(),
+		}, {
+			name: "redirected path to another path",
+			ing:  baseIngress(map[string]string{RedirectAnnotationKey: "/new-path"}),
+			expected: func() []*gatewayapi.HTTPRoute {
+				route := baseRoute(map[string]string{RedirectAnnotationKey: "/new-path"}, []gatewayapi.HTTPRouteFilter{{
+					Type: gatewayapi.HTTPRouteFilterRequestRedirect,
+					RequestRedirect: &gatewayapi.HTTPRequestRedirectFilter{
+						Path: &gatewayapi.HTTPPathModifier{
+							Type:            gatewayapi.FullPathHTTPPathModifier,
+							ReplaceFullPath: ptr.To("/new-path"),
+						},
+					},
+				}})
+				route.Spec.Rules[0].BackendRefs = nil
+				return []*gatewayapi.HTTPRoute{route}
+			}(),
+		}, {
+			name: "exact path",
+			ing: func() *v1alpha1.Ingress {
+				ing := baseIngress(map[string]string{ExactPathsAnnotationKey: "/"})
+				rule := &ing.Spec.Rules[0]
+				probe := *rule.HTTP.Paths[0].DeepCopy()
+				probe.Headers = map[string]v1alpha1.HeaderMatch{header.HashKey: {Exact: "override"}}
+				rule.HTTP.Paths = append(rule.HTTP.Paths, probe)
+				return ing
+			}(),
+			expected: func() []*gatewayapi.HTTPRoute {
+				route := baseRoute(map[string]string{ExactPathsAnnotationKey: "/"}, nil)
+				// The probes are still matched by prefix
+				probe := *route.Spec.Rules[0].DeepCopy()
+				probe.Matches[0].Headers = []gatewayapi.HTTPHeaderMatch{{
+					Type:  ptr.To(gatewayapi.HeaderMatchExact),
+					Name:  header.HashKey,
+					Value: "override",
+				}}
+				route.Spec.Rules[0].Matches[0].Path.Type = ptr.To(gatewayapi.PathMatchExact)
+				route.Spec.Rules = append([]gatewayapi.HTTPRouteRule{probe}, route.Spec.Rules...)
+				return []*gatewayapi.HTTPRoute{route}
+			}(),
+		}, {
+			name: "overlapping path prefixes",
+			ing: func() *v1alpha1.Ingress {
+				ing := baseIngress(nil)
+				rule := &ing.Spec.Rules[0]
+				for _, prefix := range []string{"/foo", "/foo/bar"} {
+					path := *rule.HTTP.Paths[0].DeepCopy()
+					path.Path = prefix
+					rule.HTTP.Paths = append(rule.HTTP.Paths, path)
+				}
+				return ing
+			}(),
+			expected: func() []*gatewayapi.HTTPRoute {
+				route := baseRoute(nil, nil)
+				// The most specific prefixes come first
+				var rules []gatewayapi.HTTPRouteRule
+				for _, prefix := range []string{"/foo/bar", "/foo"} {
+					rule := *route.Spec.Rules[0].DeepCopy()
+					rule.Matches[0].Path.Value = ptr.To(prefix)
+					rules = append(rules, rule)
+				}
+				route.Spec.Rules = append(rules, route.Spec.Rules...)
+				return []*gatewayapi.HTTPRoute{route}
+			}(),
+		}, {
+			name: "split in another namespace",
+			ing: func() *v1alpha1.Ingress {
+				ing := baseIngress(nil)
+				ing.Spec.Rules[0].HTTP.Paths[0].Splits[1].ServiceNamespace = "other-ns"
+				return ing
+			}(),
+			expected: func() []*gatewayapi.HTTPRoute {
+				route := baseRoute(nil, nil)
+				route.Spec.Rules[0].BackendRefs[1].Namespace = ptr.To[gatewayapi.Namespace]("other-ns")
+				return []*gatewayapi.HTTPRoute{route}
+			}(),
 		}, {
 			name: "gateway supports HTTPRouteRequestTimeout",
 			changeConfig: func(c *config.Config) {
@@ -619,10 +819,399 @@ func TestMakeHTTPRoute(t *testing.T) {
 					},
 				},
 			}},
+		}, {
+			name: "mirror with splits",
+			changeConfig: func(c *config.Config) {
+				for _, gateway := range c.GatewayPlugin.ExternalGateways {
+					gateway.SupportedFeatures.Insert(features.SupportHTTPRouteRequestMirror)
+				}
+			},
+			ing: baseIngress(map[string]string{
+				MirrorBackendAnnotationKey: "canary:8080",
+				MirrorPercentAnnotationKey: "25",
+			}),
+			expected: []*gatewayapi.HTTPRoute{baseRoute(map[string]string{
+				MirrorBackendAnnotationKey: "canary:8080",
+				MirrorPercentAnnotationKey: "25",
+			}, []gatewayapi.HTTPRouteFilter{{
+				Type: gatewayapi.HTTPRouteFilterRequestMirror,
+				RequestMirror: &gatewayapi.HTTPRequestMirrorFilter{
+					BackendRef: gatewayapi.BackendObjectReference{
+						Group: (*gatewayapi.Group)(ptr.To("")),
+						Kind:  (*gatewayapi.Kind)(ptr.To("Service")),
+						Name:  "canary",
+						Port:  ptr.To[gatewayapi.PortNumber](8080),
+					},
+					Percent: ptr.To[int32](25),
+				},
+			}})},
+		}, {
+			name: "mirror not supported by gateway",
+			ing: baseIngress(map[string]string{
+				MirrorBackendAnnotationKey: "canary:8080",
+			}),
+			expected: []*gatewayapi.HTTPRoute{baseRoute(map[string]string{
+				MirrorBackendAnnotationKey: "canary:8080",
+			}, nil)},
+		}, {
+			name: "session persistence",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.ExternalGateways[0].SupportedFeatures.Insert(SupportHTTPRouteSessionPersistence)
+			},
+			ing: baseIngress(map[string]string{
+				SessionCookieAnnotationKey:   "session",
+				SessionLifetimeAnnotationKey: "1h",
+			}),
+			expected: []*gatewayapi.HTTPRoute{func() *gatewayapi.HTTPRoute {
+				route := baseRoute(map[string]string{
+					SessionCookieAnnotationKey:   "session",
+					SessionLifetimeAnnotationKey: "1h",
+				}, nil)
+				route.Spec.Rules[0].SessionPersistence = &gatewayapi.SessionPersistence{
+					SessionName:     ptr.To("session"),
+					AbsoluteTimeout: ptr.To[gatewayapi.Duration]("1h"),
+					Type:            ptr.To(gatewayapi.CookieBasedSessionPersistence),
+					CookieConfig: &gatewayapi.CookieConfig{
+						LifetimeType: ptr.To(gatewayapi.PermanentCookieLifetimeType),
+					},
+				}
+				return route
+			}()},
+		}, {
+			name: "session persistence not supported by gateway",
+			ing: baseIngress(map[string]string{
+				SessionCookieAnnotationKey: "session",
+			}),
+			expected: []*gatewayapi.HTTPRoute{baseRoute(map[string]string{
+				SessionCookieAnnotationKey: "session",
+			}, nil)},
+		}, {
+			name:     "zero percent split kept by default",
+			ing:      drainingIngress(),
+			expected: []*gatewayapi.HTTPRoute{drainingRoute(ptr.To[int32](0))},
+		}, {
+			name: "zero percent split with minimal weight",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.ExternalGateways[0].ZeroWeightBackends = config.ZeroWeightMinimal
+			},
+			ing:      drainingIngress(),
+			expected: []*gatewayapi.HTTPRoute{drainingRoute(ptr.To[int32](1))},
+		}, {
+			name: "zero percent split dropped",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.ExternalGateways[0].ZeroWeightBackends = config.ZeroWeightDrop
+			},
+			ing:      drainingIngress(),
+			expected: []*gatewayapi.HTTPRoute{drainingRoute(nil)},
+		}, {
+			name:     "canary weights unscaled by default",
+			ing:      canaryIngress(),
+			expected: []*gatewayapi.HTTPRoute{canaryRoute(99, 1)},
+		}, {
+			name: "canary weights scaled",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.ExternalGateways[0].WeightScale = 100
+			},
+			ing:      canaryIngress(),
+			expected: []*gatewayapi.HTTPRoute{canaryRoute(9900, 100)},
+		}, {
+			name: "zero percent split keeps the minimal weight when scaled",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.ExternalGateways[0].WeightScale = 100
+				c.GatewayPlugin.ExternalGateways[0].ZeroWeightBackends = config.ZeroWeightMinimal
+			},
+			ing: drainingIngress(),
+			expected: []*gatewayapi.HTTPRoute{func() *gatewayapi.HTTPRoute {
+				route := drainingRoute(ptr.To[int32](1))
+				route.Spec.Rules[0].BackendRefs[0].Weight = ptr.To[int32](6000)
+				route.Spec.Rules[0].BackendRefs[1].Weight = ptr.To[int32](4000)
+				return route
+			}()},
+		}, {
+			name: "per-split request headers removed",
+			ing: baseIngress(map[string]string{
+				RemoveRequestHeadersAnnotationKey: `{"goo": ["X-Debug", "Cookie"]}`,
+			}),
+			expected: []*gatewayapi.HTTPRoute{func() *gatewayapi.HTTPRoute {
+				route := baseRoute(map[string]string{
+					RemoveRequestHeadersAnnotationKey: `{"goo": ["X-Debug", "Cookie"]}`,
+				}, nil)
+				route.Spec.Rules[0].BackendRefs[0].Filters[0].RequestHeaderModifier.Remove = []string{"Cookie", "X-Debug"}
+				return route
+			}()},
+		}, {
+			name: "per-split response headers removed",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.ExternalGateways[0].SupportedFeatures = sets.New(features.SupportHTTPRouteResponseHeaderModification)
+			},
+			ing: baseIngress(map[string]string{
+				RemoveRequestHeadersAnnotationKey:  `{"doo": ["X-Debug"]}`,
+				RemoveResponseHeadersAnnotationKey: `{"doo": ["Server"]}`,
+			}),
+			expected: []*gatewayapi.HTTPRoute{func() *gatewayapi.HTTPRoute {
+				route := baseRoute(map[string]string{
+					RemoveRequestHeadersAnnotationKey:  `{"doo": ["X-Debug"]}`,
+					RemoveResponseHeadersAnnotationKey: `{"doo": ["Server"]}`,
+				}, nil)
+				backend := &route.Spec.Rules[0].BackendRefs[1]
+				backend.Filters[0].RequestHeaderModifier.Remove = []string{"X-Debug"}
+				backend.Filters = append(backend.Filters, gatewayapi.HTTPRouteFilter{
+					Type: gatewayapi.HTTPRouteFilterResponseHeaderModifier,
+					ResponseHeaderModifier: &gatewayapi.HTTPHeaderFilter{
+						Remove: []string{"Server"},
+					},
+				})
+				return route
+			}()},
+		}, {
+			name: "per-split response headers not supported by gateway",
+			ing: baseIngress(map[string]string{
+				RemoveResponseHeadersAnnotationKey: `{"doo": ["Server"]}`,
+			}),
+			expected: []*gatewayapi.HTTPRoute{baseRoute(map[string]string{
+				RemoveResponseHeadersAnnotationKey: `{"doo": ["Server"]}`,
+			}, nil)},
+		}, {
+			name:     "all labels propagated by default",
+			ing:      labelledIngress(),
+			expected: []*gatewayapi.HTTPRoute{labelledRoute("serving.knative.dev/route", "team")},
+		}, {
+			name: "only allowlisted labels propagated",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.RouteLabelsAllowlist = sets.New("team")
+			},
+			ing:      labelledIngress(),
+			expected: []*gatewayapi.HTTPRoute{labelledRoute("team")},
+		}, {
+			name: "denylisted labels not propagated",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.RouteLabelsAllowlist = sets.New("team", "serving.knative.dev/route")
+				c.GatewayPlugin.RouteLabelsDenylist = sets.New("serving.knative.dev/route", networking.IngressLabelKey)
+			},
+			ing:      labelledIngress(),
+			expected: []*gatewayapi.HTTPRoute{labelledRoute("team")},
+		}, {
+			name:     "paths with identical matches merged",
+			ing:      duplicateMatchIngress(""),
+			expected: []*gatewayapi.HTTPRoute{drainingRoute(ptr.To[int32](100))},
+		}, {
+			name: "parent ref pinned to a listener",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.ExternalGateways[0].SectionName = ptr.To[gatewayapi.SectionName]("http")
+				c.GatewayPlugin.ExternalGateways[0].Port = ptr.To[gatewayapi.PortNumber](8080)
+			},
+			ing: baseIngress(nil),
+			expected: []*gatewayapi.HTTPRoute{func() *gatewayapi.HTTPRoute {
+				route := baseRoute(nil, nil)
+				route.Spec.ParentRefs[0].SectionName = ptr.To[gatewayapi.SectionName]("http")
+				route.Spec.ParentRefs[0].Port = ptr.To[gatewayapi.PortNumber](8080)
+				return route
+			}()},
+		}, {
+			name: "parent ref pinned to a listener port",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.ExternalGateways[0].Port = ptr.To[gatewayapi.PortNumber](8443)
+			},
+			ing: baseIngress(nil),
+			expected: []*gatewayapi.HTTPRoute{func() *gatewayapi.HTTPRoute {
+				route := baseRoute(nil, nil)
+				route.Spec.ParentRefs[0].Port = ptr.To[gatewayapi.PortNumber](8443)
+				return route
+			}()},
+		}, {
+			name: "routes in the configured namespace",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.RouteNamespace = "gateways"
+			},
+			ing: baseIngress(nil),
+			expected: []*gatewayapi.HTTPRoute{func() *gatewayapi.HTTPRoute {
+				route := baseRoute(nil, nil)
+				route.Namespace = "gateways"
+				route.Labels[IngressNamespaceLabelKey] = testNamespace
+				for i := range route.Spec.Rules {
+					for j := range route.Spec.Rules[i].BackendRefs {
+						route.Spec.Rules[i].BackendRefs[j].Namespace = ptr.To[gatewayapi.Namespace](testNamespace)
+					}
+				}
+				return route
+			}()},
+		}, {
+			name: "mirror with routes in the configured namespace",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.RouteNamespace = "gateways"
+				c.GatewayPlugin.ExternalGateways[0].SupportedFeatures.Insert(features.SupportHTTPRouteRequestMirror)
+			},
+			ing: baseIngress(map[string]string{
+				MirrorBackendAnnotationKey: "canary:8080",
+			}),
+			expected: []*gatewayapi.HTTPRoute{func() *gatewayapi.HTTPRoute {
+				route := baseRoute(map[string]string{
+					MirrorBackendAnnotationKey: "canary:8080",
+				}, []gatewayapi.HTTPRouteFilter{{
+					Type: gatewayapi.HTTPRouteFilterRequestMirror,
+					RequestMirror: &gatewayapi.HTTPRequestMirrorFilter{
+						BackendRef: gatewayapi.BackendObjectReference{
+							Group:     ptr.To[gatewayapi.Group](""),
+							Kind:      ptr.To[gatewayapi.Kind]("Service"),
+							Name:      "canary",
+							Namespace: ptr.To[gatewayapi.Namespace](testNamespace),
+							Port:      ptr.To[gatewayapi.PortNumber](8080),
+						},
+					},
+				}})
+				route.Namespace = "gateways"
+				route.Labels[IngressNamespaceLabelKey] = testNamespace
+				for i := range route.Spec.Rules {
+					for j := range route.Spec.Rules[i].BackendRefs {
+						route.Spec.Rules[i].BackendRefs[j].Namespace = ptr.To[gatewayapi.Namespace](testNamespace)
+					}
+				}
+				return route
+			}()},
+		}, {
+			name: "route annotations of the gateway",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.ExternalGateways[0].RouteAnnotations = map[string]string{
+					"example.com/load-balancing": "round-robin",
+					MirrorBackendAnnotationKey:   "overridden",
+				}
+			},
+			ing: baseIngress(map[string]string{MirrorBackendAnnotationKey: "canary:8080"}),
+			expected: []*gatewayapi.HTTPRoute{func() *gatewayapi.HTTPRoute {
+				route := baseRoute(nil, nil)
+				// The annotations of the Ingress take precedence
+				route.Annotations = map[string]string{
+					"example.com/load-balancing": "round-robin",
+					MirrorBackendAnnotationKey:   "canary:8080",
+				}
+				return route
+			}()},
+		}, {
+			name: "non-Service backends of the gateway",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.ExternalGateways[0].BackendGroup = "gateway.envoyproxy.io"
+				c.GatewayPlugin.ExternalGateways[0].BackendKind = "Backend"
+			},
+			ing: baseIngress(nil),
+			expected: []*gatewayapi.HTTPRoute{func() *gatewayapi.HTTPRoute {
+				route := baseRoute(nil, nil)
+				for i := range route.Spec.Rules {
+					for j := range route.Spec.Rules[i].BackendRefs {
+						route.Spec.Rules[i].BackendRefs[j].Group = ptr.To[gatewayapi.Group]("gateway.envoyproxy.io")
+						route.Spec.Rules[i].BackendRefs[j].Kind = ptr.To[gatewayapi.Kind]("Backend")
+					}
+				}
+				return route
+			}()},
+		}, {
+			name: "mirror to non-Service backends of the gateway",
+			changeConfig: func(c *config.Config) {
+				c.GatewayPlugin.ExternalGateways[0].BackendGroup = "gateway.envoyproxy.io"
+				c.GatewayPlugin.ExternalGateways[0].BackendKind = "Backend"
+				c.GatewayPlugin.ExternalGateways[0].SupportedFeatures.Insert(features.SupportHTTPRouteRequestMirror)
+			},
+			ing: baseIngress(map[string]string{
+				MirrorBackendAnnotationKey: "canary:8080",
+			}),
+			expected: []*gatewayapi.HTTPRoute{func() *gatewayapi.HTTPRoute {
+				route := baseRoute(map[string]string{
+					MirrorBackendAnnotationKey: "canary:8080",
+				}, []gatewayapi.HTTPRouteFilter{{
+					Type: gatewayapi.HTTPRouteFilterRequestMirror,
+					RequestMirror: &gatewayapi.HTTPRequestMirrorFilter{
+						BackendRef: gatewayapi.BackendObjectReference{
+							Group: ptr.To[gatewayapi.Group]("gateway.envoyproxy.io"),
+							Kind:  ptr.To[gatewayapi.Kind]("Backend"),
+							Name:  "canary",
+							Port:  ptr.To[gatewayapi.PortNumber](8080),
+						},
+					},
+				}})
+				for i := range route.Spec.Rules {
+					for j := range route.Spec.Rules[i].BackendRefs {
+						route.Spec.Rules[i].BackendRefs[j].Group = ptr.To[gatewayapi.Group]("gateway.envoyproxy.io")
+						route.Spec.Rules[i].BackendRefs[j].Kind = ptr.To[gatewayapi.Kind]("Backend")
+					}
+				}
+				return route
+			}()},
+		}, {
+			name: "tagged path",
+			ing: func() *v1alpha1.Ingress {
+				ing := baseIngress(nil)
+				paths := &ing.Spec.Rules[0].HTTP.Paths
+				// The requests of the tag go to its revision, the others
+				// are split
+				*paths = append([]v1alpha1.HTTPIngressPath{{
+					Headers: map[string]v1alpha1.HeaderMatch{
+						header.RouteTagKey: {Exact: "canary"},
+					},
+					AppendHeaders: map[string]string{
+						header.RouteTagKey: "canary",
+					},
+					Splits: []v1alpha1.IngressBackendSplit{{
+						IngressBackend: v1alpha1.IngressBackend{
+							ServiceName:      "doo",
+							ServiceNamespace: testNamespace,
+							ServicePort:      intstr.FromInt(80),
+						},
+						Percent: 100,
+					}},
+				}}, *paths...)
+				return ing
+			}(),
+			expected: []*gatewayapi.HTTPRoute{func() *gatewayapi.HTTPRoute {
+				route := baseRoute(nil, nil)
+				tagged := *route.Spec.Rules[0].DeepCopy()
+				tagged.BackendRefs = tagged.BackendRefs[1:]
+				tagged.BackendRefs[0].Weight = ptr.To[int32](100)
+				tagged.Matches[0].Headers = []gatewayapi.HTTPHeaderMatch{{
+					Type:  ptr.To(gatewayapi.HeaderMatchExact),
+					Name:  header.RouteTagKey,
+					Value: "canary",
+				}}
+				tagged.Filters = []gatewayapi.HTTPRouteFilter{{
+					Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
+					RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
+						Set: []gatewayapi.HTTPHeader{{
+							Name:  header.RouteTagKey,
+							Value: "canary",
+						}},
+					},
+				}}
+				route.Spec.Rules = append([]gatewayapi.HTTPRouteRule{tagged}, route.Spec.Rules...)
+				return route
+			}()},
+		}, {
+			name: "hosts split across routes",
+			ing:  manyHostsIngress(MaxHTTPRouteHostnames + 4),
+			expected: []*gatewayapi.HTTPRoute{
+				manyHostsRoute("host-19.example.com", 4, 19),
+				manyHostsRoute("host-03.example.com-part-1", 0, 3),
+			},
+		}, {
+			name:          "cluster local with custom cluster domain",
+			clusterDomain: "example.internal",
+			ing:           clusterLocalIngress(),
+			expected: []*gatewayapi.HTTPRoute{clusterLocalRoute(
+				localHostShortest, localHostShort, "hello-example.default.svc.example.internal")},
+		}, {
+			name:     "cluster local with default cluster domain",
+			ing:      clusterLocalIngress(),
+			expected: []*gatewayapi.HTTPRoute{clusterLocalRoute(localHostShortest, localHostShort, localHostFull)},
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			for i, rule := range tc.ing.Spec.Rules {
+			if tc.clusterDomain != "" {
+				clusterDomainName = func() string { return tc.clusterDomain }
+				t.Cleanup(func() { clusterDomainName = network.GetClusterDomainName })
+			}
+
+			var parts []v1alpha1.IngressRule
+			for _, rule := range tc.ing.Spec.Rules {
+				parts = append(parts, SplitHTTPRouteRule(&rule, 0)...)
+			}
+			for i, rule := range parts {
 				cfg := testConfig.DeepCopy()
 				if tc.changeConfig != nil {
 					tc.changeConfig(cfg)
@@ -632,11 +1221,32 @@ func TestMakeHTTPRoute(t *testing.T) {
 				tcs := &testConfigStore{config: cfg}
 				ctx := tcs.ToContext(context.Background())
 
-				route, err := MakeHTTPRoute(ctx, tc.ing, &rule)
+				// The parts of a rule follow its first one
+				part := 0
+				if i > 0 && parts[i-1].Visibility == rule.Visibility {
+					part = i
+				}
+				route, err := MakeHTTPRoute(ctx, tc.ing, &rule, WithHTTPRoutePart(part))
 				if err != nil {
 					t.Fatal("MakeHTTPRoute failed:", err)
 				}
-				tc.expected[i].OwnerReferences = []metav1.OwnerReference{*kmeta.NewControllerRef(tc.ing)}
+				if tc.expected[i].Namespace == tc.ing.Namespace {
+					tc.expected[i].OwnerReferences = []metav1.OwnerReference{*kmeta.NewControllerRef(tc.ing)}
+				}
+				tc.expected[i].Labels = kmeta.UnionMaps(tc.expected[i].Labels,
+					map[string]string{IngressUIDLabelKey: string(tc.ing.UID)})
+				inputsHash, err := HTTPRouteInputsHash(ctx, tc.ing, &rule, part)
+				if err != nil {
+					t.Fatal("HTTPRouteInputsHash failed:", err)
+				}
+				specHash, err := HTTPRouteSpecHash(tc.expected[i].Spec)
+				if err != nil {
+					t.Fatal("HTTPRouteSpecHash failed:", err)
+				}
+				tc.expected[i].Annotations = kmeta.UnionMaps(tc.expected[i].Annotations, map[string]string{
+					InputsHashAnnotationKey: inputsHash,
+					SpecHashAnnotationKey:   specHash,
+				})
 				if diff := cmp.Diff(tc.expected[i], route); diff != "" {
 					t.Error("Unexpected HTTPRoute (-want +got):", diff)
 				}
@@ -645,6 +1255,794 @@ func TestMakeHTTPRoute(t *testing.T) {
 	}
 }
 
+func TestMakeHTTPRouteMirrorErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+		want        string
+	}{{
+		name:        "percent without backend",
+		annotations: map[string]string{MirrorPercentAnnotationKey: "10"},
+		want:        `annotation "gateway-api.networking.knative.dev/mirror-percent" requires "gateway-api.networking.knative.dev/mirror-backend"`,
+	}, {
+		name:        "missing port",
+		annotations: map[string]string{MirrorBackendAnnotationKey: "canary"},
+		want:        `annotation "gateway-api.networking.knative.dev/mirror-backend" must be of the form name:port, was: "canary"`,
+	}, {
+		name:        "bad service name",
+		annotations: map[string]string{MirrorBackendAnnotationKey: "Canary_1:80"},
+		want:        `annotation "gateway-api.networking.knative.dev/mirror-backend" has an invalid Service name "Canary_1": a DNS-1035 label must consist of lower case alphanumeric characters or '-', start with an alphabetic character, and end with an alphanumeric character (e.g. 'my-name',  or 'abc-123', regex used for validation is '[a-z]([-a-z0-9]*[a-z0-9])?')`,
+	}, {
+		name:        "bad port",
+		annotations: map[string]string{MirrorBackendAnnotationKey: "canary:0"},
+		want:        `annotation "gateway-api.networking.knative.dev/mirror-backend" has an invalid port "0"`,
+	}, {
+		name: "bad percent",
+		annotations: map[string]string{
+			MirrorBackendAnnotationKey: "canary:80",
+			MirrorPercentAnnotationKey: "101",
+		},
+		want: `annotation "gateway-api.networking.knative.dev/mirror-percent" must be an integer between 0 and 100, was: "101"`,
+	}, {
+		name:        "backend receives traffic",
+		annotations: map[string]string{MirrorBackendAnnotationKey: "goo:80"},
+		want:        `mirror backend "goo" already receives traffic from the Ingress`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ing := baseIngress(tc.annotations)
+			ctx := (&testConfigStore{config: testConfig}).ToContext(context.Background())
+
+			_, err := MakeHTTPRoute(ctx, ing, &ing.Spec.Rules[0])
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("MakeHTTPRoute() = %v, want: %s", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestMakeHTTPRouteRedirectErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+		want        string
+	}{{
+		name:        "status code without redirect",
+		annotations: map[string]string{RedirectStatusCodeAnnotationKey: "301"},
+		want:        `annotation "gateway-api.networking.knative.dev/redirect-status-code" requires "gateway-api.networking.knative.dev/redirect"`,
+	}, {
+		name: "redirect with mirror backend",
+		annotations: map[string]string{
+			RedirectAnnotationKey:      "/new-path",
+			MirrorBackendAnnotationKey: "canary:80",
+		},
+		want: `annotation "gateway-api.networking.knative.dev/redirect" can't be used with "gateway-api.networking.knative.dev/mirror-backend"`,
+	}, {
+		name:        "empty redirect",
+		annotations: map[string]string{RedirectAnnotationKey: ""},
+		want:        `annotation "gateway-api.networking.knative.dev/redirect" must be of the form [scheme:][//host[:port]][/path], was: ""`,
+	}, {
+		name:        "redirect with a query",
+		annotations: map[string]string{RedirectAnnotationKey: "/new-path?foo=bar"},
+		want:        `annotation "gateway-api.networking.knative.dev/redirect" must be of the form [scheme:][//host[:port]][/path], was: "/new-path?foo=bar"`,
+	}, {
+		name:        "bad scheme",
+		annotations: map[string]string{RedirectAnnotationKey: "ftp://new.example.com"},
+		want:        `annotation "gateway-api.networking.knative.dev/redirect" has an invalid scheme "ftp", must be http or https`,
+	}, {
+		name:        "bad host",
+		annotations: map[string]string{RedirectAnnotationKey: "//New_Host"},
+		want:        `annotation "gateway-api.networking.knative.dev/redirect" has an invalid host "New_Host": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+	}, {
+		name:        "bad port",
+		annotations: map[string]string{RedirectAnnotationKey: "//new.example.com:0"},
+		want:        `annotation "gateway-api.networking.knative.dev/redirect" has an invalid port "0"`,
+	}, {
+		name:        "relative path",
+		annotations: map[string]string{RedirectAnnotationKey: "new-path"},
+		want:        `annotation "gateway-api.networking.knative.dev/redirect" has a relative path "new-path"`,
+	}, {
+		name: "bad status code",
+		annotations: map[string]string{
+			RedirectAnnotationKey:           "/new-path",
+			RedirectStatusCodeAnnotationKey: "307",
+		},
+		want: `annotation "gateway-api.networking.knative.dev/redirect-status-code" must be 301 or 302, was: "307"`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ing := baseIngress(tc.annotations)
+			ctx := (&testConfigStore{config: testConfig}).ToContext(context.Background())
+
+			_, err := MakeHTTPRoute(ctx, ing, &ing.Spec.Rules[0])
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("MakeHTTPRoute() = %v, want: %s", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestMakeHTTPRouteInvalidRewriteHost(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		rewriteHost string
+		want        string
+	}{{
+		name:        "wildcard",
+		rewriteHost: "*.example.com",
+		want:        `invalid rewrite host "*.example.com": a lowercase RFC 1123 subdomain must consist of`,
+	}, {
+		name:        "not a hostname",
+		rewriteHost: "Example_Host",
+		want:        `invalid rewrite host "Example_Host": a lowercase RFC 1123 subdomain must consist of`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ing := baseIngress(nil)
+			ing.Spec.Rules[0].HTTP.Paths[0].RewriteHost = tc.rewriteHost
+			ctx := (&testConfigStore{config: testConfig}).ToContext(context.Background())
+
+			_, err := MakeHTTPRoute(ctx, ing, &ing.Spec.Rules[0])
+			if !errors.Is(err, ErrInvalidRewriteHost) || !strings.HasPrefix(err.Error(), tc.want) {
+				t.Errorf("MakeHTTPRoute() = %v, want: %s...", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestMakeHTTPRouteInvalidPreserveHost(t *testing.T) {
+	ing := baseIngress(map[string]string{PreserveHostAnnotationKey: "yes"})
+	ctx := (&testConfigStore{config: testConfig}).ToContext(context.Background())
+
+	want := `annotation "gateway-api.networking.knative.dev/preserve-host" must be a boolean, was: "yes"`
+	if _, err := MakeHTTPRoute(ctx, ing, &ing.Spec.Rules[0]); err == nil || err.Error() != want {
+		t.Errorf("MakeHTTPRoute() = %v, want: %s", err, want)
+	}
+}
+
+func TestMakeHTTPRoutePreserveRewriteHost(t *testing.T) {
+	ing := baseIngress(map[string]string{PreserveHostAnnotationKey: "true"})
+	ing.Spec.Rules[0].Hosts = append(ing.Spec.Rules[0].Hosts, "other.example.com")
+	ing.Spec.Rules[0].HTTP.Paths[0].RewriteHost = "hello-example.example.com"
+	ctx := (&testConfigStore{config: testConfig}).ToContext(context.Background())
+
+	// The original host of the requests of a rule with several hosts can't
+	// be kept in a header with a fixed value
+	if _, err := MakeHTTPRoute(ctx, ing, &ing.Spec.Rules[0]); !errors.Is(err, ErrPreservedRewriteHost) {
+		t.Errorf("MakeHTTPRoute() = %v, want: %v", err, ErrPreservedRewriteHost)
+	}
+}
+
+func TestMakeHTTPRouteSessionPersistence(t *testing.T) {
+	ing := baseIngress(map[string]string{SessionCookieAnnotationKey: "session"})
+	ing.Spec.Rules[0].HTTP.Paths = append(ing.Spec.Rules[0].HTTP.Paths, v1alpha1.HTTPIngressPath{
+		Headers: map[string]v1alpha1.HeaderMatch{header.HashKey: {Exact: "hash"}},
+		Splits:  ing.Spec.Rules[0].HTTP.Paths[0].Splits,
+	})
+
+	cfg := testConfig.DeepCopy()
+	cfg.GatewayPlugin.ExternalGateways[0].SupportedFeatures.Insert(SupportHTTPRouteSessionPersistence)
+	ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+
+	route, err := MakeHTTPRoute(ctx, ing, &ing.Spec.Rules[0])
+	if err != nil {
+		t.Fatal("MakeHTTPRoute() =", err)
+	}
+
+	// The session cookie only lasts for the browser session without a lifetime
+	want := &gatewayapi.SessionPersistence{
+		SessionName: ptr.To("session"),
+		Type:        ptr.To(gatewayapi.CookieBasedSessionPersistence),
+		CookieConfig: &gatewayapi.CookieConfig{
+			LifetimeType: ptr.To(gatewayapi.SessionCookieLifetimeType),
+		},
+	}
+	for _, rule := range route.Spec.Rules {
+		probe := len(rule.Matches[0].Headers) > 0
+		if probe && rule.SessionPersistence != nil {
+			t.Errorf("SessionPersistence of the probe rule = %v, want: nil", rule.SessionPersistence)
+		}
+		if !probe && !cmp.Equal(rule.SessionPersistence, want) {
+			t.Errorf("SessionPersistence = %v, want: %v", rule.SessionPersistence, want)
+		}
+	}
+}
+
+func TestMakeHTTPRouteSessionPersistenceErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+		want        string
+	}{{
+		name:        "lifetime without cookie",
+		annotations: map[string]string{SessionLifetimeAnnotationKey: "1h"},
+		want:        `annotation "gateway-api.networking.knative.dev/session-lifetime" requires "gateway-api.networking.knative.dev/session-cookie"`,
+	}, {
+		name:        "empty cookie name",
+		annotations: map[string]string{SessionCookieAnnotationKey: ""},
+		want:        `annotation "gateway-api.networking.knative.dev/session-cookie" must have between 1 and 128 characters, was: ""`,
+	}, {
+		name:        "bad cookie name",
+		annotations: map[string]string{SessionCookieAnnotationKey: "my session"},
+		want:        `annotation "gateway-api.networking.knative.dev/session-cookie" has an invalid cookie name "my session": a valid HTTP header must consist of alphanumeric characters or '-' (e.g. 'X-Header-Name', regex used for validation is '[-A-Za-z0-9]+')`,
+	}, {
+		name: "bad lifetime",
+		annotations: map[string]string{
+			SessionCookieAnnotationKey:   "session",
+			SessionLifetimeAnnotationKey: "1.5h",
+		},
+		want: `annotation "gateway-api.networking.knative.dev/session-lifetime" must be a positive duration such as 1h or 30m, was: "1.5h"`,
+	}, {
+		name: "zero lifetime",
+		annotations: map[string]string{
+			SessionCookieAnnotationKey:   "session",
+			SessionLifetimeAnnotationKey: "0s",
+		},
+		want: `annotation "gateway-api.networking.knative.dev/session-lifetime" must be a positive duration such as 1h or 30m, was: "0s"`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ing := baseIngress(tc.annotations)
+			ctx := (&testConfigStore{config: testConfig}).ToContext(context.Background())
+
+			_, err := MakeHTTPRoute(ctx, ing, &ing.Spec.Rules[0])
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("MakeHTTPRoute() = %v, want: %s", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestMakeHTTPRouteHeaderRemovalErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+		want        string
+	}{{
+		name:        "not a JSON object",
+		annotations: map[string]string{RemoveRequestHeadersAnnotationKey: `["X-Debug"]`},
+		want:        `annotation "gateway-api.networking.knative.dev/remove-request-headers" must be a JSON object of Service names to header names: json: cannot unmarshal array into Go value of type map[string][]string`,
+	}, {
+		name:        "bad service name",
+		annotations: map[string]string{RemoveResponseHeadersAnnotationKey: `{"Goo": ["Server"]}`},
+		want:        `annotation "gateway-api.networking.knative.dev/remove-response-headers" has an invalid Service name "Goo": a DNS-1035 label must consist of lower case alphanumeric characters or '-', start with an alphabetic character, and end with an alphanumeric character (e.g. 'my-name',  or 'abc-123', regex used for validation is '[a-z]([-a-z0-9]*[a-z0-9])?')`,
+	}, {
+		name:        "bad header name",
+		annotations: map[string]string{RemoveRequestHeadersAnnotationKey: `{"goo": ["X Debug"]}`},
+		want:        `annotation "gateway-api.networking.knative.dev/remove-request-headers" has an invalid header name "X Debug": a valid HTTP header must consist of alphanumeric characters or '-' (e.g. 'X-Header-Name', regex used for validation is '[-A-Za-z0-9]+')`,
+	}, {
+		name:        "probe header",
+		annotations: map[string]string{RemoveRequestHeadersAnnotationKey: `{"goo": ["k-network-hash"]}`},
+		want:        `annotation "gateway-api.networking.knative.dev/remove-request-headers" must not remove "k-network-hash", it is used for probing`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ing := baseIngress(tc.annotations)
+			ctx := (&testConfigStore{config: testConfig}).ToContext(context.Background())
+
+			_, err := MakeHTTPRoute(ctx, ing, &ing.Spec.Rules[0])
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("MakeHTTPRoute() = %v, want: %s", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestMakeHTTPRouteAnnotatedPathErrors(t *testing.T) {
+	ing := baseIngress(map[string]string{DenyPathsAnnotationKey: "/admin,internal"})
+	ctx := (&testConfigStore{config: testConfig}).ToContext(context.Background())
+
+	want := `annotation "gateway-api.networking.knative.dev/deny-paths" has an invalid path "internal", it must start with "/"`
+	if _, err := MakeHTTPRoute(ctx, ing, &ing.Spec.Rules[0]); err == nil || err.Error() != want {
+		t.Errorf("MakeHTTPRoute() = %v, want: %s", err, want)
+	}
+
+	ing = baseIngress(map[string]string{ExactPathsAnnotationKey: ""})
+	want = `annotation "gateway-api.networking.knative.dev/exact-paths" has an invalid path "", it must start with "/"`
+	if _, err := MakeHTTPRoute(ctx, ing, &ing.Spec.Rules[0]); err == nil || err.Error() != want {
+		t.Errorf("MakeHTTPRoute() = %v, want: %s", err, want)
+	}
+}
+
+func TestHTTPRouteInputsHash(t *testing.T) {
+	hash := func(ing *v1alpha1.Ingress, cfg *config.Config, part int) string {
+		t.Helper()
+		ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+		got, err := HTTPRouteInputsHash(ctx, ing, &ing.Spec.Rules[0], part)
+		if err != nil {
+			t.Fatal("HTTPRouteInputsHash() =", err)
+		}
+		return got
+	}
+	want := hash(testIngress, testConfig, 0)
+
+	for _, tc := range []struct {
+		name          string
+		changeIngress func(*v1alpha1.Ingress)
+		changeConfig  func(*config.Config)
+		part          int
+		changed       bool
+	}{{
+		name: "same inputs",
+	}, {
+		name: "rule changed",
+		changeIngress: func(ing *v1alpha1.Ingress) {
+			ing.Spec.Rules[0].HTTP.Paths[0].Splits[0].Percent = 50
+		},
+		changed: true,
+	}, {
+		name: "annotation changed",
+		changeIngress: func(ing *v1alpha1.Ingress) {
+			ing.Annotations = map[string]string{MirrorBackendAnnotationKey: "canary:8080"}
+		},
+		changed: true,
+	}, {
+		name: "HTTP option changed",
+		changeIngress: func(ing *v1alpha1.Ingress) {
+			ing.Spec.HTTPOption = v1alpha1.HTTPOptionRedirected
+		},
+		changed: true,
+	}, {
+		name:    "part changed",
+		part:    1,
+		changed: true,
+	}, {
+		name: "gateway features changed",
+		changeConfig: func(cfg *config.Config) {
+			cfg.GatewayPlugin.ExternalGateways[0].SupportedFeatures = sets.New(features.SupportHTTPRouteRequestTimeout)
+		},
+		changed: true,
+	}, {
+		name: "route annotations changed",
+		changeConfig: func(cfg *config.Config) {
+			cfg.GatewayPlugin.ExternalGateways[0].RouteAnnotations = map[string]string{"example.com/mode": "fast"}
+		},
+		changed: true,
+	}, {
+		name: "route namespace changed",
+		changeConfig: func(cfg *config.Config) {
+			cfg.GatewayPlugin.RouteNamespace = "routes"
+		},
+		changed: true,
+	}, {
+		name: "endpoint probe headers changed",
+		changeConfig: func(cfg *config.Config) {
+			cfg.GatewayPlugin.EndpointProbeHeaders.Revision = "X-Revision"
+		},
+		changed: true,
+	}, {
+		name: "deny filter changed",
+		changeConfig: func(cfg *config.Config) {
+			cfg.GatewayPlugin.ExternalGateways[0].DenyFilter = &gatewayapi.LocalObjectReference{
+				Kind: "HTTPRouteFilter",
+				Name: "not-found",
+			}
+		},
+		changed: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ing := testIngress.DeepCopy()
+			if tc.changeIngress != nil {
+				tc.changeIngress(ing)
+			}
+			cfg := testConfig.DeepCopy()
+			if tc.changeConfig != nil {
+				tc.changeConfig(cfg)
+			}
+
+			if got := hash(ing, cfg, tc.part); (got != want) != tc.changed {
+				t.Errorf("HTTPRouteInputsHash() = %s, original: %s, want changed: %v", got, want, tc.changed)
+			}
+		})
+	}
+
+	t.Run("cluster domain changed", func(t *testing.T) {
+		clusterDomainName = func() string { return "example.local" }
+		t.Cleanup(func() { clusterDomainName = network.GetClusterDomainName })
+
+		if got := hash(testIngress, testConfig, 0); got == want {
+			t.Errorf("HTTPRouteInputsHash() = %s, want changed", got)
+		}
+	})
+}
+
+func TestMakeHTTPRouteHashAnnotations(t *testing.T) {
+	ctx := (&testConfigStore{config: testConfig}).ToContext(context.Background())
+	ing := testIngress.DeepCopy()
+
+	route, err := MakeHTTPRoute(ctx, ing, &ing.Spec.Rules[0])
+	if err != nil {
+		t.Fatal("MakeHTTPRoute() =", err)
+	}
+
+	inputsHash, err := HTTPRouteInputsHash(ctx, ing, &ing.Spec.Rules[0], 0)
+	if err != nil {
+		t.Fatal("HTTPRouteInputsHash() =", err)
+	}
+	if got := route.Annotations[InputsHashAnnotationKey]; got != inputsHash {
+		t.Errorf("Inputs hash annotation = %s, want: %s", got, inputsHash)
+	}
+
+	specHash, err := HTTPRouteSpecHash(route.Spec)
+	if err != nil {
+		t.Fatal("HTTPRouteSpecHash() =", err)
+	}
+	if got := route.Annotations[SpecHashAnnotationKey]; got != specHash {
+		t.Errorf("Spec hash annotation = %s, want: %s", got, specHash)
+	}
+
+	// The spec hash changes with the spec, e.g. when edited by others.
+	route.Spec.Hostnames = append(route.Spec.Hostnames, "unmanaged.example.com")
+	if got, err := HTTPRouteSpecHash(route.Spec); err != nil || got == specHash {
+		t.Errorf("HTTPRouteSpecHash() = %s, %v, want changed", got, err)
+	}
+}
+
+// baseIngress is an Ingress with a single external rule split between two
+// backends, with the annotations.
+func baseIngress(annotations map[string]string) *v1alpha1.Ingress {
+	return &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testIngressName,
+			Namespace: testNamespace,
+			Labels: map[string]string{
+				networking.IngressLabelKey: testIngressName,
+			},
+			Annotations: annotations,
+		},
+		Spec: v1alpha1.IngressSpec{Rules: []v1alpha1.IngressRule{{
+			Hosts:      testHosts,
+			Visibility: v1alpha1.IngressVisibilityExternalIP,
+			HTTP: &v1alpha1.HTTPIngressRuleValue{
+				Paths: []v1alpha1.HTTPIngressPath{{
+					Splits: []v1alpha1.IngressBackendSplit{{
+						IngressBackend: v1alpha1.IngressBackend{
+							ServiceName:      "goo",
+							ServiceNamespace: testNamespace,
+							ServicePort:      intstr.FromInt(80),
+						},
+						Percent: 60,
+					}, {
+						IngressBackend: v1alpha1.IngressBackend{
+							ServiceName:      "doo",
+							ServiceNamespace: testNamespace,
+							ServicePort:      intstr.FromInt(80),
+						},
+						Percent: 40,
+					}},
+				}},
+			},
+		}}},
+	}
+}
+
+// duplicateMatchIngress adds a path to the baseIngress with the same match
+// as its existing one, rewriting the host when rewriteHost isn't empty.
+func duplicateMatchIngress(rewriteHost string) *v1alpha1.Ingress {
+	ing := baseIngress(nil)
+	rule := &ing.Spec.Rules[0]
+	rule.HTTP.Paths = append(rule.HTTP.Paths, v1alpha1.HTTPIngressPath{
+		Path:        "/",
+		RewriteHost: rewriteHost,
+		Splits: []v1alpha1.IngressBackendSplit{{
+			IngressBackend: v1alpha1.IngressBackend{
+				ServiceName:      "old",
+				ServiceNamespace: testNamespace,
+				ServicePort:      intstr.FromInt(80),
+			},
+			Percent: 100,
+		}},
+	})
+	return ing
+}
+
+func TestMakeHTTPRouteConflictingMatches(t *testing.T) {
+	ctx := (&testConfigStore{config: testConfig}).ToContext(context.Background())
+	ing := duplicateMatchIngress("other.example.com")
+
+	_, err := MakeHTTPRoute(ctx, ing, &ing.Spec.Rules[0])
+	if want := `paths with identical matches for "/" have different filters`; err == nil || err.Error() != want {
+		t.Fatalf("MakeHTTPRoute() error = %v, want: %s", err, want)
+	}
+}
+
+// labelledIngress adds labels to the baseIngress.
+func labelledIngress() *v1alpha1.Ingress {
+	ing := baseIngress(nil)
+	ing.Labels["serving.knative.dev/route"] = "route"
+	ing.Labels["team"] = "blue"
+	return ing
+}
+
+// labelledRoute returns the route of the labelledIngress with the given
+// labels propagated, in addition to the IngressLabelKey one.
+func labelledRoute(keys ...string) *gatewayapi.HTTPRoute {
+	labels := labelledIngress().Labels
+	route := baseRoute(nil, nil)
+	for _, key := range keys {
+		route.Labels[key] = labels[key]
+	}
+	return route
+}
+
+// drainingIngress adds a zero percent split, as for a revision being
+// drained, to the baseIngress.
+func drainingIngress() *v1alpha1.Ingress {
+	ing := baseIngress(nil)
+	path := &ing.Spec.Rules[0].HTTP.Paths[0]
+	path.Splits = append(path.Splits, v1alpha1.IngressBackendSplit{
+		IngressBackend: v1alpha1.IngressBackend{
+			ServiceName:      "old",
+			ServiceNamespace: testNamespace,
+			ServicePort:      intstr.FromInt(80),
+		},
+	})
+	return ing
+}
+
+// canaryIngress is the baseIngress with a 1% canary split.
+func canaryIngress() *v1alpha1.Ingress {
+	ing := baseIngress(nil)
+	path := &ing.Spec.Rules[0].HTTP.Paths[0]
+	path.Splits[0].Percent = 99
+	path.Splits[1].Percent = 1
+	return ing
+}
+
+// canaryRoute returns the route of the canaryIngress with the given weights.
+func canaryRoute(stable, canary int32) *gatewayapi.HTTPRoute {
+	route := baseRoute(nil, nil)
+	route.Spec.Rules[0].BackendRefs[0].Weight = ptr.To(stable)
+	route.Spec.Rules[0].BackendRefs[1].Weight = ptr.To(canary)
+	return route
+}
+
+// drainingRoute returns the route of the drainingIngress, with the backend of
+// the zero percent split only when its weight isn't nil.
+func drainingRoute(weight *int32) *gatewayapi.HTTPRoute {
+	route := baseRoute(nil, nil)
+	if weight != nil {
+		rule := &route.Spec.Rules[0]
+		backend := rule.BackendRefs[0].DeepCopy()
+		backend.Name = "old"
+		backend.Weight = weight
+		rule.BackendRefs = append(rule.BackendRefs, *backend)
+	}
+	return route
+}
+
+// clusterLocalIngress is the baseIngress with a single cluster-local rule.
+func clusterLocalIngress() *v1alpha1.Ingress {
+	ing := baseIngress(nil)
+	ing.Spec.Rules[0].Hosts = testLocalHosts
+	ing.Spec.Rules[0].Visibility = v1alpha1.IngressVisibilityClusterLocal
+	return ing
+}
+
+// manyHostsIngress is the baseIngress with n hosts.
+func manyHostsIngress(n int) *v1alpha1.Ingress {
+	ing := baseIngress(nil)
+	ing.Spec.Rules[0].Hosts = make([]string, 0, n)
+	for i := range n {
+		ing.Spec.Rules[0].Hosts = append(ing.Spec.Rules[0].Hosts, fmt.Sprintf("host-%02d.example.com", i))
+	}
+	return ing
+}
+
+// manyHostsRoute is the route of the manyHostsIngress with the given name
+// and the hosts from first to last.
+func manyHostsRoute(name string, first, last int) *gatewayapi.HTTPRoute {
+	route := baseRoute(nil, nil)
+	route.Name = name
+	route.Spec.Hostnames = nil
+	for i := first; i <= last; i++ {
+		route.Spec.Hostnames = append(route.Spec.Hostnames, gatewayapi.Hostname(fmt.Sprintf("host-%02d.example.com", i)))
+	}
+	return route
+}
+
+// clusterLocalRoute is the route of the clusterLocalIngress with the given
+// hostnames.
+func clusterLocalRoute(hostnames ...gatewayapi.Hostname) *gatewayapi.HTTPRoute {
+	route := baseRoute(nil, nil)
+	route.Name = LongestHost(testLocalHosts)
+	route.Labels["networking.knative.dev/visibility"] = "cluster-local"
+	route.Spec.Hostnames = hostnames
+	route.Spec.ParentRefs[0].Name = "foo-local"
+	return route
+}
+
+// baseRoute is the HTTPRoute of the baseIngress with the annotations, with
+// the filters on its rule.
+func baseRoute(annotations map[string]string, filters []gatewayapi.HTTPRouteFilter) *gatewayapi.HTTPRoute {
+	backendRef := func(name string, weight int32) gatewayapi.HTTPBackendRef {
+		return gatewayapi.HTTPBackendRef{
+			BackendRef: gatewayapi.BackendRef{
+				BackendObjectReference: gatewayapi.BackendObjectReference{
+					Group: (*gatewayapi.Group)(ptr.To("")),
+					Kind:  (*gatewayapi.Kind)(ptr.To("Service")),
+					Name:  gatewayapi.ObjectName(name),
+					Port:  ptr.To[gatewayapi.PortNumber](80),
+				},
+				Weight: ptr.To(weight),
+			},
+			Filters: []gatewayapi.HTTPRouteFilter{{
+				Type: gatewayapi.HTTPRouteFilterRequestHeaderModifier,
+				RequestHeaderModifier: &gatewayapi.HTTPHeaderFilter{
+					Set: []gatewayapi.HTTPHeader{},
+				},
+			}},
+		}
+	}
+
+	return &gatewayapi.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      LongestHost(testHosts),
+			Namespace: testNamespace,
+			Labels: map[string]string{
+				networking.IngressLabelKey:          testIngressName,
+				IngressUIDLabelKey:                  "",
+				"networking.knative.dev/visibility": "",
+			},
+			Annotations: annotations,
+		},
+		Spec: gatewayapi.HTTPRouteSpec{
+			Hostnames: []gatewayapi.Hostname{externalHost},
+			Rules: []gatewayapi.HTTPRouteRule{{
+				Filters:     filters,
+				BackendRefs: []gatewayapi.HTTPBackendRef{backendRef("goo", 60), backendRef("doo", 40)},
+				Matches: []gatewayapi.HTTPRouteMatch{{
+					Path: &gatewayapi.HTTPPathMatch{
+						Type:  ptr.To(gatewayapi.PathMatchPathPrefix),
+						Value: ptr.To("/"),
+					},
+				}},
+			}},
+			CommonRouteSpec: gatewayapi.CommonRouteSpec{
+				ParentRefs: []gatewayapi.ParentReference{{
+					Group:     (*gatewayapi.Group)(ptr.To("gateway.networking.k8s.io")),
+					Kind:      (*gatewayapi.Kind)(ptr.To("Gateway")),
+					Namespace: ptr.To[gatewayapi.Namespace]("test-ns"),
+					Name:      gatewayapi.ObjectName("foo"),
+				}},
+			},
+		},
+	}
+}
+
+func TestSplitHTTPRouteRule(t *testing.T) {
+	paths := func(names ...string) []v1alpha1.HTTPIngressPath {
+		paths := make([]v1alpha1.HTTPIngressPath, 0, len(names))
+		for _, name := range names {
+			path := v1alpha1.HTTPIngressPath{Path: "/" + strings.TrimPrefix(name, "probe-")}
+			if strings.HasPrefix(name, "probe-") {
+				path.Headers = map[string]v1alpha1.HeaderMatch{
+					header.HashKey: {Exact: header.HashValueOverride},
+				}
+			}
+			paths = append(paths, path)
+		}
+		return paths
+	}
+	rule := func(paths []v1alpha1.HTTPIngressPath) v1alpha1.IngressRule {
+		return v1alpha1.IngressRule{
+			Hosts:      testHosts,
+			Visibility: v1alpha1.IngressVisibilityExternalIP,
+			HTTP:       &v1alpha1.HTTPIngressRuleValue{Paths: paths},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		paths    []v1alpha1.HTTPIngressPath
+		maxRules int
+		want     [][]v1alpha1.HTTPIngressPath
+	}{{
+		name:     "not split when unlimited",
+		paths:    paths("probe-a", "probe-b", "a", "b"),
+		maxRules: 0,
+		want:     [][]v1alpha1.HTTPIngressPath{paths("probe-a", "probe-b", "a", "b")},
+	}, {
+		name:     "not split within the limit",
+		paths:    paths("probe-a", "probe-b", "a", "b"),
+		maxRules: 4,
+		want:     [][]v1alpha1.HTTPIngressPath{paths("probe-a", "probe-b", "a", "b")},
+	}, {
+		name:     "probe paths kept with their path",
+		paths:    paths("probe-a", "probe-b", "probe-c", "a", "b", "c"),
+		maxRules: 5,
+		want: [][]v1alpha1.HTTPIngressPath{
+			paths("probe-a", "probe-b", "a", "b"),
+			paths("probe-c", "c"),
+		},
+	}, {
+		name:     "without probe paths",
+		paths:    paths("a", "b", "c", "d", "e"),
+		maxRules: 2,
+		want: [][]v1alpha1.HTTPIngressPath{
+			paths("a", "b"),
+			paths("c", "d"),
+			paths("e"),
+		},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := rule(tc.paths)
+			got := SplitHTTPRouteRule(&r, tc.maxRules)
+
+			want := make([]v1alpha1.IngressRule, 0, len(tc.want))
+			for _, paths := range tc.want {
+				want = append(want, rule(paths))
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Error("SplitHTTPRouteRule (-want, +got):", diff)
+			}
+		})
+	}
+}
+
+func TestMakeRedirectHTTPRoute(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		statusCode int
+		want       int
+	}{{
+		name: "default status code",
+		want: http.StatusMovedPermanently,
+	}, {
+		name:       "configured status code",
+		statusCode: http.StatusPermanentRedirect,
+		want:       http.StatusPermanentRedirect,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := testConfig.DeepCopy()
+			cfg.GatewayPlugin.HTTPSRedirectStatusCode = tc.statusCode
+			ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+
+			ing := baseIngress(nil)
+			route := MakeRedirectHTTPRoute(ctx, ing, &ing.Spec.Rules[0])
+
+			want := []gatewayapi.HTTPRouteFilter{{
+				Type: gatewayapi.HTTPRouteFilterRequestRedirect,
+				RequestRedirect: &gatewayapi.HTTPRequestRedirectFilter{
+					Scheme:     ptr.To("https"),
+					StatusCode: ptr.To(tc.want),
+				},
+			}}
+			if diff := cmp.Diff(want, route.Spec.Rules[0].Filters); diff != "" {
+				t.Error("Unexpected redirect filters (-want +got):", diff)
+			}
+		})
+	}
+}
+
+func TestMakeHTTPRoutePart(t *testing.T) {
+	tcs := &testConfigStore{config: testConfig}
+	ctx := tcs.ToContext(context.Background())
+
+	ing := testIngress.DeepCopy()
+	rule := &ing.Spec.Rules[0]
+
+	for part, want := range []string{
+		"hello-example.default.example.com",
+		"hello-example.default.example.com-part-1",
+	} {
+		if got := HTTPRouteName(rule, part); got != want {
+			t.Errorf("HTTPRouteName(%d) = %q, want %q", part, got, want)
+		}
+
+		route, err := MakeHTTPRoute(ctx, ing, rule, WithHTTPRoutePart(part))
+		if err != nil {
+			t.Fatal("MakeHTTPRoute() =", err)
+		}
+		if route.Name != want {
+			t.Errorf("MakeHTTPRoute(%d).Name = %q, want %q", part, route.Name, want)
+		}
+	}
+
+	// The names of the parts of long hosts are kept short enough
+	rule.Hosts = []string{strings.Repeat("a", 60) + ".example.com"}
+	if got := HTTPRouteName(rule, 1); len(got) > 63 {
+		t.Errorf("HTTPRouteName(1) = %q, want at most 63 characters", got)
+	}
+}
+
 func TestAddEndpointProbes(t *testing.T) {
 	tcs := &testConfigStore{config: testConfig}
 	ctx := tcs.ToContext(context.Background())
@@ -656,8 +2054,8 @@ func TestAddEndpointProbes(t *testing.T) {
 		t.Fatal("MakeHTTPRoute failed:", err)
 	}
 
-	AddEndpointProbe(route, "hash", rule.HTTP.Paths[0].Splits[0])
-	AddEndpointProbe(route, "hash", rule.HTTP.Paths[0].Splits[1])
+	AddEndpointProbe(route, "hash", rule.HTTP.Paths[0].Splits[0], testConfig.GatewayPlugin.ExternalGateway(), config.EndpointProbeHeaders{})
+	AddEndpointProbe(route, "hash", rule.HTTP.Paths[0].Splits[1], testConfig.GatewayPlugin.ExternalGateway(), config.EndpointProbeHeaders{})
 
 	expected := &gatewayapi.HTTPRoute{
 		ObjectMeta: metav1.ObjectMeta{
@@ -665,9 +2063,10 @@ func TestAddEndpointProbes(t *testing.T) {
 			Namespace: testNamespace,
 			Labels: map[string]string{
 				networking.IngressLabelKey:          testIngressName,
+				IngressUIDLabelKey:                  "",
 				"networking.knative.dev/visibility": "",
 			},
-			Annotations:     map[string]string{},
+			Annotations:     hashAnnotations(ctx, t, ing, rule),
 			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing)},
 		},
 		Spec: gatewayapi.HTTPRouteSpec{
@@ -838,6 +2237,145 @@ func TestAddEndpointProbes(t *testing.T) {
 	}
 }
 
+func TestOwnedHTTPRoutes(t *testing.T) {
+	other := testIngress.DeepCopy()
+	other.Name = "other-ingress"
+	other.UID = "other-uid"
+
+	route := func(name, namespace string, owner *v1alpha1.Ingress) *gatewayapi.HTTPRoute {
+		r := &gatewayapi.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		}
+		if owner != nil {
+			r.OwnerReferences = []metav1.OwnerReference{*kmeta.NewControllerRef(owner)}
+		}
+		return r
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, r := range []*gatewayapi.HTTPRoute{
+		route("b.example.com", testNamespace, testIngress),
+		route("a.example.com", testNamespace, testIngress),
+		route("other.example.com", testNamespace, other),
+		route("unowned.example.com", testNamespace, nil),
+		route("c.example.com", "other-ns", testIngress),
+	} {
+		indexer.Add(r)
+	}
+
+	ctx := (&testConfigStore{config: testConfig}).ToContext(context.Background())
+	got, err := OwnedHTTPRoutes(ctx, testIngress, gatewaylisters.NewHTTPRouteLister(indexer))
+	if err != nil {
+		t.Fatal("OwnedHTTPRoutes() =", err)
+	}
+	want := []*gatewayapi.HTTPRoute{
+		route("a.example.com", testNamespace, testIngress),
+		route("b.example.com", testNamespace, testIngress),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("Unexpected routes (-want +got):", diff)
+	}
+
+	// Routes in the configured route namespace are found by their labels
+	labelled := func(name string, ing *v1alpha1.Ingress) *gatewayapi.HTTPRoute {
+		r := route(name, "gateways", nil)
+		r.Labels = map[string]string{
+			networking.IngressLabelKey: ing.Name,
+			IngressNamespaceLabelKey:   ing.Namespace,
+		}
+		return r
+	}
+	indexer.Add(labelled("d.example.com", testIngress))
+	indexer.Add(labelled("e.example.com", other))
+
+	cfg := testConfig.DeepCopy()
+	cfg.GatewayPlugin.RouteNamespace = "gateways"
+	ctx = (&testConfigStore{config: cfg}).ToContext(context.Background())
+	got, err = OwnedHTTPRoutes(ctx, testIngress, gatewaylisters.NewHTTPRouteLister(indexer))
+	if err != nil {
+		t.Fatal("OwnedHTTPRoutes() =", err)
+	}
+	want = []*gatewayapi.HTTPRoute{
+		labelled("d.example.com", testIngress),
+		route("a.example.com", testNamespace, testIngress),
+		route("b.example.com", testNamespace, testIngress),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("Unexpected routes (-want +got):", diff)
+	}
+}
+
+func TestIsOwnedHTTPRoute(t *testing.T) {
+	other := testIngress.DeepCopy()
+	other.Name = "other-ingress"
+	other.UID = "other-uid"
+
+	owned := func(namespace string, owner *v1alpha1.Ingress) *gatewayapi.HTTPRoute {
+		return &gatewayapi.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "example.com",
+				Namespace:       namespace,
+				OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(owner)},
+			},
+		}
+	}
+	labelled := func(namespace string, ing *v1alpha1.Ingress) *gatewayapi.HTTPRoute {
+		return &gatewayapi.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "example.com",
+				Namespace: namespace,
+				Labels: map[string]string{
+					networking.IngressLabelKey: ing.Name,
+					IngressNamespaceLabelKey:   ing.Namespace,
+				},
+			},
+		}
+	}
+
+	cfg := testConfig.DeepCopy()
+	cfg.GatewayPlugin.RouteNamespace = "gateways"
+
+	for _, tc := range []struct {
+		name  string
+		cfg   *config.Config
+		route *gatewayapi.HTTPRoute
+		want  bool
+	}{{
+		name:  "controlled by the Ingress",
+		cfg:   testConfig,
+		route: owned(testNamespace, testIngress),
+		want:  true,
+	}, {
+		name:  "controlled by another Ingress",
+		cfg:   testConfig,
+		route: owned(testNamespace, other),
+	}, {
+		name:  "labelled in the Ingress namespace",
+		cfg:   testConfig,
+		route: labelled(testNamespace, testIngress),
+	}, {
+		name:  "labelled in the route namespace",
+		cfg:   cfg,
+		route: labelled("gateways", testIngress),
+		want:  true,
+	}, {
+		name:  "labelled for another Ingress in the route namespace",
+		cfg:   cfg,
+		route: labelled("gateways", other),
+	}, {
+		name:  "labelled outside of the route namespace",
+		cfg:   testConfig,
+		route: labelled("gateways", testIngress),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := (&testConfigStore{config: tc.cfg}).ToContext(context.Background())
+			if got := IsOwnedHTTPRoute(ctx, testIngress, tc.route); got != tc.want {
+				t.Errorf("IsOwnedHTTPRoute() = %v, want: %v", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestRemoveEndpointProbes(t *testing.T) {
 	tcs := &testConfigStore{config: testConfig}
 	ctx := tcs.ToContext(context.Background())
@@ -851,8 +2389,8 @@ func TestRemoveEndpointProbes(t *testing.T) {
 
 	expected := route.DeepCopy()
 
-	AddEndpointProbe(route, "hash", rule.HTTP.Paths[0].Splits[0])
-	AddEndpointProbe(route, "hash", rule.HTTP.Paths[0].Splits[1])
+	AddEndpointProbe(route, "hash", rule.HTTP.Paths[0].Splits[0], testConfig.GatewayPlugin.ExternalGateway(), config.EndpointProbeHeaders{})
+	AddEndpointProbe(route, "hash", rule.HTTP.Paths[0].Splits[1], testConfig.GatewayPlugin.ExternalGateway(), config.EndpointProbeHeaders{})
 	RemoveEndpointProbes(route)
 
 	if diff := cmp.Diff(expected, route); diff != "" {
@@ -870,8 +2408,8 @@ func TestUpdateProbeHash(t *testing.T) {
 		t.Fatal("MakeHTTPRoute failed:", err)
 	}
 
-	AddEndpointProbe(route, "hash", rule.HTTP.Paths[0].Splits[0])
-	AddEndpointProbe(route, "hash", rule.HTTP.Paths[0].Splits[1])
+	AddEndpointProbe(route, "hash", rule.HTTP.Paths[0].Splits[0], testConfig.GatewayPlugin.ExternalGateway(), config.EndpointProbeHeaders{})
+	AddEndpointProbe(route, "hash", rule.HTTP.Paths[0].Splits[1], testConfig.GatewayPlugin.ExternalGateway(), config.EndpointProbeHeaders{})
 	UpdateProbeHash(route, "second-hash")
 
 	expected := &gatewayapi.HTTPRoute{
@@ -880,9 +2418,10 @@ func TestUpdateProbeHash(t *testing.T) {
 			Namespace: testNamespace,
 			Labels: map[string]string{
 				networking.IngressLabelKey:          testIngressName,
+				IngressUIDLabelKey:                  "",
 				"networking.knative.dev/visibility": "",
 			},
-			Annotations:     map[string]string{},
+			Annotations:     hashAnnotations(ctx, t, ing, rule),
 			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing)},
 		},
 		Spec: gatewayapi.HTTPRouteSpec{
@@ -1084,7 +2623,7 @@ func TestAddOldBackend(t *testing.T) {
 				}},
 			},
 		}},
-	})
+	}, config.EndpointProbeHeaders{})
 
 	expected := &gatewayapi.HTTPRoute{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1092,9 +2631,10 @@ func TestAddOldBackend(t *testing.T) {
 			Namespace: testNamespace,
 			Labels: map[string]string{
 				networking.IngressLabelKey:          testIngressName,
+				IngressUIDLabelKey:                  "",
 				"networking.knative.dev/visibility": "",
 			},
-			Annotations:     map[string]string{},
+			Annotations:     hashAnnotations(ctx, t, ing, rule),
 			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing)},
 		},
 		Spec: gatewayapi.HTTPRouteSpec{
@@ -1222,6 +2762,138 @@ func TestAddOldBackend(t *testing.T) {
 	}
 }
 
+func TestAddEndpointProbeBackendKind(t *testing.T) {
+	route := &gatewayapi.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace}}
+	gw := config.Gateway{
+		BackendGroup: "gateway.envoyproxy.io",
+		BackendKind:  "Backend",
+	}
+	AddEndpointProbe(route, "tr-hash", v1alpha1.IngressBackendSplit{
+		IngressBackend: v1alpha1.IngressBackend{
+			ServiceName: "goo",
+			ServicePort: intstr.FromInt(123),
+		},
+	}, gw, config.EndpointProbeHeaders{})
+
+	want := gatewayapi.BackendObjectReference{
+		Group: ptr.To[gatewayapi.Group]("gateway.envoyproxy.io"),
+		Kind:  ptr.To[gatewayapi.Kind]("Backend"),
+		Name:  "goo",
+		Port:  ptr.To[gatewayapi.PortNumber](123),
+	}
+	if diff := cmp.Diff(want, route.Spec.Rules[0].BackendRefs[0].BackendObjectReference); diff != "" {
+		t.Error("Unexpected probe backend (-want, +got):", diff)
+	}
+}
+
+func TestEndpointProbeHeaders(t *testing.T) {
+	names := config.EndpointProbeHeaders{
+		Namespace: "X-Fork-Namespace",
+		Revision:  "X-Fork-Revision",
+	}
+	// probeHeaders returns the headers set on the backend of the probe rule
+	probeHeaders := func(r *gatewayapi.HTTPRoute) []gatewayapi.HTTPHeader {
+		return r.Spec.Rules[len(r.Spec.Rules)-1].BackendRefs[0].Filters[0].RequestHeaderModifier.Set
+	}
+
+	t.Run("endpoint probe", func(t *testing.T) {
+		route := &gatewayapi.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace}}
+		AddEndpointProbe(route, "hash", v1alpha1.IngressBackendSplit{
+			IngressBackend: v1alpha1.IngressBackend{
+				ServiceName: "goo",
+				ServicePort: intstr.FromInt(123),
+			},
+			AppendHeaders: map[string]string{
+				"Foo": "bar",
+			},
+		}, config.Gateway{}, names)
+
+		want := []gatewayapi.HTTPHeader{{
+			Name:  "Foo",
+			Value: "bar",
+		}, {
+			Name:  "X-Fork-Namespace",
+			Value: testNamespace,
+		}, {
+			Name:  "X-Fork-Revision",
+			Value: "goo",
+		}}
+		if diff := cmp.Diff(want, probeHeaders(route)); diff != "" {
+			t.Error("Unexpected probe headers (-want, +got):", diff)
+		}
+	})
+
+	t.Run("endpoint probe with the headers set", func(t *testing.T) {
+		route := &gatewayapi.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace}}
+		AddEndpointProbe(route, "hash", v1alpha1.IngressBackendSplit{
+			IngressBackend: v1alpha1.IngressBackend{
+				ServiceName:      "goo",
+				ServiceNamespace: "other-ns",
+				ServicePort:      intstr.FromInt(123),
+			},
+			AppendHeaders: map[string]string{
+				"x-fork-revision": "goo-00001",
+			},
+		}, config.Gateway{}, names)
+
+		want := []gatewayapi.HTTPHeader{{
+			Name:  "X-Fork-Namespace",
+			Value: "other-ns",
+		}, {
+			Name:  "x-fork-revision",
+			Value: "goo-00001",
+		}}
+		if diff := cmp.Diff(want, probeHeaders(route)); diff != "" {
+			t.Error("Unexpected probe headers (-want, +got):", diff)
+		}
+	})
+
+	t.Run("old backend", func(t *testing.T) {
+		route := &gatewayapi.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace}}
+		AddOldBackend(route, "hash", gatewayapi.HTTPBackendRef{
+			BackendRef: gatewayapi.BackendRef{
+				BackendObjectReference: gatewayapi.BackendObjectReference{
+					Name: "blah",
+					Port: ptr.To[gatewayapi.PortNumber](127),
+				},
+			},
+		}, names)
+
+		want := []gatewayapi.HTTPHeader{{
+			Name:  "X-Fork-Namespace",
+			Value: testNamespace,
+		}, {
+			Name:  "X-Fork-Revision",
+			Value: "blah",
+		}}
+		if diff := cmp.Diff(want, probeHeaders(route)); diff != "" {
+			t.Error("Unexpected probe headers (-want, +got):", diff)
+		}
+	})
+}
+
+// hashAnnotations returns the hash annotations of the HTTPRoute of the rule,
+// as built by MakeHTTPRoute.
+func hashAnnotations(ctx context.Context, t *testing.T, ing *v1alpha1.Ingress, rule *v1alpha1.IngressRule) map[string]string {
+	t.Helper()
+	route, err := MakeHTTPRoute(ctx, ing, rule)
+	if err != nil {
+		t.Fatal("MakeHTTPRoute failed:", err)
+	}
+	inputsHash, err := HTTPRouteInputsHash(ctx, ing, rule, 0)
+	if err != nil {
+		t.Fatal("HTTPRouteInputsHash failed:", err)
+	}
+	specHash, err := HTTPRouteSpecHash(route.Spec)
+	if err != nil {
+		t.Fatal("HTTPRouteSpecHash failed:", err)
+	}
+	return map[string]string{
+		InputsHashAnnotationKey: inputsHash,
+		SpecHashAnnotationKey:   specHash,
+	}
+}
+
 type testConfigStore struct {
 	config *config.Config
 }
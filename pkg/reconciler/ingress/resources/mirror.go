@@ -0,0 +1,134 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/networking/pkg/http/header"
+
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
+)
+
+const (
+	// MirrorBackendAnnotationKey is the annotation on the Ingress naming the
+	// Service, as "name:port" in the Ingress namespace, that receives a copy
+	// of the requests routed by the Ingress.
+	MirrorBackendAnnotationKey = "gateway-api.networking.knative.dev/mirror-backend"
+
+	// MirrorPercentAnnotationKey is the annotation on the Ingress with the
+	// percentage of requests that are mirrored. All requests are mirrored
+	// when it is not set.
+	MirrorPercentAnnotationKey = "gateway-api.networking.knative.dev/mirror-percent"
+)
+
+// ValidateMirror checks the mirror annotations of the Ingress against its
+// rules.
+func ValidateMirror(ing *netv1alpha1.Ingress) error {
+	for i := range ing.Spec.Rules {
+		rule := &ing.Spec.Rules[i]
+		if rule.HTTP == nil {
+			continue
+		}
+		if _, err := makeMirrorFilter(ing, rule, config.Gateway{}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// makeMirrorFilter returns the RequestMirror filter requested by the
+// annotations of the Ingress for the rule, or nil if there is none. The
+// mirrored Service is referenced as a backend of the gateway of the rule, in
+// the backendNamespace when set for routes outside of the Ingress namespace.
+func makeMirrorFilter(
+	ing *netv1alpha1.Ingress,
+	rule *netv1alpha1.IngressRule,
+	gw config.Gateway,
+	backendNamespace *gatewayapi.Namespace,
+) (*gatewayapi.HTTPRouteFilter, error) {
+	backend, hasBackend := ing.GetAnnotations()[MirrorBackendAnnotationKey]
+	percent, hasPercent := ing.GetAnnotations()[MirrorPercentAnnotationKey]
+	if !hasBackend {
+		if hasPercent {
+			return nil, fmt.Errorf("annotation %q requires %q", MirrorPercentAnnotationKey, MirrorBackendAnnotationKey)
+		}
+		return nil, nil
+	}
+
+	name, portStr, ok := strings.Cut(backend, ":")
+	if !ok {
+		return nil, fmt.Errorf("annotation %q must be of the form name:port, was: %q", MirrorBackendAnnotationKey, backend)
+	}
+	if errs := validation.IsDNS1035Label(name); len(errs) > 0 {
+		return nil, fmt.Errorf("annotation %q has an invalid Service name %q: %s", MirrorBackendAnnotationKey, name, strings.Join(errs, ", "))
+	}
+	port, err := strconv.ParseInt(portStr, 10, 32)
+	if err != nil || validation.IsValidPortNum(int(port)) != nil {
+		return nil, fmt.Errorf("annotation %q has an invalid port %q", MirrorBackendAnnotationKey, portStr)
+	}
+
+	group, kind := backendGroupKind(gw)
+	mirror := &gatewayapi.HTTPRequestMirrorFilter{
+		BackendRef: gatewayapi.BackendObjectReference{
+			Group:     ptr.To(group),
+			Kind:      ptr.To(kind),
+			Name:      gatewayapi.ObjectName(name),
+			Namespace: backendNamespace,
+			Port:      ptr.To(gatewayapi.PortNumber(port)),
+		},
+	}
+
+	if hasPercent {
+		p, err := strconv.ParseInt(percent, 10, 32)
+		if err != nil || p < 0 || p > 100 {
+			return nil, fmt.Errorf("annotation %q must be an integer between 0 and 100, was: %q", MirrorPercentAnnotationKey, percent)
+		}
+		mirror.Percent = ptr.To(int32(p))
+	}
+
+	// The mirror applies to all the splits of a path, so a backend that
+	// already receives traffic would get those requests twice.
+	for _, path := range rule.HTTP.Paths {
+		if isProbePath(path) {
+			continue
+		}
+		for _, split := range path.Splits {
+			if split.ServiceName == name && split.Percent > 0 {
+				return nil, fmt.Errorf("mirror backend %q already receives traffic from the Ingress", name)
+			}
+		}
+	}
+
+	return &gatewayapi.HTTPRouteFilter{
+		Type:          gatewayapi.HTTPRouteFilterRequestMirror,
+		RequestMirror: mirror,
+	}, nil
+}
+
+// isProbePath returns true for the paths added to the Ingress for probing.
+func isProbePath(path netv1alpha1.HTTPIngressPath) bool {
+	_, ok := path.Headers[header.HashKey]
+	return ok
+}
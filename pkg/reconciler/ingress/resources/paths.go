@@ -0,0 +1,96 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
+)
+
+const (
+	// DenyPathsAnnotationKey is the annotation on the Ingress with the comma
+	// separated paths of its rules that are denied, e.g. "/admin,/internal".
+	// Their requests aren't routed to the backends of their splits, and are
+	// answered by the deny filter of the Gateway instead. The probes of the
+	// paths are still routed.
+	DenyPathsAnnotationKey = "gateway-api.networking.knative.dev/deny-paths"
+
+	// ExactPathsAnnotationKey is the annotation on the Ingress with the comma
+	// separated paths of its rules that only match their exact path rather
+	// than the path prefix, in the format of DenyPathsAnnotationKey. The
+	// probes of the paths are still matched by prefix.
+	ExactPathsAnnotationKey = "gateway-api.networking.knative.dev/exact-paths"
+)
+
+// annotatedPaths are the paths of the rules of the Ingress listed by its
+// annotations.
+type annotatedPaths struct {
+	Denied sets.Set[string]
+	Exact  sets.Set[string]
+}
+
+// makeAnnotatedPaths returns the paths listed by the annotations of the
+// Ingress.
+func makeAnnotatedPaths(ing *netv1alpha1.Ingress) (annotatedPaths, error) {
+	denied, err := parsePaths(ing, DenyPathsAnnotationKey)
+	if err != nil {
+		return annotatedPaths{}, err
+	}
+	exact, err := parsePaths(ing, ExactPathsAnnotationKey)
+	if err != nil {
+		return annotatedPaths{}, err
+	}
+	return annotatedPaths{Denied: denied, Exact: exact}, nil
+}
+
+// parsePaths returns the paths listed by the annotation, or nil if it isn't
+// set.
+func parsePaths(ing *netv1alpha1.Ingress, key string) (sets.Set[string], error) {
+	value, ok := ing.GetAnnotations()[key]
+	if !ok {
+		return nil, nil
+	}
+
+	paths := sets.New[string]()
+	for _, path := range strings.Split(value, ",") {
+		path = strings.TrimSpace(path)
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("annotation %q has an invalid path %q, it must start with \"/\"", key, path)
+		}
+		paths.Insert(path)
+	}
+	return paths, nil
+}
+
+// denyFilters returns the filters of the rules of the denied paths, the deny
+// filter of the Gateway when it has one.
+func denyFilters(gw config.Gateway) []gatewayapi.HTTPRouteFilter {
+	if gw.DenyFilter == nil {
+		return nil
+	}
+	return []gatewayapi.HTTPRouteFilter{{
+		Type:         gatewayapi.HTTPRouteFilterExtensionRef,
+		ExtensionRef: gw.DenyFilter.DeepCopy(),
+	}}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+const (
+	// RedirectAnnotationKey is the annotation on the Ingress redirecting the
+	// requests of its paths instead of routing them to their backends. It is
+	// a URL of the form "[scheme:][//host[:port]][/path]", whose parts replace
+	// the ones of the requests, e.g. "https://new.example.com/new-path" or
+	// "/new-path". The path replaces the full path of the requests.
+	RedirectAnnotationKey = "gateway-api.networking.knative.dev/redirect"
+
+	// RedirectStatusCodeAnnotationKey is the annotation on the Ingress with
+	// the status code of the redirect, 301 or 302. It is 302 when not set.
+	RedirectStatusCodeAnnotationKey = "gateway-api.networking.knative.dev/redirect-status-code"
+)
+
+// ValidateRedirect checks the redirect annotations of the Ingress.
+func ValidateRedirect(ing *netv1alpha1.Ingress) error {
+	_, err := makeRedirectFilter(ing)
+	return err
+}
+
+// makeRedirectFilter returns the RequestRedirect filter requested by the
+// annotations of the Ingress, or nil if there is none.
+func makeRedirectFilter(ing *netv1alpha1.Ingress) (*gatewayapi.HTTPRouteFilter, error) {
+	location, hasLocation := ing.GetAnnotations()[RedirectAnnotationKey]
+	code, hasCode := ing.GetAnnotations()[RedirectStatusCodeAnnotationKey]
+	if !hasLocation {
+		if hasCode {
+			return nil, fmt.Errorf("annotation %q requires %q", RedirectStatusCodeAnnotationKey, RedirectAnnotationKey)
+		}
+		return nil, nil
+	}
+
+	// The redirected paths have no backends to mirror the requests to
+	if _, ok := ing.GetAnnotations()[MirrorBackendAnnotationKey]; ok {
+		return nil, fmt.Errorf("annotation %q can't be used with %q", RedirectAnnotationKey, MirrorBackendAnnotationKey)
+	}
+
+	u, err := url.Parse(location)
+	if err != nil || location == "" || u.Opaque != "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("annotation %q must be of the form [scheme:][//host[:port]][/path], was: %q", RedirectAnnotationKey, location)
+	}
+
+	redirect := &gatewayapi.HTTPRequestRedirectFilter{}
+	switch u.Scheme {
+	case "":
+	case "http", "https":
+		redirect.Scheme = ptr.To(u.Scheme)
+	default:
+		return nil, fmt.Errorf("annotation %q has an invalid scheme %q, must be http or https", RedirectAnnotationKey, u.Scheme)
+	}
+	if host := u.Hostname(); host != "" {
+		if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+			return nil, fmt.Errorf("annotation %q has an invalid host %q: %s", RedirectAnnotationKey, host, strings.Join(errs, ", "))
+		}
+		redirect.Hostname = ptr.To(gatewayapi.PreciseHostname(host))
+	}
+	if portStr := u.Port(); portStr != "" {
+		port, err := strconv.ParseInt(portStr, 10, 32)
+		if err != nil || validation.IsValidPortNum(int(port)) != nil {
+			return nil, fmt.Errorf("annotation %q has an invalid port %q", RedirectAnnotationKey, portStr)
+		}
+		redirect.Port = ptr.To(gatewayapi.PortNumber(port))
+	}
+	if u.Path != "" {
+		if !strings.HasPrefix(u.Path, "/") {
+			return nil, fmt.Errorf("annotation %q has a relative path %q", RedirectAnnotationKey, u.Path)
+		}
+		redirect.Path = &gatewayapi.HTTPPathModifier{
+			Type:            gatewayapi.FullPathHTTPPathModifier,
+			ReplaceFullPath: ptr.To(u.Path),
+		}
+	}
+
+	if hasCode {
+		c, err := strconv.Atoi(code)
+		if err != nil || (c != http.StatusMovedPermanently && c != http.StatusFound) {
+			return nil, fmt.Errorf("annotation %q must be %d or %d, was: %q",
+				RedirectStatusCodeAnnotationKey, http.StatusMovedPermanently, http.StatusFound, code)
+		}
+		redirect.StatusCode = ptr.To(c)
+	}
+
+	return &gatewayapi.HTTPRouteFilter{
+		Type:            gatewayapi.HTTPRouteFilterRequestRedirect,
+		RequestRedirect: redirect,
+	}, nil
+}
@@ -17,12 +17,18 @@ limitations under the License.
 package resources
 
 import (
+	"cmp"
 	"context"
+	"slices"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
 	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/pkg/kmeta"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	"sigs.k8s.io/gateway-api/pkg/features"
 )
 
 // Grant the resource "to" access to the resource "from"
@@ -37,7 +43,7 @@ func MakeReferenceGrant(_ context.Context, ing *netv1alpha1.Ingress, to, from me
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            name,
 			Namespace:       to.Namespace,
-			Labels:          to.Labels,
+			Labels:          kmeta.UnionMaps(to.Labels, ownershipLabels(ing)),
 			Annotations:     to.Annotations,
 			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing)},
 		},
@@ -55,3 +61,85 @@ func MakeReferenceGrant(_ context.Context, ing *netv1alpha1.Ingress, to, from me
 		},
 	}
 }
+
+// MakeBackendReferenceGrant returns the ReferenceGrant allowing the HTTPRoutes
+// placed in the configured route namespace to use the backends of the Ingress,
+// or nil when its HTTPRoutes are in its own namespace. This is the only grant
+// the backends need: the validation of the Ingress requires the namespace of
+// the Services of the splits to be the namespace of the Ingress, and the
+// mirrored Service is in the Ingress namespace.
+func MakeBackendReferenceGrant(ctx context.Context, ing *netv1alpha1.Ingress) *gatewayv1beta1.ReferenceGrant {
+	routeNamespace := HTTPRouteNamespace(ctx, ing)
+	if routeNamespace == ing.Namespace {
+		return nil
+	}
+
+	// backend is a backend of the routes, of the kind of their Gateway
+	type backend struct {
+		group gatewayv1beta1.Group
+		kind  gatewayv1beta1.Kind
+		name  string
+	}
+
+	pluginConfig := config.FromContext(ctx).GatewayPlugin
+	passthrough := IsTLSPassthrough(ing)
+	backends := sets.New[backend]()
+	for _, rule := range ing.Spec.Rules {
+		// Passthrough rules are routed by TLSRoutes in the Ingress namespace
+		if rule.HTTP == nil || (passthrough && rule.Visibility != netv1alpha1.IngressVisibilityClusterLocal) {
+			continue
+		}
+		gw := pluginConfig.ExternalGateway()
+		if rule.Visibility == netv1alpha1.IngressVisibilityClusterLocal {
+			gw = pluginConfig.LocalGateway()
+		}
+		group, kind := backendGroupKind(gw)
+		for _, path := range rule.HTTP.Paths {
+			for _, split := range path.Splits {
+				backends.Insert(backend{group: group, kind: kind, name: split.ServiceName})
+			}
+		}
+		// An invalid mirror fails the routes, so it doesn't need a grant.
+		mirror, err := makeMirrorFilter(ing, &rule, gw, nil)
+		if err == nil && mirror != nil && gw.SupportedFeatures.Has(features.SupportHTTPRouteRequestMirror) {
+			backends.Insert(backend{group: group, kind: kind, name: string(mirror.RequestMirror.BackendRef.Name)})
+		}
+	}
+	if backends.Len() == 0 {
+		return nil
+	}
+
+	sorted := backends.UnsortedList()
+	slices.SortFunc(sorted, func(a, b backend) int {
+		return cmp.Or(
+			cmp.Compare(a.group, b.group),
+			cmp.Compare(a.kind, b.kind),
+			cmp.Compare(a.name, b.name),
+		)
+	})
+	to := make([]gatewayv1beta1.ReferenceGrantTo, 0, len(sorted))
+	for _, b := range sorted {
+		to = append(to, gatewayv1beta1.ReferenceGrantTo{
+			Group: b.group,
+			Kind:  b.kind,
+			Name:  (*gatewayv1beta1.ObjectName)(&b.name),
+		})
+	}
+
+	return &gatewayv1beta1.ReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            kmeta.ChildName(ing.Name, "-httproutes"),
+			Namespace:       ing.Namespace,
+			Labels:          ownershipLabels(ing),
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing)},
+		},
+		Spec: gatewayv1beta1.ReferenceGrantSpec{
+			From: []gatewayv1beta1.ReferenceGrantFrom{{
+				Group:     gatewayv1beta1.Group(gatewayv1.GroupName),
+				Kind:      "HTTPRoute",
+				Namespace: gatewayv1beta1.Namespace(routeNamespace),
+			}},
+			To: to,
+		},
+	}
+}
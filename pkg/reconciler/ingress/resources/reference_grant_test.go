@@ -0,0 +1,93 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/ptr"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	"sigs.k8s.io/gateway-api/pkg/features"
+
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
+)
+
+func TestMakeBackendReferenceGrant(t *testing.T) {
+	service := func(name string) gatewayv1beta1.ReferenceGrantTo {
+		return gatewayv1beta1.ReferenceGrantTo{
+			Kind: "Service",
+			Name: ptr.To[gatewayv1beta1.ObjectName](gatewayv1beta1.ObjectName(name)),
+		}
+	}
+
+	for _, tc := range []struct {
+		name         string
+		annotations  map[string]string
+		changeConfig func(*config.Config)
+		want         []gatewayv1beta1.ReferenceGrantTo
+	}{{
+		name: "routes in the Ingress namespace",
+	}, {
+		name: "routes in the configured namespace",
+		changeConfig: func(c *config.Config) {
+			c.GatewayPlugin.RouteNamespace = "gateways"
+		},
+		want: []gatewayv1beta1.ReferenceGrantTo{service("doo"), service("goo")},
+	}, {
+		name:        "mirror in another namespace than the routes",
+		annotations: map[string]string{MirrorBackendAnnotationKey: "canary:8080"},
+		changeConfig: func(c *config.Config) {
+			c.GatewayPlugin.RouteNamespace = "gateways"
+			c.GatewayPlugin.ExternalGateways[0].SupportedFeatures.Insert(features.SupportHTTPRouteRequestMirror)
+		},
+		want: []gatewayv1beta1.ReferenceGrantTo{service("canary"), service("doo"), service("goo")},
+	}, {
+		name:        "mirror not supported by gateway",
+		annotations: map[string]string{MirrorBackendAnnotationKey: "canary:8080"},
+		changeConfig: func(c *config.Config) {
+			c.GatewayPlugin.RouteNamespace = "gateways"
+		},
+		want: []gatewayv1beta1.ReferenceGrantTo{service("doo"), service("goo")},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := testConfig.DeepCopy()
+			if tc.changeConfig != nil {
+				tc.changeConfig(cfg)
+			}
+			ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+
+			grant := MakeBackendReferenceGrant(ctx, baseIngress(tc.annotations))
+			if tc.want == nil {
+				if grant != nil {
+					t.Errorf("MakeBackendReferenceGrant() = %v, want: nil", grant)
+				}
+				return
+			}
+			if grant == nil {
+				t.Fatal("MakeBackendReferenceGrant() = nil")
+			}
+			if got, want := string(grant.Spec.From[0].Namespace), cfg.GatewayPlugin.RouteNamespace; got != want {
+				t.Errorf("From namespace = %q, want: %q", got, want)
+			}
+			if diff := cmp.Diff(tc.want, grant.Spec.To); diff != "" {
+				t.Error("Unexpected grant targets (-want +got):", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+	"sigs.k8s.io/yaml"
+
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/kmeta"
+)
+
+// ResiliencyPolicyAnnotationKey is the annotation on the Ingress requesting a
+// resiliency policy (e.g. retry budgets or circuit breaking) for its routes.
+// Its value is a YAML object of fields overriding the ones of the spec of the
+// configured policy template, and may be empty to use the template as is.
+const ResiliencyPolicyAnnotationKey = "gateway-api.networking.knative.dev/resiliency-policy"
+
+// MakeResiliencyPolicy creates the resiliency policy object for the routes of
+// the rule from the configured template. It returns nil if no template is
+// configured or the Ingress does not request a policy.
+func MakeResiliencyPolicy(
+	ctx context.Context,
+	ing *netv1alpha1.Ingress,
+	rule *netv1alpha1.IngressRule,
+) (*unstructured.Unstructured, error) {
+	template := config.FromContext(ctx).GatewayPlugin.ResiliencyPolicyTemplate
+	value, ok := ing.GetAnnotations()[ResiliencyPolicyAnnotationKey]
+	if template == nil || !ok {
+		return nil, nil
+	}
+
+	overrides := map[string]interface{}{}
+	if strings.TrimSpace(value) != "" {
+		if err := yaml.Unmarshal([]byte(value), &overrides); err != nil {
+			return nil, fmt.Errorf("annotation %q must be a YAML object: %w", ResiliencyPolicyAnnotationKey, err)
+		}
+	}
+
+	policy := template.DeepCopy()
+	spec, _, err := unstructured.NestedMap(policy.Object, "spec")
+	if err != nil {
+		return nil, fmt.Errorf("invalid resiliency policy template: %w", err)
+	}
+	if spec == nil {
+		spec = make(map[string]interface{}, len(overrides))
+	}
+	for k, v := range overrides {
+		spec[k] = v
+	}
+	if len(spec) > 0 {
+		if err := unstructured.SetNestedMap(policy.Object, spec, "spec"); err != nil {
+			return nil, fmt.Errorf("invalid resiliency policy overrides: %w", err)
+		}
+	}
+
+	policy.SetName(LongestHost(rule.Hosts))
+	policy.SetNamespace(ing.Namespace)
+	policy.SetLabels(kmeta.UnionMaps(ing.Labels, ownershipLabels(ing)))
+	policy.SetOwnerReferences([]metav1.OwnerReference{*kmeta.NewControllerRef(ing)})
+	return policy, nil
+}
+
+// makeResiliencyPolicyFilter returns the filter referencing the policy.
+func makeResiliencyPolicyFilter(policy *unstructured.Unstructured) *gatewayapi.HTTPRouteFilter {
+	if policy == nil {
+		return nil
+	}
+	gvk := policy.GroupVersionKind()
+	return &gatewayapi.HTTPRouteFilter{
+		Type: gatewayapi.HTTPRouteFilterExtensionRef,
+		ExtensionRef: &gatewayapi.LocalObjectReference{
+			Group: gatewayapi.Group(gvk.Group),
+			Kind:  gatewayapi.Kind(gvk.Kind),
+			Name:  gatewayapi.ObjectName(policy.GetName()),
+		},
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/pkg/kmeta"
+)
+
+var testPolicyTemplate = &unstructured.Unstructured{Object: map[string]interface{}{
+	"apiVersion": "policy.example.com/v1",
+	"kind":       "RetryBudget",
+	"spec": map[string]interface{}{
+		"budgetPercent": float64(20),
+		"minRetries":    float64(3),
+	},
+}}
+
+func TestMakeResiliencyPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		template   *unstructured.Unstructured
+		annotation *string
+		want       *unstructured.Unstructured
+		wantErr    string
+	}{{
+		name:       "no template",
+		annotation: ptr.To(""),
+	}, {
+		name:     "not requested",
+		template: testPolicyTemplate,
+	}, {
+		name:       "template defaults",
+		template:   testPolicyTemplate,
+		annotation: ptr.To(""),
+		want: testPolicy(map[string]interface{}{
+			"budgetPercent": float64(20),
+			"minRetries":    float64(3),
+		}),
+	}, {
+		name:       "overrides",
+		template:   testPolicyTemplate,
+		annotation: ptr.To("budgetPercent: 5\ncircuitBreaker:\n  maxRequests: 100"),
+		want: testPolicy(map[string]interface{}{
+			"budgetPercent": float64(5),
+			"minRetries":    float64(3),
+			"circuitBreaker": map[string]interface{}{
+				"maxRequests": float64(100),
+			},
+		}),
+	}, {
+		name:       "bad annotation",
+		template:   testPolicyTemplate,
+		annotation: ptr.To("[1, 2]"),
+		wantErr:    `annotation "gateway-api.networking.knative.dev/resiliency-policy" must be a YAML object: error unmarshaling JSON: while decoding JSON: json: cannot unmarshal array into Go value of type map[string]interface {}`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ing := baseIngress(nil)
+			if tc.annotation != nil {
+				ing.Annotations = map[string]string{ResiliencyPolicyAnnotationKey: *tc.annotation}
+			}
+
+			cfg := testConfig.DeepCopy()
+			cfg.GatewayPlugin.ResiliencyPolicyTemplate = tc.template
+			ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+
+			got, err := MakeResiliencyPolicy(ctx, ing, &ing.Spec.Rules[0])
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("MakeResiliencyPolicy() error = %v, want: %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("MakeResiliencyPolicy() =", err)
+			}
+
+			if tc.want != nil {
+				tc.want.SetOwnerReferences([]metav1.OwnerReference{*kmeta.NewControllerRef(ing)})
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error("Unexpected policy (-want +got):", diff)
+			}
+		})
+	}
+}
+
+func TestMakeHTTPRouteResiliencyPolicy(t *testing.T) {
+	ing := baseIngress(map[string]string{ResiliencyPolicyAnnotationKey: ""})
+
+	cfg := testConfig.DeepCopy()
+	cfg.GatewayPlugin.ResiliencyPolicyTemplate = testPolicyTemplate
+	ctx := (&testConfigStore{config: cfg}).ToContext(context.Background())
+
+	got, err := MakeHTTPRoute(ctx, ing, &ing.Spec.Rules[0])
+	if err != nil {
+		t.Fatal("MakeHTTPRoute() =", err)
+	}
+
+	want := baseRoute(ing.Annotations, []gatewayapi.HTTPRouteFilter{{
+		Type: gatewayapi.HTTPRouteFilterExtensionRef,
+		ExtensionRef: &gatewayapi.LocalObjectReference{
+			Group: "policy.example.com",
+			Kind:  "RetryBudget",
+			Name:  gatewayapi.ObjectName(LongestHost(testHosts)),
+		},
+	}})
+	want.OwnerReferences = []metav1.OwnerReference{*kmeta.NewControllerRef(ing)}
+	want.Annotations = kmeta.UnionMaps(want.Annotations, hashAnnotations(ctx, t, ing, &ing.Spec.Rules[0]))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("Unexpected HTTPRoute (-want +got):", diff)
+	}
+}
+
+func testPolicy(spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "policy.example.com/v1",
+		"kind":       "RetryBudget",
+		"metadata": map[string]interface{}{
+			"name":      LongestHost(testHosts),
+			"namespace": testNamespace,
+			"labels": map[string]interface{}{
+				networking.IngressLabelKey: testIngressName,
+				IngressUIDLabelKey:         "",
+			},
+		},
+		"spec": spec,
+	}}
+}
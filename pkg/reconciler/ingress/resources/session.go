@@ -0,0 +1,103 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+	"sigs.k8s.io/gateway-api/pkg/features"
+
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+const (
+	// SessionCookieAnnotationKey is the annotation on the Ingress naming the
+	// cookie that keeps the requests of a client on the same backend, e.g.
+	// for applications holding websockets.
+	SessionCookieAnnotationKey = "gateway-api.networking.knative.dev/session-cookie"
+
+	// SessionLifetimeAnnotationKey is the annotation on the Ingress with the
+	// lifetime of the session cookie, as a Gateway API duration (e.g. "1h").
+	// The cookie only lasts for the browser session when it is not set.
+	SessionLifetimeAnnotationKey = "gateway-api.networking.knative.dev/session-lifetime"
+
+	// SupportHTTPRouteSessionPersistence is the feature of the Gateways
+	// supporting the session persistence of HTTPRoute rules, which isn't
+	// named by this version of the Gateway API.
+	SupportHTTPRouteSessionPersistence features.FeatureName = "HTTPRouteSessionPersistence"
+
+	// maxSessionNameLength is the maximum length of the session name of a rule
+	maxSessionNameLength = 128
+)
+
+// gatewayDuration matches the durations of the Gateway API (GEP-2257).
+var gatewayDuration = regexp.MustCompile(`^([0-9]{1,5}(h|m|s|ms)){1,4}$`)
+
+// ValidateSessionPersistence checks the session annotations of the Ingress.
+func ValidateSessionPersistence(ing *netv1alpha1.Ingress) error {
+	_, err := makeSessionPersistence(ing)
+	return err
+}
+
+// makeSessionPersistence returns the cookie based session persistence
+// requested by the annotations of the Ingress, or nil if there is none.
+func makeSessionPersistence(ing *netv1alpha1.Ingress) (*gatewayapi.SessionPersistence, error) {
+	name, hasName := ing.GetAnnotations()[SessionCookieAnnotationKey]
+	lifetime, hasLifetime := ing.GetAnnotations()[SessionLifetimeAnnotationKey]
+	if !hasName {
+		if hasLifetime {
+			return nil, fmt.Errorf("annotation %q requires %q", SessionLifetimeAnnotationKey, SessionCookieAnnotationKey)
+		}
+		return nil, nil
+	}
+
+	if name == "" || len(name) > maxSessionNameLength {
+		return nil, fmt.Errorf("annotation %q must have between 1 and %d characters, was: %q",
+			SessionCookieAnnotationKey, maxSessionNameLength, name)
+	}
+	// Cookie names are tokens, like header names
+	if errs := validation.IsHTTPHeaderName(name); len(errs) > 0 {
+		return nil, fmt.Errorf("annotation %q has an invalid cookie name %q: %s",
+			SessionCookieAnnotationKey, name, strings.Join(errs, ", "))
+	}
+
+	session := &gatewayapi.SessionPersistence{
+		SessionName: ptr.To(name),
+		Type:        ptr.To(gatewayapi.CookieBasedSessionPersistence),
+		CookieConfig: &gatewayapi.CookieConfig{
+			LifetimeType: ptr.To(gatewayapi.SessionCookieLifetimeType),
+		},
+	}
+
+	if hasLifetime {
+		d, err := time.ParseDuration(lifetime)
+		if err != nil || d <= 0 || !gatewayDuration.MatchString(lifetime) {
+			return nil, fmt.Errorf("annotation %q must be a positive duration such as 1h or 30m, was: %q",
+				SessionLifetimeAnnotationKey, lifetime)
+		}
+		session.AbsoluteTimeout = ptr.To(gatewayapi.Duration(lifetime))
+		session.CookieConfig.LifetimeType = ptr.To(gatewayapi.PermanentCookieLifetimeType)
+	}
+
+	return session, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
+	"knative.dev/networking/pkg/apis/networking"
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/networking/pkg/http/header"
+	"knative.dev/pkg/kmeta"
+)
+
+const (
+	// TLSModeAnnotationKey is the annotation on the Ingress selecting how TLS is
+	// handled for its external rules.
+	TLSModeAnnotationKey = "gateway-api.networking.knative.dev/tls-mode"
+
+	// TLSModePassthrough routes TLS connections to the backends based on SNI
+	// without terminating them at the Gateway. The backends are expected to
+	// terminate TLS themselves.
+	TLSModePassthrough = "passthrough"
+)
+
+// IsTLSPassthrough returns true if the external rules of the Ingress should be
+// exposed using TLS passthrough.
+func IsTLSPassthrough(ing *netv1alpha1.Ingress) bool {
+	return strings.EqualFold(ing.GetAnnotations()[TLSModeAnnotationKey], TLSModePassthrough)
+}
+
+// MakeTLSRoute creates a TLSRoute routing TLS connections for the rule's hosts to
+// the rule's backends based on SNI.
+func MakeTLSRoute(
+	ctx context.Context,
+	ing *netv1alpha1.Ingress,
+	rule *netv1alpha1.IngressRule,
+) (*gatewayapiv1alpha2.TLSRoute, error) {
+	if rule.Visibility == netv1alpha1.IngressVisibilityClusterLocal {
+		return nil, errors.New("TLS passthrough is only supported for external rules")
+	}
+
+	var path *netv1alpha1.HTTPIngressPath
+	for i := range rule.HTTP.Paths {
+		if _, ok := rule.HTTP.Paths[i].Headers[header.HashKey]; ok {
+			// Probes are not routable with TLS passthrough
+			continue
+		}
+		if path != nil {
+			return nil, errors.New("TLS passthrough does not support routing on multiple paths")
+		}
+		path = &rule.HTTP.Paths[i]
+	}
+	if path == nil {
+		return nil, errors.New("TLS passthrough requires a path with backends")
+	}
+
+	hostnames := make([]gatewayapi.Hostname, 0, len(rule.Hosts))
+	for _, hostname := range rule.Hosts {
+		hostnames = append(hostnames, gatewayapi.Hostname(hostname))
+	}
+
+	gateway := config.FromContext(ctx).GatewayPlugin.ExternalGateway()
+	group, kind := backendGroupKind(gateway)
+
+	backendRefs := make([]gatewayapi.BackendRef, 0, len(path.Splits))
+	for _, split := range path.Splits {
+		backendRefs = append(backendRefs, gatewayapi.BackendRef{
+			BackendObjectReference: gatewayapi.BackendObjectReference{
+				Group: ptr.To(group),
+				Kind:  ptr.To(kind),
+				Name:  gatewayapi.ObjectName(split.ServiceName),
+				//nolint:gosec // port numbers are bounded
+				Port: ptr.To(gatewayapi.PortNumber(split.ServicePort.IntValue())),
+			},
+			Weight: ptr.To(int32(split.Percent)), //nolint:gosec // percent is bounded [0,100]
+		})
+	}
+
+	return &gatewayapiv1alpha2.TLSRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      LongestHost(rule.Hosts),
+			Namespace: ing.Namespace,
+			Labels: kmeta.UnionMaps(routeLabels(ctx, ing), ownershipLabels(ing), map[string]string{
+				networking.VisibilityLabelKey: "",
+			}),
+			Annotations: kmeta.FilterMap(ing.GetAnnotations(), func(key string) bool {
+				return key == corev1.LastAppliedConfigAnnotation
+			}),
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing)},
+		},
+		Spec: gatewayapiv1alpha2.TLSRouteSpec{
+			CommonRouteSpec: gatewayapi.CommonRouteSpec{ParentRefs: []gatewayapi.ParentReference{{
+				Group:     (*gatewayapi.Group)(&gatewayapi.GroupVersion.Group),
+				Kind:      (*gatewayapi.Kind)(ptr.To("Gateway")),
+				Namespace: ptr.To(gatewayapi.Namespace(gateway.Namespace)),
+				Name:      gatewayapi.ObjectName(gateway.Name),
+			}}},
+			Hostnames: hostnames,
+			Rules: []gatewayapiv1alpha2.TLSRouteRule{{
+				BackendRefs: backendRefs,
+			}},
+		},
+	}, nil
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/networking/pkg/http/header"
+	"knative.dev/pkg/kmeta"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func TestMakeTLSRoute(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		rule     v1alpha1.IngressRule
+		expected *gatewayapiv1alpha2.TLSRoute
+		wantErr  string
+	}{{
+		name: "split between revisions",
+		rule: v1alpha1.IngressRule{
+			Hosts:      testHosts,
+			Visibility: v1alpha1.IngressVisibilityExternalIP,
+			HTTP: &v1alpha1.HTTPIngressRuleValue{
+				Paths: []v1alpha1.HTTPIngressPath{{
+					// Probe paths are skipped
+					Headers: map[string]v1alpha1.HeaderMatch{
+						header.HashKey: {Exact: header.HashValueOverride},
+					},
+					Splits: []v1alpha1.IngressBackendSplit{{
+						IngressBackend: v1alpha1.IngressBackend{
+							ServiceName:      "goo",
+							ServiceNamespace: testNamespace,
+							ServicePort:      intstr.FromInt(123),
+						},
+						Percent: 100,
+					}},
+				}, {
+					Splits: []v1alpha1.IngressBackendSplit{{
+						IngressBackend: v1alpha1.IngressBackend{
+							ServiceName:      "goo",
+							ServiceNamespace: testNamespace,
+							ServicePort:      intstr.FromInt(123),
+						},
+						Percent: 12,
+					}, {
+						IngressBackend: v1alpha1.IngressBackend{
+							ServiceName:      "doo",
+							ServiceNamespace: testNamespace,
+							ServicePort:      intstr.FromInt(124),
+						},
+						Percent: 88,
+					}},
+				}},
+			},
+		},
+		expected: &gatewayapiv1alpha2.TLSRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      LongestHost(testHosts),
+				Namespace: testNamespace,
+				Labels: map[string]string{
+					networking.IngressLabelKey:          testIngressName,
+					IngressUIDLabelKey:                  "test-uid",
+					"networking.knative.dev/visibility": "",
+				},
+				Annotations: map[string]string{
+					TLSModeAnnotationKey: TLSModePassthrough,
+				},
+			},
+			Spec: gatewayapiv1alpha2.TLSRouteSpec{
+				CommonRouteSpec: gatewayapi.CommonRouteSpec{
+					ParentRefs: []gatewayapi.ParentReference{{
+						Group:     (*gatewayapi.Group)(ptr.To("gateway.networking.k8s.io")),
+						Kind:      (*gatewayapi.Kind)(ptr.To("Gateway")),
+						Namespace: ptr.To[gatewayapi.Namespace]("test-ns"),
+						Name:      gatewayapi.ObjectName("foo"),
+					}},
+				},
+				Hostnames: []gatewayapi.Hostname{externalHost},
+				Rules: []gatewayapiv1alpha2.TLSRouteRule{{
+					BackendRefs: []gatewayapi.BackendRef{{
+						BackendObjectReference: gatewayapi.BackendObjectReference{
+							Group: (*gatewayapi.Group)(ptr.To("")),
+							Kind:  (*gatewayapi.Kind)(ptr.To("Service")),
+							Name:  "goo",
+							Port:  ptr.To[gatewayapi.PortNumber](123),
+						},
+						Weight: ptr.To[int32](12),
+					}, {
+						BackendObjectReference: gatewayapi.BackendObjectReference{
+							Group: (*gatewayapi.Group)(ptr.To("")),
+							Kind:  (*gatewayapi.Kind)(ptr.To("Service")),
+							Name:  "doo",
+							Port:  ptr.To[gatewayapi.PortNumber](124),
+						},
+						Weight: ptr.To[int32](88),
+					}},
+				}},
+			},
+		},
+	}, {
+		name: "cluster local rule",
+		rule: v1alpha1.IngressRule{
+			Hosts:      testLocalHosts,
+			Visibility: v1alpha1.IngressVisibilityClusterLocal,
+			HTTP: &v1alpha1.HTTPIngressRuleValue{
+				Paths: []v1alpha1.HTTPIngressPath{{}},
+			},
+		},
+		wantErr: "TLS passthrough is only supported for external rules",
+	}, {
+		name: "multiple paths",
+		rule: v1alpha1.IngressRule{
+			Hosts:      testHosts,
+			Visibility: v1alpha1.IngressVisibilityExternalIP,
+			HTTP: &v1alpha1.HTTPIngressRuleValue{
+				Paths: []v1alpha1.HTTPIngressPath{{Path: "/foo"}, {Path: "/bar"}},
+			},
+		},
+		wantErr: "TLS passthrough does not support routing on multiple paths",
+	}, {
+		name: "no paths",
+		rule: v1alpha1.IngressRule{
+			Hosts:      testHosts,
+			Visibility: v1alpha1.IngressVisibilityExternalIP,
+			HTTP:       &v1alpha1.HTTPIngressRuleValue{},
+		},
+		wantErr: "TLS passthrough requires a path with backends",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testIngressName,
+					Namespace: testNamespace,
+					UID:       "test-uid",
+					Labels: map[string]string{
+						networking.IngressLabelKey: testIngressName,
+					},
+					Annotations: map[string]string{
+						TLSModeAnnotationKey: TLSModePassthrough,
+					},
+				},
+				Spec: v1alpha1.IngressSpec{Rules: []v1alpha1.IngressRule{tc.rule}},
+			}
+
+			tcs := &testConfigStore{config: testConfig}
+			ctx := tcs.ToContext(context.Background())
+
+			route, err := MakeTLSRoute(ctx, ing, &ing.Spec.Rules[0])
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("MakeTLSRoute() error = %v, want: %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("MakeTLSRoute failed:", err)
+			}
+
+			tc.expected.OwnerReferences = []metav1.OwnerReference{*kmeta.NewControllerRef(ing)}
+			if diff := cmp.Diff(tc.expected, route); diff != "" {
+				t.Error("Unexpected TLSRoute (-want +got):", diff)
+			}
+		})
+	}
+}
+
+func TestMakeTLSRouteBackendKind(t *testing.T) {
+	ing := &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testIngressName,
+			Namespace: testNamespace,
+		},
+		Spec: v1alpha1.IngressSpec{Rules: []v1alpha1.IngressRule{{
+			Hosts:      testHosts,
+			Visibility: v1alpha1.IngressVisibilityExternalIP,
+			HTTP: &v1alpha1.HTTPIngressRuleValue{
+				Paths: []v1alpha1.HTTPIngressPath{{
+					Splits: []v1alpha1.IngressBackendSplit{{
+						IngressBackend: v1alpha1.IngressBackend{
+							ServiceName: "goo",
+							ServicePort: intstr.FromInt(123),
+						},
+						Percent: 100,
+					}},
+				}},
+			},
+		}}},
+	}
+
+	cfg := testConfig.DeepCopy()
+	cfg.GatewayPlugin.ExternalGateways[0].BackendGroup = "gateway.envoyproxy.io"
+	cfg.GatewayPlugin.ExternalGateways[0].BackendKind = "Backend"
+	tcs := &testConfigStore{config: cfg}
+	ctx := tcs.ToContext(context.Background())
+
+	route, err := MakeTLSRoute(ctx, ing, &ing.Spec.Rules[0])
+	if err != nil {
+		t.Fatal("MakeTLSRoute failed:", err)
+	}
+
+	want := gatewayapi.BackendObjectReference{
+		Group: ptr.To[gatewayapi.Group]("gateway.envoyproxy.io"),
+		Kind:  ptr.To[gatewayapi.Kind]("Backend"),
+		Name:  "goo",
+		Port:  ptr.To[gatewayapi.PortNumber](123),
+	}
+	if diff := cmp.Diff(want, route.Spec.Rules[0].BackendRefs[0].BackendObjectReference); diff != "" {
+		t.Error("Unexpected backend (-want +got):", diff)
+	}
+}
+
+func TestIsTLSPassthrough(t *testing.T) {
+	for _, tc := range []struct {
+		annotations map[string]string
+		want        bool
+	}{{
+		annotations: nil,
+		want:        false,
+	}, {
+		annotations: map[string]string{TLSModeAnnotationKey: "Passthrough"},
+		want:        true,
+	}, {
+		annotations: map[string]string{TLSModeAnnotationKey: "terminate"},
+		want:        false,
+	}} {
+		ing := &v1alpha1.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		if got := IsTLSPassthrough(ing); got != tc.want {
+			t.Errorf("IsTLSPassthrough(%v) = %v, want: %v", tc.annotations, got, tc.want)
+		}
+	}
+}
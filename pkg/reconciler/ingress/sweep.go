@@ -0,0 +1,147 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/resources"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
+)
+
+// orphanSweepInterval is the interval between the sweeps of the resources
+// left behind by deleted Ingresses.
+const orphanSweepInterval = 10 * time.Minute
+
+// sweepOrphans deletes the HTTPRoutes, TLSRoutes, ReferenceGrants and
+// resiliency policies generated for Ingresses that no longer exist. Their
+// Ingress is identified by the UID in their IngressUIDLabelKey label, as the
+// routes in the configured route namespace have no owner reference, and the
+// owner references of the others are left dangling when the finalizer of
+// their Ingress was removed by hand. The resources of an Ingress recreated
+// with the same name are swept too, unless it already adopted them. The
+// policies are only swept when a template is configured, and each resource
+// is only swept by the replica leading its key so that the replicas don't
+// race each other.
+func (c *Reconciler) sweepOrphans(
+	ctx context.Context,
+	ingressLister networkinglisters.IngressLister,
+	policyTemplate *unstructured.Unstructured,
+	isLeader func(types.NamespacedName) bool,
+) error {
+	req, err := labels.NewRequirement(resources.IngressUIDLabelKey, selection.Exists, nil)
+	if err != nil {
+		return err
+	}
+	selector := labels.NewSelector().Add(*req)
+
+	ings, err := ingressLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	uids := sets.New[types.UID]()
+	for _, ing := range ings {
+		uids.Insert(ing.UID)
+	}
+	orphaned := func(obj metav1.Object) bool {
+		return !uids.Has(types.UID(obj.GetLabels()[resources.IngressUIDLabelKey])) &&
+			isLeader(types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()})
+	}
+
+	httproutes, err := c.httprouteLister.List(selector)
+	if err != nil {
+		return err
+	}
+	for _, route := range httproutes {
+		if !orphaned(route) {
+			continue
+		}
+		err := c.gwapiclient.GatewayV1().HTTPRoutes(route.Namespace).Delete(ctx, route.Name, sweepDeleteOptions(route))
+		if err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete orphaned HTTPRoute %s/%s: %w", route.Namespace, route.Name, err)
+		}
+	}
+
+	tlsroutes, err := c.tlsrouteLister.List(selector)
+	if err != nil {
+		return err
+	}
+	for _, route := range tlsroutes {
+		if !orphaned(route) {
+			continue
+		}
+		err := c.gwapiclient.GatewayV1alpha2().TLSRoutes(route.Namespace).Delete(ctx, route.Name, sweepDeleteOptions(route))
+		if err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete orphaned TLSRoute %s/%s: %w", route.Namespace, route.Name, err)
+		}
+	}
+
+	grants, err := c.referenceGrantLister.List(selector)
+	if err != nil {
+		return err
+	}
+	for _, grant := range grants {
+		if !orphaned(grant) {
+			continue
+		}
+		err := c.gwapiclient.GatewayV1beta1().ReferenceGrants(grant.Namespace).Delete(ctx, grant.Name, sweepDeleteOptions(grant))
+		if err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete orphaned ReferenceGrant %s/%s: %w", grant.Namespace, grant.Name, err)
+		}
+	}
+
+	if policyTemplate == nil {
+		return nil
+	}
+	gvk := policyTemplate.GroupVersionKind()
+	mapping, err := c.restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the resource of %s: %w", gvk, err)
+	}
+	client := c.dynamicClient.Resource(mapping.Resource)
+	policies, err := client.List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return fmt.Errorf("failed to list the %s policies: %w", gvk.Kind, err)
+	}
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		if !orphaned(policy) {
+			continue
+		}
+		err := client.Namespace(policy.GetNamespace()).Delete(ctx, policy.GetName(), sweepDeleteOptions(policy))
+		if err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete orphaned %s %s/%s: %w", gvk.Kind, policy.GetNamespace(), policy.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// sweepDeleteOptions only deletes the swept object, not another one created
+// with the same name since it was listed.
+func sweepDeleteOptions(obj metav1.Object) metav1.DeleteOptions {
+	return metav1.DeleteOptions{Preconditions: metav1.NewUIDPreconditions(string(obj.GetUID()))}
+}
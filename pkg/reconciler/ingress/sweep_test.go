@@ -0,0 +1,165 @@
+/*
+Copyright 2025 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clientgotesting "k8s.io/client-go/testing"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	fakegatewayclientset "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/resources"
+	. "knative.dev/net-gateway-api/pkg/reconciler/testing"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/kmeta"
+)
+
+func TestSweepOrphans(t *testing.T) {
+	policyGVK := schema.GroupVersionKind{Group: "policy.example.com", Version: "v1", Kind: "RetryBudget"}
+	policyGVR := policyGVK.GroupVersion().WithResource("retrybudgets")
+	policyTemplate := &unstructured.Unstructured{}
+	policyTemplate.SetGroupVersionKind(policyGVK)
+
+	live := ing(withBasicSpec, withGatewayAPIClass, func(i *v1alpha1.Ingress) {
+		i.UID = "uid-name"
+	})
+	labeled := func(name, namespace, ingressName, uid string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				networking.IngressLabelKey:   ingressName,
+				resources.IngressUIDLabelKey: uid,
+			},
+		}
+	}
+	route := func(name, ingressName, uid string, owned bool) *gatewayapi.HTTPRoute {
+		r := &gatewayapi.HTTPRoute{ObjectMeta: labeled(name, "routes", ingressName, uid)}
+		r.Labels[resources.IngressNamespaceLabelKey] = "ns"
+		if owned {
+			r.Namespace = "ns"
+			r.OwnerReferences = []metav1.OwnerReference{*kmeta.NewControllerRef(ing(func(i *v1alpha1.Ingress) {
+				i.Name = ingressName
+				i.UID = types.UID(uid)
+			}))}
+		}
+		return r
+	}
+	policy := func(name, uid string) *unstructured.Unstructured {
+		p := &unstructured.Unstructured{}
+		p.SetGroupVersionKind(policyGVK)
+		meta := labeled(name, "ns", "name", uid)
+		p.SetName(meta.Name)
+		p.SetNamespace(meta.Namespace)
+		p.SetLabels(meta.Labels)
+		return p
+	}
+
+	objs := []runtime.Object{
+		live,
+		route("live", "name", "uid-name", false),
+		route("orphaned", "deleted", "uid-deleted", false),
+		// Left behind by a deleted Ingress whose finalizer was removed
+		route("owned", "deleted", "uid-deleted", true),
+		// The Ingress was recreated with the same name
+		route("recreated", "name", "uid-previous", false),
+		// Not generated for an Ingress
+		&gatewayapi.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: "unlabeled", Namespace: "routes"}},
+		&gatewayapiv1alpha2.TLSRoute{ObjectMeta: labeled("live", "ns", "name", "uid-name")},
+		&gatewayapiv1alpha2.TLSRoute{ObjectMeta: labeled("orphaned", "ns", "deleted", "uid-deleted")},
+		&gatewayapiv1beta1.ReferenceGrant{ObjectMeta: labeled("live", "ns", "name", "uid-name")},
+		&gatewayapiv1beta1.ReferenceGrant{ObjectMeta: labeled("orphaned", "ns", "deleted", "uid-deleted")},
+	}
+
+	for _, tc := range []struct {
+		name         string
+		leader       bool
+		wantDeleted  sets.Set[string]
+		wantPolicies sets.Set[string]
+	}{{
+		name:   "leader",
+		leader: true,
+		wantDeleted: sets.New(
+			"httproutes routes/orphaned",
+			"httproutes ns/owned",
+			"httproutes routes/recreated",
+			"tlsroutes ns/orphaned",
+			"referencegrants ns/orphaned",
+		),
+		wantPolicies: sets.New("retrybudgets ns/orphaned"),
+	}, {
+		name:         "not the leader",
+		wantDeleted:  sets.New[string](),
+		wantPolicies: sets.New[string](),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			listers := NewListers(objs)
+			client := fakegatewayclientset.NewSimpleClientset(listers.GetGatewayAPIObjects()...)
+			dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{policyGVR: policyGVK.Kind + "List"},
+				policy("live", "uid-name"), policy("orphaned", "uid-deleted"))
+			mapper := meta.NewDefaultRESTMapper(nil)
+			mapper.Add(policyGVK, meta.RESTScopeNamespace)
+
+			r := &Reconciler{
+				gwapiclient:          client,
+				httprouteLister:      listers.GetHTTPRouteLister(),
+				tlsrouteLister:       listers.GetTLSRouteLister(),
+				referenceGrantLister: listers.GetReferenceGrantLister(),
+				dynamicClient:        dynamicClient,
+				restMapper:           mapper,
+			}
+			isLeader := func(types.NamespacedName) bool { return tc.leader }
+			if err := r.sweepOrphans(context.Background(), listers.GetIngressLister(), policyTemplate, isLeader); err != nil {
+				t.Fatal("sweepOrphans() =", err)
+			}
+
+			if got := deletedObjects(client.Actions()); !got.Equal(tc.wantDeleted) {
+				t.Error("Deleted objects (-want, +got):", cmp.Diff(sets.List(tc.wantDeleted), sets.List(got)))
+			}
+			if got := deletedObjects(dynamicClient.Actions()); !got.Equal(tc.wantPolicies) {
+				t.Error("Deleted policies (-want, +got):", cmp.Diff(sets.List(tc.wantPolicies), sets.List(got)))
+			}
+		})
+	}
+}
+
+// deletedObjects returns the resource and key of the objects deleted by the
+// actions.
+func deletedObjects(actions []clientgotesting.Action) sets.Set[string] {
+	deleted := sets.New[string]()
+	for _, action := range actions {
+		if del, ok := action.(clientgotesting.DeleteAction); ok {
+			deleted.Insert(del.GetResource().Resource + " " + del.GetNamespace() + "/" + del.GetName())
+		}
+	}
+	return deleted
+}
@@ -29,9 +29,11 @@ import (
 	"knative.dev/pkg/reconciler/testing"
 
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 	fakegatewayapiclientset "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
 	gatewaylisters "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1"
+	gatewaylistersv1alpha2 "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1alpha2"
 	gatewaylistersv1beta1 "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1beta1"
 )
 
@@ -97,15 +99,37 @@ func (l *Listers) GetHTTPRouteLister() gatewaylisters.HTTPRouteLister {
 	return gatewaylisters.NewHTTPRouteLister(l.IndexerFor(&gatewayv1.HTTPRoute{}))
 }
 
+// GetTLSRouteLister get lister for TLSRoute resource.
+func (l *Listers) GetTLSRouteLister() gatewaylistersv1alpha2.TLSRouteLister {
+	return gatewaylistersv1alpha2.NewTLSRouteLister(l.IndexerFor(&gatewayv1alpha2.TLSRoute{}))
+}
+
 // GetEndpointsLister get lister for K8s Endpoints resource.
 func (l *Listers) GetEndpointsLister() corev1listers.EndpointsLister {
 	return corev1listers.NewEndpointsLister(l.IndexerFor(&corev1.Endpoints{}))
 }
 
+// GetNodeLister get lister for K8s Node resource.
+func (l *Listers) GetNodeLister() corev1listers.NodeLister {
+	return corev1listers.NewNodeLister(l.IndexerFor(&corev1.Node{}))
+}
+
 func (l *Listers) GetGatewayLister() gatewaylisters.GatewayLister {
 	return gatewaylisters.NewGatewayLister(l.IndexerFor(&gatewayv1.Gateway{}))
 }
 
+func (l *Listers) GetGatewayClassLister() gatewaylisters.GatewayClassLister {
+	return gatewaylisters.NewGatewayClassLister(l.IndexerFor(&gatewayv1.GatewayClass{}))
+}
+
 func (l *Listers) GetReferenceGrantLister() gatewaylistersv1beta1.ReferenceGrantLister {
 	return gatewaylistersv1beta1.NewReferenceGrantLister(l.IndexerFor(&gatewayv1beta1.ReferenceGrant{}))
 }
+
+func (l *Listers) GetSecretLister() corev1listers.SecretLister {
+	return corev1listers.NewSecretLister(l.IndexerFor(&corev1.Secret{}))
+}
+
+func (l *Listers) GetServiceLister() corev1listers.ServiceLister {
+	return corev1listers.NewServiceLister(l.IndexerFor(&corev1.Service{}))
+}
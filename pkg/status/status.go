@@ -17,9 +17,14 @@ limitations under the License.
 package status
 
 import (
+	"cmp"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"net"
 	"net/http"
 	"net/url"
@@ -29,12 +34,15 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/net/http2"
 	"golang.org/x/time/rate"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
 
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	nethttp "knative.dev/networking/pkg/http"
@@ -52,23 +60,93 @@ const (
 	// initialDelay defines the delay before enqueuing a probing request the first time.
 	// It gives times for the change to propagate and prevents unnecessary retries.
 	initialDelay = 200 * time.Millisecond
+	// probeCacheTTL is how long a successful probe of a URL on a pod is reused
+	// by the other routes of the same version probing it, e.g. the HTTPRoutes
+	// a rule of an Ingress is split into.
+	probeCacheTTL = 5 * time.Second
 )
 
 var dialContext = (&net.Dialer{Timeout: probeTimeout}).DialContext
 
+// h2cScheme is the scheme of the URLs probed with HTTP/2 over cleartext.
+const h2cScheme = "h2c"
+
+// defaultRetryStatusCodes are the response status codes meaning that the route
+// isn't ready yet, when the Backends don't set them.
+var defaultRetryStatusCodes = sets.New(http.StatusNotFound, http.StatusServiceUnavailable)
+
 // ingressState represents the probing state of an Ingress
 type routeState struct {
 	version     string
 	key         types.NamespacedName
 	callbackKey types.NamespacedName
+	// tlsPassthrough is true when the route is probed with a TLS handshake
+	// instead of an HTTP request.
+	tlsPassthrough bool
+	// headers are the extra headers sent with the probe requests.
+	headers map[string]string
+	// retryStatusCodes are the response status codes meaning that the route
+	// isn't ready yet.
+	retryStatusCodes sets.Set[int]
+	// http1Only is true when the probes must not negotiate HTTP/2.
+	http1Only bool
+	// userAgent is the User-Agent of the probe requests.
+	userAgent string
+	// versionHeader is the response header carrying the version of the
+	// probed route, header.HashKey when empty.
+	versionHeader string
+	// maxConcurrentProbes caps the work items of the routes of the Ingress
+	// of callbackKey queued or being processed at once, unlimited when zero.
+	maxConcurrentProbes int
+	// getClientCertificate returns the client certificate presented in the
+	// TLS handshakes of the probes, none when nil.
+	getClientCertificate func(*tls.CertificateRequestInfo) (*tls.Certificate, error)
+	// getRootCAs returns the CAs verifying the certificates of the Gateways
+	// in the TLS handshakes of the probes, which aren't verified when nil.
+	getRootCAs func() (*x509.CertPool, error)
 
 	// pendingCount is the number of pods that haven't been successfully probed yet
 	pendingCount atomic.Int64
 	lastAccessed time.Time
+	// lastReady is the last time (in Unix nanoseconds) all the probes for this
+	// key succeeded, carried over from the previous versions of the route.
+	lastReady atomic.Int64
+	// lastFailure describes the last failed probe of this version.
+	lastFailure atomic.Pointer[string]
 
 	cancel func()
 }
 
+func (rs *routeState) lastReadyTime() time.Time {
+	if nanos := rs.lastReady.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
+func (rs *routeState) lastFailureMessage() string {
+	if msg := rs.lastFailure.Load(); msg != nil {
+		return *msg
+	}
+	return ""
+}
+
+func (rs *routeState) setLastFailure(u *url.URL, err error) {
+	msg := fmt.Sprintf("%s: not ready", u)
+	if err != nil {
+		msg = fmt.Sprintf("%s: %v", u, err)
+	}
+	rs.lastFailure.Store(&msg)
+}
+
+func (rs *routeState) setLastReady(t time.Time) {
+	if t.IsZero() {
+		rs.lastReady.Store(0)
+		return
+	}
+	rs.lastReady.Store(t.UnixNano())
+}
+
 // podState represents the probing state of a Pod (for a specific Ingress)
 type podState struct {
 	// pendingCount is the number of probes for the Pod
@@ -93,17 +171,46 @@ type workItem struct {
 	logger     *zap.SugaredLogger
 }
 
+// probeThrottle holds the work items of the routes of an Ingress past its cap
+// on concurrent probes. They are released one at a time as the others are
+// done, at the back of the queue, so that the Ingress doesn't crowd out the
+// probes of the other Ingresses.
+type probeThrottle struct {
+	inFlight int
+	backlog  []*workItem
+}
+
+// probeCacheKey identifies the probes of a URL of a version on a pod. As the
+// version is the hash of an Ingress and the URL one of its hosts, the cache is
+// effectively per Ingress: the probes are only reused by its own routes. They
+// can't be shared with the other Ingresses on the pod, since a probe only
+// proves that the pod serves the version for the host of its URL.
+type probeCacheKey struct {
+	podIP   string
+	podPort string
+	version string
+	url     string
+}
+
 // ProbeTarget contains the URLs to probes for a set of Pod IPs serving out of the same port.
 type ProbeTarget struct {
 	PodIPs  sets.Set[string]
 	PodPort string
 	Port    string
-	URLs    []*url.URL
+	// URLs are the URLs to probe. The URLs with the "h2c" scheme are probed
+	// with HTTP/2 over cleartext, with prior knowledge.
+	URLs []*url.URL
 }
 
 type ProbeState struct {
 	Version string
 	Ready   bool
+	// LastReady is the last time probing of the route succeeded, across
+	// versions. It is zero if the route has never been ready.
+	LastReady time.Time
+	// LastFailure describes the last failed probe of the current version,
+	// with its URL and error. It is empty if none failed.
+	LastFailure string
 }
 
 type Backends struct {
@@ -112,6 +219,46 @@ type Backends struct {
 	Version     string
 	URLs        map[Visibility]URLSet
 	HTTPOption  v1alpha1.HTTPOption
+	// TLSPassthrough is true when the Gateway routes TLS connections to the
+	// backends without terminating them, so probing is a TLS handshake with
+	// the URL host as SNI.
+	TLSPassthrough bool
+	// Headers are extra static headers sent with the probe requests, e.g.
+	// for Gateways that require authentication.
+	Headers map[string]string
+	// InitialDelay overrides the delay before the first probes of a new
+	// version are enqueued. The default initialDelay is used when nil.
+	InitialDelay *time.Duration
+	// RetryStatusCodes are the response status codes meaning that the route
+	// isn't ready yet. The defaultRetryStatusCodes are used when empty.
+	RetryStatusCodes sets.Set[int]
+	// HTTP1Only is true when the probes must stick to HTTP/1.1, for Gateways
+	// that fail when HTTP/2 is negotiated on TLS connections.
+	HTTP1Only bool
+	// UserAgent overrides the User-Agent of the probe requests, e.g. for
+	// Gateways that only allow known user agents. The
+	// header.IngressReadinessUserAgent is used when empty.
+	UserAgent string
+	// VersionHeader is the response header carrying the version of the
+	// probed routes, for Gateways that strip the header.HashKey header, which
+	// is used when empty.
+	VersionHeader string
+	// MaxConcurrentProbes caps the number of probes of the routes of the
+	// Ingress of CallbackKey queued or in flight at once, e.g. for Ingresses
+	// with many backends. The probes are not capped when zero.
+	MaxConcurrentProbes int
+	// GetClientCertificate returns the client certificate presented in the
+	// TLS handshakes of the probe requests, for Gateways requiring mutual
+	// TLS, e.g. behind a mesh sidecar. It is called for each handshake so
+	// that rotated certificates are picked up. No certificate is presented
+	// when nil.
+	GetClientCertificate func(*tls.CertificateRequestInfo) (*tls.Certificate, error)
+	// GetRootCAs returns the CAs verifying the certificates presented by the
+	// Gateways in the TLS handshakes of the probe requests, the roots of the
+	// system when it returns a nil pool. It is called for each handshake so
+	// that rotated CAs are picked up. The certificates aren't verified when
+	// nil, as the probes only check that the Gateways are configured.
+	GetRootCAs func() (*x509.CertPool, error)
 }
 
 func (b *Backends) AddURL(v Visibility, u url.URL) {
@@ -153,7 +300,16 @@ type Prober struct {
 	routeStates map[types.NamespacedName]*routeState
 	podContexts map[string]cancelContext
 
-	workQueue workqueue.TypedRateLimitingInterface[any]
+	// cacheMu guards probeCache, the time of the last successful probes
+	cacheMu    sync.Mutex
+	probeCache map[probeCacheKey]time.Time
+
+	// throttleMu guards throttles, the work items held back by Ingress
+	throttleMu sync.Mutex
+	throttles  map[types.NamespacedName]*probeThrottle
+
+	workQueue   workqueue.TypedRateLimitingInterface[any]
+	rateLimiter workqueue.TypedRateLimiter[any]
 
 	targetLister ProbeTargetLister
 
@@ -162,54 +318,157 @@ type Prober struct {
 	probeConcurrency int
 }
 
+// RateLimiterConfig are the parameters of the rate limiter of the probes.
+type RateLimiterConfig struct {
+	// QPS and Burst limit the rate of the probes of all the routes.
+	QPS   float64
+	Burst int
+
+	// BaseDelay and MaxDelay bound the exponential backoff of the retries of
+	// a probe.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// Jitter is the maximum fraction of the backoff randomly added to it, so
+	// that the retries of probes failing together spread out.
+	Jitter float64
+}
+
+// DefaultRateLimiterConfig returns the default parameters of the rate limiter
+// of the probes.
+func DefaultRateLimiterConfig() RateLimiterConfig {
+	return RateLimiterConfig{
+		QPS:       50,
+		Burst:     100,
+		BaseDelay: 50 * time.Millisecond,
+		MaxDelay:  30 * time.Second,
+	}
+}
+
 // NewProber creates a new instance of Prober
 func NewProber(
 	logger *zap.SugaredLogger,
 	targetLister ProbeTargetLister,
 	readyCallback func(types.NamespacedName),
+	rateLimiterConfig RateLimiterConfig,
 ) *Prober {
+	rateLimiter := newRateLimiter(rateLimiterConfig)
 	return &Prober{
 		logger:      logger,
 		routeStates: make(map[types.NamespacedName]*routeState),
 		podContexts: make(map[string]cancelContext),
+		probeCache:  make(map[probeCacheKey]time.Time),
+		throttles:   make(map[types.NamespacedName]*probeThrottle),
 		workQueue: workqueue.NewTypedRateLimitingQueueWithConfig(
-			workqueue.NewTypedMaxOfRateLimiter(
-				// Per item exponential backoff
-				workqueue.NewTypedItemExponentialFailureRateLimiter[any](50*time.Millisecond, 30*time.Second),
-				// Global rate limiter
-				&workqueue.TypedBucketRateLimiter[any]{Limiter: rate.NewLimiter(rate.Limit(50), 100)},
-			),
+			rateLimiter,
 			workqueue.TypedRateLimitingQueueConfig[any]{Name: "ProbingQueue"}),
+		rateLimiter:      rateLimiter,
 		targetLister:     targetLister,
 		readyCallback:    readyCallback,
 		probeConcurrency: probeConcurrency,
 	}
 }
 
+// newRateLimiter returns the rate limiter of the probes with the parameters
+// of the config.
+func newRateLimiter(cfg RateLimiterConfig) workqueue.TypedRateLimiter[any] {
+	var backoff workqueue.TypedRateLimiter[any] = workqueue.NewTypedItemExponentialFailureRateLimiter[any](cfg.BaseDelay, cfg.MaxDelay)
+	if cfg.Jitter > 0 {
+		backoff = &jitterRateLimiter{TypedRateLimiter: backoff, jitter: cfg.Jitter}
+	}
+	return workqueue.NewTypedMaxOfRateLimiter(
+		// Per item exponential backoff
+		backoff,
+		// Global rate limiter
+		&workqueue.TypedBucketRateLimiter[any]{Limiter: rate.NewLimiter(rate.Limit(cfg.QPS), cfg.Burst)},
+	)
+}
+
+// jitterRateLimiter randomly adds up to the jitter fraction of the delays of
+// the rate limiter it wraps.
+type jitterRateLimiter struct {
+	workqueue.TypedRateLimiter[any]
+	jitter float64
+}
+
+func (r *jitterRateLimiter) When(item any) time.Duration {
+	return wait.Jitter(r.TypedRateLimiter.When(item), r.jitter)
+}
+
 // IsProbeActive will return the state of the probes for the given key
 func (m *Prober) IsProbeActive(key types.NamespacedName) (ProbeState, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	if ingState, ok := m.routeStates[key]; ok {
-		return ProbeState{Version: ingState.version, Ready: ingState.pendingCount.Load() == 0}, true
+		return ProbeState{
+			Version:     ingState.version,
+			Ready:       ingState.pendingCount.Load() == 0,
+			LastReady:   ingState.lastReadyTime(),
+			LastFailure: ingState.lastFailureMessage(),
+		}, true
 	}
 	return ProbeState{}, false
 }
 
+// Stats are counts describing the probing backlog of a Prober.
+type Stats struct {
+	// QueueDepth is the number of probes waiting to be processed.
+	QueueDepth int `json:"queueDepth"`
+	// ActiveRoutes is the number of routes with a probing state.
+	ActiveRoutes int `json:"activeRoutes"`
+	// PendingRoutes is the number of those routes that aren't ready yet.
+	PendingRoutes int `json:"pendingRoutes"`
+}
+
+// Stats returns the current probing backlog.
+func (m *Prober) Stats() Stats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := Stats{
+		QueueDepth:   m.workQueue.Len(),
+		ActiveRoutes: len(m.routeStates),
+	}
+	for _, state := range m.routeStates {
+		if state.pendingCount.Load() > 0 {
+			stats.PendingRoutes++
+		}
+	}
+	return stats
+}
+
+// StatsHandler returns a handler serving the Stats of the Prober as JSON.
+func StatsHandler(m *Prober) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(m.Stats()); err != nil {
+			m.logger.Errorw("Failed to write prober stats", zap.Error(err))
+		}
+	})
+}
+
 // DoProbes will start probing the desired backends. If probing is already active with the
 // correct backend versions it will return the current state.
 func (m *Prober) DoProbes(ctx context.Context, backends Backends) (ProbeState, error) {
+	var lastReady time.Time
 	if state, ok := func() (ProbeState, bool) {
 		m.mu.Lock()
 		defer m.mu.Unlock()
 		if ingState, ok := m.routeStates[backends.Key]; ok {
-			pstate := ProbeState{Version: ingState.version}
+			pstate := ProbeState{Version: ingState.version, LastReady: ingState.lastReadyTime()}
 			if ingState.version == backends.Version {
 				ingState.lastAccessed = time.Now()
 				pstate.Ready = ingState.pendingCount.Load() == 0
+				pstate.LastFailure = ingState.lastFailureMessage()
 				return pstate, true
 			}
 
+			// The outdated version was ready up until now
+			lastReady = pstate.LastReady
+			if ingState.pendingCount.Load() == 0 {
+				lastReady = time.Now()
+			}
+
 			// Cancel the polling for the outdated version
 			ingState.cancel()
 			delete(m.routeStates, backends.Key)
@@ -225,34 +484,45 @@ func (m *Prober) DoProbes(ctx context.Context, backends Backends) (ProbeState, e
 	}
 
 	logger := logging.FromContext(ctx)
-	ready := m.probeRequest(logger,
-		backends.Version,
-		backends.Key,
-		backends.CallbackKey,
-		targets,
-	)
+	ready := m.probeRequest(logger, backends, targets, lastReady)
+
+	if ready {
+		lastReady = time.Now()
+	}
 
 	return ProbeState{
-		Version: backends.Version,
-		Ready:   ready,
+		Version:   backends.Version,
+		Ready:     ready,
+		LastReady: lastReady,
 	}, nil
 }
 
 func (m *Prober) probeRequest(
 	logger *zap.SugaredLogger,
-	version string,
-	key types.NamespacedName,
-	callbackKey types.NamespacedName,
+	backends Backends,
 	targets []ProbeTarget,
+	lastReady time.Time,
 ) bool {
 	ingCtx, cancel := context.WithCancel(context.Background())
 	routeState := &routeState{
-		version:      version,
-		key:          key,
-		callbackKey:  callbackKey,
-		lastAccessed: time.Now(),
-		cancel:       cancel,
+		version:          backends.Version,
+		key:              backends.Key,
+		callbackKey:      backends.CallbackKey,
+		tlsPassthrough:   backends.TLSPassthrough,
+		headers:          backends.Headers,
+		retryStatusCodes: backends.RetryStatusCodes,
+		http1Only:        backends.HTTP1Only,
+		userAgent:        cmp.Or(backends.UserAgent, header.IngressReadinessUserAgent),
+		versionHeader:    backends.VersionHeader,
+		lastAccessed:     time.Now(),
+		cancel:           cancel,
+
+		maxConcurrentProbes:  backends.MaxConcurrentProbes,
+		getClientCertificate: backends.GetClientCertificate,
+		getRootCAs:           backends.GetRootCAs,
 	}
+	routeState.setLastReady(lastReady)
+	delay := ptr.Deref(backends.InitialDelay, initialDelay)
 
 	workItems := make(map[string][]*workItem)
 	for _, target := range targets {
@@ -270,6 +540,9 @@ func (m *Prober) probeRequest(
 	}
 
 	routeState.pendingCount.Store(int64(len(workItems)))
+	if len(workItems) == 0 {
+		routeState.setLastReady(time.Now())
+	}
 
 	for ip, ipWorkItems := range workItems {
 		// Get or create the context for that IP
@@ -316,7 +589,9 @@ func (m *Prober) probeRequest(
 		for _, wi := range ipWorkItems {
 			wi.podState = podState
 			wi.context = podCtx //nolint:fatcontext
-			m.workQueue.AddAfter(wi, initialDelay)
+		}
+		for _, wi := range m.throttle(routeState, ipWorkItems) {
+			m.workQueue.AddAfter(wi, delay)
 			logger.Infof("Queuing probe for %s, IP: %s:%s (version: %s)(depth: %d)",
 				wi.url, wi.podIP, wi.podPort, wi.routeState.version, m.workQueue.Len())
 		}
@@ -325,7 +600,7 @@ func (m *Prober) probeRequest(
 	func() {
 		m.mu.Lock()
 		defer m.mu.Unlock()
-		m.routeStates[key] = routeState
+		m.routeStates[backends.Key] = routeState
 	}()
 	return len(workItems) == 0
 }
@@ -373,16 +648,62 @@ func (m *Prober) CancelIngressProbing(obj interface{}) {
 
 // CancelIngressProbingByKey cancels probing of the Ingress identified by the provided key.
 func (m *Prober) CancelIngressProbingByKey(key types.NamespacedName) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	for _, v := range m.routeStates {
-		if v.callbackKey == key {
-			v.cancel()
-			delete(m.routeStates, key)
-		}
+	m.cancelRoutes(key)
+}
+
+// RefreshIngress cancels probing of the Ingress identified by the provided key
+// and notifies its owner, so that the next DoProbes probes it from scratch even
+// when its version is unchanged, e.g. after the gateway was reconfigured.
+func (m *Prober) RefreshIngress(key types.NamespacedName) {
+	if m.cancelRoutes(key) {
+		m.readyCallback(key)
 	}
 }
 
+// cancelRoutes cancels probing of the routes of the Ingress identified by the
+// provided key and drops their cached probes. It returns whether the Ingress
+// had routes being probed.
+func (m *Prober) cancelRoutes(key types.NamespacedName) bool {
+	versions := func() sets.Set[string] {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		versions := sets.New[string]()
+		for k, v := range m.routeStates {
+			if v.callbackKey == key {
+				v.cancel()
+				delete(m.routeStates, k)
+				versions.Insert(v.version)
+			}
+		}
+		return versions
+	}()
+	m.uncacheProbes(versions)
+	return versions.Len() > 0
+}
+
+// RefreshHandler returns a handler refreshing the probing of the Ingress
+// named by the "namespace" and "name" query parameters of POST requests.
+func RefreshHandler(m *Prober) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		key := types.NamespacedName{
+			Namespace: r.URL.Query().Get("namespace"),
+			Name:      r.URL.Query().Get("name"),
+		}
+		if key.Namespace == "" || key.Name == "" {
+			http.Error(w, `the "namespace" and "name" query parameters are required`, http.StatusBadRequest)
+			return
+		}
+		m.logger.Infof("Refreshing the probing of %s", key)
+		m.RefreshIngress(key)
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
+
 // CancelPodProbing cancels probing of the provided Pod IP.
 //
 // TODO(#6269): make this cancellation based on Pod x port instead of just Pod.
@@ -395,6 +716,13 @@ func (m *Prober) CancelPodProbing(obj interface{}) {
 			ctx.cancel()
 			delete(m.podContexts, pod.Status.PodIP)
 		}
+
+		// The IP may be reused by another pod
+		m.cacheMu.Lock()
+		defer m.cacheMu.Unlock()
+		maps.DeleteFunc(m.probeCache, func(key probeCacheKey, _ time.Time) bool {
+			return key.podIP == pod.Status.PodIP
+		})
 	}
 }
 
@@ -417,12 +745,47 @@ func (m *Prober) processWorkItem() bool {
 	item.logger.Infof("Processing probe for %s, IP: %s:%s (depth: %d)",
 		item.url, item.podIP, item.podPort, m.workQueue.Len())
 
+	if item.routeState.tlsPassthrough {
+		return m.processTLSWorkItem(obj, item)
+	}
+
+	// Another route of the same version just probed the pod
+	if item.context.Err() == nil && m.cachedProbe(item) {
+		item.logger.Infof("Reusing the probe of %s, IP: %s:%s (version: %s)",
+			item.url, item.podIP, item.podPort, item.routeState.version)
+		m.onProbingSuccess(item.routeState, item.podState)
+		m.release(item)
+		return true
+	}
+
+	probeURL := deepCopy(item.url)
+
+	if probeURL.Path == "" {
+		probeURL.Path = nethttp.HealthCheckPath
+	}
+
 	transport := http.DefaultTransport.(*http.Transport).Clone()
 	transport.TLSClientConfig = &tls.Config{
 		//nolint:gosec
 		// We only want to know that the Gateway is configured, not that the configuration is valid.
-		// Therefore, we can safely ignore any TLS certificate validation.
+		// Therefore, we can safely ignore any TLS certificate validation, unless the
+		// certificates are verified against the CAs of the route below.
 		InsecureSkipVerify: true,
+		// Set explicitly so that the handshakes always send the route host
+		// as SNI, which Gateways use to select the certificate and listener
+		ServerName:           probeURL.Hostname(),
+		GetClientCertificate: item.routeState.getClientCertificate,
+	}
+	if getRootCAs := item.routeState.getRootCAs; getRootCAs != nil {
+		transport.TLSClientConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyConnection(cs, getRootCAs)
+		}
+	}
+	if item.routeState.http1Only {
+		// A non-nil empty TLSNextProto disables HTTP/2, which is otherwise
+		// negotiated with ALPN on TLS connections
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
 	}
 	transport.DialContext = func(ctx context.Context, network, _ string) (conn net.Conn, e error) {
 		// Requests with the IP as hostname and the Host header set do no pass client-side validation
@@ -431,39 +794,224 @@ func (m *Prober) processWorkItem() bool {
 		// hostname and it is substituted it here with the target IP.
 		return dialContext(ctx, network, net.JoinHostPort(item.podIP, item.podPort))
 	}
-
-	probeURL := deepCopy(item.url)
-
-	if probeURL.Path == "" {
-		probeURL.Path = nethttp.HealthCheckPath
+	// The transports dial the pod of a single probe, so their connections
+	// are closed when it is done rather than kept idle.
+	defer transport.CloseIdleConnections()
+	var roundTripper http.RoundTripper = transport
+	if probeURL.Scheme == h2cScheme {
+		probeURL.Scheme = "http"
+		h2cTransport := &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, _ string, _ *tls.Config) (net.Conn, error) {
+				return dialContext(ctx, network, net.JoinHostPort(item.podIP, item.podPort))
+			},
+		}
+		defer h2cTransport.CloseIdleConnections()
+		roundTripper = h2cTransport
 	}
 
-	ctx, cancel := context.WithTimeout(item.context, probeTimeout)
-	defer cancel()
-	ok, err := prober.Do(
-		ctx,
-		transport,
-		probeURL.String(),
-		prober.WithHeader(header.UserAgentKey, header.IngressReadinessUserAgent),
+	opts := make([]interface{}, 0, len(item.routeState.headers)+4)
+	for name, value := range item.routeState.headers {
+		opts = append(opts, prober.WithHeader(name, value))
+	}
+	// The probe headers are added last so they can't be overridden
+	opts = append(opts,
+		prober.WithHeader(header.UserAgentKey, item.routeState.userAgent),
 		prober.WithHeader(header.ProbeKey, header.ProbeValue),
 		prober.WithHeader(header.HashKey, header.HashValueOverride),
 		m.probeVerifier(item))
 
+	ctx, cancel := context.WithTimeout(item.context, probeTimeout)
+	defer cancel()
+	ok, err := prober.Do(ctx, roundTripper, probeURL.String(), opts...)
+
 	// In case of cancellation, drop the work item
 	select {
 	case <-item.context.Done():
 		m.workQueue.Forget(obj)
+		m.release(item)
 		return true
 	default:
 	}
 
 	if err != nil || !ok {
 		// In case of error, enqueue for retry
+		item.routeState.setLastFailure(item.url, err)
 		m.workQueue.AddRateLimited(obj)
 		item.logger.Errorf("Probing of %s failed, IP: %s:%s, ready: %t, error: %v (depth: %d)",
 			item.url, item.podIP, item.podPort, ok, err, m.workQueue.Len())
+	} else {
+		m.cacheProbe(item)
+		m.onProbingSuccess(item.routeState, item.podState)
+		m.release(item)
+	}
+	return true
+}
+
+// throttle returns the work items of the route that can be queued within the
+// cap of its Ingress on concurrent probes, and holds back the others until
+// release is called for the ones in flight.
+func (m *Prober) throttle(routeState *routeState, items []*workItem) []*workItem {
+	// Without items, a throttle with nothing in flight would never be released
+	if routeState.maxConcurrentProbes <= 0 || len(items) == 0 {
+		return items
+	}
+
+	m.throttleMu.Lock()
+	defer m.throttleMu.Unlock()
+	t, ok := m.throttles[routeState.callbackKey]
+	if !ok {
+		t = &probeThrottle{}
+		m.throttles[routeState.callbackKey] = t
+	}
+
+	n := max(0, min(len(items), routeState.maxConcurrentProbes-t.inFlight))
+	t.inFlight += n
+	t.backlog = append(t.backlog, items[n:]...)
+	return items[:n]
+}
+
+// release is called when the work item is done, to queue the next work item
+// held back by the cap of its Ingress on concurrent probes, if any. The held
+// back work items whose probing was cancelled in the meantime are dropped.
+func (m *Prober) release(item *workItem) {
+	if item.routeState.maxConcurrentProbes <= 0 {
+		return
+	}
+
+	next := func() *workItem {
+		m.throttleMu.Lock()
+		defer m.throttleMu.Unlock()
+		t, ok := m.throttles[item.routeState.callbackKey]
+		if !ok {
+			return nil
+		}
+		for len(t.backlog) > 0 {
+			next := t.backlog[0]
+			t.backlog = t.backlog[1:]
+			if next.context.Err() == nil {
+				return next
+			}
+		}
+		if t.inFlight--; t.inFlight <= 0 {
+			delete(m.throttles, item.routeState.callbackKey)
+		}
+		return nil
+	}()
+	if next != nil {
+		m.workQueue.Add(next)
+		next.logger.Infof("Queuing held back probe for %s, IP: %s:%s (version: %s)(depth: %d)",
+			next.url, next.podIP, next.podPort, next.routeState.version, m.workQueue.Len())
+	}
+}
+
+func newProbeCacheKey(item *workItem) probeCacheKey {
+	return probeCacheKey{
+		podIP:   item.podIP,
+		podPort: item.podPort,
+		version: item.routeState.version,
+		url:     item.url.String(),
+	}
+}
+
+// cachedProbe returns whether the probe of the work item succeeded less than
+// probeCacheTTL ago.
+func (m *Prober) cachedProbe(item *workItem) bool {
+	m.cacheMu.Lock()
+	defer m.cacheMu.Unlock()
+	probed, ok := m.probeCache[newProbeCacheKey(item)]
+	return ok && time.Since(probed) < probeCacheTTL
+}
+
+// uncacheProbes drops the successful probes of the versions, so that they are
+// probed again from scratch.
+func (m *Prober) uncacheProbes(versions sets.Set[string]) {
+	if versions.Len() == 0 {
+		return
+	}
+	m.cacheMu.Lock()
+	defer m.cacheMu.Unlock()
+	maps.DeleteFunc(m.probeCache, func(key probeCacheKey, _ time.Time) bool {
+		return versions.Has(key.version)
+	})
+}
+
+// cacheProbe records the success of the probe of the work item, and drops the
+// expired ones.
+func (m *Prober) cacheProbe(item *workItem) {
+	now := time.Now()
+	m.cacheMu.Lock()
+	defer m.cacheMu.Unlock()
+	maps.DeleteFunc(m.probeCache, func(_ probeCacheKey, probed time.Time) bool {
+		return now.Sub(probed) >= probeCacheTTL
+	})
+	m.probeCache[newProbeCacheKey(item)] = now
+}
+
+// verifyConnection verifies the certificate chain presented in the TLS
+// handshake for its server name against the CAs returned by getRootCAs.
+func verifyConnection(cs tls.ConnectionState, getRootCAs func() (*x509.CertPool, error)) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("no certificate presented by the Gateway")
+	}
+	roots, err := getRootCAs()
+	if err != nil {
+		return err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err = cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+// processTLSWorkItem probes a route exposed with TLS passthrough by completing a
+// TLS handshake with the URL host as SNI.
+func (m *Prober) processTLSWorkItem(obj any, item *workItem) bool {
+	ctx, cancel := context.WithTimeout(item.context, probeTimeout)
+	defer cancel()
+
+	err := func() error {
+		conn, err := dialContext(ctx, "tcp", net.JoinHostPort(item.podIP, item.podPort))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		tlsConn := tls.Client(conn, &tls.Config{
+			ServerName: item.url.Hostname(),
+			//nolint:gosec
+			// We only want to know that the Gateway routes the SNI to a backend
+			// terminating TLS, not that its certificate is valid.
+			InsecureSkipVerify: true,
+		})
+		return tlsConn.HandshakeContext(ctx)
+	}()
+
+	// In case of cancellation, drop the work item
+	select {
+	case <-item.context.Done():
+		m.workQueue.Forget(obj)
+		m.release(item)
+		return true
+	default:
+	}
+
+	if err != nil {
+		// In case of error, enqueue for retry
+		item.routeState.setLastFailure(item.url, err)
+		m.workQueue.AddRateLimited(obj)
+		item.logger.Errorf("TLS probing of %s failed, IP: %s:%s, error: %v (depth: %d)",
+			item.url, item.podIP, item.podPort, err, m.workQueue.Len())
 	} else {
 		m.onProbingSuccess(item.routeState, item.podState)
+		m.release(item)
 	}
 	return true
 }
@@ -476,6 +1024,8 @@ func (m *Prober) onProbingSuccess(routeState *routeState, podState *podState) {
 
 		// This is the last pod being successfully probed, the Ingress is ready
 		if routeState.pendingCount.Add(-1) == 0 {
+			routeState.setLastReady(time.Now())
+			routeState.lastFailure.Store(nil)
 			m.readyCallback(routeState.callbackKey)
 		}
 	}
@@ -493,6 +1043,7 @@ func (m *Prober) onProbingCancellation(routeState *routeState, podState *podStat
 		if podState.pendingCount.CompareAndSwap(pendingCount, 0) {
 			// This is the last pod being successfully probed, the Ingress is ready
 			if routeState.pendingCount.Add(-1) == 0 {
+				routeState.setLastReady(time.Now())
 				m.readyCallback(routeState.callbackKey)
 			}
 			return
@@ -503,36 +1054,42 @@ func (m *Prober) onProbingCancellation(routeState *routeState, podState *podStat
 func (m *Prober) probeVerifier(item *workItem) prober.Verifier {
 	return func(r *http.Response, _ []byte) (bool, error) {
 		// In the happy path, the probe request is forwarded to Activator or Queue-Proxy and the response (HTTP 200)
-		// contains the "K-Network-Hash" header that can be compared with the expected hash. If the hashes match,
-		// probing is successful, if they don't match, a new probe will be sent later.
+		// contains the "K-Network-Hash" header, or the configured version header for Gateways that strip it, that
+		// can be compared with the expected hash. If the hashes match, probing is successful, if they don't match,
+		// a new probe will be sent later.
 		// An HTTP 404/503 is expected in the case of the creation of a new Knative service because the rules will
-		// not be present in the Envoy config until the new VirtualService is applied.
+		// not be present in the Envoy config until the new VirtualService is applied. Some Gateways use other
+		// status codes while warming up, so these can be configured.
 		// No information can be extracted from any other scenario (e.g. HTTP 302), therefore in that case,
 		// probing is assumed to be successful because it is better to say that an Ingress is Ready before it
 		// actually is Ready than never marking it as Ready. It is best effort.
-		switch r.StatusCode {
-		case http.StatusOK:
-			hash := r.Header.Get(header.HashKey)
+		if r.StatusCode == http.StatusOK {
+			versionHeader := cmp.Or(item.routeState.versionHeader, header.HashKey)
+			hash := r.Header.Get(versionHeader)
 			switch hash {
 			case "":
 				item.logger.Errorf("Probing of %s abandoned, IP: %s:%s: the response doesn't contain the %q header",
-					item.url, item.podIP, item.podPort, header.HashKey)
+					item.url, item.podIP, item.podPort, versionHeader)
 				return true, nil
 			case item.routeState.version:
 				return true, nil
 			default:
 				return false, fmt.Errorf("unexpected version: want %q, got %q", item.routeState.version, hash)
 			}
+		}
 
-		case http.StatusNotFound, http.StatusServiceUnavailable:
+		retryStatusCodes := item.routeState.retryStatusCodes
+		if retryStatusCodes.Len() == 0 {
+			retryStatusCodes = defaultRetryStatusCodes
+		}
+		if retryStatusCodes.Has(r.StatusCode) {
 			return false, fmt.Errorf("unexpected status code: want %v, got %v", http.StatusOK, r.StatusCode)
-
-		default:
-			item.logger.Errorf("Probing of %s abandoned, IP: %s:%s: the response status is %v, expected one of: %v",
-				item.url, item.podIP, item.podPort, r.StatusCode,
-				[]int{http.StatusOK, http.StatusNotFound, http.StatusServiceUnavailable})
-			return true, nil
 		}
+
+		item.logger.Errorf("Probing of %s abandoned, IP: %s:%s: the response status is %v, expected one of: %v",
+			item.url, item.podIP, item.podPort, r.StatusCode,
+			append([]int{http.StatusOK}, sets.List(retryStatusCodes)...))
+		return true, nil
 	}
 }
 
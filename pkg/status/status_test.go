@@ -18,25 +18,42 @@ package status
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/networking/pkg/http/header"
 	"knative.dev/networking/pkg/http/probe"
 	"knative.dev/pkg/logging"
 
 	"go.uber.org/zap/zaptest"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -137,7 +154,8 @@ func TestProbeAllHosts(t *testing.T) {
 		},
 		func(ing types.NamespacedName) {
 			ready <- ing
-		})
+		},
+		DefaultRateLimiterConfig())
 
 	done := make(chan struct{})
 	cancelled := prober.Start(done)
@@ -207,6 +225,12 @@ func TestProbeAllHosts(t *testing.T) {
 		break
 	}
 
+	// The failed probes of hostB are reported
+	state, _ = prober.IsProbeActive(ingressNN)
+	if want := hostBURL.String() + ": unexpected status code: want 200, got 404"; state.LastFailure != want {
+		t.Errorf("LastFailure = %q, want: %q", state.LastFailure, want)
+	}
+
 	// Make probes to hostB succeed
 	hostBEnabled.Store(true)
 
@@ -227,9 +251,12 @@ func TestProbeAllHosts(t *testing.T) {
 	if !active {
 		t.Error("active probe should report active")
 	}
-	if diff := cmp.Diff(ProbeState{Version: hash, Ready: true}, state); diff != "" {
+	if diff := cmp.Diff(ProbeState{Version: hash, Ready: true}, state, cmpopts.IgnoreFields(ProbeState{}, "LastReady")); diff != "" {
 		t.Error("probe should ready: ", diff)
 	}
+	if state.LastReady.IsZero() {
+		t.Error("ready probe should report LastReady")
+	}
 }
 
 func TestProbeLifecycle(t *testing.T) {
@@ -291,7 +318,283 @@ func TestProbeLifecycle(t *testing.T) {
 		},
 		func(nn types.NamespacedName) {
 			ready <- nn
-		})
+		},
+		DefaultRateLimiterConfig())
+
+	done := make(chan struct{})
+	cancelled := prober.Start(done)
+	defer func() {
+		close(done)
+		<-cancelled
+	}()
+
+	backends := Backends{
+		CallbackKey: ingressNN,
+		Key:         ingressNN,
+		Version:     hash,
+		URLs: map[v1alpha1.IngressVisibility]URLSet{
+			v1alpha1.IngressVisibilityExternalIP: sets.New(
+				hostAURL,
+			),
+		},
+	}
+
+	// The first call to DoProbes must succeed and return false
+	state, err := prober.DoProbes(ctx, backends)
+	if err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+	if state.Ready {
+		t.Fatal("Probing returned ready but should be false")
+	}
+
+	// Wait for the first (failing) and second (success) requests to be executed and validate Host header
+	for range 2 {
+		req := <-probeRequests
+		if req.Host != hostA {
+			t.Fatalf("Host header = %q, want %q", req.Host, hostA)
+		}
+	}
+
+	select {
+	case <-ready:
+		// Wait for the probing to eventually succeed
+	case <-time.After(5 * time.Second):
+		t.Error("Timed out waiting for probing to succeed.")
+	}
+
+	// The subsequent calls to DoProbes must succeed and return true
+	for range 5 {
+		if state, err = prober.DoProbes(ctx, backends); err != nil {
+			t.Fatal("DoProbes failed:", err)
+		}
+		if !state.Ready {
+			t.Fatal("Probing should be ready")
+		}
+	}
+
+	// Cancel Ingress probing -> deletes the cached state
+	prober.CancelIngressProbing(ing)
+
+	select {
+	// Validate that no probe requests were issued (cached)
+	case <-probeRequests:
+		t.Fatal("An unexpected probe request was received")
+	// Validate that no requests went through the probe handler
+	case <-failedRequests:
+		t.Fatal("An unexpected request went through the probe handler")
+	default:
+	}
+
+	// The state has been removed and DoProbes must return False
+	state, err = prober.DoProbes(ctx, backends)
+	if err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+	if state.Ready {
+		t.Fatal("Probing returned ready but should be false")
+	}
+
+	// Wait for the first request (success) to be executed
+	<-probeRequests
+
+	select {
+	case <-ready:
+		// Wait for the probing to eventually succeed
+	case <-time.After(5 * time.Second):
+		t.Error("Timed out waiting for probing to succeed.")
+	}
+
+	select {
+	// Validate that no requests went through the probe handler
+	case <-failedRequests:
+		t.Fatal("An unexpected request went through the probe handler")
+	default:
+		break
+	}
+}
+
+func TestProbeCache(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+
+	hash := "some-hash"
+	probeHandler := probe.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	var probeRequests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probeRequests.Add(1)
+		r.Header.Set(header.HashKey, hash)
+		probeHandler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %q: %v", ts.URL, err)
+	}
+
+	ready := make(chan types.NamespacedName, 2)
+	prober := NewProber(
+		zaptest.NewLogger(t).Sugar(),
+		fakeProbeTargetLister{
+			PodIPs:  sets.New(tsURL.Hostname()),
+			PodPort: tsURL.Port(),
+		},
+		func(nn types.NamespacedName) {
+			ready <- nn
+		},
+		DefaultRateLimiterConfig())
+
+	done := make(chan struct{})
+	cancelled := prober.Start(done)
+	defer func() {
+		close(done)
+		<-cancelled
+	}()
+
+	// Two routes of an Ingress, e.g. the parts of a split rule, probing the
+	// same host on the same pod
+	for _, key := range []types.NamespacedName{{Namespace: "default", Name: "route"}, {Namespace: "default", Name: "route-1"}} {
+		backends := Backends{
+			CallbackKey: ingressNN,
+			Key:         key,
+			Version:     hash,
+			URLs: map[v1alpha1.IngressVisibility]URLSet{
+				v1alpha1.IngressVisibilityExternalIP: sets.New(
+					url.URL{Scheme: "http", Host: "foo.bar.com"},
+				),
+			},
+		}
+		if _, err := prober.DoProbes(ctx, backends); err != nil {
+			t.Fatal("DoProbes failed:", err)
+		}
+
+		select {
+		case got := <-ready:
+			if got != ingressNN {
+				t.Errorf("Ready callback for %v, want: %v", got, ingressNN)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %v to be ready", key)
+		}
+	}
+
+	// The second route reused the probe of the first one
+	if got := probeRequests.Load(); got != 1 {
+		t.Errorf("Probe requests = %d, want: 1", got)
+	}
+}
+
+func TestProbeConcurrencyCap(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+
+	const maxProbes = 2
+	hash := "some-hash"
+	probeHandler := probe.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	var inFlight, maxInFlight atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if n <= seen || maxInFlight.CompareAndSwap(seen, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		r.Header.Set(header.HashKey, hash)
+		probeHandler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %q: %v", ts.URL, err)
+	}
+
+	ready := make(chan types.NamespacedName)
+	prober := NewProber(
+		zaptest.NewLogger(t).Sugar(),
+		fakeProbeTargetLister{
+			PodIPs:  sets.New(tsURL.Hostname()),
+			PodPort: tsURL.Port(),
+		},
+		func(nn types.NamespacedName) {
+			ready <- nn
+		},
+		DefaultRateLimiterConfig())
+
+	done := make(chan struct{})
+	cancelled := prober.Start(done)
+	defer func() {
+		close(done)
+		<-cancelled
+	}()
+
+	urls := sets.New[url.URL]()
+	for i := range 8 {
+		urls.Insert(url.URL{Scheme: "http", Host: fmt.Sprintf("foo-%d.bar.com", i)})
+	}
+	backends := Backends{
+		CallbackKey:         ingressNN,
+		Key:                 ingressNN,
+		Version:             hash,
+		URLs:                map[v1alpha1.IngressVisibility]URLSet{v1alpha1.IngressVisibilityExternalIP: urls},
+		MaxConcurrentProbes: maxProbes,
+	}
+	if _, err := prober.DoProbes(ctx, backends); err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the Ingress to be ready")
+	}
+
+	if got := maxInFlight.Load(); got > maxProbes {
+		t.Errorf("Concurrent probes = %d, want at most: %d", got, maxProbes)
+	}
+	prober.throttleMu.Lock()
+	defer prober.throttleMu.Unlock()
+	if got := len(prober.throttles); got != 0 {
+		t.Errorf("Throttles = %d, want: 0", got)
+	}
+}
+
+func TestThrottleWithoutItems(t *testing.T) {
+	prober := NewProber(zaptest.NewLogger(t).Sugar(), fakeProbeTargetLister{}, func(types.NamespacedName) {}, DefaultRateLimiterConfig())
+
+	routeState := &routeState{callbackKey: ingressNN, maxConcurrentProbes: 2}
+	if got := prober.throttle(routeState, nil); len(got) != 0 {
+		t.Errorf("throttle() = %v, want no items", got)
+	}
+	// No probe will release a throttle of the Ingress
+	if _, ok := prober.throttles[ingressNN]; ok {
+		t.Error("throttle() kept a throttle for the Ingress without items")
+	}
+}
+
+func TestProbeLastReady(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+
+	// Every probe fails with HTTP 404
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %q: %v", ts.URL, err)
+	}
+
+	// No pods to probe, so the first version is ready immediately
+	prober := NewProber(
+		zaptest.NewLogger(t).Sugar(),
+		fakeProbeTargetLister{PodIPs: sets.New[string]()},
+		func(types.NamespacedName) {},
+		DefaultRateLimiterConfig())
 
 	done := make(chan struct{})
 	cancelled := prober.Start(done)
@@ -303,86 +606,915 @@ func TestProbeLifecycle(t *testing.T) {
 	backends := Backends{
 		CallbackKey: ingressNN,
 		Key:         ingressNN,
-		Version:     hash,
+		Version:     "first-hash",
+		URLs: map[v1alpha1.IngressVisibility]URLSet{
+			v1alpha1.IngressVisibilityExternalIP: sets.New(
+				url.URL{Host: "foo.bar.com"},
+			),
+		},
+	}
+
+	before := time.Now()
+	state, err := prober.DoProbes(ctx, backends)
+	if err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+	if !state.Ready {
+		t.Fatal("Probing should be ready")
+	}
+	if state.LastReady.Before(before) {
+		t.Fatalf("LastReady = %v, want after %v", state.LastReady, before)
+	}
+
+	// The next version never becomes ready, but remembers when the
+	// previous version was last ready.
+	prober.targetLister = fakeProbeTargetLister{
+		PodIPs:  sets.New(tsURL.Hostname()),
+		PodPort: tsURL.Port(),
+	}
+	backends.Version = "second-hash"
+
+	state, err = prober.DoProbes(ctx, backends)
+	if err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+	if state.Ready {
+		t.Fatal("Probing returned ready but should be false")
+	}
+	if state.LastReady.IsZero() || state.LastReady.Before(before) {
+		t.Fatalf("LastReady = %v, want after %v", state.LastReady, before)
+	}
+
+	active, ok := prober.IsProbeActive(ingressNN)
+	if !ok {
+		t.Fatal("IsProbeActive() = false, want true")
+	}
+	if !active.LastReady.Equal(state.LastReady) {
+		t.Errorf("IsProbeActive().LastReady = %v, want %v", active.LastReady, state.LastReady)
+	}
+
+	// A new key has never been ready
+	backends.Key = types.NamespacedName{Namespace: "default", Name: "other"}
+	state, err = prober.DoProbes(ctx, backends)
+	if err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+	if !state.LastReady.IsZero() {
+		t.Errorf("LastReady = %v, want zero", state.LastReady)
+	}
+}
+
+func TestProbeIPv6(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+
+	l, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skip("IPv6 loopback is not available:", err)
+	}
+
+	hash := "some-hash"
+	probeHandler := probe.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set(header.HashKey, hash)
+		probeHandler.ServeHTTP(w, r)
+	}))
+	ts.Listener.Close()
+	ts.Listener = l
+	ts.Start()
+	defer ts.Close()
+
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %q: %v", ts.URL, err)
+	}
+
+	ready := make(chan types.NamespacedName)
+	prober := NewProber(
+		zaptest.NewLogger(t).Sugar(),
+		fakeProbeTargetLister{
+			// Hostname() strips the brackets of the IPv6 address
+			PodIPs:  sets.New(tsURL.Hostname()),
+			PodPort: tsURL.Port(),
+		},
+		func(nn types.NamespacedName) {
+			ready <- nn
+		},
+		DefaultRateLimiterConfig())
+
+	done := make(chan struct{})
+	cancelled := prober.Start(done)
+	defer func() {
+		close(done)
+		<-cancelled
+	}()
+
+	backends := Backends{
+		CallbackKey: ingressNN,
+		Key:         ingressNN,
+		Version:     hash,
+		URLs: map[v1alpha1.IngressVisibility]URLSet{
+			v1alpha1.IngressVisibilityExternalIP: sets.New(
+				url.URL{Scheme: "http", Host: "foo.bar.com"},
+			),
+		},
+	}
+
+	state, err := prober.DoProbes(ctx, backends)
+	if err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+	if state.Ready {
+		t.Fatal("Probing returned ready but should be false")
+	}
+
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the IPv6 gateway to be probed")
+	}
+
+	state, err = prober.DoProbes(ctx, backends)
+	if err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+	if !state.Ready {
+		t.Fatal("Probing returned not ready but should be true")
+	}
+}
+
+func TestProbeHeaders(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+
+	hash := "some-hash"
+	probeHandler := probe.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	received := make(chan http.Header, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case received <- r.Header.Clone():
+		default:
+		}
+		r.Header.Set(header.HashKey, hash)
+		probeHandler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %q: %v", ts.URL, err)
+	}
+
+	prober := NewProber(
+		zaptest.NewLogger(t).Sugar(),
+		fakeProbeTargetLister{
+			PodIPs:  sets.New(tsURL.Hostname()),
+			PodPort: tsURL.Port(),
+		},
+		func(types.NamespacedName) {},
+		DefaultRateLimiterConfig())
+
+	done := make(chan struct{})
+	cancelled := prober.Start(done)
+	defer func() {
+		close(done)
+		<-cancelled
+	}()
+
+	backends := Backends{
+		CallbackKey: ingressNN,
+		Key:         ingressNN,
+		Version:     hash,
+		URLs: map[v1alpha1.IngressVisibility]URLSet{
+			v1alpha1.IngressVisibilityExternalIP: sets.New(
+				url.URL{Scheme: "http", Host: "foo.bar.com"},
+			),
+		},
+		Headers: map[string]string{
+			"X-Gateway-Auth": "secret",
+			// The probe headers can't be overridden
+			header.ProbeKey: "overridden",
+		},
+	}
+
+	if _, err := prober.DoProbes(ctx, backends); err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+
+	select {
+	case h := <-received:
+		if got, want := h.Get("X-Gateway-Auth"), "secret"; got != want {
+			t.Errorf("X-Gateway-Auth header = %q, want: %q", got, want)
+		}
+		if got, want := h.Get(header.ProbeKey), header.ProbeValue; got != want {
+			t.Errorf("%s header = %q, want: %q", header.ProbeKey, got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the probe request")
+	}
+}
+
+func TestProbeUserAgent(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		userAgent string
+		want      string
+	}{{
+		name: "default",
+		want: header.IngressReadinessUserAgent,
+	}, {
+		name:      "overridden",
+		userAgent: "allowlisted-prober/1.0",
+		want:      "allowlisted-prober/1.0",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+
+			hash := "some-hash"
+			probeHandler := probe.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			received := make(chan string, 1)
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case received <- r.UserAgent():
+				default:
+				}
+				r.Header.Set(header.HashKey, hash)
+				probeHandler.ServeHTTP(w, r)
+			}))
+			defer ts.Close()
+
+			tsURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatalf("Failed to parse URL %q: %v", ts.URL, err)
+			}
+
+			prober := NewProber(
+				zaptest.NewLogger(t).Sugar(),
+				fakeProbeTargetLister{
+					PodIPs:  sets.New(tsURL.Hostname()),
+					PodPort: tsURL.Port(),
+				},
+				func(types.NamespacedName) {},
+				DefaultRateLimiterConfig())
+
+			done := make(chan struct{})
+			cancelled := prober.Start(done)
+			defer func() {
+				close(done)
+				<-cancelled
+			}()
+
+			if _, err := prober.DoProbes(ctx, Backends{
+				CallbackKey: ingressNN,
+				Key:         ingressNN,
+				Version:     hash,
+				URLs: map[v1alpha1.IngressVisibility]URLSet{
+					v1alpha1.IngressVisibilityExternalIP: sets.New(
+						url.URL{Scheme: "http", Host: "foo.bar.com"},
+					),
+				},
+				UserAgent: tc.userAgent,
+			}); err != nil {
+				t.Fatal("DoProbes failed:", err)
+			}
+
+			select {
+			case got := <-received:
+				if got != tc.want {
+					t.Errorf("User-Agent = %q, want: %q", got, tc.want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Timed out waiting for the probe request")
+			}
+		})
+	}
+}
+
+func TestProbeTLSPassthrough(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+
+	serverNames := make(chan string, 1)
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// The prober only completes the TLS handshake
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	ts.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverNames <- hello.ServerName
+			return nil, nil
+		},
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %q: %v", ts.URL, err)
+	}
+
+	ready := make(chan types.NamespacedName)
+	prober := NewProber(
+		zaptest.NewLogger(t).Sugar(),
+		fakeProbeTargetLister{
+			PodIPs:  sets.New(tsURL.Hostname()),
+			PodPort: tsURL.Port(),
+		},
+		func(nn types.NamespacedName) {
+			ready <- nn
+		},
+		DefaultRateLimiterConfig())
+
+	done := make(chan struct{})
+	cancelled := prober.Start(done)
+	defer func() {
+		close(done)
+		<-cancelled
+	}()
+
+	state, err := prober.DoProbes(ctx, Backends{
+		CallbackKey: ingressNN,
+		Key:         ingressNN,
+		Version:     "some-hash",
+		URLs: map[v1alpha1.IngressVisibility]URLSet{
+			v1alpha1.IngressVisibilityExternalIP: sets.New(
+				url.URL{Scheme: "https", Host: "foo.bar.com"},
+			),
+		},
+		TLSPassthrough: true,
+	})
+	if err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+	if state.Ready {
+		t.Fatal("Probing returned ready but should be false")
+	}
+
+	select {
+	case sni := <-serverNames:
+		if sni != "foo.bar.com" {
+			t.Errorf("SNI = %q, want: %q", sni, "foo.bar.com")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the TLS handshake.")
+	}
+
+	select {
+	case <-ready:
+		// Wait for the probing to eventually succeed
+	case <-time.After(5 * time.Second):
+		t.Error("Timed out waiting for probing to succeed.")
+	}
+}
+
+func TestProbeHTTP1Only(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+
+	hash := "some-hash"
+	probeHandler := probe.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set(header.HashKey, hash)
+		probeHandler.ServeHTTP(w, r)
+	}))
+	// A strictly HTTP/1.1 Gateway failing the handshakes offering HTTP/2
+	ts.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			if slices.Contains(hello.SupportedProtos, "h2") {
+				return nil, errors.New("HTTP/2 is not supported")
+			}
+			return nil, nil
+		},
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %q: %v", ts.URL, err)
+	}
+
+	ready := make(chan types.NamespacedName)
+	prober := NewProber(
+		zaptest.NewLogger(t).Sugar(),
+		fakeProbeTargetLister{
+			PodIPs:  sets.New(tsURL.Hostname()),
+			PodPort: tsURL.Port(),
+		},
+		func(nn types.NamespacedName) {
+			ready <- nn
+		},
+		DefaultRateLimiterConfig())
+
+	done := make(chan struct{})
+	cancelled := prober.Start(done)
+	defer func() {
+		close(done)
+		<-cancelled
+	}()
+
+	state, err := prober.DoProbes(ctx, Backends{
+		CallbackKey: ingressNN,
+		Key:         ingressNN,
+		Version:     hash,
+		URLs: map[v1alpha1.IngressVisibility]URLSet{
+			v1alpha1.IngressVisibilityExternalIP: sets.New(
+				url.URL{Scheme: "https", Host: "foo.bar.com"},
+			),
+		},
+		HTTP1Only: true,
+	})
+	if err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+	if state.Ready {
+		t.Fatal("Probing returned ready but should be false")
+	}
+
+	select {
+	case <-ready:
+		// Wait for the probing to eventually succeed
+	case <-time.After(5 * time.Second):
+		state, _ := prober.IsProbeActive(ingressNN)
+		t.Error("Timed out waiting for probing to succeed, last failure:", state.LastFailure)
+	}
+}
+
+func TestProbeH2C(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+
+	hash := "some-hash"
+	probeHandler := probe.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	// A Gateway only speaking HTTP/2 over cleartext
+	ts := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 {
+			w.WriteHeader(http.StatusHTTPVersionNotSupported)
+			return
+		}
+		r.Header.Set(header.HashKey, hash)
+		probeHandler.ServeHTTP(w, r)
+	}), &http2.Server{}))
+	defer ts.Close()
+
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %q: %v", ts.URL, err)
+	}
+
+	// Count the connections of the probes that are still open
+	var open atomic.Int32
+	defer func(dial func(context.Context, string, string) (net.Conn, error)) {
+		dialContext = dial
+	}(dialContext)
+	dial := dialContext
+	dialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := dial(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+		open.Add(1)
+		return &countedConn{Conn: conn, open: &open}, nil
+	}
+
+	ready := make(chan types.NamespacedName)
+	prober := NewProber(
+		zaptest.NewLogger(t).Sugar(),
+		fakeProbeTargetLister{
+			PodIPs:  sets.New(tsURL.Hostname()),
+			PodPort: tsURL.Port(),
+		},
+		func(nn types.NamespacedName) {
+			ready <- nn
+		},
+		DefaultRateLimiterConfig())
+
+	done := make(chan struct{})
+	cancelled := prober.Start(done)
+	defer func() {
+		close(done)
+		<-cancelled
+	}()
+
+	if _, err := prober.DoProbes(ctx, Backends{
+		CallbackKey: ingressNN,
+		Key:         ingressNN,
+		Version:     hash,
+		URLs: map[v1alpha1.IngressVisibility]URLSet{
+			v1alpha1.IngressVisibilityClusterLocal: sets.New(
+				url.URL{Scheme: "h2c", Host: "foo.bar.svc.cluster.local"},
+			),
+		},
+	}); err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+
+	select {
+	case <-ready:
+		// Wait for the probing to eventually succeed
+	case <-time.After(5 * time.Second):
+		state, _ := prober.IsProbeActive(ingressNN)
+		t.Error("Timed out waiting for probing to succeed, last failure:", state.LastFailure)
+	}
+
+	// The transports of the probes don't keep their connections
+	if err := wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return open.Load() == 0, nil
+	}); err != nil {
+		t.Errorf("%d probe connections are still open", open.Load())
+	}
+}
+
+// countedConn decrements the count of open connections when closed.
+type countedConn struct {
+	net.Conn
+	open   *atomic.Int32
+	closed sync.Once
+}
+
+func (c *countedConn) Close() error {
+	c.closed.Do(func() { c.open.Add(-1) })
+	return c.Conn.Close()
+}
+
+func TestProbeSNI(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+
+	hash := "some-hash"
+	probeHandler := probe.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set(header.HashKey, hash)
+		probeHandler.ServeHTTP(w, r)
+	}))
+	// A Gateway selecting its listener with the SNI of the handshakes
+	serverNames := make(chan string, 10)
+	ts.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverNames <- hello.ServerName
+			if hello.ServerName != "foo.bar.com" {
+				return nil, errors.New("unknown server name")
+			}
+			return nil, nil
+		},
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %q: %v", ts.URL, err)
+	}
+
+	ready := make(chan types.NamespacedName)
+	prober := NewProber(
+		zaptest.NewLogger(t).Sugar(),
+		fakeProbeTargetLister{
+			PodIPs:  sets.New(tsURL.Hostname()),
+			PodPort: tsURL.Port(),
+		},
+		func(nn types.NamespacedName) {
+			ready <- nn
+		},
+		DefaultRateLimiterConfig())
+
+	done := make(chan struct{})
+	cancelled := prober.Start(done)
+	defer func() {
+		close(done)
+		<-cancelled
+	}()
+
+	if _, err := prober.DoProbes(ctx, Backends{
+		CallbackKey: ingressNN,
+		Key:         ingressNN,
+		Version:     hash,
+		URLs: map[v1alpha1.IngressVisibility]URLSet{
+			v1alpha1.IngressVisibilityExternalIP: sets.New(
+				url.URL{Scheme: "https", Host: "foo.bar.com:8443"},
+			),
+		},
+	}); err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		state, _ := prober.IsProbeActive(ingressNN)
+		t.Fatal("Timed out waiting for probing to succeed, last failure:", state.LastFailure)
+	}
+	if got := <-serverNames; got != "foo.bar.com" {
+		t.Errorf("SNI = %q, want: %q", got, "foo.bar.com")
+	}
+}
+
+func TestProbeClientCertificate(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+
+	hash := "some-hash"
+	probeHandler := probe.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set(header.HashKey, hash)
+		probeHandler.ServeHTTP(w, r)
+	}))
+	// A Gateway behind a mesh sidecar requiring mutual TLS
+	ts.TLS = &tls.Config{
+		ClientAuth: tls.RequireAnyClientCert,
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %q: %v", ts.URL, err)
+	}
+
+	cert := selfSignedCertificate(t)
+	// The certificate is only available from the second handshake on, e.g.
+	// while it is being issued, so the first probe is rejected.
+	var handshakes atomic.Int32
+	getClientCertificate := func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		if handshakes.Add(1) == 1 {
+			return &tls.Certificate{}, nil
+		}
+		return &cert, nil
+	}
+
+	ready := make(chan types.NamespacedName)
+	prober := NewProber(
+		zaptest.NewLogger(t).Sugar(),
+		fakeProbeTargetLister{
+			PodIPs:  sets.New(tsURL.Hostname()),
+			PodPort: tsURL.Port(),
+		},
+		func(nn types.NamespacedName) {
+			ready <- nn
+		},
+		DefaultRateLimiterConfig())
+
+	done := make(chan struct{})
+	cancelled := prober.Start(done)
+	defer func() {
+		close(done)
+		<-cancelled
+	}()
+
+	if _, err := prober.DoProbes(ctx, Backends{
+		CallbackKey: ingressNN,
+		Key:         ingressNN,
+		Version:     hash,
+		URLs: map[v1alpha1.IngressVisibility]URLSet{
+			v1alpha1.IngressVisibilityExternalIP: sets.New(
+				url.URL{Scheme: "https", Host: "foo.bar.com:8443"},
+			),
+		},
+		GetClientCertificate: getClientCertificate,
+	}); err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		state, _ := prober.IsProbeActive(ingressNN)
+		t.Fatal("Timed out waiting for probing to succeed, last failure:", state.LastFailure)
+	}
+	if got := handshakes.Load(); got < 2 {
+		t.Errorf("Handshakes = %d, want the probe without certificate retried", got)
+	}
+}
+
+func TestProbeVerifyTLS(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+
+	hash := "some-hash"
+	probeHandler := probe.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	// The certificate of the test server is valid for example.com
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set(header.HashKey, hash)
+		probeHandler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %q: %v", ts.URL, err)
+	}
+
+	trusted := x509.NewCertPool()
+	trusted.AddCert(ts.Certificate())
+
+	for _, tc := range []struct {
+		name      string
+		host      string
+		roots     *x509.CertPool
+		wantReady bool
+	}{{
+		name:      "trusted certificate",
+		host:      "example.com",
+		roots:     trusted,
+		wantReady: true,
+	}, {
+		name:  "untrusted certificate",
+		host:  "example.com",
+		roots: x509.NewCertPool(),
+	}, {
+		name:  "certificate of another host",
+		host:  "foo.bar.com",
+		roots: trusted,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ready := make(chan types.NamespacedName, 1)
+			prober := NewProber(
+				zaptest.NewLogger(t).Sugar(),
+				fakeProbeTargetLister{
+					PodIPs:  sets.New(tsURL.Hostname()),
+					PodPort: tsURL.Port(),
+				},
+				func(nn types.NamespacedName) {
+					ready <- nn
+				},
+				DefaultRateLimiterConfig())
+
+			done := make(chan struct{})
+			cancelled := prober.Start(done)
+			defer func() {
+				close(done)
+				<-cancelled
+			}()
+
+			if _, err := prober.DoProbes(ctx, Backends{
+				CallbackKey: ingressNN,
+				Key:         ingressNN,
+				Version:     hash,
+				URLs: map[v1alpha1.IngressVisibility]URLSet{
+					v1alpha1.IngressVisibilityExternalIP: sets.New(
+						url.URL{Scheme: "https", Host: tc.host},
+					),
+				},
+				GetRootCAs: func() (*x509.CertPool, error) {
+					return tc.roots, nil
+				},
+			}); err != nil {
+				t.Fatal("DoProbes failed:", err)
+			}
+
+			if tc.wantReady {
+				select {
+				case <-ready:
+				case <-time.After(5 * time.Second):
+					state, _ := prober.IsProbeActive(ingressNN)
+					t.Fatal("Timed out waiting for probing to succeed, last failure:", state.LastFailure)
+				}
+				return
+			}
+
+			if err := wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+				state, _ := prober.IsProbeActive(ingressNN)
+				return state.LastFailure != "", nil
+			}); err != nil {
+				t.Fatal("Timed out waiting for probing to fail:", err)
+			}
+			select {
+			case <-ready:
+				t.Error("Probing succeeded with an unverified certificate")
+			default:
+			}
+		})
+	}
+}
+
+// selfSignedCertificate returns a certificate for the TLS handshakes of the
+// tests.
+func selfSignedCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("Failed to generate key:", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "prober"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal("Failed to create certificate:", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestProbeInitialDelay(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		delay      time.Duration
+		wantQueued int
+	}{{
+		name:       "no delay",
+		delay:      0,
+		wantQueued: 1,
+	}, {
+		name:       "long delay",
+		delay:      time.Hour,
+		wantQueued: 0,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+
+			// The prober isn't started, so the probes stay queued
+			prober := NewProber(
+				zaptest.NewLogger(t).Sugar(),
+				fakeProbeTargetLister{
+					PodIPs:  sets.New("1.1.1.1"),
+					PodPort: "8080",
+				},
+				func(types.NamespacedName) {},
+				DefaultRateLimiterConfig())
+			defer prober.workQueue.ShutDown()
+
+			backends := Backends{
+				CallbackKey: ingressNN,
+				Key:         ingressNN,
+				Version:     "hash",
+				URLs: map[v1alpha1.IngressVisibility]URLSet{
+					v1alpha1.IngressVisibilityExternalIP: sets.New(
+						url.URL{Host: "foo.bar.com"},
+					),
+				},
+				InitialDelay: ptr.To(tc.delay),
+			}
+			if _, err := prober.DoProbes(ctx, backends); err != nil {
+				t.Fatal("DoProbes failed:", err)
+			}
+
+			if got := prober.Stats().QueueDepth; got != tc.wantQueued {
+				t.Errorf("QueueDepth = %d, want: %d", got, tc.wantQueued)
+			}
+		})
+	}
+}
+
+func TestProberStats(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+
+	// The prober isn't started, so the probes stay queued
+	prober := NewProber(
+		zaptest.NewLogger(t).Sugar(),
+		fakeProbeTargetLister{
+			PodIPs:  sets.New("1.1.1.1", "2.2.2.2"),
+			PodPort: "8080",
+		},
+		func(types.NamespacedName) {},
+		DefaultRateLimiterConfig())
+	defer prober.workQueue.ShutDown()
+
+	if got, want := prober.Stats(), (Stats{}); got != want {
+		t.Errorf("Stats() = %+v, want: %+v", got, want)
+	}
+
+	backends := Backends{
+		CallbackKey: ingressNN,
+		Key:         ingressNN,
+		Version:     "hash",
 		URLs: map[v1alpha1.IngressVisibility]URLSet{
 			v1alpha1.IngressVisibilityExternalIP: sets.New(
-				hostAURL,
+				url.URL{Host: "foo.bar.com"},
 			),
 		},
 	}
-
-	// The first call to DoProbes must succeed and return false
-	state, err := prober.DoProbes(ctx, backends)
-	if err != nil {
+	if _, err := prober.DoProbes(ctx, backends); err != nil {
 		t.Fatal("DoProbes failed:", err)
 	}
-	if state.Ready {
-		t.Fatal("Probing returned ready but should be false")
-	}
-
-	// Wait for the first (failing) and second (success) requests to be executed and validate Host header
-	for range 2 {
-		req := <-probeRequests
-		if req.Host != hostA {
-			t.Fatalf("Host header = %q, want %q", req.Host, hostA)
-		}
-	}
-
-	select {
-	case <-ready:
-		// Wait for the probing to eventually succeed
-	case <-time.After(5 * time.Second):
-		t.Error("Timed out waiting for probing to succeed.")
-	}
-
-	// The subsequent calls to DoProbes must succeed and return true
-	for range 5 {
-		if state, err = prober.DoProbes(ctx, backends); err != nil {
-			t.Fatal("DoProbes failed:", err)
-		}
-		if !state.Ready {
-			t.Fatal("Probing should be ready")
-		}
-	}
-
-	// Cancel Ingress probing -> deletes the cached state
-	prober.CancelIngressProbing(ing)
-
-	select {
-	// Validate that no probe requests were issued (cached)
-	case <-probeRequests:
-		t.Fatal("An unexpected probe request was received")
-	// Validate that no requests went through the probe handler
-	case <-failedRequests:
-		t.Fatal("An unexpected request went through the probe handler")
-	default:
-	}
 
-	// The state has been removed and DoProbes must return False
-	state, err = prober.DoProbes(ctx, backends)
-	if err != nil {
-		t.Fatal("DoProbes failed:", err)
+	// The probes are queued after an initial delay
+	want := Stats{QueueDepth: 2, ActiveRoutes: 1, PendingRoutes: 1}
+	if err := wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return prober.Stats() == want, nil
+	}); err != nil {
+		t.Fatalf("Stats() = %+v, want: %+v", prober.Stats(), want)
 	}
-	if state.Ready {
-		t.Fatal("Probing returned ready but should be false")
-	}
-
-	// Wait for the first request (success) to be executed
-	<-probeRequests
 
-	select {
-	case <-ready:
-		// Wait for the probing to eventually succeed
-	case <-time.After(5 * time.Second):
-		t.Error("Timed out waiting for probing to succeed.")
+	rec := httptest.NewRecorder()
+	StatsHandler(prober).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	var got Stats
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to decode %q: %v", rec.Body.String(), err)
 	}
-
-	select {
-	// Validate that no requests went through the probe handler
-	case <-failedRequests:
-		t.Fatal("An unexpected request went through the probe handler")
-	default:
-		break
+	if got != want {
+		t.Errorf("StatsHandler() = %+v, want: %+v", got, want)
 	}
 }
 
@@ -396,7 +1528,8 @@ func TestProbeListerFail(t *testing.T) {
 		notFoundLister{},
 		func(ing types.NamespacedName) {
 			ready <- ing
-		})
+		},
+		DefaultRateLimiterConfig())
 
 	backends := Backends{
 		Key:     ingressNN,
@@ -462,7 +1595,8 @@ func TestCancelPodProbing(t *testing.T) {
 		},
 		func(ing types.NamespacedName) {
 			ready <- ing
-		})
+		},
+		DefaultRateLimiterConfig())
 
 	done := make(chan struct{})
 	cancelled := prober.Start(done)
@@ -626,7 +1760,8 @@ func TestPartialPodCancellation(t *testing.T) {
 		},
 		func(ing types.NamespacedName) {
 			ready <- ing
-		})
+		},
+		DefaultRateLimiterConfig())
 
 	done := make(chan struct{})
 	cancelled := prober.Start(done)
@@ -703,7 +1838,8 @@ func TestCancelIngressProbing(t *testing.T) {
 		},
 		func(ing types.NamespacedName) {
 			ready <- ing
-		})
+		},
+		DefaultRateLimiterConfig())
 
 	done := make(chan struct{})
 	cancelled := prober.Start(done)
@@ -783,24 +1919,195 @@ func TestCancelIngressProbing(t *testing.T) {
 	}
 }
 
+func TestCancelIngressProbingByKey(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+	prober := NewProber(
+		zaptest.NewLogger(t).Sugar(),
+		fakeProbeTargetLister{
+			PodIPs:  sets.New("10.0.0.1"),
+			PodPort: "80",
+		},
+		func(types.NamespacedName) {},
+		DefaultRateLimiterConfig())
+
+	// The routes of the Ingress are probed under their own keys
+	routes := []types.NamespacedName{{Namespace: "default", Name: "route"}, {Namespace: "default", Name: "route-1"}}
+	for _, route := range routes {
+		if _, err := prober.DoProbes(ctx, Backends{
+			CallbackKey: ingressNN,
+			Key:         route,
+			Version:     "some-hash",
+			URLs: map[v1alpha1.IngressVisibility]URLSet{
+				v1alpha1.IngressVisibilityExternalIP: sets.New(
+					url.URL{Scheme: "http", Host: "foo.bar.com"},
+				),
+			},
+		}); err != nil {
+			t.Fatal("DoProbes failed:", err)
+		}
+	}
+
+	prober.CancelIngressProbingByKey(ingressNN)
+	for _, route := range routes {
+		if _, ok := prober.IsProbeActive(route); ok {
+			t.Errorf("Probing of %v is still active", route)
+		}
+	}
+}
+
+func TestRefreshIngress(t *testing.T) {
+	ctx := logging.WithLogger(context.Background(), zaptest.NewLogger(t).Sugar())
+	// Handler keeping track of received requests and mimicking an Ingress not ready
+	requests := make(chan *http.Request, 100)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %q: %v", ts.URL, err)
+	}
+
+	ready := make(chan types.NamespacedName, 1)
+	prober := NewProber(
+		zaptest.NewLogger(t).Sugar(),
+		fakeProbeTargetLister{
+			PodIPs:  sets.New(tsURL.Hostname()),
+			PodPort: tsURL.Port(),
+		},
+		func(ing types.NamespacedName) {
+			ready <- ing
+		},
+		DefaultRateLimiterConfig())
+
+	done := make(chan struct{})
+	cancelled := prober.Start(done)
+	defer func() {
+		close(done)
+		<-cancelled
+	}()
+
+	backends := Backends{
+		CallbackKey: ingressNN,
+		Key:         ingressNN,
+		Version:     "some-hash",
+		URLs: map[v1alpha1.IngressVisibility]URLSet{
+			v1alpha1.IngressVisibilityExternalIP: sets.New(
+				url.URL{Scheme: "http", Host: "foo.bar.com"},
+			),
+		},
+	}
+	if _, err := prober.DoProbes(ctx, backends); err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+
+	select {
+	case <-requests:
+		// Wait for the first probe request
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the first probe request.")
+	}
+
+	// Refreshing an unknown Ingress is a no-op
+	prober.RefreshIngress(types.NamespacedName{Namespace: "default", Name: "unknown"})
+	if _, ok := prober.IsProbeActive(ingressNN); !ok {
+		t.Fatal("IsProbeActive() = false after refreshing another Ingress")
+	}
+
+	rec := httptest.NewRecorder()
+	RefreshHandler(prober).ServeHTTP(rec, httptest.NewRequest(http.MethodPost,
+		"/?namespace="+ingressNN.Namespace+"&name="+ingressNN.Name, nil))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("RefreshHandler() = %d, want: %d", rec.Code, http.StatusAccepted)
+	}
+
+	// The owner of the Ingress is notified and the probing state is dropped
+	select {
+	case key := <-ready:
+		if key != ingressNN {
+			t.Errorf("Callback key = %s, want: %s", key, ingressNN)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the callback.")
+	}
+	if _, ok := prober.IsProbeActive(ingressNN); ok {
+		t.Fatal("IsProbeActive() = true after refreshing the Ingress")
+	}
+
+	// Drain the requests of the cancelled probing
+	time.Sleep(100 * time.Millisecond)
+	for len(requests) > 0 {
+		<-requests
+	}
+
+	// Probing the same version issues new probe requests
+	state, err := prober.DoProbes(ctx, backends)
+	if err != nil {
+		t.Fatal("DoProbes failed:", err)
+	}
+	if state.Ready {
+		t.Fatal("Probing returned ready but should be false")
+	}
+	select {
+	case req := <-requests:
+		if !strings.HasPrefix(req.Host, "foo.bar.com") {
+			t.Errorf("Host = %s, want: foo.bar.com", req.Host)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a probe request after the refresh.")
+	}
+}
+
+func TestRefreshHandler(t *testing.T) {
+	prober := NewProber(
+		zaptest.NewLogger(t).Sugar(),
+		fakeProbeTargetLister{},
+		func(types.NamespacedName) {},
+		DefaultRateLimiterConfig())
+	defer prober.workQueue.ShutDown()
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		target string
+		want   int
+	}{{
+		name:   "refresh",
+		method: http.MethodPost,
+		target: "/?namespace=default&name=whatever",
+		want:   http.StatusAccepted,
+	}, {
+		name:   "wrong method",
+		method: http.MethodGet,
+		target: "/?namespace=default&name=whatever",
+		want:   http.StatusMethodNotAllowed,
+	}, {
+		name:   "missing name",
+		method: http.MethodPost,
+		target: "/?namespace=default",
+		want:   http.StatusBadRequest,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			RefreshHandler(prober).ServeHTTP(rec, httptest.NewRequest(tc.method, tc.target, nil))
+			if rec.Code != tc.want {
+				t.Errorf("RefreshHandler() = %d, want: %d", rec.Code, tc.want)
+			}
+		})
+	}
+}
+
 func TestProbeVerifier(t *testing.T) {
 	const hash = "Hi! I am hash!"
-	prober := NewProber(zaptest.NewLogger(t).Sugar(), nil, nil)
-	verifier := prober.probeVerifier(&workItem{
-		routeState: &routeState{
-			version: hash,
-		},
-		podState: nil,
-		context:  nil,
-		url:      nil,
-		podIP:    "",
-		podPort:  "",
-		logger:   zaptest.NewLogger(t).Sugar(),
-	})
+	prober := NewProber(zaptest.NewLogger(t).Sugar(), nil, nil, DefaultRateLimiterConfig())
 	cases := []struct {
-		name string
-		resp *http.Response
-		want bool
+		name          string
+		versionHeader string
+		resp          *http.Response
+		want          bool
 	}{{
 		name: "HTTP 200 matching hash",
 		resp: &http.Response{
@@ -845,6 +2152,12 @@ func TestProbeVerifier(t *testing.T) {
 			StatusCode: http.StatusServiceUnavailable,
 		},
 		want: false,
+	}, {
+		name: "HTTP 502",
+		resp: &http.Response{
+			StatusCode: http.StatusBadGateway,
+		},
+		want: true,
 	}, {
 		name: "HTTP 301",
 		resp: &http.Response{
@@ -857,10 +2170,44 @@ func TestProbeVerifier(t *testing.T) {
 			StatusCode: http.StatusFound,
 		},
 		want: true,
+	}, {
+		name:          "HTTP 200 matching alternate header",
+		versionHeader: "X-Knative-Version",
+		resp: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"X-Knative-Version": []string{hash}},
+		},
+		want: true,
+	}, {
+		name:          "HTTP 200 mismatching alternate header",
+		versionHeader: "X-Knative-Version",
+		resp: &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"X-Knative-Version": []string{"nope"},
+				header.HashKey:      []string{hash},
+			},
+		},
+		want: false,
+	}, {
+		name:          "HTTP 200 missing alternate header",
+		versionHeader: "x-knative-version",
+		resp: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{header.HashKey: []string{"nope"}},
+		},
+		want: true,
 	}}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
+			verifier := prober.probeVerifier(&workItem{
+				routeState: &routeState{
+					version:       hash,
+					versionHeader: c.versionHeader,
+				},
+				logger: zaptest.NewLogger(t).Sugar(),
+			})
 			got, _ := verifier(c.resp, nil)
 			if got != c.want {
 				t.Errorf("got: %v, want: %v", got, c.want)
@@ -869,6 +2216,51 @@ func TestProbeVerifier(t *testing.T) {
 	}
 }
 
+func TestProbeVerifierRetryStatusCodes(t *testing.T) {
+	prober := NewProber(zaptest.NewLogger(t).Sugar(), nil, nil, DefaultRateLimiterConfig())
+	verifier := prober.probeVerifier(&workItem{
+		routeState: &routeState{
+			version:          "hash",
+			retryStatusCodes: sets.New(http.StatusNotFound, http.StatusBadGateway, http.StatusServiceUnavailable),
+		},
+		logger: zaptest.NewLogger(t).Sugar(),
+	})
+	cases := []struct {
+		name string
+		code int
+		want bool
+	}{{
+		name: "HTTP 404",
+		code: http.StatusNotFound,
+		want: false,
+	}, {
+		name: "HTTP 502",
+		code: http.StatusBadGateway,
+		want: false,
+	}, {
+		name: "HTTP 503",
+		code: http.StatusServiceUnavailable,
+		want: false,
+	}, {
+		name: "HTTP 504",
+		code: http.StatusGatewayTimeout,
+		want: true,
+	}, {
+		name: "HTTP 302",
+		code: http.StatusFound,
+		want: true,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, _ := verifier(&http.Response{StatusCode: c.code}, nil)
+			if got != c.want {
+				t.Errorf("got: %v, want: %v", got, c.want)
+			}
+		})
+	}
+}
+
 type fakeProbeTargetLister struct {
 	PodIPs  sets.Set[string]
 	PodPort string
@@ -896,3 +2288,41 @@ type notFoundLister struct{}
 func (l notFoundLister) BackendsToProbeTargets(context.Context, Backends) ([]ProbeTarget, error) {
 	return nil, errors.New("not found")
 }
+
+func TestNewProberRateLimiter(t *testing.T) {
+	prober := NewProber(zaptest.NewLogger(t).Sugar(), nil, nil, RateLimiterConfig{
+		QPS:       1,
+		Burst:     2,
+		BaseDelay: time.Nanosecond,
+		MaxDelay:  4 * time.Nanosecond,
+	})
+
+	// The exponential backoff of an item is bounded by the delays, while the
+	// burst isn't exhausted
+	for i, want := range []time.Duration{time.Nanosecond, 2 * time.Nanosecond} {
+		if got := prober.rateLimiter.When("item"); got != want {
+			t.Errorf("When() #%d = %v, want: %v", i, got, want)
+		}
+	}
+
+	// Once the burst is exhausted the probes are limited to the QPS
+	if got := prober.rateLimiter.When("item"); got < 900*time.Millisecond {
+		t.Errorf("When() after the burst = %v, want: ~1s", got)
+	}
+}
+
+func TestNewProberRateLimiterJitter(t *testing.T) {
+	prober := NewProber(zaptest.NewLogger(t).Sugar(), nil, nil, RateLimiterConfig{
+		QPS:       1000,
+		Burst:     1000,
+		BaseDelay: time.Second,
+		MaxDelay:  time.Minute,
+		Jitter:    0.5,
+	})
+
+	for item := range 10 {
+		if got := prober.rateLimiter.When(item); got < time.Second || got > 1500*time.Millisecond {
+			t.Errorf("When(%d) = %v, want between 1s and 1.5s", item, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/gateway-api/pkg/features"
+
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/resources"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/webhook/resourcesemantics"
+)
+
+// Ingress validates the Ingresses of the gateway-api class, rejecting the
+// configurations that can't be implemented with the configured Gateways
+// before they reach the reconciler. The Ingresses of the other classes are
+// left alone.
+type Ingress struct {
+	v1alpha1.Ingress
+}
+
+var _ resourcesemantics.GenericCRD = (*Ingress)(nil)
+
+// Validate implements apis.Validatable
+func (i *Ingress) Validate(ctx context.Context) *apis.FieldError {
+	if networking.GetIngressClass(i.GetAnnotations()) != resources.IngressClassName {
+		return nil
+	}
+
+	errs := i.Ingress.Validate(ctx)
+	errs = errs.Also(validateRewriteHosts(&i.Spec).ViaField("spec"))
+	if err := resources.ValidateRedirect(&i.Ingress); err != nil {
+		errs = errs.Also(apis.ErrGeneric(err.Error(), resources.RedirectAnnotationKey).ViaField("metadata", "annotations"))
+	}
+	if err := resources.ValidatePreserveHost(&i.Ingress); err != nil {
+		errs = errs.Also(apis.ErrGeneric(err.Error(), resources.PreserveHostAnnotationKey).ViaField("metadata", "annotations"))
+	}
+	if err := resources.ValidateMirror(&i.Ingress); err != nil {
+		errs = errs.Also(apis.ErrGeneric(err.Error(), resources.MirrorBackendAnnotationKey).ViaField("metadata", "annotations"))
+	}
+	if err := resources.ValidateSessionPersistence(&i.Ingress); err != nil {
+		errs = errs.Also(apis.ErrGeneric(err.Error(), resources.SessionCookieAnnotationKey).ViaField("metadata", "annotations"))
+	}
+	if err := resources.ValidateHeaderRemovals(&i.Ingress); err != nil {
+		errs = errs.Also(apis.ErrGeneric(err.Error(), resources.RemoveRequestHeadersAnnotationKey).ViaField("metadata", "annotations"))
+	}
+	if resources.IsTLSPassthrough(&i.Ingress) {
+		gateway := config.FromContext(ctx).GatewayPlugin.ExternalGateway()
+		if !gateway.SupportedFeatures.Has(features.SupportTLSRoute) {
+			errs = errs.Also(apis.ErrGeneric(
+				"TLS passthrough is not supported by the Gateway "+gateway.NamespacedName.String(),
+				resources.TLSModeAnnotationKey,
+			).ViaField("metadata", "annotations"))
+		}
+	}
+	return errs
+}
+
+// validateRewriteHosts checks the hosts the paths are rewritten to are valid
+// HTTPRoute hostnames.
+func validateRewriteHosts(spec *v1alpha1.IngressSpec) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, rule := range spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for j, path := range rule.HTTP.Paths {
+			if path.RewriteHost == "" {
+				continue
+			}
+			if msgs := validation.IsDNS1123Subdomain(path.RewriteHost); len(msgs) > 0 {
+				errs = errs.Also(apis.ErrInvalidValue(path.RewriteHost, "rewriteHost", strings.Join(msgs, ", ")).
+					ViaFieldIndex("paths", j).ViaField("http").ViaFieldIndex("rules", i))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/gateway-api/pkg/features"
+
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/config"
+	"knative.dev/net-gateway-api/pkg/reconciler/ingress/resources"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestIngressValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		ing      *v1alpha1.Ingress
+		features []features.FeatureName
+		want     string
+	}{{
+		name: "valid",
+		ing:  ingress(),
+	}, {
+		name: "other class",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[networking.IngressClassAnnotationKey] = "istio.ingress.networking.knative.dev"
+			i.Spec.Rules[0].HTTP.Paths[0].Splits[0].Percent = 50
+		}),
+	}, {
+		name: "splits not summing to 100",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Spec.Rules[0].HTTP.Paths[0].Splits = append(i.Spec.Rules[0].HTTP.Paths[0].Splits, v1alpha1.IngressBackendSplit{
+				IngressBackend: v1alpha1.IngressBackend{
+					ServiceName:      "second-revision",
+					ServiceNamespace: "ns",
+					ServicePort:      intstr.FromInt32(123),
+				},
+				Percent: 10,
+			})
+		}),
+		want: "traffic split percentage must total to 100, but was 110: spec.rules[0].http.paths[0].splits",
+	}, {
+		name: "invalid rewrite host",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Spec.Rules[0].HTTP.Paths[0].RewriteHost = "Not_A_Host"
+		}),
+		want: "invalid value: Not_A_Host: spec.rules[0].http.paths[0].rewriteHost\n" +
+			"a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', " +
+			"and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is " +
+			"'[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')",
+	}, {
+		name: "valid rewrite host",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Spec.Rules[0].HTTP.Paths[0].RewriteHost = "goo.ns.svc.cluster.local"
+		}),
+	}, {
+		name: "redirect",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.RedirectAnnotationKey] = "https://new.example.com/new-path"
+		}),
+	}, {
+		name: "invalid redirect",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.RedirectAnnotationKey] = "ftp://new.example.com"
+		}),
+		want: `annotation "gateway-api.networking.knative.dev/redirect" has an invalid scheme "ftp", must be http or https: ` +
+			"metadata.annotations.gateway-api.networking.knative.dev/redirect",
+	}, {
+		name: "redirect with mirror backend",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.RedirectAnnotationKey] = "/new-path"
+			i.Annotations[resources.MirrorBackendAnnotationKey] = "canary:80"
+		}),
+		want: `annotation "gateway-api.networking.knative.dev/redirect" can't be used with "gateway-api.networking.knative.dev/mirror-backend": ` +
+			"metadata.annotations.gateway-api.networking.knative.dev/redirect",
+	}, {
+		name: "preserve host",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.PreserveHostAnnotationKey] = "true"
+		}),
+	}, {
+		name: "invalid preserve host",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.PreserveHostAnnotationKey] = "yes"
+		}),
+		want: `annotation "gateway-api.networking.knative.dev/preserve-host" must be a boolean, was: "yes": ` +
+			"metadata.annotations.gateway-api.networking.knative.dev/preserve-host",
+	}, {
+		name: "preserve host with rewrite host",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.PreserveHostAnnotationKey] = "true"
+			i.Spec.Rules[0].HTTP.Paths[0].RewriteHost = "goo.ns.svc.cluster.local"
+		}),
+	}, {
+		name: "preserve host with rewrite host of several hosts",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.PreserveHostAnnotationKey] = "true"
+			i.Spec.Rules[0].Hosts = append(i.Spec.Rules[0].Hosts, "bar.example.com")
+			i.Spec.Rules[0].HTTP.Paths[0].RewriteHost = "goo.ns.svc.cluster.local"
+		}),
+		want: `rewrite host of an Ingress preserving the host: annotation "gateway-api.networking.knative.dev/preserve-host" ` +
+			`can't be used with the rewrite host "goo.ns.svc.cluster.local" of a rule with several hosts: ` +
+			"metadata.annotations.gateway-api.networking.knative.dev/preserve-host",
+	}, {
+		name: "mirror",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.MirrorBackendAnnotationKey] = "canary:80"
+			i.Annotations[resources.MirrorPercentAnnotationKey] = "25"
+		}),
+	}, {
+		name: "invalid mirror percent",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.MirrorBackendAnnotationKey] = "canary:80"
+			i.Annotations[resources.MirrorPercentAnnotationKey] = "101"
+		}),
+		want: `annotation "gateway-api.networking.knative.dev/mirror-percent" must be an integer between 0 and 100, was: "101": ` +
+			"metadata.annotations.gateway-api.networking.knative.dev/mirror-backend",
+	}, {
+		name: "mirror to a backend of the Ingress",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.MirrorBackendAnnotationKey] = "goo:80"
+		}),
+		want: `mirror backend "goo" already receives traffic from the Ingress: ` +
+			"metadata.annotations.gateway-api.networking.knative.dev/mirror-backend",
+	}, {
+		name: "session persistence",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.SessionCookieAnnotationKey] = "session"
+			i.Annotations[resources.SessionLifetimeAnnotationKey] = "1h"
+		}),
+	}, {
+		name: "invalid session lifetime",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.SessionCookieAnnotationKey] = "session"
+			i.Annotations[resources.SessionLifetimeAnnotationKey] = "1d"
+		}),
+		want: `annotation "gateway-api.networking.knative.dev/session-lifetime" must be a positive duration such as 1h or 30m, was: "1d": ` +
+			"metadata.annotations.gateway-api.networking.knative.dev/session-cookie",
+	}, {
+		name: "header removals",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.RemoveRequestHeadersAnnotationKey] = `{"goo": ["X-Debug"]}`
+			i.Annotations[resources.RemoveResponseHeadersAnnotationKey] = `{"goo": ["Server"]}`
+		}),
+	}, {
+		name: "header removal of a probe header",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.RemoveRequestHeadersAnnotationKey] = `{"goo": ["K-Network-Probe"]}`
+		}),
+		want: `annotation "gateway-api.networking.knative.dev/remove-request-headers" must not remove "K-Network-Probe", it is used for probing: ` +
+			"metadata.annotations.gateway-api.networking.knative.dev/remove-request-headers",
+	}, {
+		name: "unsupported TLS passthrough",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.TLSModeAnnotationKey] = resources.TLSModePassthrough
+		}),
+		want: "TLS passthrough is not supported by the Gateway istio-system/knative-gateway: " +
+			"metadata.annotations.gateway-api.networking.knative.dev/tls-mode",
+	}, {
+		name: "supported TLS passthrough",
+		ing: ingress(func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.TLSModeAnnotationKey] = resources.TLSModePassthrough
+		}),
+		features: []features.FeatureName{features.SupportTLSRoute},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := config.ToContext(context.Background(), &config.Config{
+				GatewayPlugin: &config.GatewayPlugin{
+					ExternalGateways: []config.Gateway{{
+						NamespacedName: types.NamespacedName{
+							Namespace: "istio-system",
+							Name:      "knative-gateway",
+						},
+						SupportedFeatures: sets.New(tc.features...),
+					}},
+				},
+			})
+
+			got := (&Ingress{Ingress: *tc.ing}).Validate(ctx)
+			if tc.want == "" {
+				if got != nil {
+					t.Error("Validate() =", got)
+				}
+			} else if got.Error() != tc.want {
+				t.Errorf("Validate() = %q, want: %q", got.Error(), tc.want)
+			}
+		})
+	}
+}
+
+func ingress(opts ...func(*v1alpha1.Ingress)) *v1alpha1.Ingress {
+	ing := &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "ns",
+			Annotations: map[string]string{
+				networking.IngressClassAnnotationKey: resources.IngressClassName,
+			},
+		},
+		Spec: v1alpha1.IngressSpec{
+			Rules: []v1alpha1.IngressRule{{
+				Hosts:      []string{"foo.example.com"},
+				Visibility: v1alpha1.IngressVisibilityExternalIP,
+				HTTP: &v1alpha1.HTTPIngressRuleValue{
+					Paths: []v1alpha1.HTTPIngressPath{{
+						Splits: []v1alpha1.IngressBackendSplit{{
+							IngressBackend: v1alpha1.IngressBackend{
+								ServiceName:      "goo",
+								ServiceNamespace: "ns",
+								ServicePort:      intstr.FromInt32(123),
+							},
+							Percent: 100,
+						}},
+					}},
+				},
+			}},
+			HTTPOption: v1alpha1.HTTPOptionEnabled,
+		},
+	}
+	for _, opt := range opts {
+		opt(ing)
+	}
+	return ing
+}